@@ -1,12 +1,14 @@
 package main
 
 import (
+	"encoding/csv"
 	"fmt"
 	"math/big"
 	"os"
 	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
@@ -59,14 +61,44 @@ Available settings:
      minstorageprice:           currency / TB / Month
      minuploadbandwidthprice:   currency / TB
 
+     pricingautopilot:        boolean
+     pricingtargetpercentile: number above 0, up to 100
+
+     contractpriceceiling:          currency
+     contractpricefloor:            currency
+     downloadbandwidthpriceceiling: currency / TB
+     downloadbandwidthpricefloor:   currency / TB
+     storagepriceceiling:           currency / TB / Month
+     storagepricefloor:             currency / TB / Month
+     uploadbandwidthpriceceiling:   currency / TB
+     uploadbandwidthpricefloor:     currency / TB
+
+     maxdownloadspeed:       bytes / second
+     maxuploadspeed:         bytes / second
+     maxrenterdownloadspeed: bytes / second
+     maxrenteruploadspeed:   bytes / second
+
+     sectorcachesize: bytes
+
 Currency units can be specified, e.g. 10SC; run 'siac help wallet' for details.
 
+Sizes and speeds can be specified with units, e.g. 10MB; a speed of 0 means
+that the bandwidth is not limited. The renter speeds apply to each renter
+separately.
+
 Durations (maxduration and windowsize) must be specified in either blocks (b),
 hours (h), days (d), or weeks (w). A block is approximately 10 minutes, so one
 hour is six blocks, a day is 144 blocks, and a week is 1008 blocks.
 
 For a description of each parameter, see doc/API.md.
 
+When pricingautopilot is enabled, the host periodically moves its minimum
+prices toward the pricingtargetpercentile of the prices of the other hosts,
+staying between the floor and ceiling of each price. A ceiling of 0 means that
+the price has no upper bound. The prices of the other hosts come from the
+renter's hostdb if siad runs a renter, and from the hosts announced in the
+blockchain otherwise.
+
 To configure the host to accept new contracts, set acceptingcontracts to true:
 	siac host config acceptingcontracts true
 `,
@@ -74,8 +106,9 @@ To configure the host to accept new contracts, set acceptingcontracts to true:
 	}
 
 	hostContractCmd = &cobra.Command{
-		Use:   "contracts",
-		Short: "Show host contracts",
+		Use:     "contracts",
+		Aliases: []string{"contract"},
+		Short:   "Show host contracts",
 		Long: `Show host contracts sorted by expiration height.
 
 Available output types:
@@ -85,6 +118,16 @@ Available output types:
 		Run: wrap(hostcontractcmd),
 	}
 
+	hostContractViewCmd = &cobra.Command{
+		Use:   "view [id]",
+		Short: "Show the details of a host contract",
+		Long: `Show everything the host knows about a contract: its revisions, the sectors
+it stores and where they are stored, the block heights at which the host will
+next act on the contract, the transactions of the contract and the history of
+its status.`,
+		Run: wrap(hostcontractviewcmd),
+	}
+
 	hostFolderAddCmd = &cobra.Command{
 		Use:   "add [path] [size]",
 		Short: "Add a storage folder to the host",
@@ -94,8 +137,17 @@ Available output types:
 
 	hostFolderCmd = &cobra.Command{
 		Use:   "folder",
-		Short: "Add, remove, or resize a storage folder",
-		Long:  "Add, remove, or resize a storage folder.",
+		Short: "Add, remove, move, or resize a storage folder",
+		Long:  "Add, remove, move, or resize a storage folder.",
+	}
+
+	hostFolderMoveCmd = &cobra.Command{
+		Use:   "move [path] [newpath]",
+		Short: "Move a storage folder to a new path",
+		Long: `Move a storage folder to a new path, for example on another disk. The data
+of the folder is copied to the new path and remains available while the copy
+is in progress. The progress of the move is shown by 'siac host'.`,
+		Run: wrap(hostfoldermovecmd),
 	}
 
 	hostFolderRemoveCmd = &cobra.Command{
@@ -116,6 +168,75 @@ other storage folders.`,
 		Run: wrap(hostfolderresizecmd),
 	}
 
+	hostLedgerCmd = &cobra.Command{
+		Use:   "ledger",
+		Short: "Show the financial events of the host",
+		Long: `Show the financial events of the host, oldest first. Each event is tied to
+the contract that caused it and the block height at which it happened.
+
+Event types:
+     contractformed:   a contract was formed or renewed; the value is the
+                       potential revenue paid into it up front
+     revisionrevenue:  potential revenue that was added to a contract by a
+                       revision
+     proofsuccess:     a storage proof succeeded; the value is the revenue
+                       that was realized with the contract
+     lostrevenue:      a storage proof was missed; the value is the potential
+                       revenue that was not earned
+     lostcollateral:   a storage proof was missed; the value is the collateral
+                       that was lost
+     contractrejected: a contract never made it into the blockchain; the value
+                       is its potential revenue
+     transactionfee:   transaction fees paid for a contract
+     feerefund:        transaction fees that were recorded but never paid
+
+Potential revenue is resolved exactly once when a contract ends, by a
+proofsuccess, lostrevenue or contractrejected event. The earnings are the
+proofsuccess and feerefund events minus the lostcollateral and transactionfee
+events.
+
+The --start and --end flags restrict the ledger to a time range, and are given
+as dates (2006-01-02). The range includes both days. The --csv flag prints the
+ledger as CSV with all amounts in hastings.`,
+		Run: wrap(hostledgercmd),
+	}
+
+	hostMaintenanceCmd = &cobra.Command{
+		Use:   "maintenance",
+		Short: "Show or schedule the maintenance of the host",
+		Long: `Show the maintenance window of the host. While the host is in maintenance it
+refuses new contracts, renewals and uploads, but it keeps serving downloads
+and submitting storage proofs. The host must be online by the block height
+that is shown, so that it can submit the transactions of its contracts.`,
+		Run: wrap(hostmaintenancecmd),
+	}
+
+	hostMaintenanceCancelCmd = &cobra.Command{
+		Use:   "cancel",
+		Short: "End or cancel the maintenance of the host",
+		Long:  "End the maintenance of the host, or cancel it if it has not started yet.",
+		Run:   wrap(hostmaintenancecancelcmd),
+	}
+
+	hostMaintenanceScheduleCmd = &cobra.Command{
+		Use:   "schedule",
+		Short: "Schedule the maintenance of the host",
+		Long: `Schedule the maintenance of the host. The --start and --end flags are given
+as local times (2006-01-02 15:04). Without --start the maintenance starts right
+away, without --end it lasts until it is cancelled.`,
+		Run: wrap(hostmaintenanceschedulecmd),
+	}
+
+	hostProofsCmd = &cobra.Command{
+		Use:   "proofs",
+		Short: "Show the storage proofs that are not yet confirmed",
+		Long: `Show the storage proofs that the host has yet to get confirmed. The host
+rebroadcasts pending storage proofs, and submits a new storage proof with a
+higher fee if a proof was dropped from the transaction pool. A proof is at risk
+if only a few blocks remain until the proof deadline.`,
+		Run: wrap(hostproofscmd),
+	}
+
 	hostSectorCmd = &cobra.Command{
 		Use:   "sector",
 		Short: "Add or delete a sector (add not supported)",
@@ -176,7 +297,9 @@ func hostcmd() {
 	}
 
 	var connectabilityString string
-	if hg.WorkingStatus == "working" {
+	if hg.WorkingStatus == modules.HostWorkingStatusMaintenance {
+		connectabilityString = "Host is in maintenance, refusing new contracts, renewals and uploads."
+	} else if hg.WorkingStatus == "working" {
 		connectabilityString = "Host appears to be working."
 	} else if hg.WorkingStatus == "not working" && hg.ConnectabilityStatus == "connectable" {
 		connectabilityString = "Nobody is connecting to host. Try re-announcing."
@@ -208,6 +331,17 @@ Host Internal Settings:
 	minstorageprice:           %v / TB / Month
 	minuploadbandwidthprice:   %v / TB
 
+	pricingautopilot:        %v
+	pricingtargetpercentile: %v
+	price adjustments:       %v
+
+	maxdownloadspeed:       %v / s
+	maxuploadspeed:         %v / s
+	maxrenterdownloadspeed: %v / s
+	maxrenteruploadspeed:   %v / s
+
+	sectorcachesize: %v
+
 Host Financials:
 	Contract Count:               %v
 	Transaction Fee Compensation: %v
@@ -251,6 +385,14 @@ RPC Stats:
 			currencyUnits(is.MinStoragePrice.Mul(modules.BlockBytesPerMonthTerabyte)),
 			currencyUnits(is.MinUploadBandwidthPrice.Mul(modules.BytesPerTerabyte)),
 
+			yesNo(is.PricingAutopilot), is.PricingTargetPercentile,
+			len(hg.PriceAdjustments),
+
+			filesizeUnits(is.MaxDownloadSpeed), filesizeUnits(is.MaxUploadSpeed),
+			filesizeUnits(is.MaxRenterDownloadSpeed), filesizeUnits(is.MaxRenterUploadSpeed),
+
+			filesizeUnits(int64(is.SectorCacheSize)),
+
 			fm.ContractCount, currencyUnits(fm.ContractCompensation),
 			currencyUnits(fm.PotentialContractCompensation),
 			currencyUnits(fm.TransactionFeeExpenses),
@@ -303,6 +445,25 @@ RPC Stats:
 		fmt.Println("\nWarning:\n	Your wallet is locked. You must unlock your wallet for the host to function properly.")
 	}
 
+	// if storage proofs risk missing their window print warning
+	hpg, err := httpClient.HostProofsGet()
+	if err == nil {
+		var atRisk int
+		for _, p := range hpg.Proofs {
+			if p.AtRisk {
+				atRisk++
+			}
+		}
+		if atRisk > 0 {
+			fmt.Printf("\nWarning:\n	%v storage proofs risk missing their proof window. Run 'siac host proofs' for details.\n", atRisk)
+		}
+	}
+
+	if hostVerbose && sg.SectorCache.Capacity > 0 {
+		sc := sg.SectorCache
+		fmt.Printf("\nSector Cache:\n	%v of %v used by %v sectors, %.2f%% hit rate\n", filesizeUnits(int64(sc.Size)), filesizeUnits(int64(sc.Capacity)), sc.Sectors, 100*sc.HitRate)
+	}
+
 	fmt.Println("\nStorage Folders:")
 
 	// display storage folder info
@@ -321,6 +482,21 @@ RPC Stats:
 		fmt.Fprintf(w, "\t%s\t%s\t%.2f\t%s\n", filesizeUnits(curSize), filesizeUnits(int64(folder.Capacity)), pctUsed, folder.Path)
 	}
 	w.Flush()
+
+	// show the progress of folders that are being added, resized or moved
+	for _, folder := range sg.Folders {
+		if folder.ProgressDenominator != 0 {
+			pct := 100 * float64(folder.ProgressNumerator) / float64(folder.ProgressDenominator)
+			fmt.Printf("\nStorage folder %v is being updated, %.2f%% complete.\n", folder.Path, pct)
+		}
+	}
+
+	// warn about sectors that failed the integrity scrub
+	for _, folder := range sg.Folders {
+		if folder.CorruptSectors != 0 || folder.MissingSectors != 0 {
+			fmt.Printf("\nWarning:\n	Storage folder %v has %v corrupt and %v missing sectors. Contracts storing these sectors may fail their storage proofs.\n", folder.Path, folder.CorruptSectors, folder.MissingSectors)
+		}
+	}
 }
 
 // hostconfigcmd is the handler for the command `siac host config [setting] [value]`.
@@ -329,14 +505,15 @@ func hostconfigcmd(param, value string) {
 	var err error
 	switch param {
 	// currency (convert to hastings)
-	case "collateralbudget", "maxcollateral", "mincontractprice":
+	case "collateralbudget", "maxcollateral", "mincontractprice", "contractpriceceiling", "contractpricefloor":
 		value, err = parseCurrency(value)
 		if err != nil {
 			die("Could not parse "+param+":", err)
 		}
 
 	// currency/TB (convert to hastings/byte)
-	case "mindownloadbandwidthprice", "minuploadbandwidthprice":
+	case "mindownloadbandwidthprice", "minuploadbandwidthprice", "downloadbandwidthpriceceiling",
+		"downloadbandwidthpricefloor", "uploadbandwidthpriceceiling", "uploadbandwidthpricefloor":
 		hastings, err := parseCurrency(value)
 		if err != nil {
 			die("Could not parse "+param+":", err)
@@ -346,7 +523,7 @@ func hostconfigcmd(param, value string) {
 		value = c.String()
 
 	// currency/TB/month (convert to hastings/byte/block)
-	case "collateral", "minstorageprice":
+	case "collateral", "minstorageprice", "storagepriceceiling", "storagepricefloor":
 		hastings, err := parseCurrency(value)
 		if err != nil {
 			die("Could not parse "+param+":", err)
@@ -356,7 +533,7 @@ func hostconfigcmd(param, value string) {
 		value = c.String()
 
 	// bool (allow "yes" and "no")
-	case "acceptingcontracts":
+	case "acceptingcontracts", "pricingautopilot":
 		switch strings.ToLower(value) {
 		case "yes":
 			value = "true"
@@ -364,6 +541,13 @@ func hostconfigcmd(param, value string) {
 			value = "false"
 		}
 
+	// bytes/second
+	case "maxdownloadspeed", "maxuploadspeed", "maxrenterdownloadspeed", "maxrenteruploadspeed", "sectorcachesize":
+		value, err = parseFilesize(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+
 	// duration (convert to blocks)
 	case "maxduration", "windowsize":
 		value, err = parsePeriod(value)
@@ -372,7 +556,7 @@ func hostconfigcmd(param, value string) {
 		}
 
 	// other valid settings
-	case "maxdownloadbatchsize", "maxrevisebatchsize", "netaddress":
+	case "maxdownloadbatchsize", "maxrevisebatchsize", "netaddress", "pricingtargetpercentile":
 
 	// invalid settings
 	default:
@@ -413,10 +597,10 @@ func hostcontractcmd() {
 				currencyUnits(so.RiskedCollateral), currencyUnits(potentialRevenue), so.ExpirationHeight, currencyUnits(so.TransactionFeesAdded))
 		}
 	case "status":
-		fmt.Fprintf(w, "Obligation ID\tObligation Status\tExpiration Height\tOrigin Confirmed\tRevision Constructed\tRevision Confirmed\tProof Constructed\tProof Confirmed\n")
+		fmt.Fprintf(w, "Obligation ID\tObligation Status\tExpiration Height\tOrigin Confirmed\tRevision Constructed\tRevision Confirmed\tProof Constructed\tProof Confirmed\tAt Risk\n")
 		for _, so := range cg.Contracts {
-			fmt.Fprintf(w, "%s\t%s\t%d\t%t\t%t\t%t\t%t\t%t\n", so.ObligationId, strings.TrimPrefix(so.ObligationStatus, "obligation"), so.ExpirationHeight, so.OriginConfirmed,
-				so.RevisionConstructed, so.RevisionConfirmed, so.ProofConstructed, so.ProofConfirmed)
+			fmt.Fprintf(w, "%s\t%s\t%d\t%t\t%t\t%t\t%t\t%t\t%t\n", so.ObligationId, strings.TrimPrefix(so.ObligationStatus, "obligation"), so.ExpirationHeight, so.OriginConfirmed,
+				so.RevisionConstructed, so.RevisionConfirmed, so.ProofConstructed, so.ProofConfirmed, so.AtRisk)
 		}
 	default:
 		die("\"" + hostContractOutputType + "\" is not a format")
@@ -424,6 +608,246 @@ func hostcontractcmd() {
 	w.Flush()
 }
 
+// hostcontractviewcmd is the handler for the command `siac host contract view
+// [id]`. It prints the details of a contract.
+func hostcontractviewcmd(id string) {
+	var fcid types.FileContractID
+	if err := (*crypto.Hash)(&fcid).LoadString(id); err != nil {
+		die("Could not parse contract id:", err)
+	}
+	hcg, err := httpClient.HostContractGet(fcid)
+	if err != nil {
+		die("Could not get contract:", err)
+	}
+	c := hcg.Contract
+	fmt.Printf(`Contract %v
+  Status:             %v
+  Data Size:          %v
+  Sectors:            %v
+  Negotiation Height: %v
+  Expiration Height:  %v
+  Proof Deadline:     %v
+  Origin Confirmed:   %v
+  Revision Confirmed: %v
+  Proof Confirmed:    %v
+  Contract Cost:      %v
+  Potential Revenue:  %v
+  Locked Collateral:  %v
+  Risked Collateral:  %v
+  Transaction Fees:   %v
+`, c.ObligationId, strings.TrimPrefix(c.ObligationStatus, "obligation"), filesizeUnits(int64(c.DataSize)), c.SectorRootsCount,
+		c.NegotiationHeight, c.ExpirationHeight, c.ProofDeadLine, c.OriginConfirmed, c.RevisionConfirmed, c.ProofConfirmed,
+		currencyUnits(c.ContractCost), currencyUnits(c.PotentialDownloadRevenue.Add(c.PotentialUploadRevenue).Add(c.PotentialStorageRevenue)),
+		currencyUnits(c.LockedCollateral), currencyUnits(c.RiskedCollateral), currencyUnits(c.TransactionFeesAdded))
+
+	fmt.Printf("\nAction Items: %v\n", c.ActionItems)
+
+	fmt.Println("\nRevisions:")
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  Number\tHeight\tTime\tFile Size\tMerkle Root")
+	for _, r := range c.Revisions {
+		fmt.Fprintf(w, "  %v\t%v\t%v\t%v\t%v\n", r.RevisionNumber, r.BlockHeight, r.Timestamp.Format("2006-01-02 15:04"), filesizeUnits(int64(r.FileSize)), r.FileMerkleRoot)
+	}
+	w.Flush()
+
+	fmt.Println("\nSectors:")
+	w = tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  Root\tStorage Folder\tIndex\tFailed")
+	for _, s := range c.Sectors {
+		folder := s.StorageFolder
+		if !s.Found {
+			folder = "(missing)"
+		}
+		fmt.Fprintf(w, "  %v\t%v\t%v\t%v\n", s.Root, folder, s.Index, s.Failed)
+	}
+	w.Flush()
+
+	fmt.Println("\nTransactions:")
+	for _, set := range []struct {
+		name string
+		txns []types.Transaction
+	}{
+		{"Origin", c.OriginTransactionSet},
+		{"Revision", c.RevisionTransactionSet},
+		{"Proof", c.ProofTransactionSet},
+	} {
+		for _, txn := range set.txns {
+			fmt.Printf("  %-9v %v\n", set.name, txn.ID())
+		}
+	}
+
+	fmt.Println("\nTimeline:")
+	w = tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  Time\tHeight\tStatus\tDetail")
+	for _, t := range c.Timeline {
+		fmt.Fprintf(w, "  %v\t%v\t%v\t%v\n", t.Timestamp.Format("2006-01-02 15:04"), t.BlockHeight, t.Status, t.Detail)
+	}
+	w.Flush()
+}
+
+// hostledgercmd is the handler for the command `siac host ledger`. It prints
+// the financial events of the host within a time range.
+func hostledgercmd() {
+	start := time.Unix(0, 0)
+	end := time.Now()
+	if hostLedgerStart != "" {
+		t, err := time.ParseInLocation("2006-01-02", hostLedgerStart, time.Local)
+		if err != nil {
+			die("Could not parse start date:", err)
+		}
+		start = t
+	}
+	if hostLedgerEnd != "" {
+		t, err := time.ParseInLocation("2006-01-02", hostLedgerEnd, time.Local)
+		if err != nil {
+			die("Could not parse end date:", err)
+		}
+		// Include the whole end day.
+		end = t.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	hlg, err := httpClient.HostLedgerGet(start, end)
+	if err != nil {
+		die("Could not get ledger:", err)
+	}
+
+	if hostLedgerCSV {
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"time", "height", "type", "obligation", "value"})
+		for _, e := range hlg.Entries {
+			w.Write([]string{
+				e.Timestamp.Format(time.RFC3339),
+				fmt.Sprint(e.BlockHeight),
+				e.Type,
+				e.ObligationID.String(),
+				e.Value.String(),
+			})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			die("Could not write CSV:", err)
+		}
+		return
+	}
+
+	if len(hlg.Entries) == 0 {
+		fmt.Println("No ledger entries.")
+		return
+	}
+	totals := make(map[string]types.Currency)
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Time\tHeight\tType\tObligation ID\tValue")
+	for _, e := range hlg.Entries {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", e.Timestamp.Format("2006-01-02 15:04"), e.BlockHeight, e.Type, e.ObligationID, currencyUnits(e.Value))
+		totals[e.Type] = totals[e.Type].Add(e.Value)
+	}
+	w.Flush()
+
+	fmt.Println("\nTotals:")
+	w = tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	for _, t := range []string{modules.HostLedgerContractFormed, modules.HostLedgerRevisionRevenue, modules.HostLedgerProofSuccess, modules.HostLedgerLostRevenue, modules.HostLedgerLostCollateral, modules.HostLedgerContractRejected, modules.HostLedgerTransactionFee, modules.HostLedgerFeeRefund} {
+		fmt.Fprintf(w, "  %v:\t%v\n", t, currencyUnits(totals[t]))
+	}
+	w.Flush()
+
+	// Only realized revenue counts towards the earnings.
+	gains := totals[modules.HostLedgerProofSuccess].Add(totals[modules.HostLedgerFeeRefund])
+	costs := totals[modules.HostLedgerLostCollateral].Add(totals[modules.HostLedgerTransactionFee])
+	if gains.Cmp(costs) >= 0 {
+		fmt.Println("\nEarnings:", currencyUnits(gains.Sub(costs)))
+	} else {
+		fmt.Println("\nLosses:", currencyUnits(costs.Sub(gains)))
+	}
+}
+
+// hostmaintenancecmd is the handler for the command `siac host maintenance`.
+// It prints the maintenance window of the host.
+func hostmaintenancecmd() {
+	hmg, err := httpClient.HostMaintenanceGet()
+	if err != nil {
+		die("Could not get maintenance:", err)
+	}
+	switch {
+	case hmg.Start.IsZero():
+		fmt.Println("No maintenance scheduled.")
+	case hmg.Active:
+		fmt.Println("Host is in maintenance since", hmg.Start.Format("2006-01-02 15:04"))
+	default:
+		fmt.Println("Maintenance scheduled for", hmg.Start.Format("2006-01-02 15:04"))
+	}
+	if !hmg.Start.IsZero() {
+		if hmg.End.IsZero() {
+			fmt.Println("Maintenance lasts until it is cancelled.")
+		} else {
+			fmt.Println("Maintenance ends at", hmg.End.Format("2006-01-02 15:04"))
+		}
+	}
+	if hmg.OnlineByHeight == 0 {
+		fmt.Println("No contracts need the host to be online.")
+		return
+	}
+	fmt.Printf("Host must be online by block %v (about %v) for contract %v.\n", hmg.OnlineByHeight, hmg.OnlineByTime.Format("2006-01-02 15:04"), hmg.OnlineByObligation)
+	if !hmg.Start.IsZero() && (hmg.End.IsZero() || hmg.End.After(hmg.OnlineByTime)) {
+		fmt.Println("WARNING: the maintenance lasts past that time. The host keeps submitting storage proofs during maintenance, but only while it is online.")
+	}
+}
+
+// hostmaintenancecancelcmd is the handler for the command `siac host
+// maintenance cancel`.
+func hostmaintenancecancelcmd() {
+	err := httpClient.HostMaintenanceCancelPost()
+	if err != nil {
+		die("Could not cancel maintenance:", err)
+	}
+	fmt.Println("Maintenance cancelled.")
+}
+
+// hostmaintenanceschedulecmd is the handler for the command `siac host
+// maintenance schedule`.
+func hostmaintenanceschedulecmd() {
+	var start, end time.Time
+	for _, f := range []struct {
+		value string
+		t     *time.Time
+	}{
+		{hostMaintenanceStart, &start},
+		{hostMaintenanceEnd, &end},
+	} {
+		if f.value == "" {
+			continue
+		}
+		t, err := time.ParseInLocation("2006-01-02 15:04", f.value, time.Local)
+		if err != nil {
+			die("Could not parse time:", err)
+		}
+		*f.t = t
+	}
+	err := httpClient.HostMaintenancePost(start, end)
+	if err != nil {
+		die("Could not schedule maintenance:", err)
+	}
+	fmt.Println("Maintenance scheduled.")
+	hostmaintenancecmd()
+}
+
+// hostproofscmd is the handler for the command `siac host proofs`. It prints
+// the storage proofs that the host has yet to get confirmed.
+func hostproofscmd() {
+	hpg, err := httpClient.HostProofsGet()
+	if err != nil {
+		die("Could not get storage proofs:", err)
+	}
+	if len(hpg.Proofs) == 0 {
+		fmt.Println("No storage proofs are waiting for confirmation.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintln(w, "Obligation Id\tAttempts\tFee\tPending\tSubmission Height\tProof Deadline\tBlocks Remaining\tAt Risk")
+	for _, p := range hpg.Proofs {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", p.ObligationId, p.Attempts, currencyUnits(p.Fee), yesNo(p.Pending), p.SubmissionHeight, p.ProofDeadline, p.BlocksRemaining, yesNo(p.AtRisk))
+	}
+	w.Flush()
+}
+
 // hostannouncecmd is the handler for the command `siac host announce`.
 // Announces yourself as a host to the network. Optionally takes an address to
 // announce as.
@@ -472,6 +896,15 @@ func hostfolderaddcmd(path, size string) {
 	fmt.Println("Added folder", path)
 }
 
+// hostfoldermovecmd moves a folder of the host to a new path.
+func hostfoldermovecmd(path, newpath string) {
+	err := httpClient.HostStorageFoldersMovePost(abs(path), abs(newpath))
+	if err != nil {
+		die("Could not move folder:", err)
+	}
+	fmt.Printf("Moved folder %v to %v\n", path, newpath)
+}
+
 // hostfolderremovecmd removes a folder from the host.
 func hostfolderremovecmd(path string) {
 	err := httpClient.HostStorageFoldersRemovePost(abs(path))
@@ -5,6 +5,7 @@ import (
 	"math/big"
 	"os"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -44,6 +45,7 @@ func printScoreBreakdown(info *api.HostdbHostsGET) {
 	fmt.Fprintf(w, "\t\tBurn:\t %.3f\n", info.ScoreBreakdown.BurnAdjustment)
 	fmt.Fprintf(w, "\t\tCollateral:\t %.3f\n", info.ScoreBreakdown.CollateralAdjustment)
 	fmt.Fprintf(w, "\t\tInteraction:\t %.3f\n", info.ScoreBreakdown.InteractionAdjustment)
+	fmt.Fprintf(w, "\t\tPerformance:\t %.3f\n", info.ScoreBreakdown.PerformanceAdjustment)
 	fmt.Fprintf(w, "\t\tPrice:\t %.3f\n", info.ScoreBreakdown.PriceAdjustment*1e6)
 	fmt.Fprintf(w, "\t\tStorage:\t %.3f\n", info.ScoreBreakdown.StorageRemainingAdjustment)
 	fmt.Fprintf(w, "\t\tUptime:\t %.3f\n", info.ScoreBreakdown.UptimeAdjustment)
@@ -326,5 +328,18 @@ func hostdbviewcmd(pubkey string) {
 	fmt.Println("\n  Scan History Length:", len(info.Entry.ScanHistory))
 	fmt.Printf("  Overall Uptime:      %.3f\n", uptimeRatio)
 
+	// Display the benchmark history, most recent first.
+	if len(info.Entry.BenchmarkHistory) > 0 {
+		fmt.Println("\n  Benchmark History:")
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\t\tTime\tLatency\tUpload\tDownload")
+		for i := len(info.Entry.BenchmarkHistory) - 1; i >= 0; i-- {
+			b := info.Entry.BenchmarkHistory[i]
+			fmt.Fprintf(w, "\t\t%v\t%v\t%v/s\t%v/s\n", b.Timestamp.Format("Jan 02 15:04"), b.Latency.Round(time.Millisecond),
+				filesizeUnits(int64(b.UploadThroughput)), filesizeUnits(int64(b.DownloadThroughput)))
+		}
+		w.Flush()
+	}
+
 	fmt.Println()
 }
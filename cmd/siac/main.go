@@ -13,14 +13,36 @@ import (
 
 var (
 	// Flags.
-	hostContractOutputType string // output type for host contracts
-	hostVerbose            bool   // display additional host info
-	initForce              bool   // destroy and re-encrypt the wallet on init if it already exists
-	initPassword           bool   // supply a custom password when creating a wallet
-	renterAllContracts     bool   // Show all active and expired contracts
-	renterDownloadAsync    bool   // Downloads files asynchronously
-	renterListVerbose      bool   // Show additional info about uploaded files.
-	renterShowHistory      bool   // Show download history in addition to download queue.
+	hostContractOutputType   string // output type for host contracts
+	hostLedgerCSV            bool   // Print the ledger as CSV.
+	hostLedgerEnd            string // Last day of the ledger to show.
+	hostLedgerStart          string // First day of the ledger to show.
+	hostMaintenanceEnd       string // End of the maintenance window.
+	hostMaintenanceStart     string // Start of the maintenance window.
+	hostVerbose              bool   // display additional host info
+	initForce                bool   // destroy and re-encrypt the wallet on init if it already exists
+	initPassword             bool   // supply a custom password when creating a wallet
+	renterAllContracts       bool   // Show all active and expired contracts
+	renterDownloadAsync      bool   // Downloads files asynchronously
+	renterDownloadExclude    string // Hosts to exclude from a download.
+	renterDownloadOverdrive  int    // Max number of extra hosts used in parallel for a download.
+	renterDownloadPrefer     string // Hosts to prefer for a download.
+	renterExpectedDownload   string // Expected download bandwidth per month for the allowance.
+	renterExpectedRedundancy string // Expected redundancy for the allowance.
+	renterExpectedStorage    string // Expected storage for the allowance.
+	renterExpectedUpload     string // Expected upload bandwidth per month for the allowance.
+	renterListVerbose        bool   // Show additional info about uploaded files.
+	renterMaxContractPrice   string // Max contract price for the allowance.
+	renterMaxDownloadPrice   string // Max download bandwidth price for the allowance.
+	renterMaxStoragePrice    string // Max storage price for the allowance.
+	renterMaxUploadPrice     string // Max upload bandwidth price for the allowance.
+	renterMinCollateral      string // Min host collateral for the allowance.
+	renterShowHistory        bool   // Show download history in addition to download queue.
+	renterSpendingBy         string // Break the spending down by host, contract or period.
+	renterSpendingCSV        bool   // Print the spending as CSV.
+	renterSpendingEnd        string // Last day of the spending to show.
+	renterSpendingHost       string // Host to show the spending of.
+	renterSpendingStart      string // First day of the spending to show.
 )
 
 var (
@@ -90,11 +112,18 @@ func main() {
 	updateCmd.AddCommand(updateCheckCmd)
 
 	root.AddCommand(hostCmd)
-	hostCmd.AddCommand(hostConfigCmd, hostAnnounceCmd, hostFolderCmd, hostContractCmd, hostSectorCmd)
-	hostFolderCmd.AddCommand(hostFolderAddCmd, hostFolderRemoveCmd, hostFolderResizeCmd)
+	hostCmd.AddCommand(hostConfigCmd, hostAnnounceCmd, hostFolderCmd, hostContractCmd, hostLedgerCmd, hostMaintenanceCmd, hostProofsCmd, hostSectorCmd)
+	hostContractCmd.AddCommand(hostContractViewCmd)
+	hostFolderCmd.AddCommand(hostFolderAddCmd, hostFolderMoveCmd, hostFolderRemoveCmd, hostFolderResizeCmd)
 	hostSectorCmd.AddCommand(hostSectorDeleteCmd)
 	hostCmd.Flags().BoolVarP(&hostVerbose, "verbose", "v", false, "Display detailed host info")
 	hostContractCmd.Flags().StringVarP(&hostContractOutputType, "type", "t", "value", "Select output type")
+	hostLedgerCmd.Flags().BoolVarP(&hostLedgerCSV, "csv", "", false, "Print the ledger as CSV")
+	hostLedgerCmd.Flags().StringVarP(&hostLedgerEnd, "end", "", "", "Last day of the ledger to show")
+	hostLedgerCmd.Flags().StringVarP(&hostLedgerStart, "start", "", "", "First day of the ledger to show")
+	hostMaintenanceCmd.AddCommand(hostMaintenanceCancelCmd, hostMaintenanceScheduleCmd)
+	hostMaintenanceScheduleCmd.Flags().StringVarP(&hostMaintenanceEnd, "end", "", "", "End of the maintenance")
+	hostMaintenanceScheduleCmd.Flags().StringVarP(&hostMaintenanceStart, "start", "", "", "Start of the maintenance")
 
 	root.AddCommand(hostdbCmd)
 	hostdbCmd.AddCommand(hostdbViewCmd)
@@ -120,16 +149,34 @@ func main() {
 		renterDownloadsCmd, renterAllowanceCmd, renterSetAllowanceCmd,
 		renterContractsCmd, renterFilesListCmd, renterFilesRenameCmd,
 		renterFilesUploadCmd, renterUploadsCmd, renterExportCmd,
-		renterPricesCmd)
+		renterPricesCmd, renterSpendingCmd, renterWorkersCmd)
 
-	renterContractsCmd.AddCommand(renterContractsViewCmd)
+	renterContractsCmd.AddCommand(renterContractsViewCmd, renterContractsFormCmd,
+		renterContractsRenewCmd, renterContractsCancelCmd, renterContractsStopRenewingCmd)
 	renterAllowanceCmd.AddCommand(renterAllowanceCancelCmd)
 
 	renterCmd.Flags().BoolVarP(&renterListVerbose, "verbose", "v", false, "Show additional file info such as redundancy")
 	renterContractsCmd.Flags().BoolVarP(&renterAllContracts, "all", "A", false, "Show all expired contracts in addition to active contracts")
 	renterDownloadsCmd.Flags().BoolVarP(&renterShowHistory, "history", "H", false, "Show download history in addition to the download queue")
 	renterFilesDownloadCmd.Flags().BoolVarP(&renterDownloadAsync, "async", "A", false, "Download file asynchronously")
+	renterFilesDownloadCmd.Flags().StringVarP(&renterDownloadExclude, "exclude-hosts", "", "", "Comma separated public keys of hosts to not download from")
+	renterFilesDownloadCmd.Flags().IntVarP(&renterDownloadOverdrive, "max-overdrive", "", -1, "Max number of extra hosts to download from in parallel")
+	renterFilesDownloadCmd.Flags().StringVarP(&renterDownloadPrefer, "prefer-hosts", "", "", "Comma separated public keys of hosts to download from first")
 	renterFilesListCmd.Flags().BoolVarP(&renterListVerbose, "verbose", "v", false, "Show additional file info such as redundancy")
+	renterSetAllowanceCmd.Flags().StringVarP(&renterMaxContractPrice, "max-contract-price", "", "", "Max contract price a host may charge")
+	renterSetAllowanceCmd.Flags().StringVarP(&renterMaxDownloadPrice, "max-download-price", "", "", "Max download bandwidth price per TB a host may charge")
+	renterSetAllowanceCmd.Flags().StringVarP(&renterMaxStoragePrice, "max-storage-price", "", "", "Max storage price per TB per month a host may charge")
+	renterSetAllowanceCmd.Flags().StringVarP(&renterMaxUploadPrice, "max-upload-price", "", "", "Max upload bandwidth price per TB a host may charge")
+	renterSetAllowanceCmd.Flags().StringVarP(&renterMinCollateral, "min-collateral", "", "", "Min collateral per TB per month a host must offer")
+	renterSetAllowanceCmd.Flags().StringVarP(&renterExpectedStorage, "expected-storage", "", "", "Amount of data the renter expects to store")
+	renterSetAllowanceCmd.Flags().StringVarP(&renterExpectedUpload, "expected-upload", "", "", "Amount of data the renter expects to upload per month")
+	renterSetAllowanceCmd.Flags().StringVarP(&renterExpectedDownload, "expected-download", "", "", "Amount of data the renter expects to download per month")
+	renterSetAllowanceCmd.Flags().StringVarP(&renterExpectedRedundancy, "expected-redundancy", "", "", "Redundancy the renter expects to upload files with")
+	renterSpendingCmd.Flags().StringVarP(&renterSpendingBy, "by", "", "host", "Break the spending down by host, contract or period")
+	renterSpendingCmd.Flags().BoolVarP(&renterSpendingCSV, "csv", "", false, "Print the spending as CSV")
+	renterSpendingCmd.Flags().StringVarP(&renterSpendingEnd, "end", "", "", "Last day of the spending to show")
+	renterSpendingCmd.Flags().StringVarP(&renterSpendingHost, "host", "", "", "Only show the spending of this host")
+	renterSpendingCmd.Flags().StringVarP(&renterSpendingStart, "start", "", "", "First day of the spending to show")
 	renterExportCmd.AddCommand(renterExportContractTxnsCmd)
 
 	root.AddCommand(gatewayCmd)
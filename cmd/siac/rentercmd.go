@@ -24,6 +24,7 @@ package main
 // few minutes. We should change the download speed to use a rolling average.
 
 import (
+	"encoding/csv"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -36,6 +37,7 @@ import (
 	"github.com/NebulousLabs/errors"
 	"github.com/spf13/cobra"
 
+	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/node/api"
 	"github.com/NebulousLabs/Sia/types"
@@ -63,6 +65,15 @@ var (
 		Run:   wrap(rentercmd),
 	}
 
+	renterContractsCancelCmd = &cobra.Command{
+		Use:   "cancel [contract-id]",
+		Short: "Cancel a contract",
+		Long: `Cancel the specified contract. A canceled contract is not renewed or
+used for uploads anymore, but its data can be downloaded until the contract
+expires. The renter will form a new contract to replace it.`,
+		Run: wrap(rentercontractscancelcmd),
+	}
+
 	renterContractsCmd = &cobra.Command{
 		Use:   "contracts",
 		Short: "View the Renter's contracts",
@@ -70,6 +81,33 @@ var (
 		Run:   wrap(rentercontractscmd),
 	}
 
+	renterContractsFormCmd = &cobra.Command{
+		Use:   "form [host-public-key] [funds] [end-height]",
+		Short: "Form a contract with a host",
+		Long: `Form a contract with the specified host. The contract is funded from the
+allowance. If no end height is provided, the contract ends with the current
+period.`,
+		Run: rentercontractsformcmd,
+	}
+
+	renterContractsRenewCmd = &cobra.Command{
+		Use:   "renew [contract-id] [funds]",
+		Short: "Renew a contract now",
+		Long: `Renew the specified contract without waiting for the renew window. The
+renewal is funded from the allowance. If no funds are provided, the renter
+estimates the funds that the contract needs.`,
+		Run: rentercontractsrenewcmd,
+	}
+
+	renterContractsStopRenewingCmd = &cobra.Command{
+		Use:   "stoprenewing [contract-id]",
+		Short: "Stop renewing a contract",
+		Long: `Stop renewing the specified contract. The contract can still be used for
+uploads until it reaches the renew window, and its data can be downloaded until
+the contract expires.`,
+		Run: wrap(rentercontractsstoprenewingcmd),
+	}
+
 	renterContractsViewCmd = &cobra.Command{
 		Use:   "view [contract-id]",
 		Short: "View details of the specified contract",
@@ -95,8 +133,13 @@ var (
 	renterFilesDownloadCmd = &cobra.Command{
 		Use:   "download [path] [destination]",
 		Short: "Download a file",
-		Long:  "Download a previously-uploaded file to a specified destination.",
-		Run:   wrap(renterfilesdownloadcmd),
+		Long: `Download a previously-uploaded file to a specified destination.
+
+The --exclude-hosts and --prefer-hosts flags take comma separated host public
+keys. Excluded hosts are never asked for pieces, preferred hosts are asked
+before all other hosts. The --max-overdrive flag caps the number of extra
+hosts that are asked for pieces in parallel.`,
+		Run: wrap(renterfilesdownloadcmd),
 	}
 
 	renterFilesListCmd = &cobra.Command{
@@ -148,18 +191,54 @@ Allowance can be automatically renewed periodically. If the current
 blockheight + the renew window >= the end height the contract,
 then the contract is renewed automatically.
 
+The optional price limit flags restrict which hosts the renter will form
+contracts with. Storage prices and the collateral floor are given in currency
+per TB per month, bandwidth prices in currency per TB, and the contract price
+in currency. Limits that are not specified keep their current value, and a
+limit of 0 disables it.
+
+The optional expected usage flags tell the renter how the allowance is going
+to be used, so that contracts are funded and hosts are chosen accordingly.
+Expected storage is given as a filesize (e.g. 1TB), expected uploads and
+downloads as a filesize per month. Values that are not specified keep their
+current value, and a value of 0 removes the expectation.
+
 Note that setting the allowance will cause siad to immediately begin forming
 contracts! You should only set the allowance once you are fully synced and you
 have a reasonable number (>30) of hosts in your hostdb.`,
 		Run: rentersetallowancecmd,
 	}
 
+	renterSpendingCmd = &cobra.Command{
+		Use:   "spending",
+		Short: "View the money paid to each host",
+		Long: `View the money that was paid to each host, broken down into storage,
+upload, download and fees.
+
+The --by flag lists the spending of each host (the default), contract or
+allowance period instead. Periods are identified by the end height of their
+contracts. The --start and --end flags restrict the spending to a time range,
+and are given as dates (2006-01-02). The range includes both days. The --csv
+flag prints the spending as CSV with all amounts in hastings.`,
+		Run: wrap(renterspendingcmd),
+	}
+
 	renterUploadsCmd = &cobra.Command{
 		Use:   "uploads",
 		Short: "View the upload queue",
 		Long:  "View the list of files currently uploading.",
 		Run:   wrap(renteruploadscmd),
 	}
+
+	renterWorkersCmd = &cobra.Command{
+		Use:   "workers",
+		Short: "View the status of the renter's workers",
+		Long: `View the status of the renter's workers. Every contract has a worker that
+uploads and downloads pieces. For every worker, the number of queued chunks,
+the remaining cooldown after failures and the average throughput are shown
+for both downloads and uploads, followed by the most recent errors.`,
+		Run: wrap(renterworkerscmd),
+	}
 )
 
 // abs returns the absolute representation of a path.
@@ -180,6 +259,18 @@ func rentercmd() {
 	if err != nil {
 		die("Could not get renter info:", err)
 	}
+	ra, err := httpClient.RenterAlertsGet()
+	if err != nil {
+		die("Could not get renter alerts:", err)
+	}
+	if len(ra.Alerts) != 0 {
+		fmt.Println("Alerts:")
+		for _, alert := range ra.Alerts {
+			fmt.Printf("	%-8v %v (%v)\n", strings.ToUpper(string(alert.Severity)), alert.Message, alert.Cause)
+		}
+		fmt.Println()
+	}
+
 	fm := rg.FinancialMetrics
 	totalSpent := fm.ContractFees.Add(fm.UploadSpending).
 		Add(fm.DownloadSpending).Add(fm.StorageSpending)
@@ -308,6 +399,38 @@ func renterallowancecmd() {
 	Amount: %v
 	Period: %v blocks
 `, currencyUnits(allowance.Funds), allowance.Period)
+
+	// display the price limits, if any are set
+	limits := []struct {
+		name  string
+		value types.Currency
+		unit  string
+	}{
+		{"Max Storage Price", allowance.MaxStoragePrice.Mul(modules.BlockBytesPerMonthTerabyte), " / TB / Month"},
+		{"Max Upload Price", allowance.MaxUploadBandwidthPrice.Mul(modules.BytesPerTerabyte), " / TB"},
+		{"Max Download Price", allowance.MaxDownloadBandwidthPrice.Mul(modules.BytesPerTerabyte), " / TB"},
+		{"Max Contract Price", allowance.MaxContractPrice, ""},
+		{"Min Collateral", allowance.MinCollateral.Mul(modules.BlockBytesPerMonthTerabyte), " / TB / Month"},
+	}
+	for _, limit := range limits {
+		if !limit.value.IsZero() {
+			fmt.Printf("\t%v: %v%v\n", limit.name, currencyUnits(limit.value), limit.unit)
+		}
+	}
+
+	// display the expected usage, if any is set
+	if allowance.ExpectedStorage != 0 {
+		fmt.Printf("\tExpected Storage: %v\n", filesizeUnits(int64(allowance.ExpectedStorage)))
+	}
+	if allowance.ExpectedUpload != 0 {
+		fmt.Printf("\tExpected Upload: %v / Month\n", filesizeUnits(int64(allowance.ExpectedUpload*blocksPerMonth)))
+	}
+	if allowance.ExpectedDownload != 0 {
+		fmt.Printf("\tExpected Download: %v / Month\n", filesizeUnits(int64(allowance.ExpectedDownload*blocksPerMonth)))
+	}
+	if allowance.ExpectedRedundancy != 0 {
+		fmt.Printf("\tExpected Redundancy: %v\n", allowance.ExpectedRedundancy)
+	}
 }
 
 // renterallowancecancelcmd cancels the current allowance.
@@ -379,6 +502,54 @@ func rentersetallowancecmd(cmd *cobra.Command, args []string) {
 			die("Could not parse renew window:", err)
 		}
 	}
+
+	// Start from the current price limits and expected usage, and apply any
+	// that were specified.
+	rg, err := httpClient.RenterGet()
+	if err != nil {
+		die("Could not get current allowance:", err)
+	}
+	current := rg.Settings.Allowance
+	allowance.MaxContractPrice = current.MaxContractPrice
+	allowance.MaxDownloadBandwidthPrice = current.MaxDownloadBandwidthPrice
+	allowance.MaxStoragePrice = current.MaxStoragePrice
+	allowance.MaxUploadBandwidthPrice = current.MaxUploadBandwidthPrice
+	allowance.MinCollateral = current.MinCollateral
+	allowance.ExpectedStorage = current.ExpectedStorage
+	allowance.ExpectedUpload = current.ExpectedUpload
+	allowance.ExpectedDownload = current.ExpectedDownload
+	allowance.ExpectedRedundancy = current.ExpectedRedundancy
+	if renterMaxContractPrice != "" {
+		allowance.MaxContractPrice = parsePriceLimit(renterMaxContractPrice, types.NewCurrency64(1))
+	}
+	if renterMaxDownloadPrice != "" {
+		allowance.MaxDownloadBandwidthPrice = parsePriceLimit(renterMaxDownloadPrice, modules.BytesPerTerabyte)
+	}
+	if renterMaxStoragePrice != "" {
+		allowance.MaxStoragePrice = parsePriceLimit(renterMaxStoragePrice, modules.BlockBytesPerMonthTerabyte)
+	}
+	if renterMaxUploadPrice != "" {
+		allowance.MaxUploadBandwidthPrice = parsePriceLimit(renterMaxUploadPrice, modules.BytesPerTerabyte)
+	}
+	if renterMinCollateral != "" {
+		allowance.MinCollateral = parsePriceLimit(renterMinCollateral, modules.BlockBytesPerMonthTerabyte)
+	}
+	if renterExpectedStorage != "" {
+		allowance.ExpectedStorage = parseExpectedUsage(renterExpectedStorage, 1)
+	}
+	if renterExpectedUpload != "" {
+		allowance.ExpectedUpload = parseExpectedUsage(renterExpectedUpload, blocksPerMonth)
+	}
+	if renterExpectedDownload != "" {
+		allowance.ExpectedDownload = parseExpectedUsage(renterExpectedDownload, blocksPerMonth)
+	}
+	if renterExpectedRedundancy != "" {
+		_, err = fmt.Sscan(renterExpectedRedundancy, &allowance.ExpectedRedundancy)
+		if err != nil {
+			die("Could not parse expected redundancy:", err)
+		}
+	}
+
 	err = httpClient.RenterPostAllowance(allowance)
 	if err != nil {
 		die("Could not set allowance:", err)
@@ -386,6 +557,45 @@ func rentersetallowancecmd(cmd *cobra.Command, args []string) {
 	fmt.Println("Allowance updated.")
 }
 
+// blocksPerMonth is the approximate number of blocks in a month, used to
+// convert between amounts per month and amounts per block.
+const blocksPerMonth = 4320
+
+// parseExpectedUsage parses a human readable filesize and divides it by the
+// provided number of blocks, converting e.g. GB/month into bytes/block.
+func parseExpectedUsage(size string, blocks uint64) uint64 {
+	if size == "0" {
+		return 0
+	}
+	bytesStr, err := parseFilesize(size)
+	if err != nil {
+		die("Could not parse expected usage:", err)
+	}
+	var bytes uint64
+	_, err = fmt.Sscan(bytesStr, &bytes)
+	if err != nil {
+		die("Could not parse expected usage:", err)
+	}
+	return bytes / blocks
+}
+
+// parsePriceLimit parses a human readable currency amount and divides it by
+// the provided unit, converting e.g. SC/TB into hastings/byte.
+func parsePriceLimit(amount string, unit types.Currency) types.Currency {
+	if amount == "0" {
+		return types.ZeroCurrency
+	}
+	hastings, err := parseCurrency(amount)
+	if err != nil {
+		die("Could not parse price limit:", err)
+	}
+	var limit types.Currency
+	if _, err := fmt.Sscan(hastings, &limit); err != nil {
+		die("Could not parse price limit:", err)
+	}
+	return limit.Div(unit)
+}
+
 // byValue sorts contracts by their value in siacoins, high to low. If two
 // contracts have the same value, they are sorted by their host's address.
 type byValue []api.RenterContract
@@ -600,6 +810,252 @@ Contract %v
 	fmt.Println("Contract not found")
 }
 
+// parseContractID parses a contract id, exiting if the id is invalid.
+func parseContractID(s string) types.FileContractID {
+	var h crypto.Hash
+	if err := h.LoadString(s); err != nil {
+		die("Could not parse contract id:", err)
+	}
+	return types.FileContractID(h)
+}
+
+// rentercontractscancelcmd is the handler for the command
+// `siac renter contracts cancel [contract-id]`.
+func rentercontractscancelcmd(cid string) {
+	err := httpClient.RenterContractCancelPost(parseContractID(cid))
+	if err != nil {
+		die("Could not cancel contract:", err)
+	}
+	fmt.Println("Contract canceled.")
+}
+
+// rentercontractsformcmd is the handler for the command
+// `siac renter contracts form [host-public-key] [funds] [end-height]`.
+func rentercontractsformcmd(cmd *cobra.Command, args []string) {
+	if len(args) < 2 || len(args) > 3 {
+		cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	var hostKey types.SiaPublicKey
+	hostKey.LoadString(args[0])
+	if len(hostKey.Key) == 0 {
+		die("Could not parse host public key")
+	}
+	hastings, err := parseCurrency(args[1])
+	if err != nil {
+		die("Could not parse funds:", err)
+	}
+	var funds types.Currency
+	if _, err := fmt.Sscan(hastings, &funds); err != nil {
+		die("Could not parse funds:", err)
+	}
+	var endHeight types.BlockHeight
+	if len(args) > 2 {
+		if _, err := fmt.Sscan(args[2], &endHeight); err != nil {
+			die("Could not parse end height:", err)
+		}
+	}
+	rcf, err := httpClient.RenterContractFormPost(hostKey, funds, endHeight)
+	if err != nil {
+		die("Could not form contract:", err)
+	}
+	fmt.Println("Formed contract", rcf.ID)
+}
+
+// rentercontractsrenewcmd is the handler for the command
+// `siac renter contracts renew [contract-id] [funds]`.
+func rentercontractsrenewcmd(cmd *cobra.Command, args []string) {
+	if len(args) < 1 || len(args) > 2 {
+		cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	var funds types.Currency
+	if len(args) > 1 {
+		hastings, err := parseCurrency(args[1])
+		if err != nil {
+			die("Could not parse funds:", err)
+		}
+		if _, err := fmt.Sscan(hastings, &funds); err != nil {
+			die("Could not parse funds:", err)
+		}
+	}
+	err := httpClient.RenterContractRenewPost(parseContractID(args[0]), funds)
+	if err != nil {
+		die("Could not renew contract:", err)
+	}
+	fmt.Println("Contract renewed.")
+}
+
+// rentercontractsstoprenewingcmd is the handler for the command
+// `siac renter contracts stoprenewing [contract-id]`.
+func rentercontractsstoprenewingcmd(cid string) {
+	err := httpClient.RenterContractStopRenewingPost(parseContractID(cid))
+	if err != nil {
+		die("Could not stop renewing contract:", err)
+	}
+	fmt.Println("Contract will not be renewed.")
+}
+
+// renterspendingcmd is the handler for the command `siac renter spending`.
+// It prints the money that was paid to each host, through each contract or in
+// each period within a time range.
+func renterspendingcmd() {
+	var host types.SiaPublicKey
+	if renterSpendingHost != "" {
+		host.LoadString(renterSpendingHost)
+		if len(host.Key) == 0 {
+			die("Could not parse host public key")
+		}
+	}
+	start := time.Unix(0, 0)
+	end := time.Now()
+	if renterSpendingStart != "" {
+		t, err := time.ParseInLocation("2006-01-02", renterSpendingStart, time.Local)
+		if err != nil {
+			die("Could not parse start date:", err)
+		}
+		start = t
+	}
+	if renterSpendingEnd != "" {
+		t, err := time.ParseInLocation("2006-01-02", renterSpendingEnd, time.Local)
+		if err != nil {
+			die("Could not parse end date:", err)
+		}
+		// Include the whole end day.
+		end = t.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	rsg, err := httpClient.RenterSpendingGet(host, start, end)
+	if err != nil {
+		die("Could not get spending:", err)
+	}
+
+	// Collect the spending to show.
+	var column string
+	var keys []string
+	var spending []modules.RenterSpending
+	switch renterSpendingBy {
+	case "host":
+		column = "Host"
+		for _, hs := range rsg.Hosts {
+			keys = append(keys, hs.HostPublicKey.String())
+			spending = append(spending, hs.RenterSpending)
+		}
+	case "contract":
+		column = "Contract"
+		for _, cs := range rsg.Contracts {
+			keys = append(keys, cs.ContractID.String())
+			spending = append(spending, cs.RenterSpending)
+		}
+	case "period":
+		column = "Period End Height"
+		for _, ps := range rsg.Periods {
+			keys = append(keys, fmt.Sprint(ps.EndHeight))
+			spending = append(spending, ps.RenterSpending)
+		}
+	default:
+		die("Could not break down spending: --by must be host, contract or period")
+	}
+
+	if renterSpendingCSV {
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{renterSpendingBy, "storage", "upload", "download", "fees", "total"})
+		for i, s := range spending {
+			w.Write([]string{
+				keys[i],
+				s.StorageSpending.String(),
+				s.UploadSpending.String(),
+				s.DownloadSpending.String(),
+				s.Fees.String(),
+				s.Total().String(),
+			})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			die("Could not write CSV:", err)
+		}
+		return
+	}
+
+	if len(spending) == 0 {
+		fmt.Println("No spending.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, column+"\tStorage\tUpload\tDownload\tFees\tTotal")
+	for i, s := range spending {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n",
+			keys[i],
+			currencyUnits(s.StorageSpending),
+			currencyUnits(s.UploadSpending),
+			currencyUnits(s.DownloadSpending),
+			currencyUnits(s.Fees),
+			currencyUnits(s.Total()))
+	}
+	w.Flush()
+}
+
+// renterworkerscmd is the handler for the command `siac renter workers`.
+// It shows the status of the renter's workers.
+func renterworkerscmd() {
+	rwg, err := httpClient.RenterWorkersGet()
+	if err != nil {
+		die("Could not get workers:", err)
+	}
+	if len(rwg.Workers) == 0 {
+		fmt.Println("No workers.")
+		return
+	}
+
+	cooldown := func(onCooldown bool, remaining time.Duration) string {
+		if !onCooldown {
+			return "-"
+		}
+		return remaining.Round(time.Second).String()
+	}
+	speed := func(bps uint64) string {
+		if bps == 0 {
+			return "-"
+		}
+		return filesizeUnits(int64(bps)) + "/s"
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Host\tDL Queue\tDL Cooldown\tDL Speed\tUL Queue\tUL Cooldown\tUL Speed")
+	for _, ws := range rwg.Workers {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			ws.HostPublicKey.String(),
+			ws.DownloadQueueSize,
+			cooldown(ws.DownloadOnCooldown, ws.DownloadCooldownRemaining),
+			speed(ws.DownloadThroughput),
+			ws.UploadQueueSize,
+			cooldown(ws.UploadOnCooldown, ws.UploadCooldownRemaining),
+			speed(ws.UploadThroughput))
+	}
+	w.Flush()
+
+	// List the most recent errors of the workers that have failed.
+	var printedHeader bool
+	for _, ws := range rwg.Workers {
+		for _, e := range []struct {
+			kind string
+			err  string
+			t    time.Time
+		}{
+			{"download", ws.DownloadRecentError, ws.DownloadRecentErrorTime},
+			{"upload", ws.UploadRecentError, ws.UploadRecentErrorTime},
+		} {
+			if e.err == "" {
+				continue
+			}
+			if !printedHeader {
+				fmt.Println()
+				fmt.Println("Recent errors:")
+				printedHeader = true
+			}
+			fmt.Printf("  %v %v (%v): %v\n", ws.HostPublicKey.String(), e.kind, e.t.Format(time.RFC822), e.err)
+		}
+	}
+}
+
 // renterfilesdeletecmd is the handler for the command `siac renter delete [path]`.
 // Removes the specified path from the Sia network.
 func renterfilesdeletecmd(path string) {
@@ -610,6 +1066,23 @@ func renterfilesdeletecmd(path string) {
 	fmt.Println("Deleted", path)
 }
 
+// parseHostKeys parses a comma separated list of host public keys.
+func parseHostKeys(s string) []types.SiaPublicKey {
+	if s == "" {
+		return nil
+	}
+	var keys []types.SiaPublicKey
+	for _, str := range strings.Split(s, ",") {
+		var pk types.SiaPublicKey
+		pk.LoadString(strings.TrimSpace(str))
+		if len(pk.Key) == 0 {
+			die("Could not parse host public key:", str)
+		}
+		keys = append(keys, pk)
+	}
+	return keys
+}
+
 // renterfilesdownloadcmd is the handler for the comand `siac renter download [path] [destination]`.
 // Downloads a path from the Sia network to the local specified destination.
 func renterfilesdownloadcmd(path, destination string) {
@@ -618,7 +1091,9 @@ func renterfilesdownloadcmd(path, destination string) {
 	// Queue the download. An error will be returned if the queueing failed, but
 	// the call will return before the download has completed. The call is made
 	// as an async call.
-	err := httpClient.RenterDownloadFullGet(path, destination, true)
+	exclude := parseHostKeys(renterDownloadExclude)
+	prefer := parseHostKeys(renterDownloadPrefer)
+	err := httpClient.RenterDownloadHostsGet(path, destination, exclude, prefer, renterDownloadOverdrive, true)
 	if err != nil {
 		die("Download could not be started:", err)
 	}
@@ -568,6 +568,12 @@ func (srv *Server) loadModules() error {
 		srv.moduleClosers = append(srv.moduleClosers, moduleCloser{name: "renter", Closer: r})
 	}
 
+	// The host's pricing autopilot follows the prices in the renter's hostdb.
+	// Without a renter, it asks the hosts announced in the blockchain.
+	if hostImpl, ok := h.(*host.Host); ok && r != nil {
+		hostImpl.SetPriceSource(r)
+	}
+
 	// Create the Sia API
 	a := api.New(
 		srv.config.Siad.RequiredUserAgent,
@@ -0,0 +1,143 @@
+package modules
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// AlertSeverityWarning indicates that something might go wrong if the
+	// user doesn't act.
+	AlertSeverityWarning AlertSeverity = "warning"
+
+	// AlertSeverityError indicates that something went wrong and needs to be
+	// fixed by the user.
+	AlertSeverityError AlertSeverity = "error"
+
+	// AlertSeverityCritical indicates that data or money is about to be lost
+	// if the user doesn't act immediately.
+	AlertSeverityCritical AlertSeverity = "critical"
+)
+
+type (
+	// AlertSeverity indicates how urgently an alert needs the user's
+	// attention.
+	AlertSeverity string
+
+	// An Alert reports a condition that needs the user's attention. An alert
+	// stays registered for as long as the condition persists.
+	Alert struct {
+		// ID identifies the condition that the alert reports. Registering an
+		// alert with the ID of an existing alert updates the existing alert.
+		ID       string        `json:"id"`
+		Module   string        `json:"module"`
+		Severity AlertSeverity `json:"severity"`
+		Message  string        `json:"message"`
+		Cause    string        `json:"cause"`
+
+		// FirstSeen is the time at which the condition was first detected
+		// and LastSeen is the time at which it was last confirmed.
+		FirstSeen time.Time `json:"firstseen"`
+		LastSeen  time.Time `json:"lastseen"`
+	}
+
+	// An Alerter tracks the alerts of a module.
+	Alerter struct {
+		alerts map[string]Alert
+		module string
+		mu     sync.Mutex
+	}
+)
+
+// rank returns the urgency of a severity, higher being more urgent.
+func (s AlertSeverity) rank() int {
+	switch s {
+	case AlertSeverityWarning:
+		return 1
+	case AlertSeverityError:
+		return 2
+	case AlertSeverityCritical:
+		return 3
+	}
+	return 0
+}
+
+// SortAlerts sorts alerts by severity, most urgent first, and then by the
+// time they were first seen, oldest first.
+func SortAlerts(alerts []Alert) {
+	sort.Slice(alerts, func(i, j int) bool {
+		if ri, rj := alerts[i].Severity.rank(), alerts[j].Severity.rank(); ri != rj {
+			return ri > rj
+		}
+		if !alerts[i].FirstSeen.Equal(alerts[j].FirstSeen) {
+			return alerts[i].FirstSeen.Before(alerts[j].FirstSeen)
+		}
+		return alerts[i].ID < alerts[j].ID
+	})
+}
+
+// NewAlerter creates an Alerter for the given module.
+func NewAlerter(module string) *Alerter {
+	return &Alerter{
+		alerts: make(map[string]Alert),
+		module: module,
+	}
+}
+
+// Alerts returns the alerts of the Alerter, most urgent first.
+func (a *Alerter) Alerts() []Alert {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	alerts := make([]Alert, 0, len(a.alerts))
+	for _, alert := range a.alerts {
+		alerts = append(alerts, alert)
+	}
+	SortAlerts(alerts)
+	return alerts
+}
+
+// Load replaces the alerts of the Alerter with previously persisted alerts.
+func (a *Alerter) Load(alerts []Alert) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.alerts = make(map[string]Alert)
+	for _, alert := range alerts {
+		a.alerts[alert.ID] = alert
+	}
+}
+
+// RegisterAlert registers an alert, or updates the alert if an alert with the
+// same ID is already registered. It returns whether the alert is new or its
+// severity, message or cause changed, which means that the alerts need to be
+// persisted again.
+func (a *Alerter) RegisterAlert(id string, severity AlertSeverity, msg, cause string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := time.Now()
+	alert, exists := a.alerts[id]
+	changed := !exists || alert.Severity != severity || alert.Message != msg || alert.Cause != cause
+	if !exists {
+		alert = Alert{
+			ID:        id,
+			Module:    a.module,
+			FirstSeen: now,
+		}
+	}
+	alert.Severity = severity
+	alert.Message = msg
+	alert.Cause = cause
+	alert.LastSeen = now
+	a.alerts[id] = alert
+	return changed
+}
+
+// UnregisterAlert removes an alert once its condition has been resolved. It
+// returns whether the alert was registered.
+func (a *Alerter) UnregisterAlert(id string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, exists := a.alerts[id]
+	delete(a.alerts, id)
+	return exists
+}
@@ -0,0 +1,46 @@
+package modules
+
+import (
+	"testing"
+)
+
+// TestAlerter checks that alerts are deduplicated, sorted by severity and
+// restored from persisted alerts.
+func TestAlerter(t *testing.T) {
+	a := NewAlerter("test")
+	if !a.RegisterAlert("low", AlertSeverityWarning, "msg", "cause") {
+		t.Fatal("new alert was not reported as changed")
+	}
+	if !a.RegisterAlert("high", AlertSeverityCritical, "msg", "cause") {
+		t.Fatal("new alert was not reported as changed")
+	}
+
+	// Registering the same alert again should only update when it was last
+	// seen.
+	first := a.Alerts()[1]
+	if a.RegisterAlert("low", AlertSeverityWarning, "msg", "cause") {
+		t.Fatal("unchanged alert was reported as changed")
+	}
+	alerts := a.Alerts()
+	if len(alerts) != 2 || alerts[0].ID != "high" || alerts[1].ID != "low" {
+		t.Fatal("wrong alerts:", alerts)
+	}
+	if !alerts[1].FirstSeen.Equal(first.FirstSeen) || alerts[1].LastSeen.Before(first.LastSeen) || alerts[1].Module != "test" {
+		t.Fatal("alert was not updated correctly:", alerts[1])
+	}
+
+	// Escalating an alert should be reported as a change.
+	if !a.RegisterAlert("low", AlertSeverityError, "msg", "cause") {
+		t.Fatal("escalated alert was not reported as changed")
+	}
+
+	// The alerts should survive being reloaded.
+	b := NewAlerter("test")
+	b.Load(a.Alerts())
+	if !b.UnregisterAlert("high") || b.UnregisterAlert("high") {
+		t.Fatal("alert was not unregistered correctly")
+	}
+	if alerts := b.Alerts(); len(alerts) != 1 || alerts[0].ID != "low" || alerts[0].Severity != AlertSeverityError {
+		t.Fatal("wrong alerts after reloading:", alerts)
+	}
+}
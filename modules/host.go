@@ -1,6 +1,9 @@
 package modules
 
 import (
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -9,6 +12,85 @@ const (
 	HostDir = "host"
 )
 
+// The ledger of the host keeps potential and realized revenue apart. The
+// potential revenue of a contract is recorded when the contract is formed and
+// revised, and it is resolved exactly once when the obligation ends, by a
+// proofsuccess, lostrevenue or contractrejected entry of the same value. The
+// earnings of the host are the sum of the proofsuccess and feerefund entries
+// minus the sum of the lostcollateral and transactionfee entries.
+const (
+	// HostLedgerContractFormed is the type of ledger entries that record the
+	// formation or renewal of a contract. The value is the compensation and
+	// revenue that the renter paid into the contract up front, which is
+	// potential revenue until the obligation is resolved.
+	HostLedgerContractFormed = "contractformed"
+
+	// HostLedgerContractRejected is the type of ledger entries that record a
+	// contract that never made it into the blockchain. The value is the
+	// potential revenue of the contract, which is no longer expected.
+	HostLedgerContractRejected = "contractrejected"
+
+	// HostLedgerFeeRefund is the type of ledger entries that record
+	// transaction fees that were recorded for a contract but never paid,
+	// because the transaction never made it into the blockchain.
+	HostLedgerFeeRefund = "feerefund"
+
+	// HostLedgerLostCollateral is the type of ledger entries that record a
+	// missed storage proof. The value is the collateral that was lost.
+	HostLedgerLostCollateral = "lostcollateral"
+
+	// HostLedgerLostRevenue is the type of ledger entries that record a
+	// missed storage proof. The value is the potential revenue of the
+	// contract, which was not earned.
+	HostLedgerLostRevenue = "lostrevenue"
+
+	// HostLedgerProofSuccess is the type of ledger entries that record a
+	// successful storage proof. The value is the revenue that was realized
+	// with the contract.
+	HostLedgerProofSuccess = "proofsuccess"
+
+	// HostLedgerRevisionRevenue is the type of ledger entries that record the
+	// potential revenue that was added to a contract by a revision.
+	HostLedgerRevisionRevenue = "revisionrevenue"
+
+	// HostLedgerTransactionFee is the type of ledger entries that record the
+	// transaction fees paid by the host for a contract.
+	HostLedgerTransactionFee = "transactionfee"
+)
+
+const (
+	// ObligationCreated is the status of a storage obligation that was just
+	// formed or renewed.
+	ObligationCreated = "created"
+
+	// ObligationOriginConfirmed and ObligationOriginReverted are the statuses
+	// of a storage obligation after its file contract was confirmed or
+	// reverted on the blockchain.
+	ObligationOriginConfirmed = "originconfirmed"
+	ObligationOriginReverted  = "originreverted"
+
+	// ObligationProofFailed is the status of a storage obligation after the
+	// host was unable to submit a storage proof. The reason is given in the
+	// detail of the transition.
+	ObligationProofFailed = "prooffailed"
+
+	// ObligationProofSubmitted, ObligationProofConfirmed and
+	// ObligationProofReverted are the statuses of a storage obligation after
+	// its storage proof was submitted to the transaction pool, and confirmed
+	// or reverted on the blockchain.
+	ObligationProofSubmitted = "proofsubmitted"
+	ObligationProofConfirmed = "proofconfirmed"
+	ObligationProofReverted  = "proofreverted"
+
+	// ObligationRevisionSubmitted, ObligationRevisionConfirmed and
+	// ObligationRevisionReverted are the statuses of a storage obligation
+	// after its latest file contract revision was submitted to the
+	// transaction pool, and confirmed or reverted on the blockchain.
+	ObligationRevisionSubmitted = "revisionsubmitted"
+	ObligationRevisionConfirmed = "revisionconfirmed"
+	ObligationRevisionReverted  = "revisionreverted"
+)
+
 var (
 	// BlockBytesPerMonthTerabyte is the conversion rate between block-bytes and month-TB.
 	BlockBytesPerMonthTerabyte = BytesPerTerabyte.Mul64(4320)
@@ -30,6 +112,10 @@ var (
 	// netaddress.
 	HostConnectabilityStatusNotConnectable = HostConnectabilityStatus("not connectable")
 
+	// HostWorkingStatusMaintenance is returned from WorkingStatus() if the
+	// host is in maintenance, refusing new contracts, renewals and uploads.
+	HostWorkingStatusMaintenance = HostWorkingStatus("maintenance")
+
 	// HostWorkingStatusChecking is returned from WorkingStatus() if the host is
 	// still determining if it is working, that is, if settings calls are
 	// incrementing.
@@ -94,6 +180,150 @@ type (
 		MinDownloadBandwidthPrice types.Currency `json:"mindownloadbandwidthprice"`
 		MinStoragePrice           types.Currency `json:"minstorageprice"`
 		MinUploadBandwidthPrice   types.Currency `json:"minuploadbandwidthprice"`
+
+		// The pricing autopilot periodically moves the minimum prices toward
+		// the PricingTargetPercentile of the prices of the other hosts on the
+		// network, keeping each price between its floor and its ceiling. A
+		// ceiling of zero means that the price has no upper bound.
+		PricingAutopilot        bool    `json:"pricingautopilot"`
+		PricingTargetPercentile float64 `json:"pricingtargetpercentile"`
+
+		ContractPriceCeiling          types.Currency `json:"contractpriceceiling"`
+		ContractPriceFloor            types.Currency `json:"contractpricefloor"`
+		DownloadBandwidthPriceCeiling types.Currency `json:"downloadbandwidthpriceceiling"`
+		DownloadBandwidthPriceFloor   types.Currency `json:"downloadbandwidthpricefloor"`
+		StoragePriceCeiling           types.Currency `json:"storagepriceceiling"`
+		StoragePriceFloor             types.Currency `json:"storagepricefloor"`
+		UploadBandwidthPriceCeiling   types.Currency `json:"uploadbandwidthpriceceiling"`
+		UploadBandwidthPriceFloor     types.Currency `json:"uploadbandwidthpricefloor"`
+
+		// Bandwidth limits for the RPC traffic of the host in bytes per
+		// second. Download is the data that renters download from the host
+		// and upload is the data that renters upload to the host. The renter
+		// limits apply to each renter separately. A limit of zero means that
+		// the bandwidth is not limited.
+		MaxDownloadSpeed       int64 `json:"maxdownloadspeed"`
+		MaxUploadSpeed         int64 `json:"maxuploadspeed"`
+		MaxRenterDownloadSpeed int64 `json:"maxrenterdownloadspeed"`
+		MaxRenterUploadSpeed   int64 `json:"maxrenteruploadspeed"`
+
+		// SectorCacheSize is the number of bytes of recently read sectors
+		// that the host keeps in memory. A size of zero disables the cache.
+		SectorCacheSize uint64 `json:"sectorcachesize"`
+	}
+
+	// HostPriceAdjustment is a change of one of the host's minimum prices
+	// made by the pricing autopilot.
+	HostPriceAdjustment struct {
+		Time        time.Time         `json:"time"`
+		BlockHeight types.BlockHeight `json:"blockheight"`
+
+		// Price is the name of the adjusted setting, e.g. "minstorageprice".
+		Price    string         `json:"price"`
+		OldPrice types.Currency `json:"oldprice"`
+		NewPrice types.Currency `json:"newprice"`
+
+		// MarketPrice is the target percentile of the prices of the other
+		// hosts that the adjustment was based on, and Hosts is the number of
+		// those hosts.
+		MarketPrice types.Currency `json:"marketprice"`
+		Hosts       int            `json:"hosts"`
+	}
+
+	// HostPolicy restricts which renters the host negotiates with and how
+	// much each renter and contract may hold. A limit of zero means that
+	// there is no limit.
+	HostPolicy struct {
+		// BlockedRenters are the public keys of the renters that the host
+		// refuses to form, renew or revise contracts with.
+		BlockedRenters []types.SiaPublicKey `json:"blockedrenters"`
+
+		// MaxContractCollateral is the most collateral the host will put into
+		// a single contract, and MaxContractSize is the largest amount of
+		// data, in bytes, that a single contract may hold.
+		MaxContractCollateral types.Currency `json:"maxcontractcollateral"`
+		MaxContractSize       uint64         `json:"maxcontractsize"`
+
+		// MaxRenterContracts is the number of active contracts a single
+		// renter may hold with the host, and MaxRenterData is the amount of
+		// data, in bytes, that a single renter may store across all of them.
+		MaxRenterContracts uint64 `json:"maxrentercontracts"`
+		MaxRenterData      uint64 `json:"maxrenterdata"`
+	}
+
+	// HostMaintenance is the maintenance window of the host. While the host is
+	// in maintenance, it refuses new contracts, renewals and uploads, but it
+	// keeps serving downloads and submitting storage proofs.
+	HostMaintenance struct {
+		// Start and End are the times at which the maintenance starts and
+		// ends. A zero Start means that no maintenance is scheduled, a zero
+		// End means that the maintenance lasts until it is cancelled.
+		Start time.Time `json:"start"`
+		End   time.Time `json:"end"`
+
+		// Active indicates whether the host is in maintenance right now.
+		Active bool `json:"active"`
+
+		// OnlineByHeight is the block height by which the host must be
+		// online to submit the next file contract revision or storage proof
+		// of its obligations, and OnlineByObligation is the obligation that
+		// needs it. OnlineByTime estimates the time at which that height is
+		// reached. They are zero if no obligation needs the host.
+		OnlineByHeight     types.BlockHeight    `json:"onlinebyheight"`
+		OnlineByObligation types.FileContractID `json:"onlinebyobligation"`
+		OnlineByTime       time.Time            `json:"onlinebytime"`
+	}
+
+	// HostStorageProof is a storage proof that the host has not yet gotten
+	// confirmed. The host rebroadcasts a pending storage proof, and submits a
+	// new one with a higher fee if the proof was dropped from the transaction
+	// pool.
+	HostStorageProof struct {
+		ObligationId types.FileContractID `json:"obligationid"`
+
+		// Attempts is the number of storage proofs that the host has
+		// submitted for the obligation. Fee is the miner fee paid by the
+		// latest one, which was submitted at SubmissionHeight. Pending
+		// indicates whether it is still waiting in the transaction pool.
+		Attempts         uint64            `json:"attempts"`
+		Fee              types.Currency    `json:"fee"`
+		Pending          bool              `json:"pending"`
+		SubmissionHeight types.BlockHeight `json:"submissionheight"`
+
+		// The storage proof must be confirmed before the proof deadline. A
+		// proof is at risk if only a few blocks remain until the deadline.
+		AtRisk          bool              `json:"atrisk"`
+		BlocksRemaining types.BlockHeight `json:"blocksremaining"`
+		ProofDeadline   types.BlockHeight `json:"proofdeadline"`
+	}
+
+	// HostBandwidthBucket is the RPC traffic of the host within a period of
+	// time starting at StartTime.
+	HostBandwidthBucket struct {
+		StartTime     time.Time `json:"starttime"`
+		DownloadBytes uint64    `json:"downloadbytes"`
+		UploadBytes   uint64    `json:"uploadbytes"`
+	}
+
+	// HostBandwidthMetrics reports the RPC traffic of the host. The totals
+	// cover the lifetime of the host, and the buckets cover the recent past,
+	// oldest first.
+	HostBandwidthMetrics struct {
+		DownloadBytes  uint64                `json:"downloadbytes"`
+		UploadBytes    uint64                `json:"uploadbytes"`
+		BucketDuration time.Duration         `json:"bucketduration"`
+		Buckets        []HostBandwidthBucket `json:"buckets"`
+	}
+
+	// HostLedgerEntry is a financial event of the host, tied to the storage
+	// obligation that caused it. The meaning of Value depends on the type of
+	// the entry.
+	HostLedgerEntry struct {
+		Type         string               `json:"type"`
+		ObligationID types.FileContractID `json:"obligationid"`
+		BlockHeight  types.BlockHeight    `json:"blockheight"`
+		Timestamp    time.Time            `json:"timestamp"`
+		Value        types.Currency       `json:"value"`
 	}
 
 	// HostNetworkMetrics reports the quantity of each type of RPC call that
@@ -122,6 +352,17 @@ type (
 		SectorRootsCount         uint64               `json:"sectorrootscount"`
 		TransactionFeesAdded     types.Currency       `json:"transactionfeesadded"`
 
+		// The number of bytes that the renter has downloaded from and
+		// uploaded to the host over the RPCs of the obligation.
+		DownloadBytes uint64 `json:"downloadbytes"`
+		UploadBytes   uint64 `json:"uploadbytes"`
+
+		// An obligation is at risk if some of its sectors were found to be
+		// corrupt or missing when the host last scrubbed its storage folders.
+		// The host cannot submit a valid storage proof for those sectors.
+		AtRisk        bool   `json:"atrisk"`
+		FailedSectors uint64 `json:"failedsectors"`
+
 		// The negotiation height specifies the block height at which the file
 		// contract was negotiated. The expiration height and the proof deadline
 		// are equal to the window start and window end. Between the expiration height
@@ -140,6 +381,66 @@ type (
 		RevisionConstructed bool   `json:"revisionconstructed"`
 	}
 
+	// StorageObligationDetails contains everything the host knows about a
+	// storage obligation, for inspecting obligations that have run into
+	// trouble.
+	StorageObligationDetails struct {
+		StorageObligation
+
+		// ActionItems are the block heights at which the host will next
+		// check on the obligation.
+		ActionItems []types.BlockHeight `json:"actionitems"`
+
+		// Revisions is the history of the file contract, starting with the
+		// contract that formed the obligation.
+		Revisions []StorageObligationRevision `json:"revisions"`
+
+		// Sectors are the sectors of the obligation and where the host stores
+		// them. They are no longer reported once the obligation is resolved.
+		Sectors []StorageObligationSector `json:"sectors"`
+
+		// Timeline is the history of the status of the obligation, oldest
+		// first.
+		Timeline []StorageObligationTransition `json:"timeline"`
+
+		// The transaction sets of the obligation. The proof transaction set
+		// is the last storage proof that the host submitted.
+		OriginTransactionSet   []types.Transaction `json:"origintransactionset"`
+		ProofTransactionSet    []types.Transaction `json:"prooftransactionset"`
+		RevisionTransactionSet []types.Transaction `json:"revisiontransactionset"`
+	}
+
+	// StorageObligationRevision is a revision of the file contract of a
+	// storage obligation, as it was agreed with the renter.
+	StorageObligationRevision struct {
+		BlockHeight    types.BlockHeight `json:"blockheight"`
+		Timestamp      time.Time         `json:"timestamp"`
+		RevisionNumber uint64            `json:"revisionnumber"`
+
+		FileMerkleRoot     crypto.Hash           `json:"filemerkleroot"`
+		FileSize           uint64                `json:"filesize"`
+		MissedProofOutputs []types.SiacoinOutput `json:"missedproofoutputs"`
+		ValidProofOutputs  []types.SiacoinOutput `json:"validproofoutputs"`
+	}
+
+	// StorageObligationSector is a sector of a storage obligation along with
+	// the place where the host stores it. Failed is set if the sector was
+	// found to be corrupt or missing when the host last checked it.
+	StorageObligationSector struct {
+		Root   crypto.Hash `json:"root"`
+		Failed bool        `json:"failed"`
+		SectorLocation
+	}
+
+	// StorageObligationTransition is a change in the status of a storage
+	// obligation. Detail gives more information about failed attempts.
+	StorageObligationTransition struct {
+		BlockHeight types.BlockHeight `json:"blockheight"`
+		Timestamp   time.Time         `json:"timestamp"`
+		Status      string            `json:"status"`
+		Detail      string            `json:"detail,omitempty"`
+	}
+
 	// HostWorkingStatus reports the working state of a host. Can be one of
 	// "checking", "working", or "not working".
 	HostWorkingStatus string
@@ -158,6 +459,13 @@ type (
 		// AnnounceAddress submits an announcement using the given address.
 		AnnounceAddress(NetAddress) error
 
+		// BandwidthMetrics returns the RPC traffic of the host.
+		BandwidthMetrics() HostBandwidthMetrics
+
+		// CancelMaintenance ends the maintenance of the host, or cancels it
+		// if it has not started yet.
+		CancelMaintenance() error
+
 		// ExternalSettings returns the settings of the host as seen by an
 		// untrusted node querying the host for settings.
 		ExternalSettings() HostExternalSettings
@@ -169,20 +477,52 @@ type (
 		// potentially private or sensitive information.
 		InternalSettings() HostInternalSettings
 
+		// Ledger returns the financial events of the host that happened
+		// between start and end, oldest first.
+		Ledger(start, end time.Time) ([]HostLedgerEntry, error)
+
+		// Maintenance returns the maintenance window of the host and the time
+		// by which the host must be online again.
+		Maintenance() HostMaintenance
+
 		// NetworkMetrics returns information on the types of RPC calls that
 		// have been made to the host.
 		NetworkMetrics() HostNetworkMetrics
 
+		// Policy returns the host's policy toward renters.
+		Policy() HostPolicy
+
+		// PriceAdjustments returns the most recent price adjustments of the
+		// pricing autopilot, oldest first.
+		PriceAdjustments() []HostPriceAdjustment
+
 		// PublicKey returns the public key of the host.
 		PublicKey() types.SiaPublicKey
 
+		// ScheduleMaintenance puts the host in maintenance between start and
+		// end. A zero end keeps the host in maintenance until it is
+		// cancelled.
+		ScheduleMaintenance(start, end time.Time) error
+
 		// SetInternalSettings sets the hosting parameters of the host.
 		SetInternalSettings(HostInternalSettings) error
 
+		// SetPolicy sets the host's policy toward renters. The policy
+		// applies to all future negotiations.
+		SetPolicy(HostPolicy) error
+
+		// StorageObligationDetails returns everything the host knows about
+		// the storage obligation with the provided id.
+		StorageObligationDetails(id types.FileContractID) (StorageObligationDetails, error)
+
 		// StorageObligations returns the set of storage obligations held by
 		// the host.
 		StorageObligations() []StorageObligation
 
+		// StorageProofs returns the storage proofs that the host has yet to
+		// get confirmed.
+		StorageProofs() []HostStorageProof
+
 		// ConnectabilityStatus returns the connectability status of the host, that
 		// is, if it can connect to itself on the configured NetAddress.
 		ConnectabilityStatus() HostConnectabilityStatus
@@ -0,0 +1,207 @@
+package host
+
+// bandwidth.go tracks and limits the RPC traffic of the host. Every incoming
+// connection is wrapped in a hostConn, which counts the bytes that are read
+// and written and applies the host's global bandwidth limits. Once an RPC
+// knows which renter it is talking to, the connection is also subjected to
+// that renter's limits and its traffic is attributed to the renter's storage
+// obligation.
+//
+// The traffic of a connection is recorded when the connection is closed. It
+// is added to the host's totals, to the current bandwidth bucket and to the
+// bandwidth of the storage obligation, if any.
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/ratelimit"
+
+	"github.com/coreos/bbolt"
+)
+
+// errNegativeSpeed is returned if a bandwidth limit is negative.
+var errNegativeSpeed = errors.New("bandwidth limits must not be negative")
+
+// hostConn is a connection to a renter that counts its traffic and enforces
+// the host's bandwidth limits.
+type hostConn struct {
+	// Atomic counters need to be placed at the top to preserve compatibility
+	// with 32bit systems.
+	atomicDownloadBytes uint64 // written to the renter
+	atomicUploadBytes   uint64 // read from the renter
+
+	// limited is the connection that reads and writes go through. It is
+	// replaced by a connection with tighter limits once the renter is known.
+	cancel  <-chan struct{}
+	limited net.Conn
+	net.Conn
+
+	// obligation is the storage obligation that the traffic is attributed
+	// to, if tracked is set.
+	obligation types.FileContractID
+	tracked    bool
+
+	mu sync.Mutex
+}
+
+// Read reads from the rate limited connection, counting the bytes that were
+// uploaded by the renter.
+func (hc *hostConn) Read(b []byte) (int, error) {
+	hc.mu.Lock()
+	conn := hc.limited
+	hc.mu.Unlock()
+	n, err := conn.Read(b)
+	atomic.AddUint64(&hc.atomicUploadBytes, uint64(n))
+	return n, err
+}
+
+// Write writes to the rate limited connection, counting the bytes that are
+// downloaded by the renter.
+func (hc *hostConn) Write(b []byte) (int, error) {
+	hc.mu.Lock()
+	conn := hc.limited
+	hc.mu.Unlock()
+	n, err := conn.Write(b)
+	atomic.AddUint64(&hc.atomicDownloadBytes, uint64(n))
+	return n, err
+}
+
+// obligationBandwidth is the traffic of a storage obligation as it is stored
+// in the database.
+type obligationBandwidth struct {
+	DownloadBytes uint64
+	UploadBytes   uint64
+}
+
+// getObligationBandwidth fetches the traffic of a storage obligation from the
+// database tx.
+func getObligationBandwidth(tx *bolt.Tx, soid types.FileContractID) (ob obligationBandwidth) {
+	b := tx.Bucket(bucketObligationBandwidth).Get(soid[:])
+	if len(b) == 16 {
+		ob.DownloadBytes = binary.LittleEndian.Uint64(b[:8])
+		ob.UploadBytes = binary.LittleEndian.Uint64(b[8:])
+	}
+	return ob
+}
+
+// putObligationBandwidth places the traffic of a storage obligation into the
+// database.
+func putObligationBandwidth(tx *bolt.Tx, soid types.FileContractID, ob obligationBandwidth) error {
+	b := make([]byte, 16)
+	binary.LittleEndian.PutUint64(b[:8], ob.DownloadBytes)
+	binary.LittleEndian.PutUint64(b[8:], ob.UploadBytes)
+	return tx.Bucket(bucketObligationBandwidth).Put(soid[:], b)
+}
+
+// addBandwidth adds traffic to the bandwidth metrics, starting a new bucket if
+// the current one has ended.
+func addBandwidth(bm *modules.HostBandwidthMetrics, now time.Time, download, upload uint64) {
+	bm.DownloadBytes += download
+	bm.UploadBytes += upload
+	bm.BucketDuration = bandwidthBucketDuration
+
+	start := now.Truncate(bandwidthBucketDuration)
+	if n := len(bm.Buckets); n == 0 || !bm.Buckets[n-1].StartTime.Equal(start) {
+		bm.Buckets = append(bm.Buckets, modules.HostBandwidthBucket{StartTime: start})
+	}
+	bucket := &bm.Buckets[len(bm.Buckets)-1]
+	bucket.DownloadBytes += download
+	bucket.UploadBytes += upload
+	if len(bm.Buckets) > bandwidthBucketLimit {
+		bm.Buckets = bm.Buckets[len(bm.Buckets)-bandwidthBucketLimit:]
+	}
+}
+
+// newHostConn wraps an incoming connection, applying the host's global
+// bandwidth limits.
+func (h *Host) newHostConn(conn net.Conn) *hostConn {
+	return &hostConn{
+		cancel:  h.tg.StopChan(),
+		limited: ratelimit.NewRLConn(conn, h.staticRL, h.tg.StopChan()),
+		Conn:    conn,
+	}
+}
+
+// managedTrackConn attributes the traffic of a connection to a storage
+// obligation and applies the bandwidth limits of the renter to the rest of
+// the connection. Connections that are not hostConns are ignored.
+func (h *Host) managedTrackConn(conn net.Conn, soid types.FileContractID, renter types.SiaPublicKey) {
+	hc, ok := conn.(*hostConn)
+	if !ok {
+		return
+	}
+	rl := h.managedRenterRateLimit(renter)
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if !hc.tracked {
+		hc.limited = ratelimit.NewRLConn(hc.limited, rl, hc.cancel)
+	}
+	hc.obligation = soid
+	hc.tracked = true
+}
+
+// managedRecordConn records the traffic of a finished connection.
+func (h *Host) managedRecordConn(hc *hostConn) {
+	download := atomic.LoadUint64(&hc.atomicDownloadBytes)
+	upload := atomic.LoadUint64(&hc.atomicUploadBytes)
+	hc.mu.Lock()
+	soid, tracked := hc.obligation, hc.tracked
+	hc.mu.Unlock()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	addBandwidth(&h.bandwidthMetrics, time.Now(), download, upload)
+	if !tracked || (download == 0 && upload == 0) {
+		return
+	}
+	err := h.db.Update(func(tx *bolt.Tx) error {
+		ob := getObligationBandwidth(tx, soid)
+		ob.DownloadBytes += download
+		ob.UploadBytes += upload
+		return putObligationBandwidth(tx, soid, ob)
+	})
+	if err != nil {
+		h.log.Println("Could not record the bandwidth of storage obligation", soid, err)
+	}
+}
+
+// managedRenterRateLimit returns the rate limit shared by all connections of a
+// renter.
+func (h *Host) managedRenterRateLimit(renter types.SiaPublicKey) *ratelimit.RateLimit {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := renter.String()
+	rl, exists := h.renterRateLimits[key]
+	if !exists {
+		rl = ratelimit.NewRateLimit(h.settings.MaxRenterUploadSpeed, h.settings.MaxRenterDownloadSpeed, rateLimitPacketSize)
+		h.renterRateLimits[key] = rl
+	}
+	return rl
+}
+
+// updateRateLimits applies the bandwidth limits of the host's settings to the
+// global limit and to the limits of all renters. The host reads the data that
+// renters upload and writes the data that they download.
+func (h *Host) updateRateLimits() {
+	h.staticRL.SetLimits(h.settings.MaxUploadSpeed, h.settings.MaxDownloadSpeed, rateLimitPacketSize)
+	for _, rl := range h.renterRateLimits {
+		rl.SetLimits(h.settings.MaxRenterUploadSpeed, h.settings.MaxRenterDownloadSpeed, rateLimitPacketSize)
+	}
+}
+
+// BandwidthMetrics returns the RPC traffic of the host.
+func (h *Host) BandwidthMetrics() modules.HostBandwidthMetrics {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	bm := h.bandwidthMetrics
+	bm.BucketDuration = bandwidthBucketDuration
+	bm.Buckets = append([]modules.HostBandwidthBucket(nil), h.bandwidthMetrics.Buckets...)
+	return bm
+}
@@ -0,0 +1,117 @@
+package host
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// TestAddBandwidth probes the buckets of addBandwidth.
+func TestAddBandwidth(t *testing.T) {
+	var bm modules.HostBandwidthMetrics
+	start := time.Unix(0, 0).Add(100 * bandwidthBucketDuration)
+
+	// Traffic within the same bucket should be added up.
+	addBandwidth(&bm, start, 1, 2)
+	addBandwidth(&bm, start.Add(bandwidthBucketDuration/2), 3, 4)
+	if len(bm.Buckets) != 1 || bm.Buckets[0].DownloadBytes != 4 || bm.Buckets[0].UploadBytes != 6 {
+		t.Fatal("wrong buckets:", bm.Buckets)
+	}
+	if !bm.Buckets[0].StartTime.Equal(start) {
+		t.Fatal("wrong bucket start time:", bm.Buckets[0].StartTime)
+	}
+
+	// Traffic in a later bucket should start a new bucket.
+	addBandwidth(&bm, start.Add(bandwidthBucketDuration), 5, 6)
+	if len(bm.Buckets) != 2 || bm.Buckets[1].DownloadBytes != 5 || bm.Buckets[1].UploadBytes != 6 {
+		t.Fatal("wrong buckets:", bm.Buckets)
+	}
+
+	// Only the most recent buckets should be kept, but the totals should
+	// cover all traffic.
+	for i := 2; i < bandwidthBucketLimit+10; i++ {
+		addBandwidth(&bm, start.Add(time.Duration(i)*bandwidthBucketDuration), 1, 1)
+	}
+	if len(bm.Buckets) != bandwidthBucketLimit {
+		t.Fatal("wrong number of buckets:", len(bm.Buckets))
+	}
+	if expected := start.Add(10 * bandwidthBucketDuration); !bm.Buckets[0].StartTime.Equal(expected) {
+		t.Fatal("wrong oldest bucket:", bm.Buckets[0].StartTime, expected)
+	}
+	if bm.DownloadBytes != 9+bandwidthBucketLimit+8 || bm.UploadBytes != 12+bandwidthBucketLimit+8 {
+		t.Fatal("wrong totals:", bm.DownloadBytes, bm.UploadBytes)
+	}
+	if bm.BucketDuration != bandwidthBucketDuration {
+		t.Fatal("wrong bucket duration:", bm.BucketDuration)
+	}
+}
+
+// TestHostConnTraffic checks that a hostConn counts its traffic and that the
+// traffic is recorded for the host and the storage obligation.
+func TestHostConnTraffic(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := blankHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	hostEnd, renterEnd := net.Pipe()
+	defer renterEnd.Close()
+	hc := ht.host.newHostConn(hostEnd)
+	var soid types.FileContractID
+	soid[0] = 1
+	renter := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1}}
+	ht.host.managedTrackConn(hc, soid, renter)
+
+	// The renter uploads 10 bytes and downloads 20 bytes.
+	go func() {
+		renterEnd.Write(make([]byte, 10))
+		io.ReadFull(renterEnd, make([]byte, 20))
+	}()
+	if _, err := io.ReadFull(hc, make([]byte, 10)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := hc.Write(make([]byte, 20)); err != nil {
+		t.Fatal(err)
+	}
+	hc.Close()
+	ht.host.managedRecordConn(hc)
+
+	bm := ht.host.BandwidthMetrics()
+	if bm.DownloadBytes != 20 || bm.UploadBytes != 10 || len(bm.Buckets) != 1 {
+		t.Fatal("wrong bandwidth metrics:", bm)
+	}
+	var ob obligationBandwidth
+	err = ht.host.db.View(func(tx *bolt.Tx) error {
+		ob = getObligationBandwidth(tx, soid)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ob.DownloadBytes != 20 || ob.UploadBytes != 10 {
+		t.Fatal("wrong obligation bandwidth:", ob)
+	}
+
+	// The renter should have been given a rate limit of its own.
+	if _, exists := ht.host.renterRateLimits[renter.String()]; !exists {
+		t.Fatal("renter has no rate limit")
+	}
+
+	// Negative bandwidth limits should be rejected.
+	settings := ht.host.InternalSettings()
+	settings.MaxRenterDownloadSpeed = -1
+	if err := ht.host.SetInternalSettings(settings); err == nil {
+		t.Fatal("expected an error for a negative bandwidth limit")
+	}
+}
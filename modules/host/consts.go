@@ -9,6 +9,10 @@ import (
 )
 
 const (
+	// bandwidthBucketLimit is the number of bandwidth buckets that the host
+	// keeps.
+	bandwidthBucketLimit = 168
+
 	// defaultMaxDuration defines the maximum number of blocks into the future
 	// that the host will accept for the duration of an incoming file contract
 	// obligation. 6 months is chosen because hosts are expected to be
@@ -26,6 +30,36 @@ const (
 	// connection.
 	iteratedConnectionTime = 1200 * time.Second
 
+	// priceAdjustmentHistoryLimit is the number of price adjustments of the
+	// pricing autopilot that the host keeps.
+	priceAdjustmentHistoryLimit = 100
+
+	// pricingDefaultTargetPercentile is the percentile of the prices of the
+	// other hosts that the pricing autopilot targets if the host has not
+	// configured a target.
+	pricingDefaultTargetPercentile = 50
+
+	// priceSourceMaxSettingsLen is the largest size in bytes of the settings
+	// that the announcement price source accepts from a host.
+	priceSourceMaxSettingsLen = 10e3
+
+	// priceSourceScanThreads is the number of hosts that the announcement
+	// price source asks for their settings at once.
+	priceSourceScanThreads = 20
+
+	// pricingMaxStep is the largest fraction by which the pricing autopilot
+	// changes a price at a time, so that a few outliers on the network can't
+	// make the host's prices jump.
+	pricingMaxStep = 0.1
+
+	// proofMaxFeeMultiplier is the largest multiple of the recommended fee
+	// that the host pays to get a storage proof confirmed.
+	proofMaxFeeMultiplier = 16
+
+	// rateLimitPacketSize is the packet size used by the host's bandwidth
+	// limits.
+	rateLimitPacketSize = 4 * 4096
+
 	// resubmissionTimeout defines the number of blocks that a host will wait
 	// before attempting to resubmit a transaction to the blockchain.
 	// Typically, this transaction will contain either a file contract, a file
@@ -34,6 +68,15 @@ const (
 )
 
 var (
+	// bandwidthBucketDuration is the period of time covered by each bucket of
+	// the host's bandwidth metrics. With an hour per bucket, the host keeps
+	// a week of bandwidth history.
+	bandwidthBucketDuration = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: time.Hour,
+		Testing:  time.Second,
+	}).(time.Duration)
+
 	// connectablityCheckFirstWait defines how often the host's connectability
 	// check is run.
 	connectabilityCheckFirstWait = build.Select(build.Var{
@@ -114,6 +157,12 @@ var (
 	// with a number like 65 MiB.
 	defaultMaxReviseBatchSize = 17 * (1 << 20)
 
+	// defaultSectorCacheSize is the number of bytes of recently read sectors
+	// that the host keeps in memory, so that popular sectors don't have to be
+	// read from disk every time a renter downloads them. 32 sectors is 128
+	// MiB.
+	defaultSectorCacheSize = 32 * modules.SectorSize
+
 	// defaultStoragePrice defines the starting price for hosts selling
 	// storage. We try to match a number that is both reasonably profitable and
 	// reasonably competitive.
@@ -179,6 +228,40 @@ var (
 		Testing:  time.Second * 3,
 	}).(time.Duration)
 
+	// pricingAutopilotFrequency defines how often the pricing autopilot
+	// adjusts the host's prices.
+	pricingAutopilotFrequency = build.Select(build.Var{
+		Dev:      time.Minute * 5,
+		Standard: time.Hour * 2,
+		Testing:  time.Second * 3,
+	}).(time.Duration)
+
+	// priceSourceScanTimeout is the time that a host has to send its settings
+	// to the announcement price source.
+	priceSourceScanTimeout = build.Select(build.Var{
+		Dev:      time.Second * 20,
+		Standard: time.Second * 60,
+		Testing:  time.Second * 5,
+	}).(time.Duration)
+
+	// pricingMinHosts is the number of other hosts that the pricing autopilot
+	// needs to know the prices of before it adjusts the host's prices.
+	pricingMinHosts = build.Select(build.Var{
+		Dev:      3,
+		Standard: 20,
+		Testing:  1,
+	}).(int)
+
+	// proofRiskBlocks is the number of blocks before the end of the proof
+	// window at which an unconfirmed storage proof is at risk. The host warns
+	// about storage proofs at risk and pays the maximum fee to get them
+	// confirmed.
+	proofRiskBlocks = build.Select(build.Var{
+		Dev:      types.BlockHeight(10),
+		Standard: types.BlockHeight(36), // 6 hours.
+		Testing:  types.BlockHeight(1),
+	}).(types.BlockHeight)
+
 	// revisionSubmissionBuffer describes the number of blocks ahead of time
 	// that the host will submit a file contract revision. The host will not
 	// accept any more revisions once inside the submission buffer.
@@ -237,6 +320,25 @@ var (
 	// bucketStorageObligations contains a set of serialized
 	// 'storageObligations' sorted by their file contract id.
 	bucketStorageObligations = []byte("BucketStorageObligations")
+
+	// bucketObligationBandwidth contains the bytes that have been downloaded
+	// and uploaded over the RPCs of each storage obligation, sorted by their
+	// file contract id. They are kept apart from the storage obligations so
+	// that they can be updated without holding the obligation's lock.
+	bucketObligationBandwidth = []byte("BucketObligationBandwidth")
+
+	// bucketLedger contains the financial events of the host, sorted by the
+	// time at which they happened.
+	bucketLedger = []byte("BucketLedger")
+
+	// bucketObligationRevisions contains the history of the file contract
+	// revisions of each storage obligation, in a nested bucket under the file
+	// contract id.
+	bucketObligationRevisions = []byte("BucketObligationRevisions")
+
+	// bucketObligationTransitions contains the history of the status of each
+	// storage obligation, in a nested bucket under the file contract id.
+	bucketObligationTransitions = []byte("BucketObligationTransitions")
 )
 
 // init runs a series of sanity checks to verify that the constants have sane
@@ -54,6 +54,11 @@ const (
 	// metadata of a single sector on disk.
 	sectorMetadataDiskSize = 14
 
+	// storageFolderMoveBufferSize is the amount of sector data that is copied
+	// at a time when a storage folder is moved to a new path. It must be a
+	// multiple of the sector size.
+	storageFolderMoveBufferSize = 1 << 25
+
 	// storageFolderGranularity defines the number of sectors that a storage
 	// folder must cleanly divide into. 64 sectors is a requirement due to the
 	// way the storage folder bitfield (field 'Usage') is constructed - the
@@ -129,3 +134,40 @@ var (
 		Testing:  time.Second * 8,
 	}).(time.Duration)
 )
+
+var (
+	// scrubCheckpointSectors is the number of sector slots that the scrubber
+	// advances through a storage folder before its position is saved, so
+	// that an interrupted scrub can resume close to where it stopped.
+	scrubCheckpointSectors = build.Select(build.Var{
+		Dev:      uint32(64),  // 256 MiB
+		Standard: uint32(256), // 1 GiB
+		Testing:  uint32(1),
+	}).(uint32)
+
+	// scrubFrequency is the amount of time that the scrubber waits after
+	// finishing a storage folder before it scrubs the folder again.
+	scrubFrequency = build.Select(build.Var{
+		Dev:      time.Hour,
+		Standard: time.Hour * 24 * 30,
+		Testing:  time.Minute,
+	}).(time.Duration)
+
+	// scrubIdleInterval is the amount of time that the scrubber waits before
+	// checking again if there is a storage folder that needs to be scrubbed.
+	scrubIdleInterval = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: time.Minute * 10,
+		Testing:  time.Second,
+	}).(time.Duration)
+
+	// scrubSectorDelay is the amount of time that the scrubber waits after
+	// reading a sector, which limits the disk IO that the scrubber can
+	// consume. On the standard network, sectors are 4 MiB, which limits the
+	// scrubber to roughly 40 MiB per second.
+	scrubSectorDelay = build.Select(build.Var{
+		Dev:      time.Millisecond * 10,
+		Standard: time.Millisecond * 100,
+		Testing:  time.Millisecond,
+	}).(time.Duration)
+)
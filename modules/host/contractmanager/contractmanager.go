@@ -93,6 +93,10 @@ type ContractManager struct {
 	// or modified.
 	lockedSectors map[sectorID]*sectorLock
 
+	// sectorCache keeps the most recently read sectors in memory. It is
+	// disabled until its size is set.
+	sectorCache *sectorCache
+
 	// Utilities.
 	dependencies modules.Dependencies
 	log          *persist.Logger
@@ -114,6 +118,7 @@ func newContractManager(dependencies modules.Dependencies, persistDir string) (*
 		sectorLocations: make(map[sectorID]sectorLocation),
 
 		lockedSectors: make(map[sectorID]*sectorLock),
+		sectorCache:   newSectorCache(0),
 
 		dependencies: dependencies,
 		persistDir:   persistDir,
@@ -212,6 +217,10 @@ func newContractManager(dependencies modules.Dependencies, persistDir string) (*
 	// and adds them if they are discovered.
 	go cm.threadedFolderRecheck()
 
+	// Spin up the thread that scrubs the storage folders for corrupt and
+	// missing sectors.
+	go cm.threadedScrub()
+
 	// Simulate an error to make sure the cleanup code is triggered correctly.
 	if cm.dependencies.Disrupt("erroredStartup") {
 		err = errors.New("startup disrupted")
@@ -1,9 +1,13 @@
 package contractmanager
 
 import (
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/fastrand"
 )
 
@@ -77,3 +81,58 @@ func BenchmarkStorageFolders(b *testing.B) {
 		randFreeSector(usage)
 	}
 }
+
+// benchmarkReadSector reads the same few sectors over and over, as a host
+// does when renters stream popular files, with a sector cache of the given
+// size.
+func benchmarkReadSector(b *testing.B, cacheSize uint64) {
+	cmt, err := newContractManagerTester(b.Name())
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer cmt.panicClose()
+	cmt.cm.SetSectorCacheSize(cacheSize)
+
+	storageFolderDir := filepath.Join(cmt.persistDir, "storageFolderOne")
+	err = os.MkdirAll(storageFolderDir, 0700)
+	if err != nil {
+		b.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var roots []crypto.Hash
+	for i := 0; i < 4; i++ {
+		root, data := randSector()
+		err = cmt.cm.AddSector(root, data)
+		if err != nil {
+			b.Fatal(err)
+		}
+		roots = append(roots, root)
+	}
+
+	b.SetBytes(int64(modules.SectorSize))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := cmt.cm.ReadSector(roots[i%len(roots)])
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	// Closing the contract manager waits for the sync loop, which should not
+	// count towards the reads.
+	b.StopTimer()
+}
+
+// BenchmarkReadSector measures repeated sector reads without the sector
+// cache, so every read goes to disk.
+func BenchmarkReadSector(b *testing.B) {
+	benchmarkReadSector(b, 0)
+}
+
+// BenchmarkReadSectorCached measures repeated sector reads with a sector
+// cache that holds all of the sectors being read.
+func BenchmarkReadSectorCached(b *testing.B) {
+	benchmarkReadSector(b, modules.SectorSize*8)
+}
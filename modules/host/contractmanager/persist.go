@@ -4,7 +4,9 @@ import (
 	"encoding/binary"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync/atomic"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
@@ -19,6 +21,11 @@ type (
 		Index uint16
 		Path  string
 		Usage []uint64
+
+		// The state of the integrity scrubber.
+		LastScrubbed  time.Time
+		ScrubCursor   uint32
+		ScrubFailures []scrubFailure
 	}
 
 	// savedSettings contains fields that are saved atomically to disk inside
@@ -37,6 +44,17 @@ func (sf *storageFolder) savedStorageFolder() savedStorageFolder {
 		Usage: make([]uint64, len(sf.usage)),
 	}
 	copy(ssf.Usage, sf.usage)
+
+	// Failures are saved in order of their index, so that the saved settings
+	// only change when the failures change.
+	ssf.LastScrubbed = sf.lastScrubbed
+	ssf.ScrubCursor = sf.scrubCheckpoint
+	for _, failure := range sf.scrubFailures {
+		ssf.ScrubFailures = append(ssf.ScrubFailures, failure)
+	}
+	sort.Slice(ssf.ScrubFailures, func(i, j int) bool {
+		return ssf.ScrubFailures[i].Index < ssf.ScrubFailures[j].Index
+	})
 	return ssf
 }
 
@@ -76,6 +94,13 @@ func (cm *ContractManager) loadSettings() error {
 		sf.index = ss.StorageFolders[i].Index
 		sf.path = ss.StorageFolders[i].Path
 		sf.usage = ss.StorageFolders[i].Usage
+		sf.lastScrubbed = ss.StorageFolders[i].LastScrubbed
+		sf.scrubCheckpoint = ss.StorageFolders[i].ScrubCursor
+		sf.scrubCursor = ss.StorageFolders[i].ScrubCursor
+		sf.scrubFailures = make(map[uint32]scrubFailure)
+		for _, failure := range ss.StorageFolders[i].ScrubFailures {
+			sf.scrubFailures[failure.Index] = failure
+		}
 		sf.metadataFile, err = cm.dependencies.OpenFile(filepath.Join(ss.StorageFolders[i].Path, metadataFile), os.O_RDWR, 0700)
 		if err != nil {
 			// Mark the folder as unavailable and log an error.
@@ -0,0 +1,234 @@
+package contractmanager
+
+// scrub.go implements the integrity scrubber of the contract manager. The
+// scrubber walks through the sectors of each storage folder, rereads them from
+// disk and checks that the Merkle root of the data matches the id that the
+// sector is stored under. Sectors that cannot be read are reported as missing,
+// sectors whose data does not match their id are reported as corrupt.
+//
+// The scrubber reads one sector at a time and sleeps after each read to limit
+// the disk IO that it consumes. Its position in each storage folder is saved
+// along with the contract manager settings, so that a scrub resumes where it
+// left off after a restart. Once a storage folder has been scrubbed
+// completely, it is scrubbed again after scrubFrequency has passed.
+
+import (
+	"sort"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// scrubFailure is a sector that failed its last scrub.
+type scrubFailure struct {
+	ID      sectorID
+	Index   uint32
+	Missing bool
+}
+
+// nextUsedSector returns the index of the first sector at or after start that
+// is marked as in use in the usage array.
+func nextUsedSector(usage []uint64, start uint32) (uint32, bool) {
+	for i := uint64(start); i < uint64(len(usage))*storageFolderGranularity; i++ {
+		if usage[i/storageFolderGranularity]&(1<<(i%storageFolderGranularity)) != 0 {
+			return uint32(i), true
+		}
+	}
+	return 0, false
+}
+
+// readSectorID reads the id of the sector at the provided index from the
+// metadata file of a storage folder.
+func readSectorID(f modules.File, sectorIndex uint32) (id sectorID, err error) {
+	_, err = f.ReadAt(id[:], sectorMetadataDiskSize*int64(sectorIndex))
+	return id, err
+}
+
+// scrubFailureCurrent returns whether a scrub failure still applies to the
+// sector that is stored at its location. A failure stops applying when the
+// sector is removed or moved, or when another sector takes its place.
+func (cm *ContractManager) scrubFailureCurrent(sf *storageFolder, index uint32, failure scrubFailure) bool {
+	sl, exists := cm.sectorLocations[failure.ID]
+	return exists && sl.storageFolder == sf.index && sl.index == index
+}
+
+// scrubFolder returns the storage folder that should be scrubbed next, or nil
+// if no folder needs to be scrubbed. Scrubs that are under way are finished
+// first, after that the folder whose last scrub is the oldest is picked.
+func (cm *ContractManager) scrubFolder() *storageFolder {
+	sfs := cm.availableStorageFolders()
+	sort.Slice(sfs, func(i, j int) bool {
+		return sfs[i].index < sfs[j].index
+	})
+	var oldest *storageFolder
+	for _, sf := range sfs {
+		if sf.scrubCursor > 0 {
+			return sf
+		}
+		if time.Since(sf.lastScrubbed) < scrubFrequency {
+			continue
+		}
+		if oldest == nil || sf.lastScrubbed.Before(oldest.lastScrubbed) {
+			oldest = sf
+		}
+	}
+	return oldest
+}
+
+// managedScrubSector scrubs the next sector of the storage folder that needs
+// scrubbing. false is returned if there was nothing to scrub.
+func (cm *ContractManager) managedScrubSector() bool {
+	err := cm.tg.Add()
+	if err != nil {
+		return false
+	}
+	defer cm.tg.Done()
+
+	// Pick the next sector. Folders that are being added, resized or removed
+	// are skipped until the operation has finished.
+	cm.wal.mu.Lock()
+	sf := cm.scrubFolder()
+	if sf == nil || !sf.mu.TryRLock() {
+		cm.wal.mu.Unlock()
+		return false
+	}
+	defer sf.mu.RUnlock()
+	index, found := nextUsedSector(sf.usage, sf.scrubCursor)
+	if !found {
+		cm.finishScrub(sf)
+		cm.wal.mu.Unlock()
+		return true
+	}
+	sf.scrubCursor = index + 1
+	if sf.scrubCursor-sf.scrubCheckpoint >= scrubCheckpointSectors {
+		sf.scrubCheckpoint = sf.scrubCursor
+	}
+	cm.wal.mu.Unlock()
+
+	// Look up the sector that is stored at the index, and make sure that it
+	// does not move while it is being scrubbed.
+	id, err := readSectorID(sf.metadataFile, index)
+	if err != nil {
+		cm.log.Printf("Unable to read the metadata of sector %v in storage folder %v: %v\n", index, sf.path, err)
+		return true
+	}
+	cm.wal.managedLockSector(id)
+	defer cm.wal.managedUnlockSector(id)
+	cm.wal.mu.Lock()
+	sl, exists := cm.sectorLocations[id]
+	cm.wal.mu.Unlock()
+	if !exists || sl.storageFolder != sf.index || sl.index != index {
+		return true
+	}
+
+	// Read the sector and check it against its id.
+	sectorData, err := readSector(sf.sectorFile, index)
+	var failed, missing bool
+	if err != nil {
+		failed, missing = true, true
+		cm.log.Printf("Scrub could not read sector %v in storage folder %v: %v\n", index, sf.path, err)
+	} else if cm.managedSectorID(crypto.MerkleRoot(sectorData)) != id {
+		failed = true
+		cm.log.Printf("Scrub found corrupt sector %v in storage folder %v\n", index, sf.path)
+	}
+
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+	if failed {
+		sf.scrubFailures[index] = scrubFailure{
+			ID:      id,
+			Index:   index,
+			Missing: missing,
+		}
+	} else {
+		delete(sf.scrubFailures, index)
+	}
+	return true
+}
+
+// finishScrub completes the scrub of a storage folder, dropping any failures
+// that no longer apply.
+func (cm *ContractManager) finishScrub(sf *storageFolder) {
+	for index, failure := range sf.scrubFailures {
+		if !cm.scrubFailureCurrent(sf, index, failure) {
+			delete(sf.scrubFailures, index)
+		}
+	}
+	sf.lastScrubbed = time.Now()
+	sf.scrubCheckpoint = 0
+	sf.scrubCursor = 0
+	if len(sf.scrubFailures) > 0 {
+		cm.log.Printf("Finished scrubbing storage folder %v, %v sectors are corrupt or missing\n", sf.path, len(sf.scrubFailures))
+	} else {
+		cm.log.Printf("Finished scrubbing storage folder %v\n", sf.path)
+	}
+}
+
+// threadedScrub continuously scrubs the storage folders of the contract
+// manager.
+func (cm *ContractManager) threadedScrub() {
+	// Don't spawn the loop if 'noScrub' disruption is set.
+	if cm.dependencies.Disrupt("noScrub") {
+		return
+	}
+
+	sleepTime := scrubIdleInterval
+	for {
+		select {
+		case <-cm.tg.StopChan():
+			return
+		case <-time.After(sleepTime):
+		}
+
+		if cm.managedScrubSector() {
+			sleepTime = scrubSectorDelay
+		} else {
+			sleepTime = scrubIdleInterval
+		}
+	}
+}
+
+// FailedSectors returns the roots out of sectorRoots whose sectors were found
+// to be corrupt or missing by the last scrub.
+func (cm *ContractManager) FailedSectors(sectorRoots []crypto.Hash) []crypto.Hash {
+	err := cm.tg.Add()
+	if err != nil {
+		return nil
+	}
+	defer cm.tg.Done()
+
+	// Most hosts have no failed sectors, skip computing the sector ids in
+	// that case.
+	var failures int
+	cm.wal.mu.Lock()
+	for _, sf := range cm.storageFolders {
+		failures += len(sf.scrubFailures)
+	}
+	cm.wal.mu.Unlock()
+	if failures == 0 {
+		return nil
+	}
+
+	ids := make([]sectorID, len(sectorRoots))
+	for i, root := range sectorRoots {
+		ids[i] = cm.managedSectorID(root)
+	}
+	var failed []crypto.Hash
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+	for i, id := range ids {
+		sl, exists := cm.sectorLocations[id]
+		if !exists {
+			continue
+		}
+		sf, exists := cm.storageFolders[sl.storageFolder]
+		if !exists {
+			continue
+		}
+		if failure, exists := sf.scrubFailures[sl.index]; exists && failure.ID == id {
+			failed = append(failed, sectorRoots[i])
+		}
+	}
+	return failed
+}
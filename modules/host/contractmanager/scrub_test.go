@@ -0,0 +1,226 @@
+package contractmanager
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// dependencyNoScrub prevents the scrub loop from running in the contract
+// manager, so that tests can scrub the storage folders manually.
+type dependencyNoScrub struct {
+	modules.ProductionDependencies
+}
+
+// Disrupt prevents the scrub loop from running in the contract manager.
+func (*dependencyNoScrub) Disrupt(s string) bool {
+	return s == "noScrub"
+}
+
+// waitForSettingsSync blocks until changes to the contract manager that are
+// not recorded in the WAL have been saved to the settings file. The settings
+// are written during one iteration of the sync loop and synced in the next.
+func (cmt *contractManagerTester) waitForSettingsSync() {
+	for i := 0; i < 2; i++ {
+		cmt.cm.wal.mu.Lock()
+		syncChan := cmt.cm.wal.syncChan
+		cmt.cm.wal.mu.Unlock()
+		<-syncChan
+	}
+}
+
+// TestNextUsedSector probes the search of nextUsedSector.
+func TestNextUsedSector(t *testing.T) {
+	usage := []uint64{1 << 3, 0, 1 << 63}
+	tests := []struct {
+		start uint32
+		index uint32
+		found bool
+	}{
+		{0, 3, true},
+		{3, 3, true},
+		{4, 191, true},
+		{191, 191, true},
+		{192, 0, false},
+		{500, 0, false},
+	}
+	for _, test := range tests {
+		index, found := nextUsedSector(usage, test.start)
+		if index != test.index || found != test.found {
+			t.Errorf("start %v: expected %v %v, got %v %v", test.start, test.index, test.found, index, found)
+		}
+	}
+}
+
+// TestScrub checks that the scrubber finds corrupt and missing sectors, that
+// the affected sectors are reported and that the results survive a restart.
+func TestScrub(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	d := new(dependencyNoScrub)
+	cmt, err := newMockedContractManagerTester(d, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Add a storage folder with three sectors.
+	storageFolderDir := filepath.Join(cmt.persistDir, "storageFolderOne")
+	err = os.MkdirAll(storageFolderDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roots []crypto.Hash
+	for i := 0; i < 3; i++ {
+		root, data := randSector()
+		err = cmt.cm.AddSector(root, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		roots = append(roots, root)
+	}
+
+	// A scrub of the healthy folder should not report any failures.
+	for cmt.cm.managedScrubSector() {
+	}
+	sfs := cmt.cm.StorageFolders()
+	if sfs[0].CorruptSectors != 0 || sfs[0].MissingSectors != 0 || sfs[0].LastScrubTime.IsZero() {
+		t.Fatal("wrong scrub results for a healthy folder:", sfs[0])
+	}
+	if failed := cmt.cm.FailedSectors(roots); len(failed) != 0 {
+		t.Fatal("healthy sectors reported as failed:", failed)
+	}
+
+	// Corrupt the first sector of the folder and cut the last sector off the
+	// end of the sector file.
+	cmt.cm.wal.mu.Lock()
+	sort.Slice(roots, func(i, j int) bool {
+		return cmt.cm.sectorLocations[cmt.cm.managedSectorID(roots[i])].index < cmt.cm.sectorLocations[cmt.cm.managedSectorID(roots[j])].index
+	})
+	sl0 := cmt.cm.sectorLocations[cmt.cm.managedSectorID(roots[0])]
+	sl1 := cmt.cm.sectorLocations[cmt.cm.managedSectorID(roots[2])]
+	sf := cmt.cm.storageFolders[sl0.storageFolder]
+	sf.lastScrubbed = sf.lastScrubbed.Add(-scrubFrequency)
+	cmt.cm.wal.mu.Unlock()
+	_, err = sf.sectorFile.WriteAt([]byte{1, 2, 3}, int64(uint64(sl0.index)*modules.SectorSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sf.sectorFile.Truncate(int64(uint64(sl1.index) * modules.SectorSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for cmt.cm.managedScrubSector() {
+	}
+	sfs = cmt.cm.StorageFolders()
+	if sfs[0].CorruptSectors != 1 || sfs[0].MissingSectors != 1 || sfs[0].ScrubProgress != 0 {
+		t.Fatal("wrong scrub results for a damaged folder:", sfs[0])
+	}
+	failed := cmt.cm.FailedSectors(roots)
+	if len(failed) != 2 || failed[0] != roots[0] || failed[1] != roots[2] {
+		t.Fatal("wrong failed sectors:", failed)
+	}
+
+	// Restart the contract manager and check that the results were kept.
+	cmt.waitForSettingsSync()
+	err = cmt.cm.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = newContractManager(d, filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sfs = cmt.cm.StorageFolders()
+	if sfs[0].CorruptSectors != 1 || sfs[0].MissingSectors != 1 {
+		t.Fatal("scrub results were not persisted:", sfs[0])
+	}
+	if failed := cmt.cm.FailedSectors(roots); len(failed) != 2 {
+		t.Fatal("wrong failed sectors after restart:", failed)
+	}
+
+	// Removing the corrupt sector should clear its failure.
+	err = cmt.cm.RemoveSector(roots[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sfs = cmt.cm.StorageFolders()
+	if sfs[0].CorruptSectors != 0 || sfs[0].MissingSectors != 1 {
+		t.Fatal("failure of a removed sector is still reported:", sfs[0])
+	}
+}
+
+// TestScrubResume checks that an interrupted scrub resumes from its last
+// checkpoint after a restart.
+func TestScrubResume(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	d := new(dependencyNoScrub)
+	cmt, err := newMockedContractManagerTester(d, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	storageFolderDir := filepath.Join(cmt.persistDir, "storageFolderOne")
+	err = os.MkdirAll(storageFolderDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		root, data := randSector()
+		err = cmt.cm.AddSector(root, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Scrub the first sector and restart.
+	if !cmt.cm.managedScrubSector() {
+		t.Fatal("nothing was scrubbed")
+	}
+	cursor := cmt.cm.StorageFolders()[0].ScrubProgress
+	if cursor == 0 {
+		t.Fatal("scrub did not advance")
+	}
+	cmt.waitForSettingsSync()
+	err = cmt.cm.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = newContractManager(d, filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress := cmt.cm.StorageFolders()[0].ScrubProgress; progress != cursor {
+		t.Fatal("scrub progress was not persisted:", progress, cursor)
+	}
+
+	// The scrub should be finished after the second sector.
+	if !cmt.cm.managedScrubSector() || !cmt.cm.managedScrubSector() {
+		t.Fatal("scrub stopped early")
+	}
+	if cmt.cm.managedScrubSector() {
+		t.Fatal("folder was scrubbed again right away")
+	}
+	if sfs := cmt.cm.StorageFolders(); sfs[0].ScrubProgress != 0 || sfs[0].LastScrubTime.IsZero() {
+		t.Fatal("scrub did not finish:", sfs[0])
+	}
+}
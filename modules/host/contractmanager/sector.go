@@ -32,7 +32,7 @@ var (
 	errMaxVirtualSectors = errors.New("sector collides with a physical sector that already has the maximum allowed number of virtual sectors")
 
 	// ErrSectorNotFound is returned when a lookup for a sector fails.
-	ErrSectorNotFound = errors.New("could not find the desired sector")
+	ErrSectorNotFound = modules.ErrSectorNotFound
 )
 
 // sectorLocation indicates the location of a sector on disk.
@@ -152,16 +152,57 @@ func (cm *ContractManager) ReadSector(root crypto.Hash) ([]byte, error) {
 		return nil, ErrSectorNotFound
 	}
 
-	// Read the sector.
+	// Serve the sector from the cache if possible, otherwise read the sector
+	// and add it to the cache.
+	if sectorData, cached := cm.sectorCache.get(id); cached {
+		return sectorData, nil
+	}
+	sf.fileMu.RLock()
 	sectorData, err := readSector(sf.sectorFile, sl.index)
+	sf.fileMu.RUnlock()
 	if err != nil {
 		atomic.AddUint64(&sf.atomicFailedReads, 1)
 		return nil, build.ExtendErr("unable to fetch sector", err)
 	}
 	atomic.AddUint64(&sf.atomicSuccessfulReads, 1)
+	cm.sectorCache.add(id, sl.storageFolder, sectorData)
 	return sectorData, nil
 }
 
+// SectorLocations returns the storage folder and the index within that folder
+// of each of the sectors with the provided roots.
+func (cm *ContractManager) SectorLocations(sectorRoots []crypto.Hash) []modules.SectorLocation {
+	err := cm.tg.Add()
+	if err != nil {
+		return nil
+	}
+	defer cm.tg.Done()
+
+	ids := make([]sectorID, len(sectorRoots))
+	for i, root := range sectorRoots {
+		ids[i] = cm.managedSectorID(root)
+	}
+	locations := make([]modules.SectorLocation, len(sectorRoots))
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+	for i, id := range ids {
+		sl, exists := cm.sectorLocations[id]
+		if !exists {
+			continue
+		}
+		sf, exists := cm.storageFolders[sl.storageFolder]
+		if !exists {
+			continue
+		}
+		locations[i] = modules.SectorLocation{
+			Found:         true,
+			Index:         sl.index,
+			StorageFolder: sf.path,
+		}
+	}
+	return locations
+}
+
 // managedLockSector grabs a sector lock.
 func (wal *writeAheadLog) managedLockSector(id sectorID) {
 	wal.mu.Lock()
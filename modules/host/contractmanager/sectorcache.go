@@ -0,0 +1,169 @@
+package contractmanager
+
+// sectorcache.go implements an in-memory cache of recently read sectors.
+// Streaming renters download popular sectors over and over, so the contract
+// manager keeps the sectors it has read most recently in memory instead of
+// reading them from disk on every request.
+//
+// The cache is a least-recently-used cache sized in bytes. A sector is only
+// served from the cache after the sector metadata has been checked, so a
+// sector that has been removed is never returned. Sectors are still removed
+// from the cache as soon as they are deleted or removed from the contract
+// manager, and the sectors of a storage folder are removed when the folder is
+// moved, so that reads after a move are served from the new path.
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+type (
+	// sectorCache is a least-recently-used cache of sector data. The most
+	// recently used sector is at the front of the list.
+	sectorCache struct {
+		capacity uint64
+		size     uint64
+		entries  map[sectorID]*list.Element
+		lru      *list.List
+
+		hits   uint64
+		misses uint64
+
+		mu sync.Mutex
+	}
+
+	// sectorCacheEntry is a sector held by the sector cache, along with the
+	// storage folder that the sector was read from.
+	sectorCacheEntry struct {
+		data          []byte
+		id            sectorID
+		storageFolder uint16
+	}
+)
+
+// newSectorCache returns a sector cache that holds up to capacity bytes of
+// sector data. A capacity of zero disables the cache.
+func newSectorCache(capacity uint64) *sectorCache {
+	return &sectorCache{
+		capacity: capacity,
+		entries:  make(map[sectorID]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// removeElement removes an element from the cache. The caller must hold the
+// lock of the cache.
+func (sc *sectorCache) removeElement(e *list.Element) {
+	entry := sc.lru.Remove(e).(*sectorCacheEntry)
+	delete(sc.entries, entry.id)
+	sc.size -= uint64(len(entry.data))
+}
+
+// evict removes the least recently used sectors until the cache fits its
+// capacity. The caller must hold the lock of the cache.
+func (sc *sectorCache) evict() {
+	for sc.size > sc.capacity {
+		sc.removeElement(sc.lru.Back())
+	}
+}
+
+// add adds a sector to the cache, evicting the least recently used sectors if
+// the cache is full. The cache keeps its own copy of the data.
+func (sc *sectorCache) add(id sectorID, storageFolder uint16, data []byte) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if uint64(len(data)) > sc.capacity {
+		return
+	}
+	if e, exists := sc.entries[id]; exists {
+		sc.removeElement(e)
+	}
+	entry := &sectorCacheEntry{
+		data:          append([]byte(nil), data...),
+		id:            id,
+		storageFolder: storageFolder,
+	}
+	sc.entries[id] = sc.lru.PushFront(entry)
+	sc.size += uint64(len(data))
+	sc.evict()
+}
+
+// get returns a copy of the data of a sector if the sector is in the cache.
+// The copy can be modified by the caller without affecting the cache.
+func (sc *sectorCache) get(id sectorID) ([]byte, bool) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.capacity == 0 {
+		return nil, false
+	}
+	e, exists := sc.entries[id]
+	if !exists {
+		sc.misses++
+		return nil, false
+	}
+	sc.hits++
+	sc.lru.MoveToFront(e)
+	return append([]byte(nil), e.Value.(*sectorCacheEntry).data...), true
+}
+
+// metrics returns the size and the hit rate of the cache.
+func (sc *sectorCache) metrics() modules.SectorCacheMetrics {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	scm := modules.SectorCacheMetrics{
+		Capacity: sc.capacity,
+		Size:     sc.size,
+		Sectors:  uint64(len(sc.entries)),
+		Hits:     sc.hits,
+		Misses:   sc.misses,
+	}
+	if sc.hits+sc.misses > 0 {
+		scm.HitRate = float64(sc.hits) / float64(sc.hits+sc.misses)
+	}
+	return scm
+}
+
+// remove removes a sector from the cache.
+func (sc *sectorCache) remove(id sectorID) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if e, exists := sc.entries[id]; exists {
+		sc.removeElement(e)
+	}
+}
+
+// removeStorageFolder removes all sectors that were read from a storage
+// folder from the cache.
+func (sc *sectorCache) removeStorageFolder(index uint16) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for e := sc.lru.Front(); e != nil; {
+		next := e.Next()
+		if e.Value.(*sectorCacheEntry).storageFolder == index {
+			sc.removeElement(e)
+		}
+		e = next
+	}
+}
+
+// setCapacity changes the capacity of the cache, evicting sectors if the
+// cache no longer fits.
+func (sc *sectorCache) setCapacity(capacity uint64) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.capacity = capacity
+	sc.evict()
+}
+
+// SectorCacheMetrics returns the size and the hit rate of the sector cache.
+func (cm *ContractManager) SectorCacheMetrics() modules.SectorCacheMetrics {
+	return cm.sectorCache.metrics()
+}
+
+// SetSectorCacheSize sets the number of bytes of sector data that the
+// contract manager keeps in memory. A size of zero disables the cache.
+func (cm *ContractManager) SetSectorCacheSize(size uint64) {
+	cm.sectorCache.setCapacity(size)
+}
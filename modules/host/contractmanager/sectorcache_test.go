@@ -0,0 +1,142 @@
+package contractmanager
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// TestSectorCacheLRU probes the eviction and the metrics of the sector cache.
+func TestSectorCacheLRU(t *testing.T) {
+	sc := newSectorCache(30)
+	ids := []sectorID{{1}, {2}, {3}, {4}}
+	for _, id := range ids[:3] {
+		sc.add(id, 0, bytes.Repeat(id[:1], 10))
+	}
+
+	// Reading the first sector makes the second sector the least recently
+	// used one, so it is evicted when the fourth sector is added.
+	data, cached := sc.get(ids[0])
+	if !cached || !bytes.Equal(data, bytes.Repeat([]byte{1}, 10)) {
+		t.Fatal("first sector was not served from the cache")
+	}
+	sc.add(ids[3], 0, bytes.Repeat([]byte{4}, 10))
+	if _, cached := sc.get(ids[1]); cached {
+		t.Fatal("least recently used sector was not evicted")
+	}
+	for _, id := range []sectorID{ids[0], ids[2], ids[3]} {
+		if _, cached := sc.get(id); !cached {
+			t.Fatal("sector was evicted:", id)
+		}
+	}
+
+	// The cache hands out copies of its data.
+	data[0] = 0
+	if data, _ := sc.get(ids[0]); data[0] != 1 {
+		t.Fatal("modifying the returned data changed the cache")
+	}
+
+	// Sectors larger than the cache are not cached.
+	sc.add(sectorID{5}, 0, make([]byte, 31))
+	if _, cached := sc.get(sectorID{5}); cached {
+		t.Fatal("sector larger than the cache was cached")
+	}
+
+	scm := sc.metrics()
+	if scm.Capacity != 30 || scm.Size != 30 || scm.Sectors != 3 || scm.Hits != 5 || scm.Misses != 2 {
+		t.Fatal("wrong metrics:", scm)
+	}
+	if scm.HitRate != 5.0/7.0 {
+		t.Fatal("wrong hit rate:", scm.HitRate)
+	}
+
+	// Shrinking the cache evicts the least recently used sectors, and a size
+	// of zero disables the cache without counting misses.
+	sc.setCapacity(10)
+	if scm := sc.metrics(); scm.Size != 10 || scm.Sectors != 1 {
+		t.Fatal("cache was not shrunk:", scm)
+	}
+	if _, cached := sc.get(ids[0]); !cached {
+		t.Fatal("most recently used sector was evicted")
+	}
+	sc.setCapacity(0)
+	if _, cached := sc.get(ids[0]); cached {
+		t.Fatal("disabled cache served a sector")
+	}
+	if scm := sc.metrics(); scm.Size != 0 || scm.Sectors != 0 || scm.Hits != 6 || scm.Misses != 2 {
+		t.Fatal("wrong metrics of the disabled cache:", scm)
+	}
+}
+
+// TestSectorCacheInvalidation checks that sectors are removed from the sector
+// cache when they are removed from the contract manager, and when their
+// storage folder is moved.
+func TestSectorCacheInvalidation(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+	cmt.cm.SetSectorCacheSize(modules.SectorSize * 10)
+
+	storageFolderOne := filepath.Join(cmt.persistDir, "storageFolderOne")
+	sectors := addMoveTestSectors(t, cmt, storageFolderOne)
+
+	// Read every sector twice, the second read is served from the cache.
+	for i := 0; i < 2; i++ {
+		checkMovedSectors(t, cmt, sectors)
+	}
+	scm := cmt.cm.SectorCacheMetrics()
+	if scm.Sectors != 5 || scm.Hits != 5 || scm.Misses != 5 || scm.HitRate != 0.5 {
+		t.Fatal("wrong cache metrics:", scm)
+	}
+
+	// Removed and deleted sectors are removed from the cache.
+	var removed int
+	for root := range sectors {
+		if removed == 0 {
+			err = cmt.cm.RemoveSector(root)
+		} else {
+			err = cmt.cm.DeleteSector(root)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cmt.cm.ReadSector(root); err != ErrSectorNotFound {
+			t.Fatal("removed sector can still be read:", err)
+		}
+		delete(sectors, root)
+		if removed++; removed == 2 {
+			break
+		}
+	}
+	if scm := cmt.cm.SectorCacheMetrics(); scm.Sectors != 3 || scm.Size != 3*modules.SectorSize {
+		t.Fatal("removed sectors were not removed from the cache:", scm)
+	}
+
+	// Moving the storage folder empties the cache of its sectors, and the
+	// sectors are read from the new path afterwards.
+	storageFolderTwo := filepath.Join(cmt.persistDir, "storageFolderTwo")
+	err = os.MkdirAll(storageFolderTwo, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.MoveStorageFolder(cmt.cm.StorageFolders()[0].Index, storageFolderTwo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scm := cmt.cm.SectorCacheMetrics(); scm.Sectors != 0 || scm.Size != 0 {
+		t.Fatal("sectors of the moved storage folder were not removed from the cache:", scm)
+	}
+	checkMovedSectors(t, cmt, sectors)
+	if scm := cmt.cm.SectorCacheMetrics(); scm.Sectors != 3 || scm.Misses != 8 {
+		t.Fatal("sectors were not read from disk after the move:", scm)
+	}
+}
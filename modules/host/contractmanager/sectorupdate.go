@@ -84,6 +84,7 @@ func (wal *writeAheadLog) managedAddPhysicalSector(id sectorID, data []byte, cou
 			}
 			// Set the usage, but mark it as uncommitted.
 			sf.setUsage(sectorIndex)
+			sf.markMovedSector(sectorIndex)
 			sf.availableSectors[id] = sectorIndex
 			wal.mu.Unlock()
 
@@ -249,6 +250,7 @@ func (wal *writeAheadLog) managedDeleteSector(id sectorID) error {
 		// Delete the sector and mark the usage as available.
 		delete(wal.cm.sectorLocations, id)
 		sf.availableSectors[id] = location.index
+		wal.cm.sectorCache.remove(id)
 
 		// Block until the change has been committed.
 		syncChan = wal.syncChan
@@ -310,6 +312,7 @@ func (wal *writeAheadLog) managedRemoveSector(id sectorID) error {
 			// Delete the sector and mark it as available.
 			delete(wal.cm.sectorLocations, id)
 			sf.availableSectors[id] = location.index
+			wal.cm.sectorCache.remove(id)
 		} else {
 			// Reduce the sector usage.
 			wal.cm.sectorLocations[id] = location
@@ -121,9 +121,29 @@ type storageFolder struct {
 	availableSectors map[sectorID]uint32
 	sectors          uint64
 
+	// The state of the integrity scrubber. scrubCursor is the index of the
+	// next sector slot to scrub, it is zero if no scrub is under way.
+	// scrubCheckpoint is the last position of the cursor that has been
+	// queued to be saved to disk. scrubFailures contains the sectors that
+	// failed their last scrub, keyed by their index in the storage folder.
+	lastScrubbed    time.Time
+	scrubCheckpoint uint32
+	scrubCursor     uint32
+	scrubFailures   map[uint32]scrubFailure
+
+	// movedSectors contains the sector slots that were written while the
+	// storage folder is being moved. These sectors need to be copied again
+	// before the storage folder switches to its new files. movedSectors is
+	// nil if no move is under way.
+	movedSectors map[uint32]struct{}
+
 	// An open file handle is kept so that writes can easily be made to the
 	// storage folder without needing to grab a new file handle. This also
 	// makes it easy to do delayed-syncing.
+	//
+	// fileMu needs to be RLocked to read sectors without holding mu, and
+	// Locked when the file handles are replaced.
+	fileMu       sync.TryRWMutex
 	metadataFile modules.File
 	sectorFile   modules.File
 }
@@ -267,6 +287,14 @@ func (sf *storageFolder) setUsage(sectorIndex uint32) {
 	}
 }
 
+// markMovedSector records that a sector slot is being written while the
+// storage folder is moved, so that the sector is copied to the new files.
+func (sf *storageFolder) markMovedSector(sectorIndex uint32) {
+	if sf.movedSectors != nil {
+		sf.movedSectors[sectorIndex] = struct{}{}
+	}
+}
+
 // availableStorageFolders returns the contract manager's storage folders as a
 // slice, excluding any unavailable storeage folders.
 func (cm *ContractManager) availableStorageFolders() []*storageFolder {
@@ -419,6 +447,19 @@ func (cm *ContractManager) StorageFolders() []modules.StorageFolderMetadata {
 			CapacityRemaining: ((64 * uint64(len(sf.usage))) - sf.sectors) * modules.SectorSize,
 			Index:             sf.index,
 			Path:              sf.path,
+
+			LastScrubTime: sf.lastScrubbed,
+			ScrubProgress: uint64(sf.scrubCursor) * modules.SectorSize,
+		}
+		for index, failure := range sf.scrubFailures {
+			if !cm.scrubFailureCurrent(sf, index, failure) {
+				continue
+			}
+			if failure.Missing {
+				sfm.MissingSectors++
+			} else {
+				sfm.CorruptSectors++
+			}
 		}
 
 		// Set some of the values to extreme numbers if the storage folder is
@@ -310,6 +310,7 @@ func (wal *writeAheadLog) commitAddStorageFolder(ssf savedStorageFolder) {
 		usage: ssf.Usage,
 
 		availableSectors: make(map[sectorID]uint32),
+		scrubFailures:    make(map[uint32]scrubFailure),
 	}
 
 	var err error
@@ -366,6 +367,7 @@ func (cm *ContractManager) AddStorageFolder(path string, size uint64) error {
 		usage: make([]uint64, sectors/64),
 
 		availableSectors: make(map[sectorID]uint32),
+		scrubFailures:    make(map[uint32]scrubFailure),
 	}
 	err = cm.wal.managedAddStorageFolder(newSF)
 	if err != nil {
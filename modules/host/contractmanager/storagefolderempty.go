@@ -83,6 +83,7 @@ func (wal *writeAheadLog) managedMoveSector(id sectorID) error {
 			}
 			// Set the usage, but mark it as uncommitted.
 			sf.setUsage(sectorIndex)
+			sf.markMovedSector(sectorIndex)
 			sf.availableSectors[id] = sectorIndex
 			wal.mu.Unlock()
 
@@ -133,6 +134,7 @@ func (wal *writeAheadLog) managedMoveSector(id sectorID) error {
 			delete(wal.cm.sectorLocations, oldSU.ID)
 			delete(sf.availableSectors, id)
 			wal.cm.sectorLocations[id] = sl
+			wal.cm.sectorCache.remove(id)
 			wal.mu.Unlock()
 			return nil
 		}()
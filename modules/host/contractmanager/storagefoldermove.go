@@ -0,0 +1,460 @@
+package contractmanager
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+var (
+	// errMoveInterrupted is returned if the contract manager shuts down while
+	// a storage folder is being moved. The storage folder stays at its old
+	// path.
+	errMoveInterrupted = errors.New("storage folder move interrupted by shutdown")
+
+	// errSameStorageFolderPath is returned if a storage folder is moved to the
+	// path that it already has.
+	errSameStorageFolderPath = errors.New("storage folder is already at that path")
+
+	// errStorageFolderChanged is returned if a storage folder is resized or
+	// removed while it is being moved.
+	errStorageFolderChanged = errors.New("storage folder was changed while it was being moved")
+
+	// errStorageFolderMoving is returned if a storage folder is moved while it
+	// is already being moved.
+	errStorageFolderMoving = errors.New("storage folder is already being moved")
+)
+
+type (
+	// storageFolderMove is the data saved to the WAL to indicate that a
+	// storage folder has been copied to a new path successfully.
+	storageFolderMove struct {
+		Index   uint16
+		OldPath string
+		NewPath string
+	}
+
+	// unfinishedStorageFolderMove contains the data necessary to clean up a
+	// storage folder move that has failed.
+	unfinishedStorageFolderMove struct {
+		Index   uint16
+		NewPath string
+	}
+)
+
+// findUnfinishedStorageFolderMoves will scroll through a set of state changes
+// and pull out all of the storage folder moves which have not yet completed.
+func findUnfinishedStorageFolderMoves(scs []stateChange) []unfinishedStorageFolderMove {
+	// Use a map to figure out what unfinished storage folder moves exist and
+	// use it to remove the ones that have terminated.
+	usfmMap := make(map[uint16]unfinishedStorageFolderMove)
+	for _, sc := range scs {
+		for _, usfm := range sc.UnfinishedStorageFolderMoves {
+			usfmMap[usfm.Index] = usfm
+		}
+		for _, sfm := range sc.StorageFolderMoves {
+			delete(usfmMap, sfm.Index)
+		}
+		for _, index := range sc.ErroredStorageFolderMoves {
+			delete(usfmMap, index)
+		}
+		for _, sfr := range sc.StorageFolderRemovals {
+			delete(usfmMap, sfr.Index)
+		}
+	}
+
+	// Return the active unfinished storage folder moves as a slice.
+	usfms := make([]unfinishedStorageFolderMove, 0, len(usfmMap))
+	for _, usfm := range usfmMap {
+		usfms = append(usfms, usfm)
+	}
+	return usfms
+}
+
+// cleanupUnfinishedStorageFolderMoves will remove the partial copies of any
+// unsuccessful storage folder moves from the previous run. The storage folders
+// themselves were never switched to the new path.
+func (wal *writeAheadLog) cleanupUnfinishedStorageFolderMoves(scs []stateChange) {
+	usfms := findUnfinishedStorageFolderMoves(scs)
+	for _, usfm := range usfms {
+		removeStorageFolderFiles(wal.cm, usfm.NewPath)
+
+		// Append an error call to the changeset, indicating that the storage
+		// folder move was not completed successfully.
+		wal.appendChange(stateChange{
+			ErroredStorageFolderMoves: []uint16{usfm.Index},
+		})
+	}
+}
+
+// removeStorageFolderFiles removes the metadata and sector files from a path,
+// ignoring files that do not exist.
+func removeStorageFolderFiles(cm *ContractManager, path string) {
+	for _, name := range []string{metadataFile, sectorFile} {
+		err := cm.dependencies.RemoveFile(filepath.Join(path, name))
+		if err != nil && !os.IsNotExist(err) {
+			cm.log.Printf("Error: unable to remove %v from %v: %v\n", name, path, err)
+		}
+	}
+}
+
+// commitStorageFolderMove will switch a storage folder to the files at its
+// new path and remove the files at its old path.
+func (wal *writeAheadLog) commitStorageFolderMove(sfm storageFolderMove) {
+	sf, exists := wal.cm.storageFolders[sfm.Index]
+	if !exists {
+		wal.cm.log.Critical("ERROR: storage folder move provided for storage folder that does not exist")
+		return
+	}
+
+	if sf.path != sfm.NewPath {
+		// Sectors can be read without holding the storage folder lock, so the
+		// file handles are swapped while holding the file lock.
+		sf.fileMu.Lock()
+		defer sf.fileMu.Unlock()
+
+		// Close the files at the old path.
+		if atomic.LoadUint64(&sf.atomicUnavailable) == 0 {
+			err := build.ComposeErrors(sf.metadataFile.Close(), sf.sectorFile.Close())
+			if err != nil {
+				wal.cm.log.Printf("Error: unable to close the files of storage folder %v: %v\n", sf.path, err)
+			}
+		}
+		sf.path = sfm.NewPath
+
+		// Open the files at the new path. If that fails, the storage folder
+		// is marked as unavailable until the files can be found.
+		var err1, err2 error
+		sf.metadataFile, err1 = wal.cm.dependencies.OpenFile(filepath.Join(sf.path, metadataFile), os.O_RDWR, 0700)
+		sf.sectorFile, err2 = wal.cm.dependencies.OpenFile(filepath.Join(sf.path, sectorFile), os.O_RDWR, 0700)
+		if err1 == nil && err2 == nil {
+			atomic.StoreUint64(&sf.atomicUnavailable, 0)
+		} else {
+			atomic.StoreUint64(&sf.atomicUnavailable, 1)
+			if err1 == nil {
+				sf.metadataFile.Close()
+			}
+			if err2 == nil {
+				sf.sectorFile.Close()
+			}
+			wal.cm.log.Printf("ERROR: unable to open the files of moved storage folder %v: %v\n", sf.path, build.ComposeErrors(err1, err2))
+		}
+	}
+
+	// The files at the old path are only removed once the storage folder is
+	// available at the new path, so that no data is lost if the new files have
+	// gone missing.
+	if atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+		return
+	}
+	err := wal.writeMovedMetadata(sf)
+	if err != nil {
+		wal.cm.log.Printf("ERROR: unable to update the metadata of moved storage folder %v: %v\n", sf.path, err)
+	}
+	removeStorageFolderFiles(wal.cm, sfm.OldPath)
+}
+
+// overlaySectorMetadata writes the metadata of all sectors of a storage folder
+// that are held in memory over the metadata that was read from disk. Sector
+// updates are applied in memory before they are written to the metadata file.
+func (wal *writeAheadLog) overlaySectorMetadata(sf *storageFolder, metadata []byte) {
+	for id, sl := range wal.cm.sectorLocations {
+		if sl.storageFolder != sf.index {
+			continue
+		}
+		entry := metadata[sectorMetadataDiskSize*int(sl.index):]
+		copy(entry, id[:])
+		binary.LittleEndian.PutUint16(entry[12:], sl.count)
+	}
+}
+
+// writeMovedMetadata writes the metadata of all sectors that are held in
+// memory for a moved storage folder to its new metadata file. Sector updates
+// that were made after the metadata was copied only reached the old file.
+func (wal *writeAheadLog) writeMovedMetadata(sf *storageFolder) error {
+	metadata, err := readFullMetadata(sf.metadataFile, len(sf.usage)*storageFolderGranularity)
+	if err != nil {
+		atomic.AddUint64(&sf.atomicFailedReads, 1)
+		return err
+	}
+	wal.overlaySectorMetadata(sf, metadata)
+	_, err = sf.metadataFile.WriteAt(metadata, 0)
+	if err != nil {
+		atomic.AddUint64(&sf.atomicFailedWrites, 1)
+		return err
+	}
+	return sf.metadataFile.Sync()
+}
+
+// managedMoveStorageFolder copies the files of a storage folder to a new path
+// and then switches the storage folder to the new files through the WAL. The
+// storage folder must be locked by the caller. The lock is downgraded to a
+// read lock while the sectors are copied, so that sectors can be read from and
+// added to the storage folder for most of the move, and it is held again when
+// the function returns.
+func (wal *writeAheadLog) managedMoveStorageFolder(sf *storageFolder, newPath string) (err error) {
+	metadataName := filepath.Join(newPath, metadataFile)
+	sectorName := filepath.Join(newPath, sectorFile)
+
+	// Create the files at the new path and record the unfinished move in the
+	// WAL, so that the copies can be removed after an unclean shutdown.
+	var newMetadataFile, newSectorFile modules.File
+	var usage []uint64
+	var syncChan chan struct{}
+	err = func() error {
+		wal.mu.Lock()
+		defer wal.mu.Unlock()
+
+		if _, exists := wal.cm.storageFolders[sf.index]; !exists {
+			return errStorageFolderNotFound
+		}
+		if sf.path == newPath {
+			return errSameStorageFolderPath
+		}
+		if sf.movedSectors != nil {
+			return errStorageFolderMoving
+		}
+		for _, csf := range wal.cm.storageFolders {
+			if csf.path == newPath {
+				return ErrRepeatFolder
+			}
+		}
+
+		var err error
+		newMetadataFile, err = wal.cm.dependencies.CreateFile(metadataName)
+		if err != nil {
+			return build.ExtendErr("could not create storage folder file", err)
+		}
+		newSectorFile, err = wal.cm.dependencies.CreateFile(sectorName)
+		if err != nil {
+			err = build.ComposeErrors(err, newMetadataFile.Close())
+			err = build.ComposeErrors(err, wal.cm.dependencies.RemoveFile(metadataName))
+			return build.ExtendErr("could not create storage folder file", err)
+		}
+
+		// No sectors are being written to the storage folder while it is
+		// locked, so the sectors in the current usage are copied first.
+		// Sectors that are written later are tracked in movedSectors.
+		usage = append([]uint64(nil), sf.usage...)
+		sf.movedSectors = make(map[uint32]struct{})
+		wal.appendChange(stateChange{
+			UnfinishedStorageFolderMoves: []unfinishedStorageFolderMove{{
+				Index:   sf.index,
+				NewPath: newPath,
+			}},
+		})
+		syncChan = wal.syncChan
+		return nil
+	}()
+	if err != nil {
+		return err
+	}
+	<-syncChan
+
+	// If there's an error in the rest of the function, the copies need to be
+	// removed and the WAL needs to be informed that the move has failed.
+	defer func() {
+		atomic.StoreUint64(&sf.atomicProgressNumerator, 0)
+		atomic.StoreUint64(&sf.atomicProgressDenominator, 0)
+		wal.mu.Lock()
+		defer wal.mu.Unlock()
+		sf.movedSectors = nil
+		if err != nil {
+			err = build.ComposeErrors(err, newMetadataFile.Close())
+			err = build.ComposeErrors(err, newSectorFile.Close())
+			removeStorageFolderFiles(wal.cm, newPath)
+			wal.appendChange(stateChange{
+				ErroredStorageFolderMoves: []uint16{sf.index},
+			})
+		}
+	}()
+
+	// Copy the sector data in chunks of storageFolderGranularity sectors,
+	// skipping the chunks that hold no sectors. Only a read lock is held on
+	// the storage folder during the copy.
+	numSectors := uint64(len(usage)) * storageFolderGranularity
+	sf.mu.Unlock()
+	sf.mu.RLock()
+	err = func() error {
+		err := newSectorFile.Truncate(int64(numSectors * modules.SectorSize))
+		if err != nil {
+			return build.ExtendErr("could not allocate sector data file", err)
+		}
+		err = newMetadataFile.Truncate(int64(numSectors * sectorMetadataDiskSize))
+		if err != nil {
+			return build.ExtendErr("could not allocate sector metadata file", err)
+		}
+		chunkSize := storageFolderGranularity * modules.SectorSize
+		var usedChunks uint64
+		for _, u := range usage {
+			if u != 0 {
+				usedChunks++
+			}
+		}
+		atomic.StoreUint64(&sf.atomicProgressDenominator, usedChunks*chunkSize)
+		bufSize := uint64(storageFolderMoveBufferSize)
+		if bufSize > chunkSize {
+			bufSize = chunkSize
+		}
+		buf := make([]byte, bufSize)
+		for i, u := range usage {
+			if u == 0 {
+				continue
+			}
+			for offset := uint64(i) * chunkSize; offset < uint64(i+1)*chunkSize; offset += bufSize {
+				select {
+				case <-wal.cm.tg.StopChan():
+					return errMoveInterrupted
+				default:
+				}
+				_, err = sf.sectorFile.ReadAt(buf, int64(offset))
+				if err != nil {
+					atomic.AddUint64(&sf.atomicFailedReads, 1)
+					return build.ExtendErr("could not read sectors from storage folder", err)
+				}
+				_, err = newSectorFile.WriteAt(buf, int64(offset))
+				if err != nil {
+					return build.ExtendErr("could not write sectors to new storage folder", err)
+				}
+				atomic.AddUint64(&sf.atomicProgressNumerator, bufSize)
+			}
+		}
+
+		// Block the move after the copy when a specific dependency is
+		// provided.
+		wal.cm.dependencies.Disrupt("blockStorageFolderMove")
+		return nil
+	}()
+	sf.mu.RUnlock()
+	sf.mu.Lock()
+	if err != nil {
+		return err
+	}
+
+	// The storage folder is locked again, so no more sectors are being
+	// written to it. Copy the sectors that were written during the copy. The
+	// move fails if the storage folder was resized or removed in the
+	// meantime.
+	var movedSectors []uint32
+	wal.mu.Lock()
+	current, exists := wal.cm.storageFolders[sf.index]
+	if !exists || current != sf || len(sf.usage) != len(usage) {
+		wal.mu.Unlock()
+		return errStorageFolderChanged
+	}
+	for index := range sf.movedSectors {
+		movedSectors = append(movedSectors, index)
+	}
+	wal.mu.Unlock()
+	for _, index := range movedSectors {
+		sectorData, err := readSector(sf.sectorFile, index)
+		if err != nil {
+			atomic.AddUint64(&sf.atomicFailedReads, 1)
+			return build.ExtendErr("could not read sectors from storage folder", err)
+		}
+		err = writeSector(newSectorFile, index, sectorData)
+		if err != nil {
+			return build.ExtendErr("could not write sectors to new storage folder", err)
+		}
+	}
+	err = newSectorFile.Sync()
+	if err != nil {
+		return build.ExtendErr("could not synchronize new sector data file", err)
+	}
+
+	// Simulate power failure at this point for some testing scenarios.
+	if wal.cm.dependencies.Disrupt("incompleteMoveStorageFolder") {
+		return build.ComposeErrors(newMetadataFile.Close(), newSectorFile.Close())
+	}
+
+	// Copy the metadata while holding the WAL lock. The metadata of the
+	// sectors in memory is written over the copy, as sector updates may still
+	// be on their way to the old metadata file. Updates that are made after
+	// the copy are applied to the new file when the move is committed.
+	wal.mu.Lock()
+	err = func() error {
+		metadata, err := readFullMetadata(sf.metadataFile, int(numSectors))
+		if err != nil {
+			atomic.AddUint64(&sf.atomicFailedReads, 1)
+			return err
+		}
+		wal.overlaySectorMetadata(sf, metadata)
+		_, err = newMetadataFile.WriteAt(metadata, 0)
+		if err != nil {
+			return build.ExtendErr("could not write metadata to new storage folder", err)
+		}
+		err = newMetadataFile.Sync()
+		if err != nil {
+			return build.ExtendErr("could not synchronize new sector metadata file", err)
+		}
+		err = build.ComposeErrors(newMetadataFile.Close(), newSectorFile.Close())
+		if err != nil {
+			return build.ExtendErr("could not close new storage folder files", err)
+		}
+
+		// The storage folder switches to the new files once the move has
+		// been committed to the WAL.
+		wal.appendChange(stateChange{
+			StorageFolderMoves: []storageFolderMove{{
+				Index:   sf.index,
+				OldPath: sf.path,
+				NewPath: newPath,
+			}},
+		})
+		syncChan = wal.syncChan
+		return nil
+	}()
+	wal.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	<-syncChan
+	return nil
+}
+
+// MoveStorageFolder moves a storage folder to a new path. The sectors are
+// copied to the new path in bulk and stay readable during the move. New
+// sectors can be added to the storage folder while the sectors are copied.
+func (cm *ContractManager) MoveStorageFolder(index uint16, newPath string) error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+
+	// Check that the new path is an absolute path to an existing folder.
+	if !filepath.IsAbs(newPath) {
+		return errRelativePath
+	}
+	pathInfo, err := os.Stat(newPath)
+	if err != nil {
+		return err
+	}
+	if !pathInfo.Mode().IsDir() {
+		return errStorageFolderNotFolder
+	}
+
+	cm.wal.mu.Lock()
+	sf, exists := cm.storageFolders[index]
+	cm.wal.mu.Unlock()
+	if !exists || atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+		return errStorageFolderNotFound
+	}
+
+	// Lock the storage folder, the lock is only held while the move is set
+	// up and committed.
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	err = cm.wal.managedMoveStorageFolder(sf, newPath)
+	if err != nil {
+		cm.log.Println("Call to MoveStorageFolder has failed:", err)
+		return err
+	}
+	cm.sectorCache.removeStorageFolder(index)
+	return nil
+}
@@ -0,0 +1,379 @@
+package contractmanager
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// dependencyIncompleteMove will stop a storage folder move after the sectors
+// have been copied, and leave the WAL on disk during shutdown.
+type dependencyIncompleteMove struct {
+	modules.ProductionDependencies
+}
+
+// Disrupt will stop a storage folder move before it is committed to the WAL,
+// simulating a power failure during the move.
+func (*dependencyIncompleteMove) Disrupt(s string) bool {
+	return s == "incompleteMoveStorageFolder" || s == "cleanWALFile"
+}
+
+// dependencyBlockMove blocks a storage folder move after the sectors have been
+// copied, until resume is closed.
+type dependencyBlockMove struct {
+	modules.ProductionDependencies
+	blocked chan struct{}
+	resume  chan struct{}
+}
+
+// Disrupt will block a storage folder move before it is committed.
+func (d *dependencyBlockMove) Disrupt(s string) bool {
+	if s == "blockStorageFolderMove" {
+		close(d.blocked)
+		<-d.resume
+	}
+	return false
+}
+
+// addMoveTestSectors adds a storage folder at storageFolderOne holding a few
+// sectors, returning the sectors that were added.
+func addMoveTestSectors(t *testing.T, cmt *contractManagerTester, storageFolderOne string) map[crypto.Hash][]byte {
+	err := os.MkdirAll(storageFolderOne, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderOne, modules.SectorSize*storageFolderGranularity*2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sectors := make(map[crypto.Hash][]byte)
+	for i := 0; i < 5; i++ {
+		root, data := randSector()
+		err = cmt.cm.AddSector(root, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sectors[root] = data
+	}
+	return sectors
+}
+
+// checkMovedSectors checks that all sectors can be read from the contract
+// manager.
+func checkMovedSectors(t *testing.T, cmt *contractManagerTester, sectors map[crypto.Hash][]byte) {
+	for root, data := range sectors {
+		read, err := cmt.cm.ReadSector(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(read, data) {
+			t.Fatal("sector data does not match after the move")
+		}
+	}
+}
+
+// checkFolderFiles checks whether the files of a storage folder exist at a
+// path.
+func checkFolderFiles(t *testing.T, path string, exist bool) {
+	for _, name := range []string{metadataFile, sectorFile} {
+		_, err := os.Stat(filepath.Join(path, name))
+		if exist && err != nil {
+			t.Fatal("storage folder file is missing:", err)
+		} else if !exist && !os.IsNotExist(err) {
+			t.Fatal("storage folder file was not removed:", filepath.Join(path, name), err)
+		}
+	}
+}
+
+// TestMoveStorageFolder moves a storage folder holding sectors to a new path
+// and checks that the sectors are available at the new path, also after a
+// restart.
+func TestMoveStorageFolder(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	storageFolderOne := filepath.Join(cmt.persistDir, "storageFolderOne")
+	storageFolderTwo := filepath.Join(cmt.persistDir, "storageFolderTwo")
+	sectors := addMoveTestSectors(t, cmt, storageFolderOne)
+	err = os.MkdirAll(storageFolderTwo, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sfIndex := cmt.cm.StorageFolders()[0].Index
+
+	// Moves to invalid paths should be rejected.
+	if err := cmt.cm.MoveStorageFolder(sfIndex, "relative/path"); err != errRelativePath {
+		t.Fatal("expected errRelativePath, got", err)
+	}
+	if err := cmt.cm.MoveStorageFolder(sfIndex, storageFolderOne); err != errSameStorageFolderPath {
+		t.Fatal("expected errSameStorageFolderPath, got", err)
+	}
+	if err := cmt.cm.MoveStorageFolder(sfIndex+1, storageFolderTwo); err != errStorageFolderNotFound {
+		t.Fatal("expected errStorageFolderNotFound, got", err)
+	}
+
+	// Move the storage folder.
+	err = cmt.cm.MoveStorageFolder(sfIndex, storageFolderTwo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sfs := cmt.cm.StorageFolders()
+	if len(sfs) != 1 || sfs[0].Path != storageFolderTwo {
+		t.Fatal("storage folder was not moved:", sfs)
+	}
+	if sfs[0].ProgressNumerator != 0 || sfs[0].ProgressDenominator != 0 {
+		t.Fatal("progress was not reset after the move")
+	}
+	if sfs[0].Capacity-sfs[0].CapacityRemaining != 5*modules.SectorSize {
+		t.Fatal("storage folder reports the wrong usage after the move")
+	}
+	checkMovedSectors(t, cmt, sectors)
+	checkFolderFiles(t, storageFolderOne, false)
+	checkFolderFiles(t, storageFolderTwo, true)
+	var roots []crypto.Hash
+	for root := range sectors {
+		roots = append(roots, root)
+	}
+	for _, sl := range cmt.cm.SectorLocations(roots) {
+		if !sl.Found || sl.StorageFolder != storageFolderTwo {
+			t.Fatal("wrong sector location after the move:", sl)
+		}
+	}
+
+	// Sectors can be added and removed after the move.
+	root, data := randSector()
+	err = cmt.cm.AddSector(root, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sectors[root] = data
+	for root = range sectors {
+		break
+	}
+	err = cmt.cm.RemoveSector(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delete(sectors, root)
+
+	// Restart the contract manager and check that the move was kept.
+	cmt.waitForSettingsSync()
+	err = cmt.cm.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sfs = cmt.cm.StorageFolders()
+	if len(sfs) != 1 || sfs[0].Path != storageFolderTwo {
+		t.Fatal("storage folder move was not persisted:", sfs)
+	}
+	checkMovedSectors(t, cmt, sectors)
+}
+
+// TestMoveStorageFolderConcurrent adds sectors to a storage folder while it is
+// being moved, and reads sectors from it for the whole duration of the move.
+func TestMoveStorageFolderConcurrent(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	d := &dependencyBlockMove{
+		blocked: make(chan struct{}),
+		resume:  make(chan struct{}),
+	}
+	cmt, err := newMockedContractManagerTester(d, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	storageFolderOne := filepath.Join(cmt.persistDir, "storageFolderOne")
+	storageFolderTwo := filepath.Join(cmt.persistDir, "storageFolderTwo")
+	sectors := addMoveTestSectors(t, cmt, storageFolderOne)
+	err = os.MkdirAll(storageFolderTwo, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roots []crypto.Hash
+	for root := range sectors {
+		roots = append(roots, root)
+	}
+
+	// Read the sectors from disk until the move has completed.
+	cmt.cm.SetSectorCacheSize(0)
+	moveErr := make(chan error)
+	go func() {
+		moveErr <- cmt.cm.MoveStorageFolder(cmt.cm.StorageFolders()[0].Index, storageFolderTwo)
+	}()
+	done := make(chan struct{})
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			select {
+			case <-done:
+				readErr <- nil
+				return
+			default:
+			}
+			for _, root := range roots {
+				if _, err := cmt.cm.ReadSector(root); err != nil {
+					readErr <- err
+					return
+				}
+			}
+		}
+	}()
+
+	// Add sectors after the sectors have been copied.
+	<-d.blocked
+	for i := 0; i < 3; i++ {
+		root, data := randSector()
+		err = cmt.cm.AddSector(root, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sectors[root] = data
+	}
+	close(d.resume)
+	err = <-moveErr
+	if err != nil {
+		t.Fatal(err)
+	}
+	close(done)
+	err = <-readErr
+	if err != nil {
+		t.Fatal("sector could not be read during the move:", err)
+	}
+
+	sfs := cmt.cm.StorageFolders()
+	if len(sfs) != 1 || sfs[0].Path != storageFolderTwo {
+		t.Fatal("storage folder was not moved:", sfs)
+	}
+	if sfs[0].Capacity-sfs[0].CapacityRemaining != 8*modules.SectorSize {
+		t.Fatal("storage folder reports the wrong usage after the move")
+	}
+	checkMovedSectors(t, cmt, sectors)
+	checkFolderFiles(t, storageFolderOne, false)
+
+	// The sectors that were added during the move were copied to the new
+	// files.
+	cmt.waitForSettingsSync()
+	err = cmt.cm.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkMovedSectors(t, cmt, sectors)
+}
+
+// TestMoveStorageFolderIncomplete simulates a power failure during a storage
+// folder move. The storage folder should stay at its old path and the partial
+// copy should be removed.
+func TestMoveStorageFolderIncomplete(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	d := new(dependencyIncompleteMove)
+	cmt, err := newMockedContractManagerTester(d, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	storageFolderOne := filepath.Join(cmt.persistDir, "storageFolderOne")
+	storageFolderTwo := filepath.Join(cmt.persistDir, "storageFolderTwo")
+	sectors := addMoveTestSectors(t, cmt, storageFolderOne)
+	err = os.MkdirAll(storageFolderTwo, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.MoveStorageFolder(cmt.cm.StorageFolders()[0].Index, storageFolderTwo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkFolderFiles(t, storageFolderTwo, true)
+
+	// Restart the contract manager.
+	err = cmt.cm.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sfs := cmt.cm.StorageFolders()
+	if len(sfs) != 1 || sfs[0].Path != storageFolderOne {
+		t.Fatal("storage folder should have stayed at its old path:", sfs)
+	}
+	checkMovedSectors(t, cmt, sectors)
+	checkFolderFiles(t, storageFolderOne, true)
+	checkFolderFiles(t, storageFolderTwo, false)
+}
+
+// TestMoveStorageFolderWAL completes a storage folder move, but leaves the WAL
+// behind so that a commit is necessary to finalize things.
+func TestMoveStorageFolderWAL(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	d := new(dependencyLeaveWAL)
+	cmt, err := newMockedContractManagerTester(d, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	storageFolderOne := filepath.Join(cmt.persistDir, "storageFolderOne")
+	storageFolderTwo := filepath.Join(cmt.persistDir, "storageFolderTwo")
+	sectors := addMoveTestSectors(t, cmt, storageFolderOne)
+	err = os.MkdirAll(storageFolderTwo, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.MoveStorageFolder(cmt.cm.StorageFolders()[0].Index, storageFolderTwo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.mu.Lock()
+	d.triggered = true
+	d.mu.Unlock()
+
+	// Restart the contract manager.
+	err = cmt.cm.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sfs := cmt.cm.StorageFolders()
+	if len(sfs) != 1 || sfs[0].Path != storageFolderTwo {
+		t.Fatal("storage folder should be at its new path:", sfs)
+	}
+	checkMovedSectors(t, cmt, sectors)
+	checkFolderFiles(t, storageFolderOne, false)
+	checkFolderFiles(t, storageFolderTwo, true)
+}
@@ -50,12 +50,15 @@ type (
 		// storage folder addition.
 		ErroredStorageFolderAdditions     []uint16
 		ErroredStorageFolderExtensions    []uint16
+		ErroredStorageFolderMoves         []uint16
 		StorageFolderAdditions            []savedStorageFolder
 		StorageFolderExtensions           []storageFolderExtension
+		StorageFolderMoves                []storageFolderMove
 		StorageFolderRemovals             []storageFolderRemoval
 		StorageFolderReductions           []storageFolderReduction
 		UnfinishedStorageFolderAdditions  []savedStorageFolder
 		UnfinishedStorageFolderExtensions []unfinishedStorageFolderExtension
+		UnfinishedStorageFolderMoves      []unfinishedStorageFolderMove
 
 		// Updates to the sector metadata. Careful ordering of events ensures
 		// that a sector update will not make it into the synced WAL unless the
@@ -182,6 +185,11 @@ func (wal *writeAheadLog) commitChange(sc stateChange) {
 			wal.commitStorageFolderReduction(sfr)
 		}
 	}
+	for _, sfm := range sc.StorageFolderMoves {
+		for i := uint64(0); i < wal.cm.dependencies.AtLeastOne(); i++ {
+			wal.commitStorageFolderMove(sfm)
+		}
+	}
 	for _, sfr := range sc.StorageFolderRemovals {
 		for i := uint64(0); i < wal.cm.dependencies.AtLeastOne(); i++ {
 			wal.commitStorageFolderRemoval(sfr)
@@ -250,6 +258,7 @@ func (wal *writeAheadLog) recoverWAL(walFile modules.File) error {
 	// completed.
 	wal.cleanupUnfinishedStorageFolderAdditions(scs)
 	wal.cleanupUnfinishedStorageFolderExtensions(scs)
+	wal.cleanupUnfinishedStorageFolderMoves(scs)
 	return nil
 }
 
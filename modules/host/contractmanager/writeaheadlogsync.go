@@ -129,6 +129,9 @@ func (wal *writeAheadLog) syncResources() {
 		for _, sfr := range sc.StorageFolderReductions {
 			wal.commitStorageFolderReduction(sfr)
 		}
+		for _, sfm := range sc.StorageFolderMoves {
+			wal.commitStorageFolderMove(sfm)
+		}
 		for _, sfr := range sc.StorageFolderRemovals {
 			wal.commitStorageFolderRemoval(sfr)
 		}
@@ -206,6 +209,7 @@ func (wal *writeAheadLog) commit() {
 		// Extract any unfinished long-running jobs from the list of WAL items.
 		unfinishedAdditions := findUnfinishedStorageFolderAdditions(wal.uncommittedChanges)
 		unfinishedExtensions := findUnfinishedStorageFolderExtensions(wal.uncommittedChanges)
+		unfinishedMoves := findUnfinishedStorageFolderMoves(wal.uncommittedChanges)
 
 		// Recreate the wal file so that it can receive new updates.
 		var err error
@@ -224,6 +228,7 @@ func (wal *writeAheadLog) commit() {
 		wal.appendChange(stateChange{
 			UnfinishedStorageFolderAdditions:  unfinishedAdditions,
 			UnfinishedStorageFolderExtensions: unfinishedExtensions,
+			UnfinishedStorageFolderMoves:      unfinishedMoves,
 		})
 
 		// Clear the set of uncommitted changes.
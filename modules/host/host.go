@@ -69,6 +69,7 @@ import (
 	"net"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
@@ -77,13 +78,15 @@ import (
 	"github.com/NebulousLabs/Sia/persist"
 	siasync "github.com/NebulousLabs/Sia/sync"
 	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/ratelimit"
 )
 
 const (
 	// Names of the various persistent files in the host.
-	dbFilename   = modules.HostDir + ".db"
-	logFile      = modules.HostDir + ".log"
-	settingsFile = modules.HostDir + ".json"
+	announcementPricesFile = "announcementprices.json"
+	dbFilename             = modules.HostDir + ".db"
+	logFile                = modules.HostDir + ".log"
+	settingsFile           = modules.HostDir + ".json"
 )
 
 var (
@@ -160,6 +163,30 @@ type Host struct {
 	workingStatus        modules.HostWorkingStatus
 	connectabilityStatus modules.HostConnectabilityStatus
 
+	// The pricing autopilot follows the prices of the hosts reported by the
+	// price source and records its adjustments. Without a price source, it
+	// follows the hosts announced in the blockchain.
+	announcementPrices *announcementPriceSource
+	priceAdjustments   []modules.HostPriceAdjustment
+	priceSource        PriceSource
+
+	// The policy restricts the renters that the host negotiates with.
+	// renterUsage counts the active contracts and data of each renter, keyed
+	// by the renter's public key.
+	policy      modules.HostPolicy
+	renterUsage map[string]renterUsage
+
+	// The host refuses new contracts, renewals and uploads between the start
+	// and the end of its maintenance.
+	maintenanceEnd   time.Time
+	maintenanceStart time.Time
+
+	// The bandwidth metrics track the RPC traffic of the host, which is
+	// limited globally by staticRL and per renter by renterRateLimits.
+	bandwidthMetrics modules.HostBandwidthMetrics
+	renterRateLimits map[string]*ratelimit.RateLimit
+	staticRL         *ratelimit.RateLimit
+
 	// A map of storage obligations that are currently being modified. Locks on
 	// storage obligations can be long-running, and each storage obligation can
 	// be locked separately.
@@ -234,9 +261,13 @@ func newHost(dependencies modules.Dependencies, cs modules.ConsensusSet, tpool m
 		dependencies: dependencies,
 
 		lockedStorageObligations: make(map[types.FileContractID]*siasync.TryMutex),
+		renterUsage:              make(map[string]renterUsage),
+		renterRateLimits:         make(map[string]*ratelimit.RateLimit),
+		staticRL:                 ratelimit.NewRateLimit(0, 0, 0),
 
 		persistDir: persistDir,
 	}
+	h.announcementPrices = newAnnouncementPriceSource(h)
 
 	// Call stop in the event of a partial startup.
 	var err error
@@ -287,6 +318,8 @@ func newHost(dependencies modules.Dependencies, cs modules.ConsensusSet, tpool m
 	if err != nil {
 		return nil, err
 	}
+	h.updateRateLimits()
+	h.SetSectorCacheSize(h.settings.SectorCacheSize)
 	h.tg.AfterStop(func() {
 		err = h.saveSync()
 		if err != nil {
@@ -303,6 +336,13 @@ func newHost(dependencies modules.Dependencies, cs modules.ConsensusSet, tpool m
 		h.log.Println("Could not initialize host networking:", err)
 		return nil, err
 	}
+
+	// Launch the pricing autopilot.
+	threadedPricingAutopilotClosedChan := make(chan struct{})
+	go h.threadedPricingAutopilot(threadedPricingAutopilotClosedChan)
+	h.tg.OnStop(func() {
+		<-threadedPricingAutopilotClosedChan
+	})
 	return h, nil
 }
 
@@ -332,10 +372,14 @@ func (h *Host) ExternalSettings() modules.HostExternalSettings {
 
 // WorkingStatus returns the working state of the host, where working is
 // defined as having received more than workingStatusThreshold settings calls
-// over the period of workingStatusFrequency.
+// over the period of workingStatusFrequency. A host in maintenance reports
+// that it is in maintenance.
 func (h *Host) WorkingStatus() modules.HostWorkingStatus {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
+	if h.inMaintenance() {
+		return modules.HostWorkingStatusMaintenance
+	}
 	return h.workingStatus
 }
 
@@ -394,6 +438,26 @@ func (h *Host) SetInternalSettings(settings modules.HostInternalSettings) error
 		}
 	}
 
+	if settings.MaxDownloadSpeed < 0 || settings.MaxUploadSpeed < 0 || settings.MaxRenterDownloadSpeed < 0 || settings.MaxRenterUploadSpeed < 0 {
+		return errors.New("internal settings not updated, " + errNegativeSpeed.Error())
+	}
+	if settings.PricingAutopilot && (settings.PricingTargetPercentile <= 0 || settings.PricingTargetPercentile > 100) {
+		return errors.New("internal settings not updated, the pricing target percentile must be above 0 and at most 100")
+	}
+	floorsAndCeilings := []struct {
+		floor, ceiling types.Currency
+	}{
+		{settings.ContractPriceFloor, settings.ContractPriceCeiling},
+		{settings.DownloadBandwidthPriceFloor, settings.DownloadBandwidthPriceCeiling},
+		{settings.StoragePriceFloor, settings.StoragePriceCeiling},
+		{settings.UploadBandwidthPriceFloor, settings.UploadBandwidthPriceCeiling},
+	}
+	for _, fc := range floorsAndCeilings {
+		if !fc.ceiling.IsZero() && fc.floor.Cmp(fc.ceiling) > 0 {
+			return errors.New("internal settings not updated, a price floor is above its price ceiling")
+		}
+	}
+
 	// Check if the net address for the host has changed. If it has, and it's
 	// not equal to the auto address, then the host is going to need to make
 	// another blockchain announcement.
@@ -403,6 +467,8 @@ func (h *Host) SetInternalSettings(settings modules.HostInternalSettings) error
 
 	h.settings = settings
 	h.revisionNumber++
+	h.updateRateLimits()
+	h.SetSectorCacheSize(h.settings.SectorCacheSize)
 
 	err = h.saveSync()
 	if err != nil {
@@ -0,0 +1,89 @@
+package host
+
+// ledger.go keeps a persistent record of the financial events of the host.
+// Unlike the financial metrics, which only hold running totals, the ledger
+// keeps every event along with the storage obligation that caused it and the
+// block height and time at which it happened, so that the earnings of the host
+// can be broken down by period and by contract.
+//
+// Entries are stored in the database sorted by time, the key of each entry is
+// its timestamp in nanoseconds followed by a sequence number, both as big
+// endian uint64s.
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// ledgerKey returns the database key prefix of the ledger entries recorded at
+// time t.
+func ledgerKey(t time.Time) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key[:8], uint64(t.UnixNano()))
+	return key
+}
+
+// obligationRevenue returns the revenue that the host earns if the storage
+// obligation succeeds.
+func obligationRevenue(so storageObligation) types.Currency {
+	return so.ContractCost.Add(so.PotentialDownloadRevenue).Add(so.PotentialStorageRevenue).Add(so.PotentialUploadRevenue)
+}
+
+// putLedgerEntry adds an entry to the ledger in the database tx. Entries with
+// a zero value are not recorded.
+func putLedgerEntry(tx *bolt.Tx, entryType string, soid types.FileContractID, height types.BlockHeight, value types.Currency) error {
+	if value.IsZero() {
+		return nil
+	}
+	e := modules.HostLedgerEntry{
+		Type:         entryType,
+		ObligationID: soid,
+		BlockHeight:  height,
+		Timestamp:    time.Now(),
+		Value:        value,
+	}
+	entryBytes, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	b := tx.Bucket(bucketLedger)
+	seq, err := b.NextSequence()
+	if err != nil {
+		return err
+	}
+	key := ledgerKey(e.Timestamp)
+	binary.BigEndian.PutUint64(key[8:], seq)
+	return b.Put(key, entryBytes)
+}
+
+// Ledger returns the financial events of the host that happened between start
+// and end, oldest first.
+func (h *Host) Ledger(start, end time.Time) (entries []modules.HostLedgerEntry, err error) {
+	err = h.tg.Add()
+	if err != nil {
+		return nil, err
+	}
+	defer h.tg.Done()
+
+	endKey := ledgerKey(end)
+	binary.BigEndian.PutUint64(endKey[8:], ^uint64(0))
+	err = h.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketLedger).Cursor()
+		for k, v := c.Seek(ledgerKey(start)); k != nil && bytes.Compare(k, endKey) <= 0; k, v = c.Next() {
+			var e modules.HostLedgerEntry
+			if err := json.Unmarshal(v, &e); err != nil {
+				return err
+			}
+			entries = append(entries, e)
+		}
+		return nil
+	})
+	return entries, err
+}
@@ -0,0 +1,139 @@
+package host
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// TestLedger checks that ledger entries are stored in order and that they can
+// be queried by time range.
+func TestLedger(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := blankHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Record three entries, noting the time between them. Entries without a
+	// value should be skipped.
+	var soid types.FileContractID
+	var times []time.Time
+	for i := 1; i <= 3; i++ {
+		times = append(times, time.Now())
+		time.Sleep(10 * time.Millisecond)
+		err = ht.host.db.Update(func(tx *bolt.Tx) error {
+			if err := putLedgerEntry(tx, modules.HostLedgerTransactionFee, soid, types.BlockHeight(i), types.ZeroCurrency); err != nil {
+				return err
+			}
+			return putLedgerEntry(tx, modules.HostLedgerProofSuccess, soid, types.BlockHeight(i), types.NewCurrency64(uint64(i)))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	times = append(times, time.Now())
+
+	entries, err := ht.host.Ledger(times[0], times[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatal("wrong number of entries:", len(entries))
+	}
+	for i, e := range entries {
+		if e.Type != modules.HostLedgerProofSuccess || e.BlockHeight != types.BlockHeight(i+1) || !e.Value.Equals64(uint64(i+1)) {
+			t.Fatal("wrong entry:", e)
+		}
+	}
+
+	// Only the second entry was recorded between the second and third time.
+	entries, err = ht.host.Ledger(times[1], times[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].BlockHeight != 2 {
+		t.Fatal("wrong entries in time range:", entries)
+	}
+
+	// The ledger should be empty before the first entry.
+	entries, err = ht.host.Ledger(time.Unix(0, 0), times[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatal("expected no entries, got", entries)
+	}
+}
+
+// TestLedgerObligationOutcomes checks that every outcome of a storage
+// obligation resolves its potential revenue exactly once, and that a rejected
+// obligation refunds the fees that were recorded for it.
+func TestLedgerObligationOutcomes(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := blankHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	outcomes := []struct {
+		status  storageObligationStatus
+		entries map[string]uint64
+	}{
+		{obligationSucceeded, map[string]uint64{modules.HostLedgerProofSuccess: 60}},
+		{obligationFailed, map[string]uint64{modules.HostLedgerLostRevenue: 60, modules.HostLedgerLostCollateral: 5}},
+		{obligationRejected, map[string]uint64{modules.HostLedgerContractRejected: 60, modules.HostLedgerFeeRefund: 7}},
+	}
+	for i, outcome := range outcomes {
+		so := storageObligation{
+			ContractCost:            types.NewCurrency64(10),
+			PotentialStorageRevenue: types.NewCurrency64(20),
+			PotentialUploadRevenue:  types.NewCurrency64(30),
+			RiskedCollateral:        types.NewCurrency64(5),
+			TransactionFeesAdded:    types.NewCurrency64(7),
+			OriginTransactionSet: []types.Transaction{{
+				FileContracts: []types.FileContract{{FileMerkleRoot: crypto.Hash{byte(i)}}},
+			}},
+		}
+		start := time.Now()
+		ht.host.mu.Lock()
+		// Account for the obligation as if it had been added.
+		fm := &ht.host.financialMetrics
+		fm.ContractCount++
+		fm.PotentialContractCompensation = fm.PotentialContractCompensation.Add(so.ContractCost)
+		fm.PotentialStorageRevenue = fm.PotentialStorageRevenue.Add(so.PotentialStorageRevenue)
+		fm.PotentialUploadBandwidthRevenue = fm.PotentialUploadBandwidthRevenue.Add(so.PotentialUploadRevenue)
+		fm.RiskedStorageCollateral = fm.RiskedStorageCollateral.Add(so.RiskedCollateral)
+		fm.TransactionFeeExpenses = fm.TransactionFeeExpenses.Add(so.TransactionFeesAdded)
+		err = ht.host.removeStorageObligation(so, outcome.status)
+		ht.host.mu.Unlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries, err := ht.host.Ledger(start, time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != len(outcome.entries) {
+			t.Fatalf("%v: wrong entries: %v", outcome.status, entries)
+		}
+		for _, e := range entries {
+			if e.ObligationID != so.id() || !e.Value.Equals64(outcome.entries[e.Type]) {
+				t.Fatalf("%v: wrong entry: %v", outcome.status, e)
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package host
+
+// maintenance.go implements the maintenance mode of the host. While the host
+// is in maintenance it refuses new contracts, renewals and uploads, so that
+// the operator can work on the host's disks without new data arriving. The
+// host keeps serving downloads and submitting revisions and storage proofs,
+// so that no obligation fails because of the maintenance.
+//
+// Maintenance is scheduled with a start and an end time. To help the operator
+// plan the downtime of the host, the host reports the block height by which
+// it needs to be online again to act on its obligations.
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+var (
+	// errMaintenance is returned if a renter tries to renew a contract or to
+	// upload data while the host is in maintenance.
+	errMaintenance = ErrorCommunication("rejected because the host is in maintenance")
+
+	// errMaintenanceEnd is returned if a maintenance window ends before it
+	// starts, or has already ended.
+	errMaintenanceEnd = errors.New("maintenance must end after it starts and after the current time")
+)
+
+// inMaintenance returns whether the host is in maintenance at the current
+// time.
+func (h *Host) inMaintenance() bool {
+	now := time.Now()
+	if h.maintenanceStart.IsZero() || now.Before(h.maintenanceStart) {
+		return false
+	}
+	return h.maintenanceEnd.IsZero() || now.Before(h.maintenanceEnd)
+}
+
+// managedCheckMaintenance returns errMaintenance if the host is in
+// maintenance.
+func (h *Host) managedCheckMaintenance() error {
+	h.mu.RLock()
+	maintenance := h.inMaintenance()
+	h.mu.RUnlock()
+	if maintenance {
+		return errMaintenance
+	}
+	return nil
+}
+
+// onlineByHeight returns the height by which the host needs to act on the
+// storage obligation to submit its file contract revision and storage proof.
+// The host needs a few blocks to get its transactions confirmed, so it needs
+// to be online resubmissionTimeout blocks before the last height at which the
+// transactions are accepted. false is returned if the host does not need to
+// act on the obligation.
+func (so storageObligation) onlineByHeight() (types.BlockHeight, bool) {
+	if so.ObligationStatus != obligationUnresolved {
+		return 0, false
+	}
+	var height types.BlockHeight
+	var needed bool
+	if len(so.RevisionTransactionSet) > 0 && !so.RevisionConfirmed {
+		height, needed = so.expiration()-resubmissionTimeout, true
+	}
+	if len(so.SectorRoots) > 0 && !so.ProofConfirmed {
+		if proofHeight := so.proofDeadline() - resubmissionTimeout; !needed || proofHeight < height {
+			height, needed = proofHeight, true
+		}
+	}
+	return height, needed
+}
+
+// Maintenance returns the maintenance window of the host and the time by
+// which the host must be online again to act on its obligations.
+func (h *Host) Maintenance() modules.HostMaintenance {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	hm := modules.HostMaintenance{
+		Start:  h.maintenanceStart,
+		End:    h.maintenanceEnd,
+		Active: h.inMaintenance(),
+	}
+
+	var found bool
+	err := h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			if err := json.Unmarshal(soBytes, &so); err != nil {
+				return build.ExtendErr("unable to unmarshal storage obligation:", err)
+			}
+			height, needed := so.onlineByHeight()
+			if needed && (!found || height < hm.OnlineByHeight) {
+				hm.OnlineByHeight = height
+				hm.OnlineByObligation = so.id()
+				found = true
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		h.log.Println("Unable to compute when the host must be online:", err)
+	}
+	if found {
+		hm.OnlineByTime = time.Now()
+		if hm.OnlineByHeight > h.blockHeight {
+			blocks := hm.OnlineByHeight - h.blockHeight
+			hm.OnlineByTime = hm.OnlineByTime.Add(time.Duration(blocks*types.BlockFrequency) * time.Second)
+		}
+	}
+	return hm
+}
+
+// ScheduleMaintenance puts the host in maintenance between start and end. A
+// zero start starts the maintenance right away, a zero end keeps the host in
+// maintenance until it is cancelled.
+func (h *Host) ScheduleMaintenance(start, end time.Time) error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+	if start.IsZero() {
+		start = time.Now()
+	}
+	if !end.IsZero() && (!end.After(start) || !end.After(time.Now())) {
+		return errMaintenanceEnd
+	}
+
+	h.mu.Lock()
+	h.maintenanceStart = start
+	h.maintenanceEnd = end
+	err = h.saveSync()
+	h.mu.Unlock()
+	if err != nil {
+		return errors.New("maintenance scheduled, but failed saving to disk: " + err.Error())
+	}
+
+	// Warn the operator if the host would be in maintenance when it needs to
+	// submit transactions for its obligations. The host keeps doing so during
+	// maintenance, but only if it is online.
+	hm := h.Maintenance()
+	if hm.OnlineByHeight != 0 && (end.IsZero() || end.After(hm.OnlineByTime)) {
+		h.log.Printf("WARN: maintenance lasts past %v, when the host needs to be online to submit transactions for obligation %v at height %v\n", hm.OnlineByTime, hm.OnlineByObligation, hm.OnlineByHeight)
+	}
+	h.log.Printf("Maintenance scheduled from %v until %v\n", start, end)
+	return nil
+}
+
+// CancelMaintenance ends the maintenance of the host, or cancels it if it
+// has not started yet.
+func (h *Host) CancelMaintenance() error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.maintenanceStart = time.Time{}
+	h.maintenanceEnd = time.Time{}
+	err = h.saveSync()
+	if err != nil {
+		return errors.New("maintenance cancelled, but failed saving to disk: " + err.Error())
+	}
+	h.log.Println("Maintenance cancelled")
+	return nil
+}
@@ -0,0 +1,189 @@
+package host
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestOnlineByHeight probes the height by which the host needs to act on a
+// storage obligation.
+func TestOnlineByHeight(t *testing.T) {
+	so := storageObligation{
+		OriginTransactionSet: []types.Transaction{{
+			FileContracts: []types.FileContract{{
+				WindowStart: 100,
+				WindowEnd:   150,
+			}},
+		}},
+	}
+
+	// An empty obligation does not need a storage proof.
+	if _, needed := so.onlineByHeight(); needed {
+		t.Fatal("empty obligation should not need the host")
+	}
+
+	// An obligation with data needs a storage proof.
+	so.SectorRoots = []crypto.Hash{{}}
+	if height, needed := so.onlineByHeight(); !needed || height != 150-resubmissionTimeout {
+		t.Fatal("wrong height for a storage proof:", height, needed)
+	}
+
+	// An unconfirmed revision needs to be submitted before the proof window.
+	so.RevisionTransactionSet = []types.Transaction{{
+		FileContractRevisions: []types.FileContractRevision{{
+			NewWindowStart: 100,
+			NewWindowEnd:   150,
+		}},
+	}}
+	if height, needed := so.onlineByHeight(); !needed || height != 100-resubmissionTimeout {
+		t.Fatal("wrong height for a revision:", height, needed)
+	}
+	so.RevisionConfirmed = true
+	if height, needed := so.onlineByHeight(); !needed || height != 150-resubmissionTimeout {
+		t.Fatal("wrong height after the revision was confirmed:", height, needed)
+	}
+
+	// Confirmed proofs and resolved obligations don't need the host.
+	so.ProofConfirmed = true
+	if _, needed := so.onlineByHeight(); needed {
+		t.Fatal("obligation with a confirmed proof should not need the host")
+	}
+	so.ProofConfirmed = false
+	so.ObligationStatus = obligationSucceeded
+	if _, needed := so.onlineByHeight(); needed {
+		t.Fatal("resolved obligation should not need the host")
+	}
+}
+
+// TestScheduleMaintenance checks that maintenance can be scheduled and
+// cancelled, that it is reported by the host and that it survives a restart.
+func TestScheduleMaintenance(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+	settings := ht.host.InternalSettings()
+	settings.AcceptingContracts = true
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// Maintenance that ends before it starts is rejected.
+	now := time.Now()
+	if err := ht.host.ScheduleMaintenance(now.Add(time.Hour), now); err != errMaintenanceEnd {
+		t.Fatal("expected errMaintenanceEnd, got", err)
+	}
+	if err := ht.host.ScheduleMaintenance(now.Add(-time.Hour), now.Add(-time.Minute)); err != errMaintenanceEnd {
+		t.Fatal("expected errMaintenanceEnd, got", err)
+	}
+
+	// Scheduled maintenance is not active until it starts.
+	if err := ht.host.ScheduleMaintenance(now.Add(time.Hour), time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if hm := ht.host.Maintenance(); hm.Active || !hm.Start.Equal(now.Add(time.Hour)) || !hm.End.IsZero() {
+		t.Fatal("wrong maintenance:", hm)
+	}
+	if ht.host.WorkingStatus() == modules.HostWorkingStatusMaintenance || !ht.host.ExternalSettings().AcceptingContracts {
+		t.Fatal("host is in maintenance before the maintenance starts")
+	}
+
+	// Start the maintenance right away.
+	end := now.Add(time.Hour)
+	if err := ht.host.ScheduleMaintenance(time.Time{}, end); err != nil {
+		t.Fatal(err)
+	}
+	if hm := ht.host.Maintenance(); !hm.Active || !hm.End.Equal(end) {
+		t.Fatal("maintenance is not active:", hm)
+	}
+	if ht.host.WorkingStatus() != modules.HostWorkingStatusMaintenance {
+		t.Fatal("wrong working status:", ht.host.WorkingStatus())
+	}
+	if ht.host.ExternalSettings().AcceptingContracts {
+		t.Fatal("host is accepting contracts during maintenance")
+	}
+	if err := ht.host.managedCheckMaintenance(); err != errMaintenance {
+		t.Fatal("expected errMaintenance, got", err)
+	}
+
+	// Reboot the host and check that the maintenance was kept.
+	if err := ht.host.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ht.host, err = New(ht.cs, ht.tpool, ht.wallet, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hm := ht.host.Maintenance(); !hm.Active || !hm.End.Equal(end) {
+		t.Fatal("maintenance was not persisted:", hm)
+	}
+
+	// Cancel the maintenance.
+	if err := ht.host.CancelMaintenance(); err != nil {
+		t.Fatal(err)
+	}
+	if hm := ht.host.Maintenance(); hm.Active || !hm.Start.IsZero() {
+		t.Fatal("maintenance was not cancelled:", hm)
+	}
+	if err := ht.host.managedCheckMaintenance(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestMaintenanceOnlineBy checks that the host reports the height by which it
+// must be online to act on its storage obligations.
+func TestMaintenanceOnlineBy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	if hm := ht.host.Maintenance(); hm.OnlineByHeight != 0 || !hm.OnlineByTime.IsZero() {
+		t.Fatal("host without obligations needs to be online:", hm)
+	}
+
+	// Add a storage obligation holding a sector.
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sectorRoot, sectorData := randSector()
+	so.SectorRoots = []crypto.Hash{sectorRoot}
+	err = ht.host.modifyStorageObligation(so, nil, []crypto.Hash{sectorRoot}, [][]byte{sectorData})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so.id())
+
+	hm := ht.host.Maintenance()
+	if hm.OnlineByHeight != so.proofDeadline()-resubmissionTimeout || hm.OnlineByObligation != so.id() {
+		t.Fatal("wrong online by height:", hm)
+	}
+	ht.host.mu.RLock()
+	blocks := hm.OnlineByHeight - ht.host.blockHeight
+	ht.host.mu.RUnlock()
+	expected := time.Now().Add(time.Duration(blocks*types.BlockFrequency) * time.Second)
+	if hm.OnlineByTime.After(expected) || hm.OnlineByTime.Before(expected.Add(-time.Minute)) {
+		t.Fatal("wrong online by time:", hm.OnlineByTime, expected)
+	}
+}
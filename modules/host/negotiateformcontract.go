@@ -182,6 +182,7 @@ func (h *Host) managedRPCFormContract(conn net.Conn) error {
 		return extendErr("contract finalization failed: ", err)
 	}
 	defer h.managedUnlockStorageObligation(newSOID)
+	h.managedTrackConn(conn, newSOID, types.Ed25519PublicKey(renterPK))
 	err = modules.WriteNegotiationAcceptance(conn)
 	if err != nil {
 		return extendErr("failed to write acceptance after contract finalization: ", ErrorConnection(err.Error()))
@@ -278,6 +279,11 @@ func (h *Host) managedVerifyNewContract(txnSet []types.Transaction, renterPK cry
 	if lockedStorageCollateral.Add(expectedCollateral).Cmp(iSettings.CollateralBudget) > 0 {
 		return errCollateralBudgetExceeded
 	}
+	// Check that the host's policy allows the renter to form the contract.
+	err := h.managedVerifyPolicy(types.Ed25519PublicKey(renterPK), storageObligation{}, true, fc.FileSize, expectedCollateral)
+	if err != nil {
+		return err
+	}
 
 	// The unlock hash for the file contract must match the unlock hash that
 	// the host knows how to spend.
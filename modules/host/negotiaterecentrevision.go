@@ -138,6 +138,10 @@ func (h *Host) managedRPCRecentRevision(conn net.Conn) (types.FileContractID, st
 			h.managedUnlockStorageObligation(fcid)
 		}
 	}()
+	// The rest of the connection is the renter's traffic for the obligation.
+	if renter, ok := so.renterKey(); ok {
+		h.managedTrackConn(conn, fcid, renter)
+	}
 
 	// Send the file contract revision and the corresponding signatures to the
 	// renter.
@@ -195,6 +195,9 @@ func (h *Host) managedRPCRenewContract(conn net.Conn) error {
 		return extendErr("failed to finalize contract: ", err)
 	}
 	defer h.managedUnlockStorageObligation(newSOID)
+
+	// The old contract no longer counts towards the usage of the renter.
+	h.managedMarkObligationRenewed(so.id())
 	err = modules.WriteNegotiationAcceptance(conn)
 	if err != nil {
 		return extendErr("failed to write acceptance: ", ErrorConnection(err.Error()))
@@ -280,6 +283,17 @@ func (h *Host) managedVerifyRenewedContract(so storageObligation, txnSet []types
 	if lockedStorageCollateral.Add(expectedCollateral).Cmp(internalSettings.CollateralBudget) > 0 {
 		return errCollateralBudgetExceeded
 	}
+	// Renewals are refused while the host is in maintenance.
+	err := h.managedCheckMaintenance()
+	if err != nil {
+		return err
+	}
+	// Check that the host's policy allows the renter to renew the contract.
+	// The renewed contract replaces the old one in the usage of the renter.
+	err = h.managedVerifyPolicy(types.Ed25519PublicKey(renterPK), so, true, fc.FileSize, expectedCollateral)
+	if err != nil {
+		return err
+	}
 	// Check that the missed proof outputs contain enough money, and that the
 	// void output contains enough money.
 	basePrice := renewBasePrice(so, externalSettings, fc)
@@ -136,6 +136,29 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 				return errUnknownModification
 			}
 		}
+		// Uploads are refused while the host is in maintenance. Revisions
+		// that only remove data are still accepted.
+		if len(sectorsGained) > 0 {
+			if err := h.managedCheckMaintenance(); err != nil {
+				return err
+			}
+		}
+		// Check that the host's policy allows the renter to revise the
+		// contract. The size limits only apply to revisions that add data,
+		// so that renters can always free up space. The collateral of the
+		// contract was checked when it was formed.
+		if renter, ok := so.renterKey(); ok {
+			var err error
+			if revision.NewFileSize > so.fileSize() {
+				err = h.managedVerifyPolicy(renter, *so, false, revision.NewFileSize, types.ZeroCurrency)
+			} else {
+				err = h.managedVerifyRenterAllowed(renter)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
 		newRevenue := storageRevenue.Add(bandwidthRevenue)
 		return extendErr("unable to verify updated contract: ", verifyRevision(*so, revision, blockHeight, newRevenue, newCollateral))
 	}()
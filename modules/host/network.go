@@ -249,6 +249,12 @@ func (h *Host) threadedHandleConn(conn net.Conn) {
 	}
 	defer h.tg.Done()
 
+	// Track and limit the traffic of the connection. The traffic is recorded
+	// once the RPC is done.
+	hc := h.newHostConn(conn)
+	defer h.managedRecordConn(hc)
+	conn = hc
+
 	// Close the conn on host.Close or when the method terminates, whichever comes
 	// first.
 	connCloseChan := make(chan struct{})
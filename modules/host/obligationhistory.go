@@ -0,0 +1,197 @@
+package host
+
+// obligationhistory.go keeps the history of each storage obligation, so that
+// obligations which run into trouble can be inspected after the fact. The
+// host records every revision of the file contract and every change to the
+// status of the obligation, such as the confirmation of the file contract or
+// the submission of a storage proof.
+//
+// The history of each obligation is kept in a nested bucket under the id of
+// the obligation, the entries are keyed by a big endian sequence number so
+// that bolt keeps them in the order in which they were recorded.
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// appendObligationHistory appends an entry to the history of a storage
+// obligation in the bucket with the provided name.
+func appendObligationHistory(tx *bolt.Tx, bucket []byte, soid types.FileContractID, entry interface{}) error {
+	b, err := tx.Bucket(bucket).CreateBucketIfNotExists(soid[:])
+	if err != nil {
+		return err
+	}
+	entryBytes, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	seq, err := b.NextSequence()
+	if err != nil {
+		return err
+	}
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return b.Put(key, entryBytes)
+}
+
+// forEachObligationHistory calls fn with each entry of the history of a
+// storage obligation in the bucket with the provided name, oldest first.
+func forEachObligationHistory(tx *bolt.Tx, bucket []byte, soid types.FileContractID, fn func([]byte) error) error {
+	b := tx.Bucket(bucket).Bucket(soid[:])
+	if b == nil {
+		return nil
+	}
+	return b.ForEach(func(_, entryBytes []byte) error {
+		return fn(entryBytes)
+	})
+}
+
+// putObligationRevision records the latest revision of a storage obligation
+// in the database tx.
+func putObligationRevision(tx *bolt.Tx, so storageObligation, height types.BlockHeight) error {
+	valid, missed := so.payouts()
+	return appendObligationHistory(tx, bucketObligationRevisions, so.id(), modules.StorageObligationRevision{
+		BlockHeight:    height,
+		Timestamp:      time.Now(),
+		RevisionNumber: so.revisionNumber(),
+
+		FileMerkleRoot:     so.merkleRoot(),
+		FileSize:           so.fileSize(),
+		MissedProofOutputs: missed,
+		ValidProofOutputs:  valid,
+	})
+}
+
+// putObligationTransition records a change in the status of a storage
+// obligation in the database tx.
+func putObligationTransition(tx *bolt.Tx, soid types.FileContractID, height types.BlockHeight, status, detail string) error {
+	return appendObligationHistory(tx, bucketObligationTransitions, soid, modules.StorageObligationTransition{
+		BlockHeight: height,
+		Timestamp:   time.Now(),
+		Status:      status,
+		Detail:      detail,
+	})
+}
+
+// managedRecordTransition records a change in the status of a storage
+// obligation. Failures are logged, the history is informational and should
+// not interrupt the handling of the obligation.
+func (h *Host) managedRecordTransition(soid types.FileContractID, status, detail string) {
+	h.mu.RLock()
+	height := h.blockHeight
+	h.mu.RUnlock()
+	err := h.db.Update(func(tx *bolt.Tx) error {
+		return putObligationTransition(tx, soid, height, status, detail)
+	})
+	if err != nil {
+		h.log.Println("Unable to record a transition of storage obligation", soid, err)
+	}
+}
+
+// logObligationTransition records a change in the status of a storage
+// obligation within a database transaction. Failures are logged instead of
+// returned, so that they don't abort the transaction.
+func (h *Host) logObligationTransition(tx *bolt.Tx, soid types.FileContractID, height types.BlockHeight, status, detail string) {
+	if err := putObligationTransition(tx, soid, height, status, detail); err != nil {
+		h.log.Println("Unable to record a transition of storage obligation", soid, err)
+	}
+}
+
+// revisionNumber returns the revision number of the latest revision of the
+// storage obligation. The file contract itself has revision number 0.
+func (so storageObligation) revisionNumber() uint64 {
+	if len(so.RevisionTransactionSet) > 0 {
+		return so.RevisionTransactionSet[len(so.RevisionTransactionSet)-1].FileContractRevisions[0].NewRevisionNumber
+	}
+	return 0
+}
+
+// pendingActionItems returns the heights above the current block height at
+// which action items are queued for a storage obligation.
+func (h *Host) pendingActionItems(tx *bolt.Tx, soid types.FileContractID) (heights []types.BlockHeight) {
+	heightBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(heightBytes, uint64(h.blockHeight+1))
+	c := tx.Bucket(bucketActionItems).Cursor()
+	for k, v := c.Seek(heightBytes); k != nil; k, v = c.Next() {
+		for i := 0; i+crypto.HashSize <= len(v); i += crypto.HashSize {
+			var id types.FileContractID
+			copy(id[:], v[i:i+crypto.HashSize])
+			if id == soid {
+				heights = append(heights, types.BlockHeight(binary.BigEndian.Uint64(k)))
+				break
+			}
+		}
+	}
+	return heights
+}
+
+// StorageObligationDetails returns everything the host knows about the
+// storage obligation with the provided id.
+func (h *Host) StorageObligationDetails(soid types.FileContractID) (sod modules.StorageObligationDetails, err error) {
+	err = h.tg.Add()
+	if err != nil {
+		return sod, err
+	}
+	defer h.tg.Done()
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var so storageObligation
+	err = h.db.View(func(tx *bolt.Tx) error {
+		so, err = getStorageObligation(tx, soid)
+		if err != nil {
+			return err
+		}
+		sod.StorageObligation = h.storageObligationSummary(tx, so)
+		sod.ActionItems = h.pendingActionItems(tx, soid)
+		err = forEachObligationHistory(tx, bucketObligationRevisions, soid, func(entryBytes []byte) error {
+			var rev modules.StorageObligationRevision
+			err := json.Unmarshal(entryBytes, &rev)
+			sod.Revisions = append(sod.Revisions, rev)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		return forEachObligationHistory(tx, bucketObligationTransitions, soid, func(entryBytes []byte) error {
+			var t modules.StorageObligationTransition
+			err := json.Unmarshal(entryBytes, &t)
+			sod.Timeline = append(sod.Timeline, t)
+			return err
+		})
+	})
+	if err != nil {
+		return modules.StorageObligationDetails{}, err
+	}
+
+	// Look up where the sectors of the obligation are stored.
+	failed := make(map[crypto.Hash]struct{})
+	for _, root := range h.FailedSectors(so.SectorRoots) {
+		failed[root] = struct{}{}
+	}
+	locations := h.SectorLocations(so.SectorRoots)
+	for i, root := range so.SectorRoots {
+		_, isFailed := failed[root]
+		sector := modules.StorageObligationSector{
+			Root:   root,
+			Failed: isFailed,
+		}
+		if i < len(locations) {
+			sector.SectorLocation = locations[i]
+		}
+		sod.Sectors = append(sod.Sectors, sector)
+	}
+
+	sod.OriginTransactionSet = so.OriginTransactionSet
+	sod.ProofTransactionSet = so.ProofTransactionSet
+	sod.RevisionTransactionSet = so.RevisionTransactionSet
+	return sod, nil
+}
@@ -0,0 +1,122 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestStorageObligationDetails checks that the host records the revisions and
+// the status transitions of a storage obligation, and reports where its
+// sectors are stored.
+func TestStorageObligationDetails(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Unknown obligations should return an error.
+	if _, err := ht.host.StorageObligationDetails(types.FileContractID{}); err != ErrNoStorageObligation {
+		t.Fatal("expected ErrNoStorageObligation, got", err)
+	}
+
+	// Add an empty storage obligation.
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so.id())
+	sod, err := ht.host.StorageObligationDetails(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sod.ObligationId != so.id() || len(sod.OriginTransactionSet) != len(so.OriginTransactionSet) {
+		t.Fatal("wrong obligation returned:", sod.ObligationId)
+	}
+	if len(sod.Revisions) != 1 || sod.Revisions[0].RevisionNumber != 0 {
+		t.Fatal("the file contract was not recorded as the first revision:", sod.Revisions)
+	}
+	if len(sod.Timeline) != 1 || sod.Timeline[0].Status != modules.ObligationCreated {
+		t.Fatal("wrong timeline for a new obligation:", sod.Timeline)
+	}
+	if len(sod.ActionItems) == 0 || sod.ActionItems[0] <= ht.host.blockHeight {
+		t.Fatal("wrong pending action items:", sod.ActionItems)
+	}
+	if len(sod.Sectors) != 0 {
+		t.Fatal("empty obligation reports sectors:", sod.Sectors)
+	}
+
+	// Revise the obligation to add a sector.
+	sectorRoot, sectorData := randSector()
+	so.SectorRoots = []crypto.Hash{sectorRoot}
+	validPayouts, missedPayouts := so.payouts()
+	so.RevisionTransactionSet = []types.Transaction{{
+		FileContractRevisions: []types.FileContractRevision{{
+			ParentID:          so.id(),
+			UnlockConditions:  types.UnlockConditions{},
+			NewRevisionNumber: 1,
+
+			NewFileSize:           uint64(len(sectorData)),
+			NewFileMerkleRoot:     sectorRoot,
+			NewWindowStart:        so.expiration(),
+			NewWindowEnd:          so.proofDeadline(),
+			NewValidProofOutputs:  validPayouts,
+			NewMissedProofOutputs: missedPayouts,
+			NewUnlockHash:         types.UnlockConditions{}.UnlockHash(),
+		}},
+	}}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.modifyStorageObligation(so, nil, []crypto.Hash{sectorRoot}, [][]byte{sectorData})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so.id())
+
+	// Mine a block to confirm the file contract.
+	_, err = ht.miner.AddBlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sod, err = ht.host.StorageObligationDetails(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sod.Revisions) != 2 {
+		t.Fatal("wrong number of revisions:", len(sod.Revisions))
+	}
+	if rev := sod.Revisions[1]; rev.RevisionNumber != 1 || rev.FileMerkleRoot != sectorRoot || rev.FileSize != modules.SectorSize {
+		t.Fatal("revision was not recorded correctly:", rev)
+	}
+	if len(sod.Sectors) != 1 {
+		t.Fatal("wrong number of sectors:", sod.Sectors)
+	}
+	s := sod.Sectors[0]
+	if s.Root != sectorRoot || !s.Found || s.Failed {
+		t.Fatal("wrong sector location:", s)
+	}
+	var folderFound bool
+	for _, sf := range ht.host.StorageFolders() {
+		folderFound = folderFound || sf.Path == s.StorageFolder
+	}
+	if !folderFound {
+		t.Fatal("sector is not located in a storage folder of the host:", s)
+	}
+	if len(sod.Timeline) != 2 {
+		t.Fatal("wrong timeline:", sod.Timeline)
+	}
+	if tr := sod.Timeline[1]; tr.Status != modules.ObligationOriginConfirmed || tr.BlockHeight != ht.host.blockHeight {
+		t.Fatal("confirmation of the file contract was not recorded:", tr)
+	}
+}
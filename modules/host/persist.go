@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
@@ -29,6 +30,19 @@ type persistence struct {
 	SecretKey        crypto.SecretKey             `json:"secretkey"`
 	Settings         modules.HostInternalSettings `json:"settings"`
 	UnlockHash       types.UnlockHash             `json:"unlockhash"`
+
+	// Pricing Autopilot.
+	PriceAdjustments []modules.HostPriceAdjustment `json:"priceadjustments"`
+
+	// Renter Policy.
+	Policy modules.HostPolicy `json:"policy"`
+
+	// Maintenance.
+	MaintenanceEnd   time.Time `json:"maintenanceend"`
+	MaintenanceStart time.Time `json:"maintenancestart"`
+
+	// Bandwidth Tracking.
+	BandwidthMetrics modules.HostBandwidthMetrics `json:"bandwidthmetrics"`
 }
 
 // persistData returns the data in the Host that will be saved to disk.
@@ -47,6 +61,19 @@ func (h *Host) persistData() persistence {
 		SecretKey:        h.secretKey,
 		Settings:         h.settings,
 		UnlockHash:       h.unlockHash,
+
+		// Pricing Autopilot.
+		PriceAdjustments: h.priceAdjustments,
+
+		// Renter Policy.
+		Policy: h.policy,
+
+		// Maintenance.
+		MaintenanceEnd:   h.maintenanceEnd,
+		MaintenanceStart: h.maintenanceStart,
+
+		// Bandwidth Tracking.
+		BandwidthMetrics: h.bandwidthMetrics,
 	}
 }
 
@@ -68,6 +95,10 @@ func (h *Host) establishDefaults() error {
 		MinContractPrice:          defaultContractPrice,
 		MinDownloadBandwidthPrice: defaultDownloadBandwidthPrice,
 		MinUploadBandwidthPrice:   defaultUploadBandwidthPrice,
+
+		PricingTargetPercentile: pricingDefaultTargetPercentile,
+
+		SectorCacheSize: defaultSectorCacheSize,
 	}
 
 	// Generate signing key, for revising contracts.
@@ -107,6 +138,23 @@ func (h *Host) loadPersistObject(p *persistence) {
 		h.settings.NetAddress = ""
 	}
 	h.unlockHash = p.UnlockHash
+
+	// Copy over the pricing autopilot history. Hosts that were saved before
+	// the pricing autopilot existed don't have a target percentile yet.
+	h.priceAdjustments = p.PriceAdjustments
+	if h.settings.PricingTargetPercentile == 0 {
+		h.settings.PricingTargetPercentile = pricingDefaultTargetPercentile
+	}
+
+	// Copy over the renter policy.
+	h.policy = p.Policy
+
+	// Copy over the maintenance window.
+	h.maintenanceEnd = p.MaintenanceEnd
+	h.maintenanceStart = p.MaintenanceStart
+
+	// Copy over the bandwidth metrics.
+	h.bandwidthMetrics = p.BandwidthMetrics
 }
 
 // initDB will check that the database has been initialized and if not, will
@@ -129,6 +177,10 @@ func (h *Host) initDB() (err error) {
 		// database needs to be initialized. Create the database buckets.
 		buckets := [][]byte{
 			bucketActionItems,
+			bucketLedger,
+			bucketObligationBandwidth,
+			bucketObligationRevisions,
+			bucketObligationTransitions,
 			bucketStorageObligations,
 		}
 		for _, bucket := range buckets {
@@ -190,6 +242,7 @@ func (h *Host) load() error {
 				h.financialMetrics.ContractCount++
 				h.financialMetrics.LockedStorageCollateral = h.financialMetrics.LockedStorageCollateral.Add(so.LockedCollateral)
 			}
+			h.updateRenterUsage(storageObligation{}, so)
 		}
 		return nil
 	})
@@ -0,0 +1,244 @@
+package host
+
+// policy.go implements the host's policy toward renters. The policy can block
+// renters by their public key, limit the number of contracts and the amount
+// of data of each renter, and limit the size and collateral of each contract.
+// The policy is enforced when contracts are formed, renewed and revised.
+//
+// Renters are identified by the public key that they use in the unlock
+// conditions of their contracts. The usage of each renter is counted in memory
+// from the host's unresolved storage obligations. An obligation stops counting
+// towards the usage of its renter once its contract has been renewed, even
+// though it stays unresolved until its storage proof window has closed.
+
+import (
+	"errors"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+var (
+	// errContractCollateralLimit is returned if a contract needs more
+	// collateral than the host's policy allows for a single contract.
+	errContractCollateralLimit = ErrorCommunication("rejected because the contract needs more collateral than the host allows per contract")
+
+	// errContractSizeLimit is returned if a contract would hold more data than
+	// the host's policy allows for a single contract.
+	errContractSizeLimit = ErrorCommunication("rejected because the contract would exceed the maximum contract size of the host")
+
+	// errRenterBlocked is returned if the renter has been blocked by the
+	// host's policy.
+	errRenterBlocked = ErrorCommunication("rejected because the renter has been blocked by the host")
+
+	// errRenterContractLimit is returned if the renter already holds as many
+	// contracts as the host's policy allows.
+	errRenterContractLimit = ErrorCommunication("rejected because the renter has reached the host's limit of contracts per renter")
+
+	// errRenterDataLimit is returned if the renter would store more data than
+	// the host's policy allows for a single renter.
+	errRenterDataLimit = ErrorCommunication("rejected because the renter would exceed the host's limit of data per renter")
+)
+
+// renterUsage is the number of active contracts and the amount of data that a
+// renter holds with the host.
+type renterUsage struct {
+	contracts uint64
+	data      uint64
+}
+
+// renterKey returns the public key that the renter uses to sign revisions of
+// the storage obligation's contract.
+func (so storageObligation) renterKey() (types.SiaPublicKey, bool) {
+	if len(so.RevisionTransactionSet) == 0 {
+		return types.SiaPublicKey{}, false
+	}
+	revisionTxn := so.RevisionTransactionSet[len(so.RevisionTransactionSet)-1]
+	if len(revisionTxn.FileContractRevisions) == 0 || len(revisionTxn.FileContractRevisions[0].UnlockConditions.PublicKeys) == 0 {
+		return types.SiaPublicKey{}, false
+	}
+	return revisionTxn.FileContractRevisions[0].UnlockConditions.PublicKeys[0], true
+}
+
+// renterBlocked returns whether the policy blocks the renter.
+func renterBlocked(policy modules.HostPolicy, renter types.SiaPublicKey) bool {
+	renterStr := renter.String()
+	for _, blocked := range policy.BlockedRenters {
+		if blocked.String() == renterStr {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyPolicy checks a contract of a renter against the policy. usage is the
+// usage of the renter including the contract, size is the amount of data that
+// the contract will hold and collateral is the collateral that the host adds
+// to the contract.
+func verifyPolicy(policy modules.HostPolicy, renter types.SiaPublicKey, usage renterUsage, size uint64, collateral types.Currency) error {
+	if renterBlocked(policy, renter) {
+		return errRenterBlocked
+	}
+	if policy.MaxContractSize != 0 && size > policy.MaxContractSize {
+		return errContractSizeLimit
+	}
+	if !policy.MaxContractCollateral.IsZero() && collateral.Cmp(policy.MaxContractCollateral) > 0 {
+		return errContractCollateralLimit
+	}
+	if policy.MaxRenterContracts != 0 && usage.contracts > policy.MaxRenterContracts {
+		return errRenterContractLimit
+	}
+	if policy.MaxRenterData != 0 && usage.data > policy.MaxRenterData {
+		return errRenterDataLimit
+	}
+	return nil
+}
+
+// usage returns the renter of the storage obligation and the usage that the
+// obligation adds for the renter. Obligations that have been resolved or
+// renewed don't add any usage.
+func (so storageObligation) usage() (string, renterUsage) {
+	key, ok := so.renterKey()
+	if !ok || so.ObligationStatus != obligationUnresolved || so.Renewed {
+		return "", renterUsage{}
+	}
+	return key.String(), renterUsage{
+		contracts: 1,
+		data:      so.fileSize(),
+	}
+}
+
+// updateRenterUsage replaces the usage of the storage obligation oldSO with the
+// usage of its new version newSO in the usage counters of the renter. oldSO is
+// empty if the obligation is new.
+func (h *Host) updateRenterUsage(oldSO, newSO storageObligation) {
+	if renter, u := oldSO.usage(); renter != "" {
+		usage := h.renterUsage[renter]
+		usage.contracts -= u.contracts
+		usage.data -= u.data
+		h.renterUsage[renter] = usage
+		if usage == (renterUsage{}) {
+			delete(h.renterUsage, renter)
+		}
+	}
+	if renter, u := newSO.usage(); renter != "" {
+		usage := h.renterUsage[renter]
+		usage.contracts += u.contracts
+		usage.data += u.data
+		h.renterUsage[renter] = usage
+	}
+}
+
+// managedMarkObligationRenewed marks the storage obligation as renewed, so
+// that it no longer counts towards the usage of its renter.
+func (h *Host) managedMarkObligationRenewed(soid types.FileContractID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var oldSO, so storageObligation
+	err := h.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		oldSO, err = getStorageObligation(tx, soid)
+		if err != nil {
+			return err
+		}
+		so = oldSO
+		so.Renewed = true
+		return putStorageObligation(tx, so)
+	})
+	if err != nil {
+		h.log.Println("Unable to mark storage obligation", soid, "as renewed:", err)
+		return
+	}
+	h.updateRenterUsage(oldSO, so)
+}
+
+// managedRenterUsage returns the number of active contracts of the renter and
+// the amount of data they hold. The usage of the storage obligation exclude is
+// skipped, so that a contract that is being renewed or revised can be replaced
+// by its new version.
+func (h *Host) managedRenterUsage(renter types.SiaPublicKey, exclude storageObligation) renterUsage {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	usage := h.renterUsage[renter.String()]
+	if key, u := exclude.usage(); key == renter.String() {
+		usage.contracts -= u.contracts
+		usage.data -= u.data
+	}
+	return usage
+}
+
+// managedVerifyPolicy checks a new, renewed or grown contract of a renter
+// against the host's policy. replaces is the storage obligation of the
+// contract that is being renewed or revised, newContract indicates whether a
+// contract is added for the renter, size is the amount of data that the
+// contract will hold and collateral is the collateral that the host adds to
+// the contract.
+func (h *Host) managedVerifyPolicy(renter types.SiaPublicKey, replaces storageObligation, newContract bool, size uint64, collateral types.Currency) error {
+	h.mu.RLock()
+	policy := h.policy
+	h.mu.RUnlock()
+
+	usage := h.managedRenterUsage(renter, replaces)
+	if newContract {
+		usage.contracts++
+	} else {
+		// Revisions don't add a contract, so the renter's existing contracts
+		// are not held against them.
+		usage.contracts = 0
+	}
+	usage.data += size
+	err := verifyPolicy(policy, renter, usage, size, collateral)
+	if err != nil {
+		h.log.Debugf("Renter %v was rejected by the host policy: %v", renter.String(), err)
+	}
+	return err
+}
+
+// managedVerifyRenterAllowed checks that the host's policy does not block the
+// renter.
+func (h *Host) managedVerifyRenterAllowed(renter types.SiaPublicKey) error {
+	h.mu.RLock()
+	blocked := renterBlocked(h.policy, renter)
+	h.mu.RUnlock()
+	if blocked {
+		h.log.Debugf("Renter %v was rejected by the host policy: %v", renter.String(), errRenterBlocked)
+		return errRenterBlocked
+	}
+	return nil
+}
+
+// Policy returns the host's policy toward renters.
+func (h *Host) Policy() modules.HostPolicy {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	policy := h.policy
+	policy.BlockedRenters = append([]types.SiaPublicKey(nil), h.policy.BlockedRenters...)
+	return policy
+}
+
+// SetPolicy sets the host's policy toward renters. The policy applies to all
+// future negotiations, contracts that exceed a new limit are kept.
+func (h *Host) SetPolicy(policy modules.HostPolicy) error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+	for _, renter := range policy.BlockedRenters {
+		if len(renter.Key) == 0 {
+			return errors.New("policy not updated, blocked renter has an empty public key")
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.policy = policy
+	h.policy.BlockedRenters = append([]types.SiaPublicKey(nil), policy.BlockedRenters...)
+	err = h.saveSync()
+	if err != nil {
+		return errors.New("policy updated, but failed saving to disk: " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package host
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestVerifyPolicy probes each of the limits of the host policy.
+func TestVerifyPolicy(t *testing.T) {
+	renter := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1}}
+	other := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{2}}
+	policy := modules.HostPolicy{
+		BlockedRenters:        []types.SiaPublicKey{other},
+		MaxContractCollateral: types.NewCurrency64(100),
+		MaxContractSize:       10,
+		MaxRenterContracts:    2,
+		MaxRenterData:         15,
+	}
+	tests := []struct {
+		renter     types.SiaPublicKey
+		usage      renterUsage
+		size       uint64
+		collateral uint64
+		err        error
+	}{
+		{renter, renterUsage{2, 15}, 10, 100, nil},
+		{other, renterUsage{1, 0}, 0, 0, errRenterBlocked},
+		{renter, renterUsage{1, 11}, 11, 0, errContractSizeLimit},
+		{renter, renterUsage{1, 0}, 0, 101, errContractCollateralLimit},
+		{renter, renterUsage{3, 0}, 0, 0, errRenterContractLimit},
+		{renter, renterUsage{2, 16}, 10, 0, errRenterDataLimit},
+	}
+	for i, test := range tests {
+		err := verifyPolicy(policy, test.renter, test.usage, test.size, types.NewCurrency64(test.collateral))
+		if err != test.err {
+			t.Errorf("test %v: expected %v, got %v", i, test.err, err)
+		}
+	}
+
+	// An empty policy should not limit anything.
+	if err := verifyPolicy(modules.HostPolicy{}, other, renterUsage{1e6, 1e12}, 1e12, types.SiacoinPrecision); err != nil {
+		t.Error("empty policy rejected a contract:", err)
+	}
+}
+
+// TestRenterKey checks that the renter key is taken from the unlock
+// conditions of the storage obligation's revision.
+func TestRenterKey(t *testing.T) {
+	var so storageObligation
+	if _, ok := so.renterKey(); ok {
+		t.Fatal("storage obligation without revision has a renter key")
+	}
+	renter := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1}}
+	so.RevisionTransactionSet = []types.Transaction{{
+		FileContractRevisions: []types.FileContractRevision{{
+			UnlockConditions: types.UnlockConditions{
+				PublicKeys: []types.SiaPublicKey{renter, {}},
+			},
+		}},
+	}}
+	if key, ok := so.renterKey(); !ok || key.String() != renter.String() {
+		t.Fatal("wrong renter key:", key, ok)
+	}
+}
+
+// TestSetPolicy checks that the host policy is validated and survives a
+// restart of the host.
+func TestSetPolicy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := blankHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	if err := ht.host.SetPolicy(modules.HostPolicy{BlockedRenters: []types.SiaPublicKey{{}}}); err == nil {
+		t.Fatal("expected an error for an empty public key")
+	}
+	policy := modules.HostPolicy{
+		BlockedRenters:     []types.SiaPublicKey{{Algorithm: types.SignatureEd25519, Key: []byte{1}}},
+		MaxContractSize:    modules.SectorSize,
+		MaxRenterContracts: 3,
+	}
+	if err := ht.host.SetPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reboot the host and check that the policy was kept.
+	if err := ht.host.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ht.host, err = New(ht.cs, ht.tpool, ht.wallet, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := ht.host.Policy()
+	if len(loaded.BlockedRenters) != 1 || loaded.BlockedRenters[0].String() != policy.BlockedRenters[0].String() {
+		t.Fatal("blocked renters were not persisted:", loaded.BlockedRenters)
+	}
+	if loaded.MaxContractSize != policy.MaxContractSize || loaded.MaxRenterContracts != policy.MaxRenterContracts {
+		t.Fatal("limits were not persisted:", loaded)
+	}
+}
+
+// TestRenterUsageRenewals renews a contract twice while the renter is at the
+// limits of the policy, and checks that renewed contracts don't count towards
+// the usage of the renter, also after a restart.
+func TestRenterUsageRenewals(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+	err = ht.host.SetPolicy(modules.HostPolicy{
+		MaxRenterContracts: 1,
+		MaxRenterData:      modules.SectorSize,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// addObligation adds a storage obligation of the renter holding a sector.
+	renter := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1}}
+	addObligation := func() storageObligation {
+		so, err := ht.newTesterStorageObligation()
+		if err != nil {
+			t.Fatal(err)
+		}
+		fc := so.OriginTransactionSet[len(so.OriginTransactionSet)-1].FileContracts[0]
+		so.RevisionTransactionSet = []types.Transaction{{
+			FileContractRevisions: []types.FileContractRevision{{
+				ParentID:       so.id(),
+				NewFileSize:    modules.SectorSize,
+				NewWindowStart: fc.WindowStart,
+				NewWindowEnd:   fc.WindowEnd,
+				UnlockConditions: types.UnlockConditions{
+					PublicKeys: []types.SiaPublicKey{renter, {}},
+				},
+			}},
+		}}
+		ht.host.managedLockStorageObligation(so.id())
+		err = ht.host.managedAddStorageObligation(so)
+		ht.host.managedUnlockStorageObligation(so.id())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return so
+	}
+	verify := func(replaces storageObligation) error {
+		return ht.host.managedVerifyPolicy(renter, replaces, true, modules.SectorSize, types.ZeroCurrency)
+	}
+
+	// The renter is at the limits, so it can't form another contract but it
+	// can renew its contract. The renewed contracts stay unresolved until
+	// their proof windows close.
+	so := addObligation()
+	if err := verify(storageObligation{}); err != errRenterContractLimit {
+		t.Fatal("expected errRenterContractLimit, got", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := verify(so); err != nil {
+			t.Fatalf("renewal %v was rejected: %v", i, err)
+		}
+		renewed := addObligation()
+		ht.host.managedMarkObligationRenewed(so.id())
+		so = renewed
+	}
+	if fm := ht.host.FinancialMetrics(); fm.ContractCount != 3 {
+		t.Fatal("renewed contracts should stay unresolved:", fm.ContractCount)
+	}
+	if usage := ht.host.managedRenterUsage(renter, storageObligation{}); usage != (renterUsage{1, modules.SectorSize}) {
+		t.Fatal("wrong usage after the renewals:", usage)
+	}
+
+	// Reboot the host and check that the usage is counted the same way.
+	if err := ht.host.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ht.host, err = New(ht.cs, ht.tpool, ht.wallet, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage := ht.host.managedRenterUsage(renter, storageObligation{}); usage != (renterUsage{1, modules.SectorSize}) {
+		t.Fatal("wrong usage after a restart:", usage)
+	}
+	if err := verify(so); err != nil {
+		t.Fatal("renewal was rejected after a restart:", err)
+	}
+	if err := verify(storageObligation{}); err != errRenterContractLimit {
+		t.Fatal("expected errRenterContractLimit, got", err)
+	}
+}
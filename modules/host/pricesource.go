@@ -0,0 +1,214 @@
+package host
+
+// pricesource.go implements the price source that the pricing autopilot uses
+// when the host is not given one. It follows the host announcements in the
+// blockchain through its own consensus subscription and fetches the settings
+// of the announced hosts directly, so it doesn't need a renter or a hostdb.
+// The announcements and the consensus change of the subscription are
+// persisted, so that the blockchain only has to be scanned once.
+
+import (
+	"net"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// announcementPricesMetadata is the header of the persist file of the
+	// announcement price source.
+	announcementPricesMetadata = persist.Metadata{
+		Header:  "Sia Host Announcement Prices",
+		Version: "1.3.4",
+	}
+)
+
+type (
+	// announcementPriceSource is a PriceSource that reads host announcements
+	// from the consensus set and asks the announced hosts for their settings.
+	announcementPriceSource struct {
+		// announcements maps the public key of each announced host to the
+		// most recent address that it announced.
+		announcements map[string]modules.NetAddress
+		recentChange  modules.ConsensusChangeID
+		subscribed    bool
+
+		h  *Host
+		mu sync.Mutex
+	}
+
+	// announcementPricesPersist is the persisted state of an
+	// announcementPriceSource.
+	announcementPricesPersist struct {
+		Announcements map[string]modules.NetAddress `json:"announcements"`
+		RecentChange  modules.ConsensusChangeID     `json:"recentchange"`
+	}
+)
+
+// newAnnouncementPriceSource returns the announcement price source of the
+// host. The source doesn't subscribe to the consensus set until it is used.
+func newAnnouncementPriceSource(h *Host) *announcementPriceSource {
+	return &announcementPriceSource{
+		announcements: make(map[string]modules.NetAddress),
+		h:             h,
+	}
+}
+
+// ProcessConsensusChange records the host announcements of a consensus change.
+// Announcements of reverted blocks are kept, as the hosts were still seen on
+// the network.
+func (aps *announcementPriceSource) ProcessConsensusChange(cc modules.ConsensusChange) {
+	aps.mu.Lock()
+	defer aps.mu.Unlock()
+	for _, block := range cc.AppliedBlocks {
+		for _, txn := range block.Transactions {
+			for _, arb := range txn.ArbitraryData {
+				addr, pubKey, err := modules.DecodeAnnouncement(arb)
+				if err != nil {
+					continue
+				}
+				aps.announcements[pubKey.String()] = addr
+			}
+		}
+	}
+	aps.recentChange = cc.ID
+}
+
+// save saves the announcements and the consensus change of the source.
+func (aps *announcementPriceSource) save() error {
+	p := announcementPricesPersist{
+		Announcements: aps.announcements,
+		RecentChange:  aps.recentChange,
+	}
+	return persist.SaveJSON(announcementPricesMetadata, p, filepath.Join(aps.h.persistDir, announcementPricesFile))
+}
+
+// managedSubscribe loads the persisted announcements and subscribes the source
+// to the consensus set, unless it is subscribed already. The call blocks until
+// the source has caught up with the consensus set.
+func (aps *announcementPriceSource) managedSubscribe() error {
+	aps.mu.Lock()
+	if aps.subscribed {
+		aps.mu.Unlock()
+		return nil
+	}
+	var p announcementPricesPersist
+	err := persist.LoadJSON(announcementPricesMetadata, &p, filepath.Join(aps.h.persistDir, announcementPricesFile))
+	if err == nil && p.Announcements != nil {
+		aps.announcements = p.Announcements
+		aps.recentChange = p.RecentChange
+	}
+	recentChange := aps.recentChange
+	aps.mu.Unlock()
+
+	// The consensus set calls ProcessConsensusChange while catching up, so
+	// the lock can't be held while subscribing.
+	err = aps.h.cs.ConsensusSetSubscribe(aps, recentChange, aps.h.tg.StopChan())
+	if err == modules.ErrInvalidConsensusChangeID {
+		aps.mu.Lock()
+		aps.announcements = make(map[string]modules.NetAddress)
+		aps.recentChange = modules.ConsensusChangeBeginning
+		aps.mu.Unlock()
+		err = aps.h.cs.ConsensusSetSubscribe(aps, modules.ConsensusChangeBeginning, aps.h.tg.StopChan())
+	}
+	if err != nil {
+		return err
+	}
+	aps.h.tg.OnStop(func() {
+		aps.h.cs.Unsubscribe(aps)
+		aps.mu.Lock()
+		defer aps.mu.Unlock()
+		if err := aps.save(); err != nil {
+			aps.h.log.Println("Could not save the host announcements of the price source:", err)
+		}
+	})
+
+	aps.mu.Lock()
+	defer aps.mu.Unlock()
+	aps.subscribed = true
+	return aps.save()
+}
+
+// managedFetchSettings asks the host at addr for its settings.
+func (aps *announcementPriceSource) managedFetchSettings(addr modules.NetAddress, pubKey types.SiaPublicKey) (settings modules.HostExternalSettings, err error) {
+	dialer := &net.Dialer{
+		Cancel:  aps.h.tg.StopChan(),
+		Timeout: priceSourceScanTimeout,
+	}
+	conn, err := dialer.Dial("tcp", string(addr))
+	if err != nil {
+		return modules.HostExternalSettings{}, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(priceSourceScanTimeout))
+
+	if err := encoding.WriteObject(conn, modules.RPCSettings); err != nil {
+		return modules.HostExternalSettings{}, err
+	}
+	var pk crypto.PublicKey
+	copy(pk[:], pubKey.Key)
+	err = crypto.ReadSignedObject(conn, &settings, priceSourceMaxSettingsLen, pk)
+	return settings, err
+}
+
+// ActiveHosts asks every announced host for its settings and returns the
+// hosts that answered. Hosts with invalid addresses are skipped, as are local
+// hosts outside of testing.
+func (aps *announcementPriceSource) ActiveHosts() []modules.HostDBEntry {
+	if err := aps.managedSubscribe(); err != nil {
+		aps.h.log.Println("Could not subscribe the price source to the consensus set:", err)
+		return nil
+	}
+
+	var hosts []modules.HostDBEntry
+	aps.mu.Lock()
+	for pk, addr := range aps.announcements {
+		if addr.IsValid() != nil || (build.Release == "standard" && addr.IsLocal()) {
+			continue
+		}
+		var entry modules.HostDBEntry
+		entry.NetAddress = addr
+		entry.PublicKey.LoadString(pk)
+		hosts = append(hosts, entry)
+	}
+	aps.mu.Unlock()
+
+	// Fetch the settings of the hosts in parallel.
+	var active []modules.HostDBEntry
+	var activeMu sync.Mutex
+	var wg sync.WaitGroup
+	entries := make(chan modules.HostDBEntry)
+	for i := 0; i < priceSourceScanThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for entry := range entries {
+				settings, err := aps.managedFetchSettings(entry.NetAddress, entry.PublicKey)
+				if err != nil {
+					aps.h.log.Debugf("Price source could not get the settings of host %v: %v", entry.NetAddress, err)
+					continue
+				}
+				entry.HostExternalSettings = settings
+				activeMu.Lock()
+				active = append(active, entry)
+				activeMu.Unlock()
+			}
+		}()
+	}
+	for _, entry := range hosts {
+		select {
+		case entries <- entry:
+		case <-aps.h.tg.StopChan():
+		}
+	}
+	close(entries)
+	wg.Wait()
+	return active
+}
@@ -0,0 +1,176 @@
+package host
+
+// pricing.go implements the pricing autopilot. When enabled, the autopilot
+// periodically looks at the prices of the other hosts on the network and moves
+// the host's minimum prices toward a configured percentile of those prices.
+// Prices move by at most pricingMaxStep per adjustment and always stay
+// between the floor and the ceiling that the host has configured. The prices
+// come from the renter's hostdb if the node has a renter, and from the hosts
+// announced in the blockchain otherwise.
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// A PriceSource provides the prices of the other hosts on the network. The
+// renter's hostdb implements PriceSource.
+type PriceSource interface {
+	ActiveHosts() []modules.HostDBEntry
+}
+
+// SetPriceSource sets the source of the market prices that the pricing
+// autopilot follows. Without a price source the autopilot asks the hosts that
+// are announced in the blockchain for their prices.
+func (h *Host) SetPriceSource(ps PriceSource) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.priceSource = ps
+}
+
+// PriceAdjustments returns the most recent price adjustments of the pricing
+// autopilot, oldest first.
+func (h *Host) PriceAdjustments() []modules.HostPriceAdjustment {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return append([]modules.HostPriceAdjustment(nil), h.priceAdjustments...)
+}
+
+// pricePercentile returns the p-th percentile of prices using the
+// nearest-rank method. prices must not be empty.
+func pricePercentile(prices []types.Currency, p float64) types.Currency {
+	sorted := append([]types.Currency(nil), prices...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Cmp(sorted[j]) < 0
+	})
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	} else if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// adjustPrice moves current toward market by at most pricingMaxStep and
+// keeps the result between floor and ceiling. A ceiling of zero means that the
+// price has no upper bound. A current price of zero moves to the market price
+// directly.
+func adjustPrice(current, market, floor, ceiling types.Currency) types.Currency {
+	price := market
+	if !current.IsZero() {
+		maxStep := current.MulFloat(pricingMaxStep)
+		if price.Cmp(current.Add(maxStep)) > 0 {
+			price = current.Add(maxStep)
+		} else if current.Cmp(price) > 0 && current.Sub(price).Cmp(maxStep) > 0 {
+			price = current.Sub(maxStep)
+		}
+	}
+	if !ceiling.IsZero() && price.Cmp(ceiling) > 0 {
+		price = ceiling
+	}
+	if price.Cmp(floor) < 0 {
+		price = floor
+	}
+	return price
+}
+
+// managedAdjustPrices runs the pricing autopilot once, adjusting the host's
+// minimum prices toward the target percentile of the prices of the other
+// hosts.
+func (h *Host) managedAdjustPrices() {
+	h.mu.RLock()
+	settings := h.settings
+	ps := h.priceSource
+	pk := h.publicKey.String()
+	h.mu.RUnlock()
+	if !settings.PricingAutopilot {
+		return
+	}
+	if ps == nil {
+		ps = h.announcementPrices
+	}
+
+	// Collect the prices of the other hosts that are accepting contracts.
+	var contractPrices, downloadPrices, storagePrices, uploadPrices []types.Currency
+	for _, entry := range ps.ActiveHosts() {
+		if !entry.AcceptingContracts || entry.PublicKey.String() == pk {
+			continue
+		}
+		contractPrices = append(contractPrices, entry.ContractPrice)
+		downloadPrices = append(downloadPrices, entry.DownloadBandwidthPrice)
+		storagePrices = append(storagePrices, entry.StoragePrice)
+		uploadPrices = append(uploadPrices, entry.UploadBandwidthPrice)
+	}
+	hosts := len(contractPrices)
+	if hosts < pricingMinHosts {
+		h.log.Debugf("Pricing autopilot skipped, only %v hosts are known", hosts)
+		return
+	}
+	percentile := settings.PricingTargetPercentile
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// The settings may have changed while the prices were collected.
+	if !h.settings.PricingAutopilot {
+		return
+	}
+	prices := []struct {
+		name           string
+		current        *types.Currency
+		market         types.Currency
+		floor, ceiling types.Currency
+	}{
+		{"mincontractprice", &h.settings.MinContractPrice, pricePercentile(contractPrices, percentile), h.settings.ContractPriceFloor, h.settings.ContractPriceCeiling},
+		{"mindownloadbandwidthprice", &h.settings.MinDownloadBandwidthPrice, pricePercentile(downloadPrices, percentile), h.settings.DownloadBandwidthPriceFloor, h.settings.DownloadBandwidthPriceCeiling},
+		{"minstorageprice", &h.settings.MinStoragePrice, pricePercentile(storagePrices, percentile), h.settings.StoragePriceFloor, h.settings.StoragePriceCeiling},
+		{"minuploadbandwidthprice", &h.settings.MinUploadBandwidthPrice, pricePercentile(uploadPrices, percentile), h.settings.UploadBandwidthPriceFloor, h.settings.UploadBandwidthPriceCeiling},
+	}
+	var adjusted bool
+	for _, p := range prices {
+		newPrice := adjustPrice(*p.current, p.market, p.floor, p.ceiling)
+		if newPrice.Equals(*p.current) {
+			continue
+		}
+		adjustment := modules.HostPriceAdjustment{
+			Time:        time.Now(),
+			BlockHeight: h.blockHeight,
+			Price:       p.name,
+			OldPrice:    *p.current,
+			NewPrice:    newPrice,
+			MarketPrice: p.market,
+			Hosts:       hosts,
+		}
+		h.log.Printf("Pricing autopilot changed %v from %v to %v, the market price of %v hosts is %v", p.name, adjustment.OldPrice, newPrice, hosts, p.market)
+		h.priceAdjustments = append(h.priceAdjustments, adjustment)
+		*p.current = newPrice
+		adjusted = true
+	}
+	if !adjusted {
+		return
+	}
+	if len(h.priceAdjustments) > priceAdjustmentHistoryLimit {
+		h.priceAdjustments = h.priceAdjustments[len(h.priceAdjustments)-priceAdjustmentHistoryLimit:]
+	}
+	h.revisionNumber++
+	if err := h.saveSync(); err != nil {
+		h.log.Println("Could not save the host after adjusting prices:", err)
+	}
+}
+
+// threadedPricingAutopilot periodically runs the pricing autopilot.
+func (h *Host) threadedPricingAutopilot(closeChan chan struct{}) {
+	defer close(closeChan)
+	for {
+		select {
+		case <-h.tg.StopChan():
+			return
+		case <-time.After(pricingAutopilotFrequency):
+		}
+		h.managedAdjustPrices()
+	}
+}
@@ -0,0 +1,203 @@
+package host
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// stubPriceSource is a PriceSource that reports a fixed set of hosts.
+type stubPriceSource []modules.HostDBEntry
+
+// ActiveHosts returns the hosts of the stub.
+func (sps stubPriceSource) ActiveHosts() []modules.HostDBEntry { return sps }
+
+// TestPricePercentile probes the nearest-rank percentiles of pricePercentile.
+func TestPricePercentile(t *testing.T) {
+	prices := []types.Currency{
+		types.NewCurrency64(40),
+		types.NewCurrency64(10),
+		types.NewCurrency64(30),
+		types.NewCurrency64(20),
+	}
+	tests := []struct {
+		percentile float64
+		price      uint64
+	}{
+		{0, 10},
+		{25, 10},
+		{26, 20},
+		{50, 20},
+		{75, 30},
+		{100, 40},
+	}
+	for _, test := range tests {
+		if p := pricePercentile(prices, test.percentile); !p.Equals64(test.price) {
+			t.Errorf("percentile %v: expected %v, got %v", test.percentile, test.price, p)
+		}
+	}
+	// The prices should not have been sorted in place.
+	if !prices[0].Equals64(40) {
+		t.Error("pricePercentile modified its input")
+	}
+}
+
+// TestAdjustPrice probes the step limit and the bounds of adjustPrice.
+func TestAdjustPrice(t *testing.T) {
+	c := types.NewCurrency64
+	tests := []struct {
+		current, market, floor, ceiling, expected uint64
+	}{
+		{100, 105, 0, 0, 105}, // small steps are taken completely
+		{100, 200, 0, 0, 110}, // large steps are limited
+		{100, 10, 0, 0, 90},
+		{0, 500, 0, 0, 500},     // zero prices jump to the market
+		{100, 200, 0, 105, 105}, // ceilings limit the price
+		{100, 10, 95, 0, 95},    // floors limit the price
+		{50, 60, 80, 0, 80},     // floors apply even if the step is smaller
+	}
+	for _, test := range tests {
+		p := adjustPrice(c(test.current), c(test.market), c(test.floor), c(test.ceiling))
+		if !p.Equals64(test.expected) {
+			t.Errorf("%v: expected %v, got %v", test, test.expected, p)
+		}
+	}
+}
+
+// TestPricingAutopilot checks that the host adjusts its prices and records
+// the adjustments when the pricing autopilot is enabled.
+func TestPricingAutopilot(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := blankHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	var entry modules.HostDBEntry
+	entry.AcceptingContracts = true
+	entry.ContractPrice = types.SiacoinPrecision
+	entry.DownloadBandwidthPrice = defaultDownloadBandwidthPrice
+	entry.StoragePrice = defaultStoragePrice.Mul64(2)
+	entry.UploadBandwidthPrice = defaultUploadBandwidthPrice
+	ht.host.SetPriceSource(stubPriceSource{entry})
+
+	// Nothing should change while the autopilot is disabled.
+	ht.host.managedAdjustPrices()
+	if len(ht.host.PriceAdjustments()) != 0 {
+		t.Fatal("prices were adjusted with the autopilot disabled")
+	}
+
+	settings := ht.host.InternalSettings()
+	settings.PricingAutopilot = true
+	settings.ContractPriceCeiling = defaultContractPrice
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedAdjustPrices()
+
+	// The contract price is at its ceiling already and the bandwidth prices
+	// match the market, so only the storage price should go up.
+	adjustments := ht.host.PriceAdjustments()
+	if len(adjustments) != 1 || adjustments[0].Price != "minstorageprice" || adjustments[0].Hosts != 1 {
+		t.Fatal("wrong adjustments:", adjustments)
+	}
+	expected := defaultStoragePrice.Add(defaultStoragePrice.MulFloat(pricingMaxStep))
+	if is := ht.host.InternalSettings(); !is.MinStoragePrice.Equals(expected) || !adjustments[0].NewPrice.Equals(expected) {
+		t.Fatal("storage price was not adjusted:", is.MinStoragePrice, expected)
+	}
+
+	// Floors above their ceiling should be rejected.
+	settings = ht.host.InternalSettings()
+	settings.ContractPriceFloor = defaultContractPrice.Mul64(2)
+	if err := ht.host.SetInternalSettings(settings); err == nil {
+		t.Fatal("expected an error for a floor above its ceiling")
+	}
+
+	// A target percentile of 0 should be rejected while the autopilot is
+	// enabled.
+	settings = ht.host.InternalSettings()
+	settings.PricingTargetPercentile = 0
+	if err := ht.host.SetInternalSettings(settings); err == nil {
+		t.Fatal("expected an error for a target percentile of 0")
+	}
+}
+
+// TestPricingAutopilotAnnouncements checks that the pricing autopilot follows
+// the hosts announced in the blockchain if the host has no price source.
+func TestPricingAutopilotAnnouncements(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Announce a second host that charges twice as much for storage.
+	h2, err := newHost(modules.ProdDependencies, ht.cs, ht.tpool, ht.wallet, "localhost:0", filepath.Join(ht.persistDir, "host2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h2.Close()
+	settings := h2.InternalSettings()
+	settings.AcceptingContracts = true
+	settings.MinStoragePrice = defaultStoragePrice.Mul64(2)
+	if err := h2.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if err := h2.Announce(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ht.host.Announce(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ht.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both hosts are found, and they report their settings.
+	hosts := ht.host.announcementPrices.ActiveHosts()
+	h2pk := h2.PublicKey()
+	if len(hosts) != 2 {
+		t.Fatal("expected 2 announced hosts, got", len(hosts))
+	}
+	for _, entry := range hosts {
+		if entry.PublicKey.String() == h2pk.String() && !entry.StoragePrice.Equals(settings.MinStoragePrice) {
+			t.Fatal("announced host reported the wrong storage price:", entry.StoragePrice)
+		}
+	}
+
+	// The autopilot ignores the host itself and follows the second host. The
+	// advertised contract price includes the transaction fee, so the contract
+	// price is held at its ceiling.
+	settings = ht.host.InternalSettings()
+	settings.PricingAutopilot = true
+	settings.ContractPriceCeiling = defaultContractPrice
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedAdjustPrices()
+	adjustments := ht.host.PriceAdjustments()
+	if len(adjustments) != 1 || adjustments[0].Price != "minstorageprice" || adjustments[0].Hosts != 1 {
+		t.Fatal("wrong adjustments:", adjustments)
+	}
+
+	// The announcements are persisted.
+	var p announcementPricesPersist
+	err = persist.LoadJSON(announcementPricesMetadata, &p, filepath.Join(ht.host.persistDir, announcementPricesFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Announcements) != 2 {
+		t.Fatal("expected 2 persisted announcements, got", len(p.Announcements))
+	}
+}
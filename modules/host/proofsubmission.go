@@ -0,0 +1,256 @@
+package host
+
+// proofsubmission.go tracks the storage proofs of the host until they are
+// confirmed. A storage proof that is not confirmed before the end of the
+// proof window costs the host its collateral, so once the window is open the
+// host checks on the storage proof every resubmissionTimeout blocks.
+//
+// A storage proof that is waiting in the transaction pool is rebroadcast to
+// the network. The transaction pool does not replace a storage proof with a
+// conflicting one that pays a higher fee, so the fee of a pending storage
+// proof can't be raised. Storage proofs that pay too little to get into a
+// block are eventually dropped from the transaction pool though, and the host
+// then submits a new storage proof. The fee doubles with every attempt, and
+// the maximum fee is paid once the proof is at risk of missing its window.
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+var (
+	// errProofFeeTooHigh is returned if the fee of a storage proof exceeds
+	// the value of the storage obligation.
+	errProofFeeTooHigh = errors.New("the transaction fee exceeds the value of the obligation")
+)
+
+// proofFee returns the miner fee for the next storage proof of the
+// obligation, given the fee that the transaction pool recommends for it. The
+// fee doubles with every attempt up to proofMaxFeeMultiplier times the
+// recommended fee, and the maximum is paid right away if the proof is at
+// risk. The host never pays more than the value of the obligation.
+func (so storageObligation) proofFee(recommendedFee types.Currency, blocksLeft types.BlockHeight) types.Currency {
+	multiplier := uint64(1)
+	for i := uint64(0); i < so.ProofAttempts && multiplier < proofMaxFeeMultiplier; i++ {
+		multiplier *= 2
+	}
+	if blocksLeft <= proofRiskBlocks {
+		multiplier = proofMaxFeeMultiplier
+	}
+	fee := recommendedFee.Mul64(multiplier)
+	if fee.Cmp(so.value()) > 0 {
+		fee = so.value()
+	}
+	return fee
+}
+
+// proofPending returns whether the latest storage proof of the obligation is
+// waiting in the transaction pool.
+func (h *Host) proofPending(so storageObligation) bool {
+	if len(so.ProofTransactionSet) == 0 {
+		return false
+	}
+	_, _, exists := h.tpool.Transaction(so.ProofTransactionSet[len(so.ProofTransactionSet)-1].ID())
+	return exists
+}
+
+// managedQueueProofCheck queues the next check on the storage proof of the
+// obligation. The first check also queues an action item at the proof
+// deadline, where the host removes the obligation once its proof has been
+// confirmed. A check at the deadline is followed by one after the window has
+// closed, so that a missed storage proof is noticed.
+func (h *Host) managedQueueProofCheck(so *storageObligation, blockHeight types.BlockHeight) {
+	deadline := so.proofDeadline()
+	next := blockHeight + resubmissionTimeout
+	if next >= deadline {
+		next = deadline
+	}
+	if next <= blockHeight {
+		next = deadline + 1
+	}
+
+	h.mu.Lock()
+	err := h.queueActionItem(next, so.id())
+	if err == nil && so.ProofCheckHeight == 0 && next < deadline {
+		err = h.queueActionItem(deadline, so.id())
+	}
+	h.mu.Unlock()
+	if err != nil {
+		h.log.Println("Error queuing action item:", err)
+	}
+	so.ProofCheckHeight = next
+}
+
+// managedHandleStorageProof checks on the storage proof of an obligation
+// whose proof window is open. A pending storage proof is rebroadcast,
+// otherwise a new storage proof is submitted. The caller must hold the lock
+// on the storage obligation, and save the obligation afterwards.
+func (h *Host) managedHandleStorageProof(so *storageObligation, blockHeight types.BlockHeight) {
+	// The storage proof may already have been checked at this height by
+	// another action item.
+	if so.ProofCheckHeight > blockHeight {
+		return
+	}
+	h.managedQueueProofCheck(so, blockHeight)
+
+	blocksLeft := so.proofDeadline() - blockHeight
+	if blocksLeft <= proofRiskBlocks {
+		h.log.Printf("WARN: storage proof for obligation %v is not confirmed after %v attempts, the proof window closes in %v blocks\n", so.id(), so.ProofAttempts, blocksLeft)
+	}
+	if h.proofPending(*so) {
+		h.log.Debugln("Rebroadcasting the storage proof for", so.id())
+		h.tpool.Broadcast(so.ProofTransactionSet)
+		return
+	}
+	if so.ProofAttempts > 0 {
+		h.log.Printf("Storage proof for obligation %v was dropped from the transaction pool, resubmitting with a higher fee\n", so.id())
+	}
+
+	err := h.managedSubmitStorageProof(so, blockHeight)
+	if err != nil {
+		h.log.Println("Unable to submit the storage proof for", so.id(), err)
+		h.managedRecordTransition(so.id(), modules.ObligationProofFailed, err.Error())
+		return
+	}
+	h.managedRecordTransition(so.id(), modules.ObligationProofSubmitted, "")
+}
+
+// managedSubmitStorageProof builds a storage proof for the obligation and
+// submits it to the transaction pool.
+func (h *Host) managedSubmitStorageProof(so *storageObligation, blockHeight types.BlockHeight) error {
+	// Get the index of the segment, and the index of the sector containing
+	// the segment.
+	segmentIndex, err := h.cs.StorageProofSegment(so.id())
+	if err != nil {
+		return build.ExtendErr("unable to fetch the storage proof segment", err)
+	}
+	sectorIndex := segmentIndex / (modules.SectorSize / crypto.SegmentSize)
+	// Pull the corresponding sector into memory.
+	sectorRoot := so.SectorRoots[sectorIndex]
+	sectorBytes, err := h.ReadSector(sectorRoot)
+	if err != nil {
+		return build.ExtendErr("unable to read sector "+sectorRoot.String(), err)
+	}
+
+	// Build the storage proof for just the sector.
+	sectorSegment := segmentIndex % (modules.SectorSize / crypto.SegmentSize)
+	base, cachedHashSet := crypto.MerkleProof(sectorBytes, sectorSegment)
+
+	// Using the sector, build a cached root.
+	log2SectorSize := uint64(0)
+	for 1<<log2SectorSize < (modules.SectorSize / crypto.SegmentSize) {
+		log2SectorSize++
+	}
+	ct := crypto.NewCachedTree(log2SectorSize)
+	ct.SetIndex(segmentIndex)
+	for _, root := range so.SectorRoots {
+		ct.Push(root)
+	}
+	hashSet := ct.Prove(base, cachedHashSet)
+	sp := types.StorageProof{
+		ParentID: so.id(),
+		HashSet:  hashSet,
+	}
+	copy(sp.Segment[:], base)
+
+	// Create and build the transaction with the storage proof.
+	builder, err := h.wallet.StartTransaction()
+	if err != nil {
+		return build.ExtendErr("unable to start the transaction", err)
+	}
+	_, feeRecommendation := h.tpool.FeeEstimation()
+	if so.value().Cmp(feeRecommendation) < 0 {
+		// There's no sense submitting the storage proof if the fee is more
+		// than the anticipated revenue.
+		builder.Drop()
+		return errProofFeeTooHigh
+	}
+	txnSize := uint64(len(encoding.Marshal(sp)) + 300)
+	fee := so.proofFee(feeRecommendation.Mul64(txnSize), so.proofDeadline()-blockHeight)
+	err = builder.FundSiacoins(fee)
+	if err != nil {
+		builder.Drop()
+		return build.ExtendErr("unable to fund the transaction fee", err)
+	}
+	builder.AddMinerFee(fee)
+	builder.AddStorageProof(sp)
+	storageProofSet, err := builder.Sign(true)
+	if err != nil {
+		builder.Drop()
+		return build.ExtendErr("unable to sign the storage proof transaction", err)
+	}
+	err = h.tpool.AcceptTransactionSet(storageProofSet)
+	if err != nil {
+		builder.Drop()
+		return build.ExtendErr("the transaction pool rejected the storage proof", err)
+	}
+	// The storage proof replaces the previous one, which was dropped from the
+	// transaction pool, so the host never paid its fee.
+	so.TransactionFeesAdded = so.TransactionFeesAdded.Sub(so.ProofFee).Add(fee)
+	so.ProofAttempts++
+	so.ProofFee = fee
+	so.ProofSubmissionHeight = blockHeight
+	so.ProofTransactionSet = storageProofSet
+	return nil
+}
+
+// StorageProofs returns the storage proofs that the host has yet to get
+// confirmed, which are the storage proofs of the unresolved obligations whose
+// proof window is open.
+func (h *Host) StorageProofs() []modules.HostStorageProof {
+	err := h.tg.Add()
+	if err != nil {
+		return nil
+	}
+	defer h.tg.Done()
+
+	h.mu.RLock()
+	blockHeight := h.blockHeight
+	var sos []storageObligation
+	err = h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			if err := json.Unmarshal(soBytes, &so); err != nil {
+				return build.ExtendErr("unable to unmarshal storage obligation", err)
+			}
+			if so.ObligationStatus == obligationUnresolved && len(so.SectorRoots) > 0 && !so.ProofConfirmed && blockHeight >= so.expiration() {
+				sos = append(sos, so)
+			}
+			return nil
+		})
+	})
+	h.mu.RUnlock()
+	if err != nil {
+		h.log.Println("Unable to list the storage proofs:", err)
+	}
+
+	// The transaction pool is queried without holding the host lock.
+	proofs := make([]modules.HostStorageProof, 0, len(sos))
+	for _, so := range sos {
+		var blocksRemaining types.BlockHeight
+		if so.proofDeadline() > blockHeight {
+			blocksRemaining = so.proofDeadline() - blockHeight
+		}
+		proofs = append(proofs, modules.HostStorageProof{
+			ObligationId: so.id(),
+
+			Attempts:         so.ProofAttempts,
+			Fee:              so.ProofFee,
+			Pending:          h.proofPending(so),
+			SubmissionHeight: so.ProofSubmissionHeight,
+
+			AtRisk:          blocksRemaining <= proofRiskBlocks,
+			BlocksRemaining: blocksRemaining,
+			ProofDeadline:   so.proofDeadline(),
+		})
+	}
+	return proofs
+}
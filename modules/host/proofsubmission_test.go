@@ -0,0 +1,196 @@
+package host
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/coreos/bbolt"
+)
+
+// TestProofFee probes the fee escalation of storage proofs.
+func TestProofFee(t *testing.T) {
+	so := storageObligation{
+		ContractCost: types.NewCurrency64(1000),
+	}
+	fee := types.NewCurrency64(10)
+
+	// The fee doubles with every attempt.
+	for attempts, multiplier := range []uint64{1, 2, 4, 8, 16, 16} {
+		so.ProofAttempts = uint64(attempts)
+		if f := so.proofFee(fee, proofRiskBlocks+1); !f.Equals(fee.Mul64(multiplier)) {
+			t.Fatalf("wrong fee after %v attempts: %v", attempts, f)
+		}
+	}
+
+	// The maximum fee is paid once the proof is at risk.
+	so.ProofAttempts = 0
+	if f := so.proofFee(fee, proofRiskBlocks); !f.Equals(fee.Mul64(proofMaxFeeMultiplier)) {
+		t.Fatal("wrong fee for a proof at risk:", f)
+	}
+
+	// The fee never exceeds the value of the obligation.
+	if f := so.proofFee(fee.Mul64(100), proofRiskBlocks+1); !f.Equals(so.value()) {
+		t.Fatal("fee exceeds the value of the obligation:", f)
+	}
+}
+
+// TestStorageProofResubmission checks that the host rebroadcasts a pending
+// storage proof, and submits a new storage proof with a higher fee once the
+// proof was dropped from the transaction pool.
+func TestStorageProofResubmission(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Add a storage obligation holding a sector.
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so.id())
+	// The renter pays for the sector, so that the obligation is worth a
+	// storage proof.
+	sectorRoot, sectorData := randSector()
+	so.SectorRoots = []crypto.Hash{sectorRoot}
+	sectorCost := types.SiacoinPrecision.Mul64(550)
+	so.PotentialStorageRevenue = so.PotentialStorageRevenue.Add(sectorCost)
+	validPayouts, missedPayouts := so.payouts()
+	validPayouts[0].Value = validPayouts[0].Value.Sub(sectorCost)
+	validPayouts[1].Value = validPayouts[1].Value.Add(sectorCost)
+	missedPayouts[0].Value = missedPayouts[0].Value.Sub(sectorCost)
+	missedPayouts[1].Value = missedPayouts[1].Value.Add(sectorCost)
+	revisionSet := []types.Transaction{{
+		FileContractRevisions: []types.FileContractRevision{{
+			ParentID:          so.id(),
+			UnlockConditions:  types.UnlockConditions{},
+			NewRevisionNumber: 1,
+
+			NewFileSize:           uint64(len(sectorData)),
+			NewFileMerkleRoot:     sectorRoot,
+			NewWindowStart:        so.expiration(),
+			NewWindowEnd:          so.proofDeadline(),
+			NewValidProofOutputs:  validPayouts,
+			NewMissedProofOutputs: missedPayouts,
+			NewUnlockHash:         types.UnlockConditions{}.UnlockHash(),
+		}},
+	}}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.modifyStorageObligation(so, nil, []crypto.Hash{sectorRoot}, [][]byte{sectorData})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so.id())
+	err = ht.tpool.AcceptTransactionSet(revisionSet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Mine until the proof window opens, but before the host submits the
+	// storage proof by itself.
+	for ht.host.blockHeight < so.expiration() {
+		_, err := ht.miner.AddBlock()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = ht.host.tg.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	defer ht.host.managedUnlockStorageObligation(so.id())
+	err = ht.host.db.View(func(tx *bolt.Tx) error {
+		so, err = getStorageObligation(tx, so.id())
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !so.RevisionConfirmed {
+		t.Fatal("revision was not confirmed")
+	}
+	if proofs := ht.host.StorageProofs(); len(proofs) != 1 || proofs[0].Attempts != 0 || proofs[0].Pending {
+		t.Fatal("wrong storage proofs before the first attempt:", proofs)
+	}
+
+	// Submit the storage proof.
+	height := ht.host.blockHeight
+	ht.host.managedHandleStorageProof(&so, height)
+	if so.ProofAttempts != 1 || !ht.host.proofPending(so) {
+		t.Fatal("storage proof was not submitted:", so.ProofAttempts)
+	}
+	if so.ProofCheckHeight != height+resubmissionTimeout {
+		t.Fatal("wrong height for the next check:", so.ProofCheckHeight)
+	}
+	firstFee := so.ProofFee
+	firstProof := so.ProofTransactionSet
+
+	// A second check at the same height is skipped.
+	ht.host.managedHandleStorageProof(&so, height)
+	if so.ProofCheckHeight != height+resubmissionTimeout {
+		t.Fatal("duplicate check was not skipped:", so.ProofCheckHeight)
+	}
+
+	// The pending storage proof is rebroadcast, not replaced.
+	ht.host.managedHandleStorageProof(&so, so.ProofCheckHeight)
+	if so.ProofAttempts != 1 || so.ProofTransactionSet[0].ID() != firstProof[0].ID() {
+		t.Fatal("pending storage proof was replaced")
+	}
+
+	// Once the storage proof is dropped from the transaction pool, the host
+	// submits a new one with a higher fee. The deadline has been reached, so
+	// the maximum fee is paid. Another transaction is submitted so that the
+	// wallet is told about the dropped transactions.
+	ht.tpool.PurgeTransactionPool()
+	err = ht.tpool.AcceptTransactionSet([]types.Transaction{{
+		ArbitraryData: [][]byte{append(modules.PrefixNonSia[:], []byte("dropped")...)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if so.ProofCheckHeight != so.proofDeadline() {
+		t.Fatal("last check is not at the proof deadline:", so.ProofCheckHeight)
+	}
+	ht.host.managedHandleStorageProof(&so, so.ProofCheckHeight)
+	if so.ProofAttempts != 2 || !ht.host.proofPending(so) {
+		t.Fatal("storage proof was not resubmitted:", so.ProofAttempts)
+	}
+	if so.ProofFee.Cmp(firstFee) <= 0 {
+		t.Fatal("fee was not raised:", so.ProofFee, firstFee)
+	}
+	if so.ProofCheckHeight != so.proofDeadline()+1 {
+		t.Fatal("missed storage proof would not be noticed:", so.ProofCheckHeight)
+	}
+	if !so.TransactionFeesAdded.Equals(so.ProofFee) {
+		t.Fatal("only the fee of the pending storage proof should be recorded:", so.TransactionFeesAdded)
+	}
+
+	// The host reports the storage proof once the obligation is saved.
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		return putStorageObligation(tx, so)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proofs := ht.host.StorageProofs()
+	if len(proofs) != 1 || proofs[0].Attempts != 2 || !proofs[0].Pending || !proofs[0].Fee.Equals(so.ProofFee) {
+		t.Fatal("wrong storage proofs after the resubmission:", proofs)
+	}
+	if proofs[0].BlocksRemaining != so.proofDeadline()-ht.host.blockHeight || proofs[0].AtRisk {
+		t.Fatal("wrong deadline of the storage proof:", proofs[0])
+	}
+}
@@ -98,9 +98,9 @@ var (
 	// revisionSubmissionBuffer blocks.
 	errNoBuffer = errors.New("file contract rejected because storage proof window is too close")
 
-	// errNoStorageObligation is returned if the requested storage obligation
+	// ErrNoStorageObligation is returned if the requested storage obligation
 	// is not found in the database.
-	errNoStorageObligation = errors.New("storage obligation not found in database")
+	ErrNoStorageObligation = errors.New("storage obligation not found in database")
 
 	// errObligationUnlocked is returned when a storage obligation is being
 	// removed from lock, but is already unlocked.
@@ -136,19 +136,34 @@ type storageObligation struct {
 	// onto the blockchain quickly enough, the contract is pruned from the
 	// host. The origin and revision transaction set contain the contracts +
 	// revisions as well as all parent transactions. The parents are necessary
-	// because after a restart the transaction pool may be emptied out.
+	// because after a restart the transaction pool may be emptied out. The
+	// proof transaction set is the last storage proof submitted by the host.
 	NegotiationHeight      types.BlockHeight
 	OriginTransactionSet   []types.Transaction
+	ProofTransactionSet    []types.Transaction
 	RevisionTransactionSet []types.Transaction
 
+	// The host tracks its storage proof until it is confirmed. ProofAttempts
+	// is the number of storage proofs submitted for the obligation and
+	// ProofFee is the miner fee of the latest one, which was submitted at
+	// ProofSubmissionHeight. ProofCheckHeight is the height at which the host
+	// next checks on the storage proof.
+	ProofAttempts         uint64
+	ProofCheckHeight      types.BlockHeight
+	ProofFee              types.Currency
+	ProofSubmissionHeight types.BlockHeight
+
 	// Variables indicating whether the critical transactions in a storage
-	// obligation have been confirmed on the blockchain.
+	// obligation have been confirmed on the blockchain. Renewed indicates
+	// that the contract has been renewed, the obligation is resolved as usual
+	// but no longer counts towards the usage of the renter.
 	ObligationStatus    storageObligationStatus
 	OriginConfirmed     bool
 	ProofConfirmed      bool
 	ProofConstructed    bool
 	RevisionConfirmed   bool
 	RevisionConstructed bool
+	Renewed             bool
 }
 
 func (i storageObligationStatus) String() string {
@@ -171,7 +186,7 @@ func (i storageObligationStatus) String() string {
 func getStorageObligation(tx *bolt.Tx, soid types.FileContractID) (so storageObligation, err error) {
 	soBytes := tx.Bucket(bucketStorageObligations).Get(soid[:])
 	if soBytes == nil {
-		return storageObligation{}, errNoStorageObligation
+		return storageObligation{}, ErrNoStorageObligation
 	}
 	err = json.Unmarshal(soBytes, &so)
 	if err != nil {
@@ -378,6 +393,26 @@ func (h *Host) managedAddStorageObligation(so storageObligation) error {
 				}
 			}
 
+			// Record the formation of the contract in the ledger.
+			err := putLedgerEntry(tx, modules.HostLedgerContractFormed, soid, h.blockHeight, obligationRevenue(so))
+			if err != nil {
+				return err
+			}
+			err = putLedgerEntry(tx, modules.HostLedgerTransactionFee, soid, h.blockHeight, so.TransactionFeesAdded)
+			if err != nil {
+				return err
+			}
+
+			// Start the history of the obligation.
+			err = putObligationRevision(tx, so, h.blockHeight)
+			if err != nil {
+				return err
+			}
+			err = putObligationTransition(tx, soid, h.blockHeight, modules.ObligationCreated, "")
+			if err != nil {
+				return err
+			}
+
 			// Add the storage obligation to the database.
 			soBytes, err := json.Marshal(so)
 			if err != nil {
@@ -399,6 +434,7 @@ func (h *Host) managedAddStorageObligation(so storageObligation) error {
 		h.financialMetrics.PotentialUploadBandwidthRevenue = h.financialMetrics.PotentialUploadBandwidthRevenue.Add(so.PotentialUploadRevenue)
 		h.financialMetrics.RiskedStorageCollateral = h.financialMetrics.RiskedStorageCollateral.Add(so.RiskedCollateral)
 		h.financialMetrics.TransactionFeeExpenses = h.financialMetrics.TransactionFeeExpenses.Add(so.TransactionFeesAdded)
+		h.updateRenterUsage(storageObligation{}, so)
 		return nil
 	}()
 	if err != nil {
@@ -505,7 +541,25 @@ func (h *Host) modifyStorageObligation(so storageObligation, sectorsRemoved []cr
 			return err
 		}
 
+		// Record the revenue and fees that were added by the revision.
+		if obligationRevenue(so).Cmp(obligationRevenue(oldSO)) > 0 {
+			err = putLedgerEntry(tx, modules.HostLedgerRevisionRevenue, soid, h.blockHeight, obligationRevenue(so).Sub(obligationRevenue(oldSO)))
+			if err != nil {
+				return err
+			}
+		}
+		if so.TransactionFeesAdded.Cmp(oldSO.TransactionFeesAdded) > 0 {
+			err = putLedgerEntry(tx, modules.HostLedgerTransactionFee, soid, h.blockHeight, so.TransactionFeesAdded.Sub(oldSO.TransactionFeesAdded))
+			if err != nil {
+				return err
+			}
+		}
+
 		// Store the new storage obligation to replace the old one.
+		err = putObligationRevision(tx, so, h.blockHeight)
+		if err != nil {
+			return err
+		}
 		return putStorageObligation(tx, so)
 	})
 	if err != nil {
@@ -545,6 +599,7 @@ func (h *Host) modifyStorageObligation(so storageObligation, sectorsRemoved []cr
 	h.financialMetrics.PotentialUploadBandwidthRevenue = h.financialMetrics.PotentialUploadBandwidthRevenue.Sub(oldSO.PotentialUploadRevenue)
 	h.financialMetrics.RiskedStorageCollateral = h.financialMetrics.RiskedStorageCollateral.Sub(oldSO.RiskedCollateral)
 	h.financialMetrics.TransactionFeeExpenses = h.financialMetrics.TransactionFeeExpenses.Sub(oldSO.TransactionFeesAdded)
+	h.updateRenterUsage(oldSO, so)
 	return nil
 }
 
@@ -617,9 +672,37 @@ func (h *Host) removeStorageObligation(so storageObligation, sos storageObligati
 	// ended up, and the sector roots are removed because they are large
 	// objects with little purpose once storage proofs are no longer needed.
 	h.financialMetrics.ContractCount--
+	oldSO := so
 	so.ObligationStatus = sos
 	so.SectorRoots = nil
+	h.updateRenterUsage(oldSO, so)
 	return h.db.Update(func(tx *bolt.Tx) error {
+		// Record the outcome of the obligation in the ledger. Every outcome
+		// resolves the potential revenue that was recorded for the obligation.
+		var err error
+		switch sos {
+		case obligationSucceeded:
+			err = putLedgerEntry(tx, modules.HostLedgerProofSuccess, so.id(), h.blockHeight, obligationRevenue(so))
+		case obligationFailed:
+			err = putLedgerEntry(tx, modules.HostLedgerLostRevenue, so.id(), h.blockHeight, obligationRevenue(so))
+			if err == nil {
+				err = putLedgerEntry(tx, modules.HostLedgerLostCollateral, so.id(), h.blockHeight, so.RiskedCollateral)
+			}
+		case obligationRejected:
+			// The contract never made it into the blockchain, so the host
+			// didn't pay the fees of its transactions either.
+			err = putLedgerEntry(tx, modules.HostLedgerContractRejected, so.id(), h.blockHeight, obligationRevenue(so))
+			if err == nil {
+				err = putLedgerEntry(tx, modules.HostLedgerFeeRefund, so.id(), h.blockHeight, so.TransactionFeesAdded)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		err = putObligationTransition(tx, so.id(), h.blockHeight, sos.String(), "")
+		if err != nil {
+			return err
+		}
 		return putStorageObligation(tx, so)
 	})
 }
@@ -658,6 +741,8 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		// Storage obligation has already been completed, skip action item.
 		return
 	}
+	feesAdded := so.TransactionFeesAdded
+	proofAttempts, proofFee := so.ProofAttempts, so.ProofFee
 
 	// Check whether the file contract has been seen. If not, resubmit and
 	// queue another action item. Check for death. (signature should have a
@@ -770,6 +855,8 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		if err != nil {
 			h.log.Println("Error submitting transaction to transaction pool", err)
 			builder.Drop()
+		} else {
+			h.managedRecordTransition(soid, modules.ObligationRevisionSubmitted, "")
 		}
 		so.TransactionFeesAdded = so.TransactionFeesAdded.Add(requiredFee)
 		// return
@@ -805,93 +892,28 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 			}
 			return
 		}
-		// Get the index of the segment, and the index of the sector containing
-		// the segment.
-		segmentIndex, err := h.cs.StorageProofSegment(so.id())
-		if err != nil {
-			h.log.Debugln("Host got an error when fetching a storage proof segment:", err)
-			return
-		}
-		sectorIndex := segmentIndex / (modules.SectorSize / crypto.SegmentSize)
-		// Pull the corresponding sector into memory.
-		sectorRoot := so.SectorRoots[sectorIndex]
-		sectorBytes, err := h.ReadSector(sectorRoot)
-		if err != nil {
-			h.log.Debugln(err)
-			return
-		}
-
-		// Build the storage proof for just the sector.
-		sectorSegment := segmentIndex % (modules.SectorSize / crypto.SegmentSize)
-		base, cachedHashSet := crypto.MerkleProof(sectorBytes, sectorSegment)
-
-		// Using the sector, build a cached root.
-		log2SectorSize := uint64(0)
-		for 1<<log2SectorSize < (modules.SectorSize / crypto.SegmentSize) {
-			log2SectorSize++
-		}
-		ct := crypto.NewCachedTree(log2SectorSize)
-		ct.SetIndex(segmentIndex)
-		for _, root := range so.SectorRoots {
-			ct.Push(root)
-		}
-		hashSet := ct.Prove(base, cachedHashSet)
-		sp := types.StorageProof{
-			ParentID: so.id(),
-			HashSet:  hashSet,
-		}
-		copy(sp.Segment[:], base)
-
-		// Create and build the transaction with the storage proof.
-		builder, err := h.wallet.StartTransaction()
-		if err != nil {
-			h.log.Println("Failed to start transaction:", err)
-			return
-		}
-		_, feeRecommendation := h.tpool.FeeEstimation()
-		if so.value().Cmp(feeRecommendation) < 0 {
-			// There's no sense submitting the storage proof if the fee is more
-			// than the anticipated revenue.
-			h.log.Debugln("Host not submitting storage proof due to a value that does not sufficiently exceed the fee cost")
-			builder.Drop()
-			return
-		}
-		txnSize := uint64(len(encoding.Marshal(sp)) + 300)
-		requiredFee := feeRecommendation.Mul64(txnSize)
-		err = builder.FundSiacoins(requiredFee)
-		if err != nil {
-			h.log.Println("Host error when funding a storage proof transaction fee:", err)
-			builder.Drop()
-			return
-		}
-		builder.AddMinerFee(requiredFee)
-		builder.AddStorageProof(sp)
-		storageProofSet, err := builder.Sign(true)
-		if err != nil {
-			h.log.Println("Host error when signing the storage proof transaction:", err)
-			builder.Drop()
-			return
-		}
-		err = h.tpool.AcceptTransactionSet(storageProofSet)
-		if err != nil {
-			h.log.Println("Host unable to submit storage proof transaction to transaction pool:", err)
-			builder.Drop()
-			return
-		}
-		so.TransactionFeesAdded = so.TransactionFeesAdded.Add(requiredFee)
-
-		// Queue another action item to check whether the storage proof
-		// got confirmed.
-		h.mu.Lock()
-		err = h.queueActionItem(so.proofDeadline(), so.id())
-		h.mu.Unlock()
-		if err != nil {
-			h.log.Println("Error queuing action item:", err)
-		}
+		// Submit the storage proof, or check on the one that was submitted.
+		h.managedHandleStorageProof(&so, blockHeight)
 	}
 
 	// Save the storage obligation to account for any fee changes.
 	err = h.db.Update(func(tx *bolt.Tx) error {
+		// A resubmitted storage proof replaces one that was dropped from the
+		// transaction pool, whose fee was never paid.
+		var feesRefunded types.Currency
+		if so.ProofAttempts > proofAttempts {
+			feesRefunded = proofFee
+		}
+		if so.TransactionFeesAdded.Add(feesRefunded).Cmp(feesAdded) > 0 {
+			err := putLedgerEntry(tx, modules.HostLedgerTransactionFee, soid, blockHeight, so.TransactionFeesAdded.Add(feesRefunded).Sub(feesAdded))
+			if err != nil {
+				return err
+			}
+		}
+		err := putLedgerEntry(tx, modules.HostLedgerFeeRefund, soid, blockHeight, feesRefunded)
+		if err != nil {
+			return err
+		}
 		soBytes, err := json.Marshal(so)
 		if err != nil {
 			return err
@@ -912,6 +934,42 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 	}
 }
 
+// storageObligationSummary returns the metadata of a storage obligation that
+// is reported to the user.
+func (h *Host) storageObligationSummary(tx *bolt.Tx, so storageObligation) modules.StorageObligation {
+	ob := getObligationBandwidth(tx, so.id())
+	failedSectors := len(h.FailedSectors(so.SectorRoots))
+	return modules.StorageObligation{
+		ContractCost:             so.ContractCost,
+		DataSize:                 so.fileSize(),
+		LockedCollateral:         so.LockedCollateral,
+		ObligationId:             so.id(),
+		PotentialDownloadRevenue: so.PotentialDownloadRevenue,
+		PotentialStorageRevenue:  so.PotentialStorageRevenue,
+		PotentialUploadRevenue:   so.PotentialUploadRevenue,
+		RiskedCollateral:         so.RiskedCollateral,
+		SectorRootsCount:         uint64(len(so.SectorRoots)),
+		TransactionFeesAdded:     so.TransactionFeesAdded,
+
+		DownloadBytes: ob.DownloadBytes,
+		UploadBytes:   ob.UploadBytes,
+
+		AtRisk:        failedSectors > 0,
+		FailedSectors: uint64(failedSectors),
+
+		ExpirationHeight:  so.expiration(),
+		NegotiationHeight: so.NegotiationHeight,
+		ProofDeadLine:     so.proofDeadline(),
+
+		ObligationStatus:    so.ObligationStatus.String(),
+		OriginConfirmed:     so.OriginConfirmed,
+		ProofConfirmed:      so.ProofConfirmed,
+		ProofConstructed:    so.ProofConstructed,
+		RevisionConfirmed:   so.RevisionConfirmed,
+		RevisionConstructed: so.RevisionConstructed,
+	}
+}
+
 // StorageObligations fetches the set of storage obligations in the host and
 // returns metadata on them.
 func (h *Host) StorageObligations() (sos []modules.StorageObligation) {
@@ -926,30 +984,7 @@ func (h *Host) StorageObligations() (sos []modules.StorageObligation) {
 			if err != nil {
 				return build.ExtendErr("unable to unmarshal storage obligation:", err)
 			}
-			mso := modules.StorageObligation{
-				ContractCost:             so.ContractCost,
-				DataSize:                 so.fileSize(),
-				LockedCollateral:         so.LockedCollateral,
-				ObligationId:             so.id(),
-				PotentialDownloadRevenue: so.PotentialDownloadRevenue,
-				PotentialStorageRevenue:  so.PotentialStorageRevenue,
-				PotentialUploadRevenue:   so.PotentialUploadRevenue,
-				RiskedCollateral:         so.RiskedCollateral,
-				SectorRootsCount:         uint64(len(so.SectorRoots)),
-				TransactionFeesAdded:     so.TransactionFeesAdded,
-
-				ExpirationHeight:  so.expiration(),
-				NegotiationHeight: so.NegotiationHeight,
-				ProofDeadLine:     so.proofDeadline(),
-
-				ObligationStatus:    so.ObligationStatus.String(),
-				OriginConfirmed:     so.OriginConfirmed,
-				ProofConfirmed:      so.ProofConfirmed,
-				ProofConstructed:    so.ProofConstructed,
-				RevisionConfirmed:   so.RevisionConfirmed,
-				RevisionConstructed: so.RevisionConstructed,
-			}
-			sos = append(sos, mso)
+			sos = append(sos, h.storageObligationSummary(tx, so))
 			return nil
 		})
 		if err != nil {
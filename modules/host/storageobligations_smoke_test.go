@@ -349,6 +349,28 @@ func TestSingleSectorStorageObligationStack(t *testing.T) {
 	if !ht.host.financialMetrics.StorageRevenue.Equals(sectorCost) {
 		t.Fatal("the host should be reporting revenue after a successful storage proof")
 	}
+
+	// The revision revenue and the storage proof should be in the ledger.
+	entries, err := ht.host.Ledger(time.Unix(0, 0), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make(map[string]types.Currency)
+	for _, e := range entries {
+		if e.ObligationID != so.id() {
+			t.Fatal("ledger entry for the wrong obligation:", e)
+		}
+		values[e.Type] = values[e.Type].Add(e.Value)
+	}
+	if !values[modules.HostLedgerRevisionRevenue].Equals(sectorCost) {
+		t.Fatal("wrong revision revenue in the ledger:", values[modules.HostLedgerRevisionRevenue])
+	}
+	if values[modules.HostLedgerProofSuccess].Cmp(sectorCost) < 0 {
+		t.Fatal("storage proof revenue missing from the ledger:", values[modules.HostLedgerProofSuccess])
+	}
+	if values[modules.HostLedgerTransactionFee].IsZero() {
+		t.Fatal("the fees of the storage proof are missing from the ledger")
+	}
 }
 
 // TestMultiSectorObligationStack checks that the host correctly manages a
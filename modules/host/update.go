@@ -131,6 +131,9 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 	var actionItems []types.FileContractID
 	err := h.db.Update(func(tx *bolt.Tx) error {
 		for _, block := range cc.RevertedBlocks {
+			// Transitions are recorded at the height of the reverted block.
+			height := h.blockHeight
+
 			// Look for transactions relevant to open storage obligations.
 			for _, txn := range block.Transactions {
 				// Check for file contracts.
@@ -150,6 +153,7 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 						if err != nil {
 							continue
 						}
+						h.logObligationTransition(tx, so.id(), height, modules.ObligationOriginReverted, "")
 					}
 				}
 
@@ -169,6 +173,7 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 						if err != nil {
 							continue
 						}
+						h.logObligationTransition(tx, so.id(), height, modules.ObligationRevisionReverted, "")
 					}
 				}
 
@@ -189,6 +194,7 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 						if err != nil {
 							continue
 						}
+						h.logObligationTransition(tx, so.id(), height, modules.ObligationProofReverted, "")
 					}
 				}
 			}
@@ -202,6 +208,12 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 			}
 		}
 		for _, block := range cc.AppliedBlocks {
+			// Transitions are recorded at the height of the applied block.
+			height := h.blockHeight
+			if block.ID() != types.GenesisID {
+				height++
+			}
+
 			// Look for transactions relevant to open storage obligations.
 			for _, txn := range block.Transactions {
 				// Check for file contracts.
@@ -221,6 +233,7 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 						if err != nil {
 							continue
 						}
+						h.logObligationTransition(tx, so.id(), height, modules.ObligationOriginConfirmed, "")
 					}
 				}
 
@@ -240,6 +253,7 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 						if err != nil {
 							continue
 						}
+						h.logObligationTransition(tx, so.id(), height, modules.ObligationRevisionConfirmed, "")
 					}
 				}
 
@@ -259,6 +273,7 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 						if err != nil {
 							continue
 						}
+						h.logObligationTransition(tx, so.id(), height, modules.ObligationProofConfirmed, "")
 					}
 				}
 			}
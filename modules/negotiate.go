@@ -142,6 +142,11 @@ var (
 	// wrong number of transaction signatures.
 	ErrRevisionSigCount = errors.New("file contract revision has the wrong number of transaction signatures")
 
+	// ErrSectorNotFound is returned by a host's storage manager when it
+	// doesn't store a requested sector. Hosts send it to renters as part of
+	// the rejection of a download request for the sector.
+	ErrSectorNotFound = errors.New("could not find the desired sector")
+
 	// ErrStopResponse is the error returned by ReadNegotiationAcceptance when
 	// it reads the StopResponse string.
 	ErrStopResponse = errors.New("sender wishes to stop communicating")
@@ -3,6 +3,7 @@ package modules
 import (
 	"encoding/json"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/NebulousLabs/Sia/crypto"
@@ -31,6 +32,39 @@ const (
 	// the host and the renter, and will also contain a file contract and file
 	// contract revision that have each been signed by all parties.
 	EstimatedFileContractTransactionSetSize = 2048
+
+	// DefaultExpectedRedundancy is the redundancy that is assumed when an
+	// allowance contains expected usage but no expected redundancy. It matches
+	// the default erasure coding used by the renter.
+	DefaultExpectedRedundancy = 3.0
+
+	// RenterNoOverdrive is the MaxOverdrive of downloads that don't ask any
+	// extra hosts for pieces.
+	RenterNoOverdrive = -1
+
+	// RenterTenantDir is the siapath of the directory that contains the
+	// files of the renter's tenants. Each tenant's files are stored in a
+	// subdirectory named after the tenant.
+	RenterTenantDir = "tenants"
+)
+
+const (
+	// AlertIDRenterAllowanceLow is the ID of the alert that is registered
+	// when most of the allowance has been spent.
+	AlertIDRenterAllowanceLow = "renter-allowance-low"
+
+	// AlertIDRenterContractsExpiring is the ID of the alert that is
+	// registered when contracts in the renew window could not be renewed.
+	AlertIDRenterContractsExpiring = "renter-contracts-expiring"
+
+	// AlertIDRenterLowRedundancy is the ID of the alert that is registered
+	// when files drop below a redundancy of 1.0.
+	AlertIDRenterLowRedundancy = "renter-low-redundancy"
+
+	// AlertIDRenterWalletLowFunds is the ID of the alert that is registered
+	// when the wallet can't pay for the renewals of the upcoming renew
+	// window.
+	AlertIDRenterWalletLowFunds = "renter-wallet-low-funds"
 )
 
 // An ErasureCoder is an error-correcting encoder and decoder.
@@ -58,6 +92,18 @@ type ErasureCoder interface {
 	Recover(pieces [][]byte, n uint64, w io.Writer) error
 }
 
+// A Streamer is an io.ReadSeeker that streams a file from the Sia network. It
+// must be closed once the stream is finished, so that the stream can be added
+// to the transfer history.
+type Streamer interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// ErrHostExceedsAllowanceLimits is returned when a host's prices fall outside
+// of the optional price limits set in the renter's allowance.
+var ErrHostExceedsAllowanceLimits = errors.New("host prices exceed the limits set in the allowance")
+
 // An Allowance dictates how much the Renter is allowed to spend in a given
 // period. Note that funds are spent on both storage and bandwidth.
 type Allowance struct {
@@ -65,6 +111,76 @@ type Allowance struct {
 	Hosts       uint64            `json:"hosts"`
 	Period      types.BlockHeight `json:"period"`
 	RenewWindow types.BlockHeight `json:"renewwindow"`
+
+	// Optional limits on the prices that the renter is willing to pay. A
+	// zero value means that the corresponding limit is not enforced. The
+	// storage price is per byte per block, the bandwidth prices are per byte.
+	MaxContractPrice          types.Currency `json:"maxcontractprice"`
+	MaxDownloadBandwidthPrice types.Currency `json:"maxdownloadbandwidthprice"`
+	MaxStoragePrice           types.Currency `json:"maxstorageprice"`
+	MaxUploadBandwidthPrice   types.Currency `json:"maxuploadbandwidthprice"`
+	MinCollateral             types.Currency `json:"mincollateral"`
+
+	// Optional expectations about how the renter is going to use its
+	// contracts. ExpectedStorage is the number of bytes of file data that the
+	// renter expects to store, ExpectedUpload and ExpectedDownload are the
+	// number of bytes of file data that the renter expects to transfer per
+	// block, and ExpectedRedundancy is the expected redundancy of the files.
+	// The contractor and hostdb use the expectations to size contracts and
+	// weigh host prices. A zero value means that no expectation was provided.
+	ExpectedStorage    uint64  `json:"expectedstorage"`
+	ExpectedUpload     uint64  `json:"expectedupload"`
+	ExpectedDownload   uint64  `json:"expecteddownload"`
+	ExpectedRedundancy float64 `json:"expectedredundancy"`
+}
+
+// HasExpectedUsage returns true if the allowance contains any expectations
+// about the storage and bandwidth that the renter is going to use.
+func (a Allowance) HasExpectedUsage() bool {
+	return a.ExpectedStorage != 0 || a.ExpectedUpload != 0 || a.ExpectedDownload != 0
+}
+
+// ExpectedHostUsage splits the expected usage of the allowance evenly across
+// the hosts of the allowance. It returns the number of bytes that a single
+// host is expected to store, and the number of bytes that a single host is
+// expected to receive and send over the course of a period. Uploaded data is
+// multiplied by the expected redundancy, downloaded data is not, because only
+// the minimum number of pieces needs to be fetched.
+func (a Allowance) ExpectedHostUsage() (storage, upload, download uint64) {
+	if a.Hosts == 0 {
+		return 0, 0, 0
+	}
+	redundancy := a.ExpectedRedundancy
+	if redundancy == 0 {
+		redundancy = DefaultExpectedRedundancy
+	}
+	period := float64(a.Period)
+	hosts := float64(a.Hosts)
+	storage = uint64(float64(a.ExpectedStorage) * redundancy / hosts)
+	upload = uint64(float64(a.ExpectedUpload) * period * redundancy / hosts)
+	download = uint64(float64(a.ExpectedDownload) * period / hosts)
+	return storage, upload, download
+}
+
+// CheckHostPrices returns an error if the provided host settings violate any
+// of the price limits of the allowance.
+func (a Allowance) CheckHostPrices(settings HostExternalSettings) error {
+	if !a.MaxContractPrice.IsZero() && settings.ContractPrice.Cmp(a.MaxContractPrice) > 0 {
+		return errors.AddContext(ErrHostExceedsAllowanceLimits, "contract price is too high")
+	}
+	if !a.MaxDownloadBandwidthPrice.IsZero() && settings.DownloadBandwidthPrice.Cmp(a.MaxDownloadBandwidthPrice) > 0 {
+		return errors.AddContext(ErrHostExceedsAllowanceLimits, "download bandwidth price is too high")
+	}
+	if !a.MaxStoragePrice.IsZero() && settings.StoragePrice.Cmp(a.MaxStoragePrice) > 0 {
+		return errors.AddContext(ErrHostExceedsAllowanceLimits, "storage price is too high")
+	}
+	if !a.MaxUploadBandwidthPrice.IsZero() && settings.UploadBandwidthPrice.Cmp(a.MaxUploadBandwidthPrice) > 0 {
+		return errors.AddContext(ErrHostExceedsAllowanceLimits, "upload bandwidth price is too high")
+	}
+	if !a.MinCollateral.IsZero() && settings.Collateral.Cmp(a.MinCollateral) < 0 {
+		return errors.AddContext(ErrHostExceedsAllowanceLimits, "collateral is too low")
+	}
+	return nil
 }
 
 // ContractUtility contains metrics internal to the contractor that reflect the
@@ -112,6 +228,12 @@ type FileInfo struct {
 	UploadedBytes  uint64            `json:"uploadedbytes"`
 	UploadProgress float64           `json:"uploadprogress"`
 	Expiration     types.BlockHeight `json:"expiration"`
+
+	// Reencoding indicates whether the file is being re-encoded with new
+	// erasure code settings. ReencodeProgress is the UploadProgress of the
+	// new layout of the file.
+	Reencoding       bool    `json:"reencoding"`
+	ReencodeProgress float64 `json:"reencodeprogress"`
 }
 
 // A HostDBEntry represents one host entry in the Renter's host DB. It
@@ -136,6 +258,18 @@ type HostDBEntry struct {
 
 	LastHistoricUpdate types.BlockHeight
 
+	// SuccessfulAudits and FailedAudits count the storage audits of the host.
+	// An audit downloads a random piece that the host is storing for the
+	// renter and verifies it against the Merkle root that the renter stored
+	// when the piece was uploaded.
+	SuccessfulAudits uint64 `json:"successfulaudits"`
+	FailedAudits     uint64 `json:"failedaudits"`
+
+	// BenchmarkHistory contains the most recent performance measurements of
+	// the host, oldest first. Benchmarks are only taken for hosts that the
+	// renter has a contract with, and only if benchmarking is enabled.
+	BenchmarkHistory []HostDBBenchmark `json:"benchmarkhistory"`
+
 	// The public key of the host, stored separately to minimize risk of certain
 	// MitM based vulnerabilities.
 	PublicKey types.SiaPublicKey `json:"publickey"`
@@ -147,6 +281,15 @@ type HostDBScan struct {
 	Success   bool      `json:"success"`
 }
 
+// HostDBBenchmark represents a single performance measurement of a host. The
+// throughputs are given in bytes per second.
+type HostDBBenchmark struct {
+	Timestamp          time.Time     `json:"timestamp"`
+	Latency            time.Duration `json:"latency"`
+	UploadThroughput   uint64        `json:"uploadthroughput"`
+	DownloadThroughput uint64        `json:"downloadthroughput"`
+}
+
 // HostScoreBreakdown provides a piece-by-piece explanation of why a host has
 // the score that they do.
 //
@@ -162,6 +305,7 @@ type HostScoreBreakdown struct {
 	BurnAdjustment             float64 `json:"burnadjustment"`
 	CollateralAdjustment       float64 `json:"collateraladjustment"`
 	InteractionAdjustment      float64 `json:"interactionadjustment"`
+	PerformanceAdjustment      float64 `json:"performanceadjustment"`
 	PriceAdjustment            float64 `json:"pricesmultiplier"`
 	StorageRemainingAdjustment float64 `json:"storageremainingadjustment"`
 	UptimeAdjustment           float64 `json:"uptimeadjustment"`
@@ -185,12 +329,77 @@ type RenterPriceEstimation struct {
 	UploadTerabyte types.Currency `json:"uploadterabyte"`
 }
 
+// RenterQuoteParams describes a job to quote: uploading Size bytes with the
+// given erasure code, storing them for Duration blocks and downloading
+// ExpectedDownload bytes of them. If ErasureCode is nil, the default erasure
+// code of uploads is used.
+type RenterQuoteParams struct {
+	Size             uint64
+	ErasureCode      ErasureCoder
+	Duration         types.BlockHeight
+	ExpectedDownload uint64
+}
+
+// RenterQuote is the estimated cost of a job, using the prices of the hosts
+// that the renter has contracts with and of new hosts from the hostdb for the
+// remaining pieces.
+type RenterQuote struct {
+	ContractCost types.Currency `json:"contractcost"`
+	DownloadCost types.Currency `json:"downloadcost"`
+	StorageCost  types.Currency `json:"storagecost"`
+	UploadCost   types.Currency `json:"uploadcost"`
+	TotalCost    types.Currency `json:"totalcost"`
+
+	// UploadBytes is the number of bytes uploaded to the hosts, including
+	// redundancy and padding.
+	UploadBytes uint64 `json:"uploadbytes"`
+
+	// NewHosts is the number of hosts that the renter needs to form new
+	// contracts with.
+	NewHosts int `json:"newhosts"`
+
+	// RequiredFunds are the funds that need to be added to the renter's
+	// contracts, by forming new contracts and refreshing existing ones.
+	// UnallocatedFunds are the funds of the allowance that are not yet
+	// allocated to contracts in the current period.
+	RequiredFunds       types.Currency `json:"requiredfunds"`
+	UnallocatedFunds    types.Currency `json:"unallocatedfunds"`
+	AllowanceSufficient bool           `json:"allowancesufficient"`
+
+	// RefreshContracts are the existing contracts that would need to be
+	// renewed to complete the job.
+	RefreshContracts []RenterQuoteContract `json:"refreshcontracts"`
+}
+
+// RenterQuoteContract is a contract that would need to be renewed to complete
+// a quoted job, because it does not have enough funds left or because it ends
+// before the job does.
+type RenterQuoteContract struct {
+	ID            types.FileContractID `json:"id"`
+	HostPublicKey types.SiaPublicKey   `json:"hostpublickey"`
+	Reason        string               `json:"reason"`
+
+	// RequiredFunds is the cost of the job's pieces on the contract's host.
+	RequiredFunds types.Currency `json:"requiredfunds"`
+}
+
 // RenterSettings control the behavior of the Renter.
 type RenterSettings struct {
 	Allowance        Allowance `json:"allowance"`
 	MaxUploadSpeed   int64     `json:"maxuploadspeed"`
 	MaxDownloadSpeed int64     `json:"maxdownloadspeed"`
 	StreamCacheSize  uint64    `json:"streamcachesize"`
+
+	// BenchmarkHosts enables the periodic benchmarking of the hosts that the
+	// renter has contracts with. Every benchmark uploads and downloads a
+	// sector, which costs a small amount of money.
+	BenchmarkHosts bool `json:"benchmarkhosts"`
+
+	// TenantUploadDir is the directory on disk that contains the upload
+	// directories of the tenants, one per tenant named after the tenant. It
+	// must be an absolute path outside of the renter's persist directory.
+	// Tenants can't upload files from disk if it is empty.
+	TenantUploadDir string `json:"tenantuploaddir"`
 }
 
 // HostDBScans represents a sortable slice of scans.
@@ -316,6 +525,193 @@ type ContractorSpending struct {
 	PreviousSpending types.Currency `json:"previousspending"`
 }
 
+// RenterSpendingRecord records the money that was paid to a host by a single
+// contract formation, renewal or revision. Contracts that are formed in the
+// same allowance period share their end height, so EndHeight identifies the
+// period that the money was spent in.
+type RenterSpendingRecord struct {
+	Timestamp     time.Time            `json:"timestamp"`
+	ContractID    types.FileContractID `json:"contractid"`
+	EndHeight     types.BlockHeight    `json:"endheight"`
+	HostPublicKey types.SiaPublicKey   `json:"hostpublickey"`
+
+	DownloadSpending types.Currency `json:"downloadspending"`
+	Fees             types.Currency `json:"fees"`
+	StorageSpending  types.Currency `json:"storagespending"`
+	UploadSpending   types.Currency `json:"uploadspending"`
+}
+
+// RenterSpending sums up the money that was paid to hosts, broken down into
+// storage, upload, download and fees.
+type RenterSpending struct {
+	DownloadSpending types.Currency `json:"downloadspending"`
+	Fees             types.Currency `json:"fees"`
+	StorageSpending  types.Currency `json:"storagespending"`
+	UploadSpending   types.Currency `json:"uploadspending"`
+}
+
+// Add adds the money of a spending record to the spending.
+func (s *RenterSpending) Add(r RenterSpendingRecord) {
+	s.DownloadSpending = s.DownloadSpending.Add(r.DownloadSpending)
+	s.Fees = s.Fees.Add(r.Fees)
+	s.StorageSpending = s.StorageSpending.Add(r.StorageSpending)
+	s.UploadSpending = s.UploadSpending.Add(r.UploadSpending)
+}
+
+// Total returns the total amount of money that was paid.
+func (s RenterSpending) Total() types.Currency {
+	return s.DownloadSpending.Add(s.Fees).Add(s.StorageSpending).Add(s.UploadSpending)
+}
+
+// RenterHostSpending sums up the money that was paid to a host.
+type RenterHostSpending struct {
+	HostPublicKey types.SiaPublicKey `json:"hostpublickey"`
+	RenterSpending
+}
+
+// RenterContractSpending sums up the money that was paid to a host through a
+// single contract.
+type RenterContractSpending struct {
+	ContractID    types.FileContractID `json:"contractid"`
+	EndHeight     types.BlockHeight    `json:"endheight"`
+	HostPublicKey types.SiaPublicKey   `json:"hostpublickey"`
+	RenterSpending
+}
+
+// RenterPeriodSpending sums up the money that was paid to hosts through the
+// contracts that end at EndHeight, which are the contracts of one allowance
+// period.
+type RenterPeriodSpending struct {
+	EndHeight types.BlockHeight `json:"endheight"`
+	RenterSpending
+}
+
+// RenterGarbageCollection describes the progress of deleting the sectors of
+// deleted files from the renter's contracts.
+type RenterGarbageCollection struct {
+	// PendingSectors is the number of sectors that still have to be deleted
+	// from the hosts.
+	PendingSectors uint64 `json:"pendingsectors"`
+
+	// ReclaimedBytes is the total amount of storage that was removed from
+	// the renter's contracts.
+	ReclaimedBytes uint64 `json:"reclaimedbytes"`
+}
+
+const (
+	// RenterTransferDownload is the type of transfers that download a file
+	// for the user.
+	RenterTransferDownload = "download"
+
+	// RenterTransferRepair is the type of transfers that repair the chunks
+	// of a file, including the download of the chunks if the file is not
+	// available locally.
+	RenterTransferRepair = "repair"
+
+	// RenterTransferUpload is the type of transfers that upload the chunks of
+	// a file for the first time.
+	RenterTransferUpload = "upload"
+)
+
+// RenterTransfer is a record of a finished download, upload or repair.
+// Downloads are recorded per request or stream, uploads and repairs per file.
+// Bytes are the bytes transferred to or from the hosts, Cost is the amount
+// paid to the hosts in the contract revisions.
+type RenterTransfer struct {
+	Type      string    `json:"type"`
+	SiaPath   string    `json:"siapath"`
+	StartTime time.Time `json:"starttime"`
+	EndTime   time.Time `json:"endtime"`
+
+	Bytes      uint64               `json:"bytes"`
+	Cost       types.Currency       `json:"cost"`
+	Hosts      []types.SiaPublicKey `json:"hosts"`
+	Throughput uint64               `json:"throughput"` // bytes per second
+	Error      string               `json:"error"`
+}
+
+// RenterTransferFilter selects records of the transfer history. Empty fields
+// match every transfer.
+type RenterTransferFilter struct {
+	Type    string
+	SiaPath string
+	Host    types.SiaPublicKey
+
+	// Only transfers that started within [Start, End] are selected.
+	Start time.Time
+	End   time.Time
+
+	// FailedOnly selects only the transfers that finished with an error.
+	FailedOnly bool
+}
+
+// RenterWorkerStatus describes the state of a worker, which uploads and
+// downloads pieces using a single contract. Throughputs are averaged over all
+// successful transfers of the worker, in bytes per second.
+type RenterWorkerStatus struct {
+	ContractID    types.FileContractID `json:"contractid"`
+	HostPublicKey types.SiaPublicKey   `json:"hostpublickey"`
+
+	DownloadQueueSize           int           `json:"downloadqueuesize"`
+	DownloadOnCooldown          bool          `json:"downloadoncooldown"`
+	DownloadCooldownRemaining   time.Duration `json:"downloadcooldownremaining"`
+	DownloadConsecutiveFailures int           `json:"downloadconsecutivefailures"`
+	DownloadRecentError         string        `json:"downloadrecenterror"`
+	DownloadRecentErrorTime     time.Time     `json:"downloadrecenterrortime"`
+	DownloadThroughput          uint64        `json:"downloadthroughput"`
+
+	UploadQueueSize           int           `json:"uploadqueuesize"`
+	UploadOnCooldown          bool          `json:"uploadoncooldown"`
+	UploadCooldownRemaining   time.Duration `json:"uploadcooldownremaining"`
+	UploadConsecutiveFailures int           `json:"uploadconsecutivefailures"`
+	UploadRecentError         string        `json:"uploadrecenterror"`
+	UploadRecentErrorTime     time.Time     `json:"uploadrecenterrortime"`
+	UploadThroughput          uint64        `json:"uploadthroughput"`
+}
+
+// RenterTenantQuota limits the storage and bandwidth of a renter tenant. A
+// limit of zero means that the tenant is not limited. The bandwidth budgets
+// count bytes of file data, without redundancy, and are reset at the start of
+// every allowance period.
+type RenterTenantQuota struct {
+	StorageQuota   uint64 `json:"storagequota"`
+	UploadBudget   uint64 `json:"uploadbudget"`
+	DownloadBudget uint64 `json:"downloadbudget"`
+}
+
+// RenterTenant describes a tenant of the renter. Tenants can only access the
+// files below their root directory. StorageUsed is the total size of the
+// tenant's files, UploadUsed and DownloadUsed are the bytes transferred for
+// the tenant's files in the current allowance period.
+type RenterTenant struct {
+	Name string `json:"name"`
+	RenterTenantQuota
+
+	StorageUsed  uint64 `json:"storageused"`
+	UploadUsed   uint64 `json:"uploadused"`
+	DownloadUsed uint64 `json:"downloadused"`
+}
+
+// RenterTenantRoot returns the siapath of the root directory of a tenant,
+// including a trailing slash.
+func RenterTenantRoot(name string) string {
+	return RenterTenantDir + "/" + name + "/"
+}
+
+// RenterTenantOf returns the name of the tenant that owns a siapath, or an
+// empty string if the siapath is not below the root of a tenant.
+func RenterTenantOf(siaPath string) string {
+	if !strings.HasPrefix(siaPath, RenterTenantDir+"/") {
+		return ""
+	}
+	name := strings.TrimPrefix(siaPath, RenterTenantDir+"/")
+	i := strings.Index(name, "/")
+	if i <= 0 {
+		return ""
+	}
+	return name[:i]
+}
+
 // A Renter uploads, tracks, repairs, and downloads a set of files for the
 // user.
 type Renter interface {
@@ -323,9 +719,16 @@ type Renter interface {
 	// sorted by preference.
 	ActiveHosts() []HostDBEntry
 
+	// Alerts returns the alerts of the renter and its contractor, most
+	// urgent first.
+	Alerts() []Alert
+
 	// AllHosts returns the full list of hosts known to the renter.
 	AllHosts() []HostDBEntry
 
+	// AuthenticateTenant checks the API credentials of a tenant.
+	AuthenticateTenant(name, password string) error
+
 	// Close closes the Renter.
 	Close() error
 
@@ -338,6 +741,21 @@ type Renter interface {
 	// ContractUtility provides the contract utility for a given host key.
 	ContractUtility(pk types.SiaPublicKey) (ContractUtility, bool)
 
+	// CancelContract stops a contract from being renewed or used for
+	// uploads. Its data can still be downloaded until it expires.
+	CancelContract(id types.FileContractID) error
+
+	// FormContract forms a contract with a specific host, funded from the
+	// allowance.
+	FormContract(hostKey types.SiaPublicKey, funding types.Currency, endHeight types.BlockHeight) (RenterContract, error)
+
+	// RenewContract renews a contract right away instead of waiting for the
+	// renew window.
+	RenewContract(id types.FileContractID, funding types.Currency) error
+
+	// StopRenewingContract prevents a contract from being renewed.
+	StopRenewingContract(id types.FileContractID) error
+
 	// CurrentPeriod returns the height at which the current allowance period
 	// began.
 	CurrentPeriod() types.BlockHeight
@@ -346,9 +764,18 @@ type Renter interface {
 	// billing period.
 	PeriodSpending() ContractorSpending
 
-	// DeleteFile deletes a file entry from the renter.
+	// SpendingHistory returns the spending records of the given host between
+	// start and end. If the host key is empty, the records of all hosts are
+	// returned.
+	SpendingHistory(host types.SiaPublicKey, start, end time.Time) ([]RenterSpendingRecord, error)
+
+	// DeleteFile deletes a file entry from the renter. The sectors of the
+	// file are deleted from the hosts in the background.
 	DeleteFile(path string) error
 
+	// DeleteTenant deletes a tenant. The files of the tenant are kept.
+	DeleteTenant(name string) error
+
 	// Download performs a download according to the parameters passed, including
 	// downloads of `offset` and `length` type.
 	Download(params RenterDownloadParameters) error
@@ -358,7 +785,8 @@ type Renter interface {
 	DownloadAsync(params RenterDownloadParameters) error
 
 	// ClearDownloadHistory clears the download history of the renter
-	// inclusive for before and after times.
+	// inclusive for before and after times. The downloads are also removed
+	// from the transfer history.
 	ClearDownloadHistory(after, before time.Time) error
 
 	// DownloadHistory lists all the files that have been scheduled for download.
@@ -367,6 +795,10 @@ type Renter interface {
 	// File returns information on specific file queried by user
 	File(siaPath string) (FileInfo, error)
 
+	// GarbageCollection returns the progress of deleting the sectors of
+	// deleted files from the hosts.
+	GarbageCollection() RenterGarbageCollection
+
 	// FileList returns information on all of the files stored by the renter.
 	FileList() []FileInfo
 
@@ -389,6 +821,15 @@ type Renter interface {
 	// storage and data operations.
 	PriceEstimation() RenterPriceEstimation
 
+	// Quote estimates the cost of uploading, storing and downloading data
+	// using the renter's contracts and the hostdb.
+	Quote(params RenterQuoteParams) (RenterQuote, error)
+
+	// Reencode re-encodes a file with new erasure code settings in the
+	// background. The old pieces of the file are kept until the file has been
+	// fully uploaded with the new settings.
+	Reencode(path string, ec ErasureCoder) error
+
 	// RenameFile changes the path of a file.
 	RenameFile(path, newPath string) error
 
@@ -400,6 +841,11 @@ type Renter interface {
 	// hostdb's weighting algorithm.
 	ScoreBreakdown(entry HostDBEntry) HostScoreBreakdown
 
+	// SetTenant creates a tenant or updates the quota of an existing tenant.
+	// The password is required when creating a tenant and is left unchanged
+	// if it is empty when updating a tenant.
+	SetTenant(name, password string, quota RenterTenantQuota) error
+
 	// Settings returns the Renter's current settings.
 	Settings() RenterSettings
 
@@ -412,13 +858,27 @@ type Renter interface {
 	// ShareFilesAscii creates an ASCII-encoded '.sia' file.
 	ShareFilesASCII(paths []string) (asciiSia string, err error)
 
-	// Streamer creates a io.ReadSeeker that can be used to stream downloads
-	// from the Sia network and also returns the fileName of the streamed
+	// Streamer creates a Streamer that can be used to stream downloads from
+	// the Sia network and also returns the fileName of the streamed
 	// resource.
-	Streamer(siaPath string) (string, io.ReadSeeker, error)
+	Streamer(siaPath string) (string, Streamer, error)
+
+	// TenantDir returns the directory on disk from which a tenant uploads
+	// files, creating it if it doesn't exist yet.
+	TenantDir(name string) (string, error)
+
+	// Tenants returns the tenants of the renter and their usage.
+	Tenants() []RenterTenant
+
+	// TransferHistory returns the records of the transfer history that match
+	// the filter, most recent first.
+	TransferHistory(filter RenterTransferFilter) ([]RenterTransfer, error)
 
 	// Upload uploads a file using the input parameters.
 	Upload(FileUploadParams) error
+
+	// WorkerPoolStatus returns the status of the workers of the renter.
+	WorkerPoolStatus() []RenterWorkerStatus
 }
 
 // RenterDownloadParameters defines the parameters passed to the Renter's
@@ -430,4 +890,16 @@ type RenterDownloadParameters struct {
 	Offset      uint64
 	SiaPath     string
 	Destination string
+
+	// ExcludeHosts are never asked for pieces of the download. PreferHosts
+	// are asked for pieces first, other hosts are only used if the preferred
+	// hosts can't provide enough pieces.
+	ExcludeHosts []types.SiaPublicKey
+	PreferHosts  []types.SiaPublicKey
+
+	// MaxOverdrive caps the number of extra hosts that are asked for pieces
+	// in parallel, to prevent slow hosts from being a bottleneck. If
+	// MaxOverdrive is zero, the default overdrive is used. Overdrive is
+	// disabled with RenterNoOverdrive.
+	MaxOverdrive int
 }
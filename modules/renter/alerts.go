@@ -0,0 +1,61 @@
+package renter
+
+// alerts.go tracks the conditions of the renter that need the user's
+// attention. The alerts of the contractor, which track the allowance, the
+// wallet and expiring contracts, are reported together with the renter's.
+
+import (
+	"fmt"
+
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+// Alerts returns the alerts of the renter and its contractor, most urgent
+// first.
+func (r *Renter) Alerts() []modules.Alert {
+	alerts := append(r.staticAlerter.Alerts(), r.hostContractor.Alerts()...)
+	modules.SortAlerts(alerts)
+	return alerts
+}
+
+// managedCheckRedundancyAlert registers an alert if files that have been
+// fully uploaded have dropped below a redundancy of 1.0. The alert is
+// critical if some of those files can't be repaired from a local copy.
+func (r *Renter) managedCheckRedundancyAlert() {
+	var lowFiles, unrepairable int
+	for _, fi := range r.FileList() {
+		if fi.Filesize == 0 || fi.UploadProgress < 100 || fi.Redundancy >= 1 {
+			continue
+		}
+		lowFiles++
+		if fi.LocalPath == "" {
+			unrepairable++
+		}
+	}
+
+	var changed bool
+	if lowFiles == 0 {
+		changed = r.staticAlerter.UnregisterAlert(modules.AlertIDRenterLowRedundancy)
+	} else {
+		severity := modules.AlertSeverityError
+		if unrepairable > 0 {
+			severity = modules.AlertSeverityCritical
+		}
+		msg := "files are below redundancy 1.0 and can't be downloaded until their hosts come back online"
+		cause := fmt.Sprintf("%v files are below redundancy 1.0, %v of them have no local copy to repair them from", lowFiles, unrepairable)
+		changed = r.staticAlerter.RegisterAlert(modules.AlertIDRenterLowRedundancy, severity, msg, cause)
+		if changed {
+			r.log.Printf("ALERT: %v: %v", msg, cause)
+		}
+	}
+	if !changed {
+		return
+	}
+	id := r.mu.Lock()
+	r.persist.Alerts = r.staticAlerter.Alerts()
+	err := r.saveSync()
+	r.mu.Unlock(id)
+	if err != nil {
+		r.log.Println("WARN: unable to save the renter alerts:", err)
+	}
+}
@@ -0,0 +1,173 @@
+package renter
+
+// audit.go periodically audits the hosts that the renter has contracts with.
+// An audit downloads a random piece that the host is storing for the renter
+// and verifies it against the Merkle root that was stored when the piece was
+// uploaded. The download protocol does not support Merkle proofs for parts of
+// a sector, so the full sector is downloaded and its root is recomputed.
+//
+// A host only fails an audit if it reports that it doesn't store the piece,
+// or if it sends data that doesn't match the piece. Other errors, like
+// timeouts and dropped connections, don't show that the host lost the piece,
+// so the audit is inconclusive and the host is audited again later.
+//
+// Hosts that fail an audit are scored down by the hostdb, and their contract
+// is marked as not good for renewing or uploading. The pieces that the host
+// was storing are then repaired onto other hosts by the upload loop.
+
+import (
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/renter/proto"
+	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/errors"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// auditTarget is a piece that is chosen to audit a host.
+type auditTarget struct {
+	contract modules.RenterContract
+	root     crypto.Hash
+}
+
+// managedAuditHost downloads the piece of the target and verifies its Merkle
+// root. The returned bool indicates whether the host passed the audit. An
+// error is returned if the audit was inconclusive, in which case the audit
+// result should be ignored.
+func (r *Renter) managedAuditHost(target auditTarget) (bool, error) {
+	downloader, err := r.hostContractor.Downloader(target.contract.HostPublicKey, r.tg.StopChan())
+	if err != nil {
+		return false, errors.AddContext(err, "unable to create downloader")
+	}
+	defer downloader.Close()
+
+	// The downloader verifies the Merkle root of the sector. Errors that are
+	// tagged with modules.ErrHostFault also include network errors, so only
+	// the errors that show that the host lost the piece fail the audit.
+	_, _, err = downloader.Sector(target.root)
+	if errors.Contains(err, proto.ErrSectorNotFound) || errors.Contains(err, proto.ErrBadSectorData) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.AddContext(err, "unable to download piece")
+	}
+	return true, nil
+}
+
+// managedAuditTargets picks a random piece for every host that the renter has
+// a contract with that hasn't failed an audit yet.
+func (r *Renter) managedAuditTargets() []auditTarget {
+	// Only audit hosts with a contract that is good for renewing. Hosts that
+	// already failed an audit are no longer good for renewing.
+	contracts := make(map[string]modules.RenterContract)
+	for _, contract := range r.hostContractor.Contracts() {
+		utility, ok := r.hostContractor.ContractUtility(contract.HostPublicKey)
+		if !ok || !utility.GoodForRenew {
+			continue
+		}
+		contracts[string(contract.HostPublicKey.Key)] = contract
+	}
+
+	// Pick a random piece for every host, giving every piece the same chance
+	// of being chosen. Pieces of expired contracts are skipped, because the
+	// host is allowed to drop them, even if the renter has a newer contract
+	// with the host.
+	blockHeight := r.cs.Height()
+	targets := make(map[string]auditTarget)
+	numPieces := make(map[string]int)
+	resolved := make(map[types.FileContractID]types.SiaPublicKey)
+	id := r.mu.RLock()
+	for _, f := range r.files {
+		f.mu.RLock()
+		for fcid, fc := range f.contracts {
+			if fc.WindowStart <= blockHeight {
+				continue
+			}
+			pk, ok := resolved[fcid]
+			if !ok {
+				pk = r.hostContractor.ResolveIDToPubKey(fcid)
+				resolved[fcid] = pk
+			}
+			contract, ok := contracts[string(pk.Key)]
+			if !ok {
+				continue
+			}
+			for _, piece := range fc.Pieces {
+				numPieces[string(pk.Key)]++
+				if fastrand.Intn(numPieces[string(pk.Key)]) == 0 {
+					targets[string(pk.Key)] = auditTarget{
+						contract: contract,
+						root:     piece.MerkleRoot,
+					}
+				}
+			}
+		}
+		f.mu.RUnlock()
+	}
+	r.mu.RUnlock(id)
+
+	auditTargets := make([]auditTarget, 0, len(targets))
+	for _, target := range targets {
+		auditTargets = append(auditTargets, target)
+	}
+	return auditTargets
+}
+
+// threadedAuditHosts is a background thread that periodically audits the hosts
+// that the renter has contracts with.
+func (r *Renter) threadedAuditHosts() {
+	err := r.tg.Add()
+	if err != nil {
+		return
+	}
+	defer r.tg.Done()
+
+	for {
+		select {
+		case <-r.tg.StopChan():
+			return
+		case <-time.After(hostAuditInterval):
+		}
+		if !r.g.Online() {
+			continue
+		}
+
+		for _, target := range r.managedAuditTargets() {
+			hpk := target.contract.HostPublicKey
+			passed, err := r.managedAuditHost(target)
+			if err != nil {
+				r.log.Debugf("Unable to audit host %v: %v", hpk, err)
+				continue
+			}
+			if err := r.hostDB.RecordAudit(hpk, passed); err != nil {
+				r.log.Debugf("Unable to record audit of host %v: %v", hpk, err)
+			}
+			if !passed {
+				r.managedHandleFailedAudit(target.contract)
+			}
+
+			// Return if the renter has shut down.
+			select {
+			case <-r.tg.StopChan():
+				return
+			default:
+			}
+		}
+	}
+}
+
+// managedHandleFailedAudit marks the contract of a host that failed an audit
+// as bad and wakes up the upload loop, so that the pieces that the host was
+// storing are repaired.
+func (r *Renter) managedHandleFailedAudit(contract modules.RenterContract) {
+	r.log.Printf("WARN: host %v failed a storage audit", contract.HostPublicKey)
+	if err := r.hostContractor.ReportFailedAudit(contract.ID); err != nil {
+		r.log.Println("WARN: unable to mark contract of audited host as bad:", err)
+		return
+	}
+	select {
+	case r.uploadHeap.newUploads <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,124 @@
+package renter
+
+import (
+	"io"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/renter/contractor"
+	"github.com/NebulousLabs/Sia/modules/renter/proto"
+	siasync "github.com/NebulousLabs/Sia/sync"
+	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/errors"
+)
+
+// auditContractor is a hostContractor whose downloaders fail with err.
+type auditContractor struct {
+	hostContractor
+	err error
+}
+
+func (c auditContractor) Downloader(types.SiaPublicKey, <-chan struct{}) (contractor.Downloader, error) {
+	return auditDownloader{c.err}, nil
+}
+
+// auditDownloader is a downloader that fails with err.
+type auditDownloader struct {
+	err error
+}
+
+func (d auditDownloader) Sector(crypto.Hash) ([]byte, types.Currency, error) {
+	return nil, types.ZeroCurrency, d.err
+}
+func (d auditDownloader) Close() error { return nil }
+
+// auditTargetsContractor is a hostContractor with a single good contract with
+// host, and with an expired contract with the same host.
+type auditTargetsContractor struct {
+	hostContractor
+	host types.SiaPublicKey
+}
+
+func (c auditTargetsContractor) Contracts() []modules.RenterContract {
+	return []modules.RenterContract{{ID: types.FileContractID{2}, HostPublicKey: c.host}}
+}
+func (c auditTargetsContractor) ContractUtility(types.SiaPublicKey) (modules.ContractUtility, bool) {
+	return modules.ContractUtility{GoodForUpload: true, GoodForRenew: true}, true
+}
+func (c auditTargetsContractor) ResolveIDToPubKey(types.FileContractID) types.SiaPublicKey {
+	return c.host
+}
+
+// auditConsensusSet is a consensus set at a fixed height.
+type auditConsensusSet struct {
+	modules.ConsensusSet
+	height types.BlockHeight
+}
+
+func (cs auditConsensusSet) Height() types.BlockHeight { return cs.height }
+
+// TestAuditHostErrors checks that a host only fails an audit if it lost the
+// piece, and that network errors make the audit inconclusive.
+func TestAuditHostErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		passed       bool
+		inconclusive bool
+	}{
+		{"passed", nil, true, false},
+		{"connection drop", errors.Extend(io.ErrUnexpectedEOF, modules.ErrHostFault), false, true},
+		{"timeout", errors.Extend(errors.New("i/o timeout"), modules.ErrHostFault), false, true},
+		{"sector not found", errors.Extend(proto.ErrSectorNotFound, modules.ErrHostFault), false, false},
+		{"short sector", errors.Extend(errors.AddContext(proto.ErrBadSectorData, "host did not send enough sector data"), modules.ErrHostFault), false, false},
+		{"bad root", errors.Extend(proto.ErrBadSectorData, modules.ErrHostFault), false, false},
+	}
+	for _, test := range tests {
+		r := &Renter{
+			hostContractor: auditContractor{err: test.err},
+		}
+		passed, err := r.managedAuditHost(auditTarget{})
+		if passed != test.passed || (err != nil) != test.inconclusive {
+			t.Errorf("%v: expected passed %v and inconclusive %v, got %v and %v", test.name, test.passed, test.inconclusive, passed, err)
+		}
+	}
+}
+
+// TestAuditTargetsExpiredContract checks that pieces of an expired contract
+// are never audited, even if the renter has a newer contract with the host.
+func TestAuditTargetsExpiredContract(t *testing.T) {
+	host := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte("host")}
+	f := &file{
+		name: "foo",
+		contracts: map[types.FileContractID]fileContract{
+			{1}: {ID: types.FileContractID{1}, WindowStart: 10, Pieces: []pieceData{{MerkleRoot: crypto.Hash{1}}}},
+		},
+	}
+	r := &Renter{
+		cs:             auditConsensusSet{height: 5},
+		files:          map[string]*file{f.name: f},
+		hostContractor: auditTargetsContractor{host: host},
+		mu:             siasync.New(modules.SafeMutexDelay, 1),
+	}
+
+	// Before the contract expires, its piece is audited.
+	targets := r.managedAuditTargets()
+	if len(targets) != 1 || targets[0].root != (crypto.Hash{1}) {
+		t.Fatal("expected the piece to be audited, got", targets)
+	}
+
+	// Once the proof window of the contract starts, the host may drop the
+	// piece, so it is no longer audited.
+	r.cs = auditConsensusSet{height: 10}
+	if targets := r.managedAuditTargets(); len(targets) != 0 {
+		t.Fatal("expected no audit targets, got", targets)
+	}
+
+	// Pieces in the current contract are still audited.
+	f.contracts[types.FileContractID{2}] = fileContract{ID: types.FileContractID{2}, WindowStart: 100, Pieces: []pieceData{{MerkleRoot: crypto.Hash{2}}}}
+	targets = r.managedAuditTargets()
+	if len(targets) != 1 || targets[0].root != (crypto.Hash{2}) {
+		t.Fatal("expected the current piece to be audited, got", targets)
+	}
+}
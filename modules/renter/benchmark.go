@@ -0,0 +1,149 @@
+package renter
+
+// benchmark.go periodically measures the performance of the hosts that the
+// renter has contracts with. The results are passed to the hostdb, which
+// uses them to adjust the scores of the hosts. Benchmarking is disabled by
+// default, because every benchmark costs a small amount of money.
+//
+// The sector that is uploaded for a benchmark is deleted from the host once
+// the benchmark is done. It is also marked as a candidate for garbage
+// collection, so that it is deleted later if the host can't be reached.
+
+import (
+	"net"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/errors"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// managedBenchmarkHost measures the latency and the upload and download
+// throughput of a host. The latency is the time it takes to establish a TCP
+// connection with the host. The throughputs are measured by uploading a
+// sector of random data to the host and downloading it again, after which the
+// sector is deleted.
+func (r *Renter) managedBenchmarkHost(host modules.HostDBEntry) (modules.HostDBBenchmark, error) {
+	benchmark := modules.HostDBBenchmark{
+		Timestamp: time.Now(),
+	}
+
+	// Measure the latency.
+	dialer := &net.Dialer{
+		Cancel:  r.tg.StopChan(),
+		Timeout: hostBenchmarkDialTimeout,
+	}
+	start := time.Now()
+	conn, err := dialer.Dial("tcp", string(host.NetAddress))
+	if err != nil {
+		return modules.HostDBBenchmark{}, errors.AddContext(err, "unable to dial host")
+	}
+	benchmark.Latency = time.Since(start)
+	conn.Close()
+
+	// Measure the upload throughput. The time it takes to create the editor
+	// is not included, since the editor might already be open.
+	editor, err := r.hostContractor.Editor(host.PublicKey, r.tg.StopChan())
+	if err != nil {
+		return modules.HostDBBenchmark{}, errors.AddContext(err, "unable to create editor")
+	}
+	data := fastrand.Bytes(int(modules.SectorSize))
+	start = time.Now()
+	root, _, err := editor.Upload(data)
+	elapsed := time.Since(start)
+	editor.Close()
+	if err != nil {
+		return modules.HostDBBenchmark{}, errors.AddContext(err, "unable to upload sector")
+	}
+	benchmark.UploadThroughput = throughput(modules.SectorSize, elapsed)
+	r.managedAddGCCandidate(host.PublicKey, root)
+	defer r.managedCollectGarbage(host.PublicKey.String(), []crypto.Hash{root})
+
+	// Measure the download throughput by fetching the sector again.
+	downloader, err := r.hostContractor.Downloader(host.PublicKey, r.tg.StopChan())
+	if err != nil {
+		return modules.HostDBBenchmark{}, errors.AddContext(err, "unable to create downloader")
+	}
+	start = time.Now()
+	_, _, err = downloader.Sector(root)
+	elapsed = time.Since(start)
+	downloader.Close()
+	if err != nil {
+		return modules.HostDBBenchmark{}, errors.AddContext(err, "unable to download sector")
+	}
+	benchmark.DownloadThroughput = throughput(modules.SectorSize, elapsed)
+	return benchmark, nil
+}
+
+// managedHostsToBenchmark returns the hosts that the renter has a contract
+// with that are good for uploading and have not been benchmarked within the
+// hostBenchmarkInterval.
+func (r *Renter) managedHostsToBenchmark() []modules.HostDBEntry {
+	var hosts []modules.HostDBEntry
+	for _, contract := range r.hostContractor.Contracts() {
+		utility, ok := r.hostContractor.ContractUtility(contract.HostPublicKey)
+		if !ok || !utility.GoodForUpload {
+			continue
+		}
+		host, ok := r.hostDB.Host(contract.HostPublicKey)
+		if !ok {
+			continue
+		}
+		if n := len(host.BenchmarkHistory); n > 0 && time.Since(host.BenchmarkHistory[n-1].Timestamp) < hostBenchmarkInterval {
+			continue
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+// threadedBenchmarkHosts is a background thread that benchmarks the hosts
+// that the renter has contracts with, if benchmarking is enabled.
+func (r *Renter) threadedBenchmarkHosts() {
+	err := r.tg.Add()
+	if err != nil {
+		return
+	}
+	defer r.tg.Done()
+
+	for {
+		select {
+		case <-r.tg.StopChan():
+			return
+		case <-time.After(hostBenchmarkCheckInterval):
+		}
+
+		id := r.mu.RLock()
+		enabled := r.persist.BenchmarkHosts
+		r.mu.RUnlock(id)
+		if !enabled || !r.g.Online() {
+			continue
+		}
+
+		for _, host := range r.managedHostsToBenchmark() {
+			benchmark, err := r.managedBenchmarkHost(host)
+			if err != nil {
+				r.log.Debugf("Unable to benchmark host %v: %v", host.PublicKey, err)
+			} else if err := r.hostDB.RecordBenchmark(host.PublicKey, benchmark); err != nil {
+				r.log.Debugf("Unable to record benchmark of host %v: %v", host.PublicKey, err)
+			}
+
+			// Return if the renter has shut down.
+			select {
+			case <-r.tg.StopChan():
+				return
+			default:
+			}
+		}
+	}
+}
+
+// throughput returns the number of bytes per second that were transferred if
+// n bytes were transferred in the given amount of time.
+func throughput(n uint64, elapsed time.Duration) uint64 {
+	if elapsed <= 0 {
+		elapsed = time.Nanosecond
+	}
+	return uint64(float64(n) / elapsed.Seconds())
+}
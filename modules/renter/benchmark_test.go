@@ -0,0 +1,128 @@
+package renter
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/renter/contractor"
+	"github.com/NebulousLabs/Sia/persist"
+	siasync "github.com/NebulousLabs/Sia/sync"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// benchmarkContractor is a hostContractor whose editors and downloaders store
+// sectors in memory. Deletions fail with deleteErr.
+type benchmarkContractor struct {
+	hostContractor
+	sectors   map[crypto.Hash][]byte
+	deleteErr error
+}
+
+func (c *benchmarkContractor) ContractByPublicKey(types.SiaPublicKey) (modules.RenterContract, bool) {
+	return modules.RenterContract{}, true
+}
+func (c *benchmarkContractor) IsOffline(types.SiaPublicKey) bool { return false }
+func (c *benchmarkContractor) Editor(types.SiaPublicKey, <-chan struct{}) (contractor.Editor, error) {
+	return benchmarkEditor{c}, nil
+}
+func (c *benchmarkContractor) Downloader(types.SiaPublicKey, <-chan struct{}) (contractor.Downloader, error) {
+	return benchmarkEditor{c}, nil
+}
+
+// benchmarkEditor is an editor and downloader of a benchmarkContractor.
+type benchmarkEditor struct {
+	c *benchmarkContractor
+}
+
+func (e benchmarkEditor) Upload(data []byte) (crypto.Hash, types.Currency, error) {
+	root := crypto.MerkleRoot(data)
+	e.c.sectors[root] = data
+	return root, types.ZeroCurrency, nil
+}
+func (e benchmarkEditor) Delete(roots []crypto.Hash) (int, error) {
+	if e.c.deleteErr != nil {
+		return 0, e.c.deleteErr
+	}
+	for _, root := range roots {
+		delete(e.c.sectors, root)
+	}
+	return len(roots), nil
+}
+func (e benchmarkEditor) Sector(root crypto.Hash) ([]byte, types.Currency, error) {
+	return e.c.sectors[root], types.ZeroCurrency, nil
+}
+func (e benchmarkEditor) Address() modules.NetAddress      { return "" }
+func (e benchmarkEditor) ContractID() types.FileContractID { return types.FileContractID{} }
+func (e benchmarkEditor) EndHeight() types.BlockHeight     { return 0 }
+func (e benchmarkEditor) Close() error                     { return nil }
+
+// TestBenchmarkHostDeletesSector checks that the sector of a benchmark is
+// deleted from the host, and that it stays a candidate for garbage collection
+// if it could not be deleted.
+func TestBenchmarkHostDeletesSector(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	dir := build.TempDir("renter", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	log, err := persist.NewFileLogger(filepath.Join(dir, logFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	host := modules.HostDBEntry{
+		HostExternalSettings: modules.HostExternalSettings{NetAddress: modules.NetAddress(l.Addr().String())},
+		PublicKey:            types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte("host")},
+	}
+	hc := &benchmarkContractor{sectors: make(map[crypto.Hash][]byte)}
+	r := &Renter{
+		hostContractor: hc,
+		log:            log,
+		mu:             siasync.New(modules.SafeMutexDelay, 1),
+		persistDir:     dir,
+	}
+
+	// The sector is deleted after the benchmark.
+	benchmark, err := r.managedBenchmarkHost(host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if benchmark.UploadThroughput == 0 || benchmark.DownloadThroughput == 0 {
+		t.Fatal("throughputs were not measured:", benchmark)
+	}
+	if len(hc.sectors) != 0 {
+		t.Fatal("benchmark sector was not deleted")
+	}
+	if gc := r.GarbageCollection(); gc.PendingSectors != 0 {
+		t.Fatal("deleted benchmark sector is still a candidate for garbage collection")
+	}
+
+	// If the sector can't be deleted, it is collected later.
+	hc.deleteErr = errors.New("host is unreachable")
+	_, err = r.managedBenchmarkHost(host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gc := r.GarbageCollection(); gc.PendingSectors != 1 {
+		t.Fatal("benchmark sector was not marked for garbage collection:", gc.PendingSectors)
+	}
+	hc.deleteErr = nil
+	for hostKey, roots := range r.managedGCTargets() {
+		r.managedCollectGarbage(hostKey, roots)
+	}
+	if len(hc.sectors) != 0 {
+		t.Fatal("benchmark sector was not collected")
+	}
+}
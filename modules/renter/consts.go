@@ -61,6 +61,12 @@ const (
 	// permissions are supplied.
 	defaultFilePerm = 0666
 
+	// defaultDownloadOverdrive is the number of extra pieces that are
+	// downloaded in parallel to prevent slow hosts from being a bottleneck.
+	//
+	// TODO: moderate default until full overdrive support is added.
+	defaultDownloadOverdrive = 3
+
 	// downloadFailureCooldown defines how long to wait for a worker after a
 	// worker has experienced a download failure.
 	downloadFailureCooldown = time.Second * 3
@@ -98,6 +104,54 @@ var (
 		Testing:  1 * time.Minute,
 	}).(time.Duration)
 
+	// gcBatchSize is the maximum number of sectors that are deleted from a
+	// contract in a single revision.
+	gcBatchSize = build.Select(build.Var{
+		Dev:      64,
+		Standard: 256,
+		Testing:  8,
+	}).(int)
+
+	// gcInterval is how often the renter deletes the sectors of deleted files
+	// from the hosts.
+	gcInterval = build.Select(build.Var{
+		Dev:      1 * time.Minute,
+		Standard: 10 * time.Minute,
+		Testing:  3 * time.Second,
+	}).(time.Duration)
+
+	// hostAuditInterval is how often the renter audits the hosts that it has
+	// contracts with.
+	hostAuditInterval = build.Select(build.Var{
+		Dev:      10 * time.Minute,
+		Standard: 24 * time.Hour,
+		Testing:  5 * time.Second,
+	}).(time.Duration)
+
+	// hostBenchmarkCheckInterval is how often the renter checks whether any
+	// of its hosts need to be benchmarked.
+	hostBenchmarkCheckInterval = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: 10 * time.Minute,
+		Testing:  time.Second,
+	}).(time.Duration)
+
+	// hostBenchmarkDialTimeout is the amount of time a host has to accept a
+	// connection when its latency is benchmarked.
+	hostBenchmarkDialTimeout = build.Select(build.Var{
+		Dev:      30 * time.Second,
+		Standard: 30 * time.Second,
+		Testing:  5 * time.Second,
+	}).(time.Duration)
+
+	// hostBenchmarkInterval is the minimum amount of time between two
+	// benchmarks of the same host.
+	hostBenchmarkInterval = build.Select(build.Var{
+		Dev:      10 * time.Minute,
+		Standard: 24 * time.Hour,
+		Testing:  5 * time.Second,
+	}).(time.Duration)
+
 	// maxConsecutivePenalty determines how many times the timeout/cooldown for
 	// being a bad host can be doubled before a maximum cooldown is reached.
 	maxConsecutivePenalty = build.Select(build.Var{
@@ -139,6 +193,64 @@ var (
 		Testing:  0.25,
 	}).(float64)
 
+	// tenantAuthAttempts is the number of failed authentications after which
+	// a tenant can't authenticate with its password until
+	// tenantAuthLockout has passed.
+	tenantAuthAttempts = build.Select(build.Var{
+		Dev:      5,
+		Standard: 5,
+		Testing:  3,
+	}).(int)
+
+	// tenantAuthCacheTTL is how long a successful authentication of a tenant
+	// is remembered, so that requests with the same credentials don't need
+	// to hash the password again.
+	tenantAuthCacheTTL = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: 5 * time.Minute,
+		Testing:  time.Second,
+	}).(time.Duration)
+
+	// tenantAuthLockout is how long a tenant can't authenticate with its
+	// password after tenantAuthAttempts failed authentications.
+	tenantAuthLockout = build.Select(build.Var{
+		Dev:      10 * time.Second,
+		Standard: time.Minute,
+		Testing:  time.Second,
+	}).(time.Duration)
+
+	// tenantScryptN is the scrypt cost parameter of tenant passwords.
+	tenantScryptN = build.Select(build.Var{
+		Dev:      1 << 15,
+		Standard: 1 << 15,
+		Testing:  1 << 10,
+	}).(int)
+
+	// tenantUsageSaveInterval is how often the usage of the tenants is saved
+	// if it has changed.
+	tenantUsageSaveInterval = build.Select(build.Var{
+		Dev:      30 * time.Second,
+		Standard: 2 * time.Minute,
+		Testing:  time.Second,
+	}).(time.Duration)
+
+	// transferHistoryLimit is the number of transfers that are kept in the
+	// transfer history. The history is compacted to this size once it holds
+	// twice as many transfers.
+	transferHistoryLimit = build.Select(build.Var{
+		Dev:      1000,
+		Standard: 100000,
+		Testing:  20,
+	}).(int)
+
+	// transferHistoryRetention is how long transfers are kept in the transfer
+	// history.
+	transferHistoryRetention = build.Select(build.Var{
+		Dev:      7 * 24 * time.Hour,
+		Standard: 365 * 24 * time.Hour,
+		Testing:  time.Hour,
+	}).(time.Duration)
+
 	// Prime to avoid intersecting with regular events.
 	uploadFailureCooldown = build.Select(build.Var{
 		Dev:      time.Second * 7,
@@ -0,0 +1,126 @@
+package contractor
+
+// alerts.go tracks the conditions of the contractor that need the user's
+// attention: an almost exhausted allowance, a wallet that can't pay for the
+// upcoming renewals and contracts that are about to expire without being
+// renewed. The alerts are checked during contract maintenance.
+
+import (
+	"fmt"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// Alerts returns the alerts of the contractor, most urgent first.
+func (c *Contractor) Alerts() []modules.Alert {
+	return c.staticAlerter.Alerts()
+}
+
+// managedRegisterAlert registers an alert, saving the contractor if the alert
+// is new or changed.
+func (c *Contractor) managedRegisterAlert(id string, severity modules.AlertSeverity, msg, cause string) {
+	if !c.staticAlerter.RegisterAlert(id, severity, msg, cause) {
+		return
+	}
+	c.log.Printf("ALERT: %v: %v", msg, cause)
+	c.mu.Lock()
+	err := c.save()
+	c.mu.Unlock()
+	if err != nil {
+		c.log.Println("Unable to save the contractor alerts:", err)
+	}
+}
+
+// managedUnregisterAlert unregisters an alert, saving the contractor if the
+// alert was registered.
+func (c *Contractor) managedUnregisterAlert(id string) {
+	if !c.staticAlerter.UnregisterAlert(id) {
+		return
+	}
+	c.mu.Lock()
+	err := c.save()
+	c.mu.Unlock()
+	if err != nil {
+		c.log.Println("Unable to save the contractor alerts:", err)
+	}
+}
+
+// managedCheckFundsAlerts checks whether the allowance is almost exhausted and
+// whether the wallet can pay for the renewals of the contracts that reach
+// their renew window within the next renew window.
+func (c *Contractor) managedCheckFundsAlerts(allowance modules.Allowance, spending modules.ContractorSpending, blockHeight types.BlockHeight) {
+	if spending.Unspent.Cmp(allowance.Funds.MulFloat(allowanceLowThreshold)) < 0 {
+		severity := modules.AlertSeverityWarning
+		if spending.Unspent.IsZero() {
+			severity = modules.AlertSeverityError
+		}
+		c.managedRegisterAlert(modules.AlertIDRenterAllowanceLow, severity,
+			"the allowance is almost exhausted, uploads and repairs will fail once it runs out",
+			fmt.Sprintf("%v of the %v allowance remain unspent", spending.Unspent.HumanString(), allowance.Funds.HumanString()))
+	} else {
+		c.managedUnregisterAlert(modules.AlertIDRenterAllowanceLow)
+	}
+
+	// Estimate the cost of the upcoming renewals. A locked wallet doesn't
+	// report its balance, in which case the alert is left as it is.
+	var renewCost types.Currency
+	var renewals int
+	inWindow := false
+	for _, contract := range c.staticContracts.ViewAll() {
+		if !contract.Utility.GoodForRenew || blockHeight+2*allowance.RenewWindow < contract.EndHeight {
+			continue
+		}
+		cost, err := c.managedEstimateRenewFundingRequirements(contract, blockHeight, allowance)
+		if err != nil {
+			continue
+		}
+		renewCost = renewCost.Add(cost)
+		renewals++
+		inWindow = inWindow || blockHeight+allowance.RenewWindow >= contract.EndHeight
+	}
+	balance, _, _, err := c.wallet.ConfirmedBalance()
+	if err != nil {
+		return
+	}
+	if renewals == 0 || balance.Cmp(renewCost) >= 0 {
+		c.managedUnregisterAlert(modules.AlertIDRenterWalletLowFunds)
+		return
+	}
+	severity := modules.AlertSeverityWarning
+	if inWindow {
+		severity = modules.AlertSeverityCritical
+	}
+	c.managedRegisterAlert(modules.AlertIDRenterWalletLowFunds, severity,
+		"the wallet does not have enough money to renew the contracts of the upcoming renew window",
+		fmt.Sprintf("renewing %v contracts needs about %v, but the wallet only has %v", renewals, renewCost.HumanString(), balance.HumanString()))
+}
+
+// managedCheckExpiringAlert registers an alert if contracts that are in the
+// renew window could not be renewed. The alert becomes critical once the
+// first of them is in the second half of the renew window.
+func (c *Contractor) managedCheckExpiringAlert(unrenewed []types.FileContractID, allowance modules.Allowance, blockHeight types.BlockHeight) {
+	var expiring int
+	var firstExpiry types.BlockHeight
+	for _, id := range unrenewed {
+		contract, ok := c.staticContracts.View(id)
+		if !ok {
+			continue
+		}
+		if expiring == 0 || contract.EndHeight < firstExpiry {
+			firstExpiry = contract.EndHeight
+		}
+		expiring++
+	}
+	if expiring == 0 {
+		c.managedUnregisterAlert(modules.AlertIDRenterContractsExpiring)
+		return
+	}
+	severity := modules.AlertSeverityError
+	if blockHeight+allowance.RenewWindow/2 >= firstExpiry {
+		severity = modules.AlertSeverityCritical
+	}
+	c.managedRegisterAlert(modules.AlertIDRenterContractsExpiring, severity,
+		"contracts are about to expire without being renewed, the data stored on them will be lost",
+		fmt.Sprintf("%v contracts could not be renewed, the first one expires at block %v", expiring, firstExpiry))
+}
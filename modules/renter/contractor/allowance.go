@@ -10,6 +10,7 @@ import (
 var (
 	errAllowanceNoHosts    = errors.New("hosts must be non-zero")
 	errAllowanceNotSynced  = errors.New("you must be synced to set an allowance")
+	errAllowanceRedundancy = errors.New("expected redundancy must be at least 1")
 	errAllowanceWindowSize = errors.New("renew window must be less than period")
 	errAllowanceZeroPeriod = errors.New("period must be non-zero")
 
@@ -56,11 +57,18 @@ func (c *Contractor) SetAllowance(a modules.Allowance) error {
 		return ErrAllowanceZeroWindow
 	} else if a.RenewWindow >= a.Period {
 		return errAllowanceWindowSize
+	} else if a.ExpectedRedundancy != 0 && a.ExpectedRedundancy < 1 {
+		return errAllowanceRedundancy
 	} else if !c.cs.Synced() {
 		return errAllowanceNotSynced
 	}
 
 	c.log.Println("INFO: setting allowance to", a)
+	// Inform the hostdb of the new allowance so that it stops selecting
+	// hosts that exceed the price limits.
+	if err := c.hdb.SetAllowance(a); err != nil {
+		return err
+	}
 	c.mu.Lock()
 	// set the current period to the blockheight if the existing allowance is
 	// empty
@@ -128,6 +136,9 @@ func (c *Contractor) managedCancelAllowance() error {
 	}
 
 	// Clear out the allowance and save.
+	if err := c.hdb.SetAllowance(modules.Allowance{}); err != nil {
+		return err
+	}
 	c.mu.Lock()
 	c.allowance = modules.Allowance{}
 	c.currentPeriod = 0
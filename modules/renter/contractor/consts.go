@@ -6,6 +6,13 @@ import (
 	"github.com/NebulousLabs/Sia/types"
 )
 
+// Constants related to alerts.
+var (
+	// allowanceLowThreshold is the fraction of the allowance below which the
+	// unspent funds of the allowance trigger an alert.
+	allowanceLowThreshold = float64(0.1) // 10%
+)
+
 // Constants related to contract formation parameters.
 var (
 	// consecutiveRenewalsBeforeReplacement is the number of times a contract
@@ -29,6 +36,11 @@ var (
 	// contract.
 	minContractFundRenewalThreshold = float64(0.03) // 3%
 
+	// minContractFundRenewalSectors defines the number of sectors worth of
+	// storage, upload and download that a contract needs to be able to pay
+	// for. Contracts with less funds remaining are prematurely renewed.
+	minContractFundRenewalSectors = uint64(3)
+
 	// randomHostsBufferForScore defines how many extra hosts are queried when trying
 	// to figure out an appropriate minimum score for the hosts that we have.
 	randomHostsBufferForScore = build.Select(build.Var{
@@ -13,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/modules/renter/proto"
@@ -55,6 +56,7 @@ type Contractor struct {
 	currentPeriod types.BlockHeight
 	lastChange    modules.ConsensusChangeID
 
+	contractOverrides   map[types.FileContractID]contractOverride
 	downloaders         map[types.FileContractID]*hostDownloader
 	editors             map[types.FileContractID]*hostEditor
 	numFailedRenews     map[types.FileContractID]types.BlockHeight
@@ -63,6 +65,7 @@ type Contractor struct {
 	renewing            map[types.FileContractID]bool // prevent revising during renewal
 	revising            map[types.FileContractID]bool // prevent overlapping revisions
 
+	staticAlerter   *modules.Alerter
 	staticContracts *proto.ContractSet
 	oldContracts    map[types.FileContractID]modules.RenterContract
 }
@@ -81,7 +84,9 @@ func (c *Contractor) PeriodSpending() modules.ContractorSpending {
 	defer c.mu.RUnlock()
 
 	var spending modules.ContractorSpending
+	activeEndHeights := make(map[string]types.BlockHeight)
 	for _, contract := range c.staticContracts.ViewAll() {
+		activeEndHeights[string(contract.HostPublicKey.Key)] = contract.EndHeight
 		// Calculate ContractFees
 		spending.ContractFees = spending.ContractFees.Add(contract.ContractFee)
 		spending.ContractFees = spending.ContractFees.Add(contract.TxnFee)
@@ -98,7 +103,12 @@ func (c *Contractor) PeriodSpending() modules.ContractorSpending {
 	// Calculate needed spending to be reported from old contracts
 	for _, contract := range c.oldContracts {
 		host, exist := c.hdb.Host(contract.HostPublicKey)
-		if contract.StartHeight >= c.currentPeriod {
+		// A refreshed contract has the same end height as the contract that
+		// replaced it. Its funds are part of the current period, even if the
+		// contract was formed before the current period started.
+		endHeight, active := activeEndHeights[string(contract.HostPublicKey.Key)]
+		refreshed := active && endHeight == contract.EndHeight
+		if contract.StartHeight >= c.currentPeriod || refreshed {
 			// Calculate spending from contracts that were renewed during the current period
 			// Calculate ContractFees
 			spending.ContractFees = spending.ContractFees.Add(contract.ContractFee)
@@ -139,6 +149,13 @@ func (c *Contractor) PeriodSpending() modules.ContractorSpending {
 	return spending
 }
 
+// SpendingHistory returns the spending records of the given host that were
+// created between start and end. If the host key is empty, the records of all
+// hosts are returned.
+func (c *Contractor) SpendingHistory(host types.SiaPublicKey, start, end time.Time) ([]modules.RenterSpendingRecord, error) {
+	return c.staticContracts.SpendingHistory(host, start, end)
+}
+
 // ContractByPublicKey returns the contract with the key specified, if it
 // exists. The contract will be resolved if possible to the most recent child
 // contract.
@@ -242,13 +259,13 @@ func New(cs consensusSet, wallet walletShim, tpool transactionPool, hdb hostDB,
 		return nil, err
 	}
 
-	// Create the contract set.
-	contractSet, err := proto.NewContractSet(filepath.Join(persistDir, "contracts"), modules.ProdDependencies)
+	// Create the logger.
+	logger, err := persist.NewFileLogger(filepath.Join(persistDir, "contractor.log"))
 	if err != nil {
 		return nil, err
 	}
-	// Create the logger.
-	logger, err := persist.NewFileLogger(filepath.Join(persistDir, "contractor.log"))
+	// Create the contract set.
+	contractSet, err := proto.NewContractSet(filepath.Join(persistDir, "contracts"), logger, modules.ProdDependencies)
 	if err != nil {
 		return nil, err
 	}
@@ -271,7 +288,9 @@ func NewCustomContractor(cs consensusSet, w wallet, tp transactionPool, hdb host
 
 		interruptMaintenance: make(chan struct{}),
 
+		staticAlerter:       modules.NewAlerter("contractor"),
 		staticContracts:     contractSet,
+		contractOverrides:   make(map[types.FileContractID]contractOverride),
 		downloaders:         make(map[types.FileContractID]*hostDownloader),
 		editors:             make(map[types.FileContractID]*hostEditor),
 		oldContracts:        make(map[types.FileContractID]modules.RenterContract),
@@ -297,6 +316,12 @@ func NewCustomContractor(cs consensusSet, w wallet, tp transactionPool, hdb host
 		return nil, err
 	}
 
+	// Pass the loaded allowance on to the hostdb so that host selection
+	// respects the price limits right away.
+	if err := c.hdb.SetAllowance(c.allowance); err != nil {
+		return nil, err
+	}
+
 	// Subscribe to the consensus set.
 	err = cs.ConsensusSetSubscribe(c, c.lastChange, c.tg.StopChan())
 	if err == modules.ErrInvalidConsensusChangeID {
@@ -327,9 +352,17 @@ func NewCustomContractor(cs consensusSet, w wallet, tp transactionPool, hdb host
 		c.contractIDToPubKey[contract.ID] = contract.HostPublicKey
 		c.pubKeysToContractID[string(contract.HostPublicKey.Key)] = contract.ID
 	}
+	// A canceled contract doesn't replace the contract that was formed with
+	// the same host after it was canceled.
+	current := make(map[string]struct{})
 	for _, contract := range c.staticContracts.ViewAll() {
+		key := string(contract.HostPublicKey.Key)
 		c.contractIDToPubKey[contract.ID] = contract.HostPublicKey
-		c.pubKeysToContractID[string(contract.HostPublicKey.Key)] = contract.ID
+		if _, exists := current[key]; exists && c.contractOverrides[contract.ID].Canceled {
+			continue
+		}
+		current[key] = struct{}{}
+		c.pubKeysToContractID[key] = contract.ID
 	}
 
 	return c, nil
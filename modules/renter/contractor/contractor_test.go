@@ -2,12 +2,15 @@ package contractor
 
 import (
 	"errors"
+	"io/ioutil"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/renter/proto"
+	"github.com/NebulousLabs/Sia/persist"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -23,6 +26,7 @@ func (newStub) Synced() bool                               { return true }
 func (newStub) Unsubscribe(modules.ConsensusSetSubscriber) { return }
 
 // wallet stubs
+func (newStub) ConfirmedBalance() (sc, sf, claim types.Currency, err error)  { return }
 func (newStub) NextAddress() (uc types.UnlockConditions, err error)          { return }
 func (newStub) StartTransaction() (tb modules.TransactionBuilder, err error) { return }
 
@@ -37,6 +41,7 @@ func (newStub) Host(types.SiaPublicKey) (settings modules.HostDBEntry, ok bool)
 func (newStub) IncrementSuccessfulInteractions(key types.SiaPublicKey)               { return }
 func (newStub) IncrementFailedInteractions(key types.SiaPublicKey)                   { return }
 func (newStub) RandomHosts(int, []types.SiaPublicKey) ([]modules.HostDBEntry, error) { return nil, nil }
+func (newStub) SetAllowance(modules.Allowance) error                                 { return nil }
 func (newStub) ScoreBreakdown(modules.HostDBEntry) modules.HostScoreBreakdown {
 	return modules.HostScoreBreakdown{}
 }
@@ -110,6 +115,7 @@ func (stubHostDB) IncrementSuccessfulInteractions(key types.SiaPublicKey)
 func (stubHostDB) IncrementFailedInteractions(key types.SiaPublicKey)                        { return }
 func (stubHostDB) PublicKey() (spk types.SiaPublicKey)                                       { return }
 func (stubHostDB) RandomHosts(int, []types.SiaPublicKey) (hs []modules.HostDBEntry, _ error) { return }
+func (stubHostDB) SetAllowance(modules.Allowance) error                                      { return nil }
 func (stubHostDB) ScoreBreakdown(modules.HostDBEntry) modules.HostScoreBreakdown {
 	return modules.HostScoreBreakdown{}
 }
@@ -407,12 +413,17 @@ func TestIntegrationSetAllowance(t *testing.T) {
 
 // testWalletShim is used to test the walletBridge type.
 type testWalletShim struct {
+	balanceCalled     bool
 	nextAddressCalled bool
 	startTxnCalled    bool
 }
 
 // These stub implementations for the walletShim interface set their respective
 // booleans to true, allowing tests to verify that they have been called.
+func (ws *testWalletShim) ConfirmedBalance() (types.Currency, types.Currency, types.Currency, error) {
+	ws.balanceCalled = true
+	return types.ZeroCurrency, types.ZeroCurrency, types.ZeroCurrency, nil
+}
 func (ws *testWalletShim) NextAddress() (types.UnlockConditions, error) {
 	ws.nextAddressCalled = true
 	return types.UnlockConditions{}, nil
@@ -426,6 +437,10 @@ func (ws *testWalletShim) StartTransaction() (modules.TransactionBuilder, error)
 func TestWalletBridge(t *testing.T) {
 	shim := new(testWalletShim)
 	bridge := WalletBridge{shim}
+	bridge.ConfirmedBalance()
+	if !shim.balanceCalled {
+		t.Error("ConfirmedBalance was not called on the shim")
+	}
 	bridge.NextAddress()
 	if !shim.nextAddressCalled {
 		t.Error("NextAddress was not called on the shim")
@@ -435,3 +450,167 @@ func TestWalletBridge(t *testing.T) {
 		t.Error("StartTransaction was not called on the shim")
 	}
 }
+
+// TestInitialContractFunding tests that the initial funding of a contract
+// follows the expected usage of the allowance.
+func TestInitialContractFunding(t *testing.T) {
+	host := modules.HostDBEntry{}
+	host.ContractPrice = types.SiacoinPrecision
+	host.StoragePrice = types.SiacoinPrecision.Div64(1e9).Div64(4032)
+	host.UploadBandwidthPrice = types.SiacoinPrecision.Div64(1e9)
+	host.DownloadBandwidthPrice = types.SiacoinPrecision.Div64(1e9)
+
+	allowance := modules.Allowance{
+		Funds:  types.SiacoinPrecision.Mul64(10e3),
+		Hosts:  10,
+		Period: 4032,
+	}
+	evenSplit := allowance.Funds.Div64(allowance.Hosts)
+
+	// Without expected usage a third of the even split is used.
+	if funding := initialContractFunding(host, 0, allowance); funding.Cmp(evenSplit.Div64(3)) != 0 {
+		t.Fatal("wrong funding without expected usage:", funding)
+	}
+
+	// With a small amount of expected usage the funding should be at the
+	// minimum.
+	allowance.ExpectedStorage = 1e6
+	minimum := allowance.Funds.MulFloat(fileContractMinimumFunding).Div64(allowance.Hosts)
+	if funding := initialContractFunding(host, 0, allowance); funding.Cmp(minimum) != 0 {
+		t.Fatal("wrong funding for small expected usage:", funding)
+	}
+
+	// With a large amount of expected usage the funding should be capped at
+	// an even split of the allowance.
+	allowance.ExpectedStorage = 1e15
+	if funding := initialContractFunding(host, 0, allowance); funding.Cmp(evenSplit) != 0 {
+		t.Fatal("wrong funding for large expected usage:", funding)
+	}
+
+	// In between, the funding should grow with the expected bandwidth.
+	allowance.ExpectedStorage = 1e12
+	storageOnly := initialContractFunding(host, 0, allowance)
+	allowance.ExpectedUpload = 1e5
+	withUpload := initialContractFunding(host, 0, allowance)
+	if storageOnly.Cmp(minimum) <= 0 || storageOnly.Cmp(evenSplit) >= 0 {
+		t.Fatal("funding should be between the minimum and the even split:", storageOnly)
+	}
+	if withUpload.Cmp(storageOnly) <= 0 {
+		t.Fatal("expected uploads should increase the funding")
+	}
+}
+
+// TestNeedsRefresh tests that contracts are refreshed once they run out of
+// funds.
+func TestNeedsRefresh(t *testing.T) {
+	host := modules.HostDBEntry{}
+	host.StoragePrice = types.SiacoinPrecision.Div64(1e9).Div64(4032)
+	host.UploadBandwidthPrice = types.SiacoinPrecision.Div64(1e9)
+	host.DownloadBandwidthPrice = types.SiacoinPrecision.Div64(1e9)
+	allowance := modules.Allowance{Period: 4032}
+
+	contract := modules.RenterContract{
+		TotalCost:   types.SiacoinPrecision.Mul64(100),
+		RenterFunds: types.SiacoinPrecision.Mul64(50),
+	}
+	if needsRefresh(contract, host, allowance) {
+		t.Fatal("contract with half of its funds remaining should not be refreshed")
+	}
+
+	// Less than 3% of the funds remaining.
+	contract.RenterFunds = types.SiacoinPrecision.Mul64(2)
+	if !needsRefresh(contract, host, allowance) {
+		t.Fatal("contract with 2% of its funds remaining should be refreshed")
+	}
+
+	// Not enough funds remaining for a few more sectors.
+	contract.TotalCost = types.SiacoinPrecision
+	contract.RenterFunds = types.SiacoinPrecision.Div64(2)
+	host.UploadBandwidthPrice = types.SiacoinPrecision.Div64(modules.SectorSize)
+	if !needsRefresh(contract, host, allowance) {
+		t.Fatal("contract that can't pay for another sector should be refreshed")
+	}
+}
+
+// TestRefreshFunding tests that refreshes draw the right amount of funds from
+// the allowance.
+func TestRefreshFunding(t *testing.T) {
+	contract := modules.RenterContract{
+		TotalCost: types.SiacoinPrecision.Mul64(100),
+	}
+
+	// With enough funds remaining the funding is doubled.
+	amount, ok := refreshFunding(contract, types.SiacoinPrecision.Mul64(1e3))
+	if !ok || amount.Cmp(types.SiacoinPrecision.Mul64(200)) != 0 {
+		t.Fatal("expected funding to be doubled:", amount, ok)
+	}
+
+	// With less funds remaining the remaining funds are used.
+	amount, ok = refreshFunding(contract, types.SiacoinPrecision.Mul64(150))
+	if !ok || amount.Cmp(types.SiacoinPrecision.Mul64(150)) != 0 {
+		t.Fatal("expected remaining funds to be used:", amount, ok)
+	}
+
+	// With too little funds remaining the contract is not refreshed.
+	_, ok = refreshFunding(contract, types.SiacoinPrecision.Mul64(50))
+	if ok {
+		t.Fatal("contract should not be refreshed with less than its previous funding")
+	}
+}
+
+// TestPeriodSpendingRefresh tests that the funds of a refreshed contract are
+// part of the spending of the current period, even if the contract was formed
+// before the current period started.
+func TestPeriodSpendingRefresh(t *testing.T) {
+	cs, err := proto.NewContractSet(build.TempDir("contractor", t.Name()), persist.NewLogger(ioutil.Discard), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	hostKey := types.SiaPublicKey{Key: []byte("host")}
+	err = cs.ConvertV130Contract(proto.V130Contract{
+		LastRevisionTxn: types.Transaction{
+			FileContractRevisions: []types.FileContractRevision{{
+				ParentID:             types.FileContractID{2},
+				NewValidProofOutputs: []types.SiacoinOutput{{}},
+				NewWindowStart:       200,
+				UnlockConditions: types.UnlockConditions{
+					PublicKeys: []types.SiaPublicKey{{}, hostKey},
+				},
+			}},
+		},
+		StartHeight: 150,
+		TotalCost:   types.SiacoinPrecision.Mul64(200),
+	}, proto.V130CachedRevision{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Contractor{
+		allowance:       modules.Allowance{Funds: types.SiacoinPrecision.Mul64(1e3)},
+		blockHeight:     150,
+		currentPeriod:   100,
+		hdb:             stubHostDB{},
+		staticContracts: cs,
+		oldContracts: map[types.FileContractID]modules.RenterContract{
+			// A contract that was refreshed by the active contract.
+			{1}: {
+				HostPublicKey: hostKey,
+				StartHeight:   50,
+				EndHeight:     200,
+				TotalCost:     types.SiacoinPrecision.Mul64(100),
+			},
+			// A contract of the previous period.
+			{3}: {
+				HostPublicKey: hostKey,
+				StartHeight:   0,
+				EndHeight:     110,
+				TotalCost:     types.SiacoinPrecision.Mul64(50),
+			},
+		},
+	}
+	spending := c.PeriodSpending()
+	if !spending.TotalAllocated.Equals(types.SiacoinPrecision.Mul64(300)) {
+		t.Fatal("refreshed contract should be part of the current period:", spending.TotalAllocated.HumanString())
+	}
+}
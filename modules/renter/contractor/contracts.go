@@ -28,6 +28,10 @@ type (
 	fileContractRenewal struct {
 		id     types.FileContractID
 		amount types.Currency
+
+		// endHeight is the end height of the renewed contract. If it is zero,
+		// the end of the current period is used.
+		endHeight types.BlockHeight
 	}
 )
 
@@ -70,26 +74,43 @@ func (c *Contractor) managedEstimateRenewFundingRequirements(contract modules.Re
 	dataStored := contract.Transaction.FileContractRevisions[0].NewFileSize
 	maintenanceCost := types.NewCurrency64(dataStored).Mul64(uint64(allowance.Period)).Mul(host.StoragePrice)
 
-	// Estimate the amount of money that's going to be needed for new storage
-	// based on the amount of new storage added in the previous period. Account
-	// for both the storage price as well as the upload price.
-	//
-	// TODO: We are currently using a very crude method to estimate the amount
-	// of data uploaded, the host could have easily changed prices partway
-	// through the contract, which would cause this estimate to fail.
-	prevUploadSpending := contract.UploadSpending
-	prevUploadDataEstimate := contract.UploadSpending.Div(host.UploadBandwidthPrice)
-	// Sanity check - the host may have changed prices, make sure we aren't
-	// assuming an unreasonable amount of data.
-	if types.NewCurrency64(dataStored).Cmp(prevUploadDataEstimate) < 0 {
-		prevUploadDataEstimate = types.NewCurrency64(dataStored)
-	}
-	// The estimated cost for new upload spending is the previous upload
-	// bandwidth plus the implied storage cost for all of the new data.
-	newUploadsCost := prevUploadSpending.Add(prevUploadDataEstimate.Mul64(uint64(allowance.Period)).Mul(host.StoragePrice))
-
-	// Estimate the amount of money that's going to be spent on downloads.
-	newDownloadsCost := contract.DownloadSpending
+	var newUploadsCost, newDownloadsCost types.Currency
+	if allowance.HasExpectedUsage() {
+		// The renter has told us how it is going to use the contract. The
+		// expected storage covers all of the data in the contract, so only
+		// the storage that goes beyond the existing data is added on top of
+		// the maintenance cost.
+		expectedStorage, expectedUpload, expectedDownload := allowance.ExpectedHostUsage()
+		if expectedStorage > dataStored {
+			newStorage := types.NewCurrency64(expectedStorage - dataStored)
+			newUploadsCost = newStorage.Mul64(uint64(allowance.Period)).Mul(host.StoragePrice)
+		}
+		newUploadsCost = newUploadsCost.Add(host.UploadBandwidthPrice.Mul64(expectedUpload))
+		newDownloadsCost = host.DownloadBandwidthPrice.Mul64(expectedDownload)
+	} else {
+		// Estimate the amount of money that's going to be needed for new
+		// storage based on the amount of new storage added in the previous
+		// period. Account for both the storage price as well as the upload
+		// price.
+		//
+		// TODO: We are currently using a very crude method to estimate the
+		// amount of data uploaded, the host could have easily changed prices
+		// partway through the contract, which would cause this estimate to
+		// fail.
+		prevUploadSpending := contract.UploadSpending
+		prevUploadDataEstimate := contract.UploadSpending.Div(host.UploadBandwidthPrice)
+		// Sanity check - the host may have changed prices, make sure we aren't
+		// assuming an unreasonable amount of data.
+		if types.NewCurrency64(dataStored).Cmp(prevUploadDataEstimate) < 0 {
+			prevUploadDataEstimate = types.NewCurrency64(dataStored)
+		}
+		// The estimated cost for new upload spending is the previous upload
+		// bandwidth plus the implied storage cost for all of the new data.
+		newUploadsCost = prevUploadSpending.Add(prevUploadDataEstimate.Mul64(uint64(allowance.Period)).Mul(host.StoragePrice))
+
+		// Estimate the amount of money that's going to be spent on downloads.
+		newDownloadsCost = contract.DownloadSpending
+	}
 
 	// We will also need to pay the host contract price.
 	contractPrice := host.ContractPrice
@@ -122,6 +143,39 @@ func (c *Contractor) managedEstimateRenewFundingRequirements(contract modules.Re
 	return estimatedCost, nil
 }
 
+// initialContractFunding returns the amount of money that should be put into
+// a new contract with the provided host. If the allowance contains the
+// expected usage of the renter, the funding is based on what the host will
+// charge for that usage, bounded by an even split of the allowance between
+// all hosts. Otherwise, a third of an even split of the allowance is used.
+func initialContractFunding(host modules.HostDBEntry, blockHeight types.BlockHeight, allowance modules.Allowance) types.Currency {
+	evenSplit := allowance.Funds.Div64(allowance.Hosts)
+	if !allowance.HasExpectedUsage() {
+		return evenSplit.Div64(3)
+	}
+
+	// Estimate the cost of the expected usage, including the siafund fees,
+	// and add 33% for error margin just like renewals.
+	storage, upload, download := allowance.ExpectedHostUsage()
+	storageCost := types.NewCurrency64(storage).Mul64(uint64(allowance.Period)).Mul(host.StoragePrice)
+	uploadCost := host.UploadBandwidthPrice.Mul64(upload)
+	downloadCost := host.DownloadBandwidthPrice.Mul64(download)
+	cost := host.ContractPrice.Add(storageCost).Add(uploadCost).Add(downloadCost)
+	cost = cost.Add(types.Tax(blockHeight, cost))
+	cost = cost.Add(cost.Div64(3))
+
+	// Bound the estimate by the same minimum that is used for renewals and by
+	// an even split of the allowance.
+	minimum := allowance.Funds.MulFloat(fileContractMinimumFunding).Div64(allowance.Hosts)
+	if cost.Cmp(minimum) < 0 {
+		cost = minimum
+	}
+	if cost.Cmp(evenSplit) > 0 {
+		cost = evenSplit
+	}
+	return cost
+}
+
 // managedInterruptContractMaintenance will issue an interrupt signal to any
 // running maintenance, stopping that maintenance. If there are multiple threads
 // running maintenance, they will all be stopped.
@@ -207,19 +261,35 @@ func (c *Contractor) managedMarkContractsUtility() error {
 				u.GoodForRenew = false
 				return
 			}
-			// Contract should not be used for uploading if the time has come to
-			// renew the contract.
 			c.mu.RLock()
+			allowance := c.allowance
 			blockHeight := c.blockHeight
-			renewWindow := c.allowance.RenewWindow
 			c.mu.RUnlock()
-			if blockHeight+renewWindow >= contract.EndHeight {
+			// Contract has no utility if the host violates the price limits
+			// of the allowance.
+			if allowance.CheckHostPrices(host.HostExternalSettings) != nil {
+				u.GoodForUpload = false
+				u.GoodForRenew = false
+				return
+			}
+			// Contract should not be used for uploading if the time has come to
+			// renew the contract.
+			if blockHeight+allowance.RenewWindow >= contract.EndHeight {
 				u.GoodForUpload = false
 				return
 			}
 			return
 		}()
 
+		// Contracts that the user has canceled or stopped renewing are never
+		// marked as good again.
+		c.mu.RLock()
+		override, overridden := c.contractOverrides[contract.ID]
+		c.mu.RUnlock()
+		if overridden {
+			utility = override.apply(utility)
+		}
+
 		// Apply changes.
 		err := c.managedUpdateContractUtility(contract.ID, utility)
 		if err != nil {
@@ -236,6 +306,13 @@ func (c *Contractor) managedNewContract(host modules.HostDBEntry, contractFundin
 	if host.StoragePrice.Cmp(maxStoragePrice) > 0 {
 		return modules.RenterContract{}, errTooExpensive
 	}
+	// reject hosts that violate the price limits of the allowance
+	c.mu.RLock()
+	allowance := c.allowance
+	c.mu.RUnlock()
+	if err := allowance.CheckHostPrices(host.HostExternalSettings); err != nil {
+		return modules.RenterContract{}, err
+	}
 	// cap host.MaxCollateral
 	if host.MaxCollateral.Cmp(maxCollateral) > 0 {
 		host.MaxCollateral = maxCollateral
@@ -273,8 +350,9 @@ func (c *Contractor) managedNewContract(host modules.HostDBEntry, contractFundin
 	// Add a mapping from the contract's id to the public key of the host.
 	c.mu.Lock()
 	c.contractIDToPubKey[contract.ID] = contract.HostPublicKey
-	_, exists := c.pubKeysToContractID[string(contract.HostPublicKey.Key)]
-	if exists {
+	// A canceled contract is replaced by the new contract with the host.
+	existingID, exists := c.pubKeysToContractID[string(contract.HostPublicKey.Key)]
+	if exists && !c.contractOverrides[existingID].Canceled {
 		c.mu.Unlock()
 		txnBuilder.Drop()
 		return modules.RenterContract{}, fmt.Errorf("We already have a contract with host %v", contract.HostPublicKey)
@@ -324,6 +402,13 @@ func (c *Contractor) managedRenew(sc *proto.SafeContract, contractFunding types.
 	} else if host.StoragePrice.Cmp(maxStoragePrice) > 0 {
 		return modules.RenterContract{}, errTooExpensive
 	}
+	// reject hosts that violate the price limits of the allowance
+	c.mu.RLock()
+	allowance := c.allowance
+	c.mu.RUnlock()
+	if err := allowance.CheckHostPrices(host.HostExternalSettings); err != nil {
+		return modules.RenterContract{}, err
+	}
 	// cap host.MaxCollateral
 	if host.MaxCollateral.Cmp(maxCollateral) > 0 {
 		host.MaxCollateral = maxCollateral
@@ -414,7 +499,10 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 	}
 
 	// Calculate endHeight for renewed contracts
-	endHeight := currentPeriod + allowance.Period
+	endHeight := renewInstructions.endHeight
+	if endHeight == 0 {
+		endHeight = currentPeriod + allowance.Period
+	}
 
 	// Perform the actual renew. If the renew fails, return the
 	// contract. If the renew fails we check how often it has failed
@@ -494,6 +582,57 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 	return amount, nil
 }
 
+// needsRefresh returns true if the contract is running out of funds. A
+// contract is running out of funds if less than
+// minContractFundRenewalThreshold of its funds remain, or if less than
+// minContractFundRenewalSectors sectors worth of storage, upload and download
+// remain.
+func needsRefresh(contract modules.RenterContract, host modules.HostDBEntry, allowance modules.Allowance) bool {
+	blockBytes := types.NewCurrency64(modules.SectorSize * uint64(allowance.Period))
+	sectorStoragePrice := host.StoragePrice.Mul(blockBytes)
+	sectorUploadBandwidthPrice := host.UploadBandwidthPrice.Mul64(modules.SectorSize)
+	sectorDownloadBandwidthPrice := host.DownloadBandwidthPrice.Mul64(modules.SectorSize)
+	sectorBandwidthPrice := sectorUploadBandwidthPrice.Add(sectorDownloadBandwidthPrice)
+	sectorPrice := sectorStoragePrice.Add(sectorBandwidthPrice)
+	if contract.RenterFunds.Cmp(sectorPrice.Mul64(minContractFundRenewalSectors)) < 0 {
+		return true
+	}
+	if contract.TotalCost.IsZero() {
+		return false
+	}
+	percentRemaining, _ := big.NewRat(0, 1).SetFrac(contract.RenterFunds.Big(), contract.TotalCost.Big()).Float64()
+	return percentRemaining < minContractFundRenewalThreshold
+}
+
+// refreshFunding returns the amount of money that a contract that is running
+// out of funds should be refreshed with, and whether the remaining funds of
+// the allowance are enough to refresh the contract at all.
+//
+// The contract is refreshed with double the amount of funds that the contract
+// had previously. The reason that we double the funding instead of doing
+// anything more clever is that we don't know what the usage pattern has been.
+// The spending could have all occured in one burst recently, and the user
+// might need a contract that has substantially more money in it. We double so
+// that heavily used contracts can grow in funding quickly without consuming
+// too many transaction fees, however this does mean that a larger percentage
+// of funds get locked away from the user in the event that the user stops
+// uploading immediately after the refresh.
+//
+// If the allowance doesn't have enough funds remaining to double the funding,
+// the remaining funds are used instead, as long as they are at least as much
+// as the contract had previously. A smaller refresh would not be worth the
+// fees.
+func refreshFunding(contract modules.RenterContract, fundsRemaining types.Currency) (types.Currency, bool) {
+	amount := contract.TotalCost.Mul64(2)
+	if amount.Cmp(fundsRemaining) <= 0 {
+		return amount, true
+	}
+	if fundsRemaining.Cmp(contract.TotalCost) < 0 {
+		return types.ZeroCurrency, false
+	}
+	return fundsRemaining, true
+}
+
 // threadedContractMaintenance checks the set of contracts that the contractor
 // has against the allownace, renewing any contracts that need to be renewed,
 // dropping contracts which are no longer worthwhile, and adding contracts if
@@ -592,47 +731,32 @@ func (c *Contractor) threadedContractMaintenance() {
 			continue
 		}
 
-		// Check if the contract is empty. We define a contract as being empty
-		// if less than 'minContractFundRenewalThreshold' funds are remaining
-		// (3% at time of writing), or if there is less than 3 sectors worth of
-		// storage+upload+download remaining.
+		// Check if the contract is running out of funds. If so, it is
+		// refreshed by renewing it early with additional funds from the
+		// allowance. The refreshed contract keeps the end height of the old
+		// contract, so the data is carried over without paying for the
+		// storage of the data a second time.
 		host, _ := c.hdb.Host(contract.HostPublicKey)
-		blockBytes := types.NewCurrency64(modules.SectorSize * uint64(allowance.Period))
-		sectorStoragePrice := host.StoragePrice.Mul(blockBytes)
-		sectorUploadBandwidthPrice := host.UploadBandwidthPrice.Mul64(modules.SectorSize)
-		sectorDownloadBandwidthPrice := host.DownloadBandwidthPrice.Mul64(modules.SectorSize)
-		sectorBandwidthPrice := sectorUploadBandwidthPrice.Add(sectorDownloadBandwidthPrice)
-		sectorPrice := sectorStoragePrice.Add(sectorBandwidthPrice)
-		percentRemaining, _ := big.NewRat(0, 1).SetFrac(contract.RenterFunds.Big(), contract.TotalCost.Big()).Float64()
-		if contract.RenterFunds.Cmp(sectorPrice.Mul64(3)) < 0 || percentRemaining < minContractFundRenewalThreshold {
-			// Renew the contract with double the amount of funds that the
-			// contract had previously. The reason that we double the funding
-			// instead of doing anything more clever is that we don't know what
-			// the usage pattern has been. The spending could have all occured
-			// in one burst recently, and the user might need a contract that
-			// has substantially more money in it.
-			//
-			// We double so that heavily used contracts can grow in funding
-			// quickly without consuming too many transaction fees, however this
-			// does mean that a larger percentage of funds get locked away from
-			// the user in the event that the user stops uploading immediately
-			// after the renew.
+		if needsRefresh(contract, host, allowance) {
 			refreshSet = append(refreshSet, fileContractRenewal{
-				id:     contract.ID,
-				amount: contract.TotalCost.Mul64(2),
+				id:        contract.ID,
+				endHeight: contract.EndHeight,
 			})
 		}
 	}
 	if len(renewSet) != 0 {
 		c.log.Printf("renewing %v contracts", len(renewSet))
 	}
+	if len(refreshSet) != 0 {
+		c.log.Printf("refreshing %v contracts", len(refreshSet))
+	}
 
 	// Remove contracts that are not scheduled for renew from the
 	// firstFailedRenew map. We do this by making a new map entirely and copying
 	// over all the elements that still matter.
 	c.mu.Lock()
 	newFirstFailedRenew := make(map[types.FileContractID]types.BlockHeight)
-	for _, r := range renewSet {
+	for _, r := range append(renewSet, refreshSet...) {
 		if _, exists := c.numFailedRenews[r.id]; exists {
 			newFirstFailedRenew[r.id] = c.numFailedRenews[r.id]
 		}
@@ -650,26 +774,29 @@ func (c *Contractor) threadedContractMaintenance() {
 	if spending.TotalAllocated.Cmp(allowance.Funds) < 0 {
 		fundsRemaining = allowance.Funds.Sub(spending.TotalAllocated)
 	}
+	c.managedCheckFundsAlerts(allowance, spending, blockHeight)
 
 	// Go through the contracts we've assembled for renewal. Any contracts that
 	// need to be renewed because they are expiring (renewSet) get priority over
 	// contracts that need to be renewed because they have exhausted their funds
 	// (refreshSet). If there is not enough money available, the more expensive
-	// contracts will be skipped.
-	//
-	// TODO: We need some sort of global warning system so that we can alert the
-	// user to the fact that they do not have enough money to keep their
-	// contracts going in the event that we run out of funds.
+	// contracts will be skipped, and an alert is registered for the expiring
+	// contracts that could not be renewed.
+	var unrenewed []types.FileContractID
 	for _, renewal := range renewSet {
 		// Skip this renewal if we don't have enough funds remaining.
 		if renewal.amount.Cmp(fundsRemaining) > 0 {
+			unrenewed = append(unrenewed, renewal.id)
 			continue
 		}
 
-		// Renew one contract. The error is ignored because the renew function
-		// already will have logged the error, and in the event of an error,
-		// 'fundsSpent' will return '0'.
-		fundsSpent, _ := c.managedRenewContract(renewal, currentPeriod, allowance, blockHeight)
+		// Renew one contract. The renew function already will have logged
+		// the error, and in the event of an error, 'fundsSpent' will return
+		// '0'.
+		fundsSpent, err := c.managedRenewContract(renewal, currentPeriod, allowance, blockHeight)
+		if err != nil {
+			unrenewed = append(unrenewed, renewal.id)
+		}
 		fundsRemaining = fundsRemaining.Sub(fundsSpent)
 
 		// Return here if an interrupt or kill signal has been sent.
@@ -681,9 +808,18 @@ func (c *Contractor) threadedContractMaintenance() {
 		default:
 		}
 	}
+	c.managedCheckExpiringAlert(unrenewed, allowance, blockHeight)
 	for _, renewal := range refreshSet {
-		// Skip this renewal if we don't have enough funds remaining.
-		if renewal.amount.Cmp(fundsRemaining) > 0 {
+		// Determine the funding of the refresh. Skip this refresh if we don't
+		// have enough funds remaining.
+		contract, ok := c.staticContracts.View(renewal.id)
+		if !ok {
+			continue
+		}
+		var enough bool
+		renewal.amount, enough = refreshFunding(contract, fundsRemaining)
+		if !enough {
+			c.log.Printf("WARN: contract %v is out of funds, but the allowance has not enough funds remaining to refresh it", renewal.id)
 			continue
 		}
 
@@ -726,7 +862,6 @@ func (c *Contractor) threadedContractMaintenance() {
 	for _, contract := range c.staticContracts.ViewAll() {
 		exclude = append(exclude, contract.HostPublicKey)
 	}
-	initialContractFunds := c.allowance.Funds.Div64(c.allowance.Hosts).Div64(3)
 	c.mu.RUnlock()
 	hosts, err := c.hdb.RandomHosts(neededContracts*2+randomHostsBufferForScore, exclude)
 	if err != nil {
@@ -738,6 +873,7 @@ func (c *Contractor) threadedContractMaintenance() {
 	// contracts.
 	for _, host := range hosts {
 		// Determine if we have enough money to form a new contract.
+		initialContractFunds := initialContractFunding(host, blockHeight, allowance)
 		if fundsRemaining.Cmp(initialContractFunds) < 0 {
 			c.log.Println("WARN: need to form new contracts, but unable to because of a low allowance")
 			break
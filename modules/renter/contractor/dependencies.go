@@ -20,10 +20,12 @@ type (
 	// provide a shim to bridge the gap between modules.Wallet and
 	// transactionBuilder.
 	walletShim interface {
+		ConfirmedBalance() (types.Currency, types.Currency, types.Currency, error)
 		NextAddress() (types.UnlockConditions, error)
 		StartTransaction() (modules.TransactionBuilder, error)
 	}
 	wallet interface {
+		ConfirmedBalance() (types.Currency, types.Currency, types.Currency, error)
 		NextAddress() (types.UnlockConditions, error)
 		StartTransaction() (transactionBuilder, error)
 	}
@@ -55,6 +57,7 @@ type (
 		IncrementFailedInteractions(key types.SiaPublicKey)
 		RandomHosts(n int, exclude []types.SiaPublicKey) ([]modules.HostDBEntry, error)
 		ScoreBreakdown(modules.HostDBEntry) modules.HostScoreBreakdown
+		SetAllowance(allowance modules.Allowance) error
 	}
 
 	persister interface {
@@ -70,6 +73,11 @@ type WalletBridge struct {
 	W walletShim
 }
 
+// ConfirmedBalance returns the confirmed balance of the wallet.
+func (ws *WalletBridge) ConfirmedBalance() (types.Currency, types.Currency, types.Currency, error) {
+	return ws.W.ConfirmedBalance()
+}
+
 // NextAddress computes and returns the next address of the wallet.
 func (ws *WalletBridge) NextAddress() (types.UnlockConditions, error) { return ws.W.NextAddress() }
 
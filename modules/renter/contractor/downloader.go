@@ -18,8 +18,8 @@ var errInvalidDownloader = errors.New("downloader has been invalidated because i
 type Downloader interface {
 	// Sector retrieves the sector with the specified Merkle root, and revises
 	// the underlying contract to pay the host proportionally to the data
-	// retrieve.
-	Sector(root crypto.Hash) ([]byte, error)
+	// retrieve. It returns the amount that was paid in the revision.
+	Sector(root crypto.Hash) (sector []byte, cost types.Currency, err error)
 
 	// Close terminates the connection to the host.
 	Close() error
@@ -34,8 +34,9 @@ type hostDownloader struct {
 	contractor   *Contractor
 	downloader   *proto.Downloader
 	hostSettings modules.HostExternalSettings
-	invalid      bool   // true if invalidate has been called
-	speed        uint64 // Bytes per second.
+	invalid      bool           // true if invalidate has been called
+	spending     types.Currency // download spending of the latest revision
+	speed        uint64         // Bytes per second.
 	mu           sync.Mutex
 }
 
@@ -85,20 +86,28 @@ func (hd *hostDownloader) HostSettings() modules.HostExternalSettings {
 
 // Sector retrieves the sector with the specified Merkle root, and revises
 // the underlying contract to pay the host proportionally to the data
-// retrieve.
-func (hd *hostDownloader) Sector(root crypto.Hash) ([]byte, error) {
+// retrieve. It returns the amount that was paid in the revision.
+func (hd *hostDownloader) Sector(root crypto.Hash) ([]byte, types.Currency, error) {
 	hd.mu.Lock()
 	defer hd.mu.Unlock()
 	if hd.invalid {
-		return nil, errInvalidDownloader
+		return nil, types.ZeroCurrency, errInvalidDownloader
 	}
 
 	// Download the sector.
-	_, sector, err := hd.downloader.Sector(root)
+	contract, sector, err := hd.downloader.Sector(root)
 	if err != nil {
-		return nil, err
+		return nil, types.ZeroCurrency, err
+	}
+
+	// The revising lock guarantees that no other downloader revised the
+	// contract, so the increase in spending is the price of the sector.
+	cost := types.ZeroCurrency
+	if contract.DownloadSpending.Cmp(hd.spending) > 0 {
+		cost = contract.DownloadSpending.Sub(hd.spending)
 	}
-	return sector, nil
+	hd.spending = contract.DownloadSpending
+	return sector, cost, nil
 }
 
 // Downloader returns a Downloader object that can be used to download sectors
@@ -171,6 +180,7 @@ func (c *Contractor) Downloader(pk types.SiaPublicKey, cancel <-chan struct{}) (
 		contractor: c,
 		downloader: d,
 		contractID: id,
+		spending:   contract.DownloadSpending,
 	}
 	c.mu.Lock()
 	c.downloaders[contract.ID] = hd
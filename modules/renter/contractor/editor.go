@@ -20,8 +20,13 @@ var maxUploadCollateral = types.SiacoinPrecision.Mul64(1e3).Div(modules.BlockByt
 // Editors are the means by which the renter uploads data to hosts.
 type Editor interface {
 	// Upload revises the underlying contract to store the new data. It
-	// returns the Merkle root of the data.
-	Upload(data []byte) (root crypto.Hash, err error)
+	// returns the Merkle root of the data and the amount that was paid in
+	// the revision.
+	Upload(data []byte) (root crypto.Hash, cost types.Currency, err error)
+
+	// Delete revises the underlying contract to no longer store the sectors
+	// with the given roots. It returns the number of deleted sectors.
+	Delete(roots []crypto.Hash) (deleted int, err error)
 
 	// Address returns the address of the host.
 	Address() modules.NetAddress
@@ -47,6 +52,7 @@ type hostEditor struct {
 	id         types.FileContractID
 	invalid    bool // true if invalidate has been called
 	netAddress modules.NetAddress
+	spending   types.Currency // storage and upload spending of the latest revision
 
 	mu sync.Mutex
 }
@@ -98,19 +104,44 @@ func (he *hostEditor) Close() error {
 }
 
 // Upload negotiates a revision that adds a sector to a file contract.
-func (he *hostEditor) Upload(data []byte) (_ crypto.Hash, err error) {
+func (he *hostEditor) Upload(data []byte) (_ crypto.Hash, _ types.Currency, err error) {
 	he.mu.Lock()
 	defer he.mu.Unlock()
 	if he.invalid {
-		return crypto.Hash{}, errInvalidEditor
+		return crypto.Hash{}, types.ZeroCurrency, errInvalidEditor
 	}
 
 	// Perform the upload.
-	_, sectorRoot, err := he.editor.Upload(data)
+	contract, sectorRoot, err := he.editor.Upload(data)
+	if err != nil {
+		return crypto.Hash{}, types.ZeroCurrency, err
+	}
+
+	// The revising lock guarantees that no other editor revised the
+	// contract, so the increase in spending is the price of the sector.
+	spending := contract.StorageSpending.Add(contract.UploadSpending)
+	cost := types.ZeroCurrency
+	if spending.Cmp(he.spending) > 0 {
+		cost = spending.Sub(he.spending)
+	}
+	he.spending = spending
+	return sectorRoot, cost, nil
+}
+
+// Delete negotiates a revision that removes sectors from a file contract.
+func (he *hostEditor) Delete(roots []crypto.Hash) (_ int, err error) {
+	he.mu.Lock()
+	defer he.mu.Unlock()
+	if he.invalid {
+		return 0, errInvalidEditor
+	}
+
+	// Perform the deletion.
+	_, deleted, err := he.editor.Delete(roots)
 	if err != nil {
-		return crypto.Hash{}, err
+		return 0, err
 	}
-	return sectorRoot, nil
+	return deleted, nil
 }
 
 // Editor returns a Editor object that can be used to upload, modify, and
@@ -186,6 +217,7 @@ func (c *Contractor) Editor(pk types.SiaPublicKey, cancel <-chan struct{}) (_ Ed
 		endHeight:  contract.EndHeight,
 		id:         id,
 		netAddress: host.NetAddress,
+		spending:   contract.StorageSpending.Add(contract.UploadSpending),
 	}
 	c.mu.Lock()
 	c.editors[contract.ID] = he
@@ -2,7 +2,6 @@ package contractor
 
 import (
 	"bytes"
-	"errors"
 	"net"
 	"os"
 	"path/filepath"
@@ -18,9 +17,12 @@ import (
 	"github.com/NebulousLabs/Sia/modules/host"
 	"github.com/NebulousLabs/Sia/modules/miner"
 	"github.com/NebulousLabs/Sia/modules/renter/hostdb"
+	"github.com/NebulousLabs/Sia/modules/renter/proto"
 	"github.com/NebulousLabs/Sia/modules/transactionpool"
 	modWallet "github.com/NebulousLabs/Sia/modules/wallet"
 	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/errors"
 	"github.com/NebulousLabs/fastrand"
 )
 
@@ -209,6 +211,47 @@ func TestIntegrationFormContract(t *testing.T) {
 	}
 }
 
+// TestIntegrationFormContractPriceLimits tests that the contractor refuses to
+// form contracts with hosts that exceed the price limits of the allowance.
+func TestIntegrationFormContractPriceLimits(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	h, c, _, err := newTestingTrio(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	defer c.Close()
+
+	// get the host's entry from the db
+	hostEntry, ok := c.hdb.Host(h.PublicKey())
+	if !ok {
+		t.Fatal("no entry for host in db")
+	}
+
+	// set a storage price limit below the host's storage price
+	c.mu.Lock()
+	c.allowance.MaxStoragePrice = hostEntry.StoragePrice.Sub(types.NewCurrency64(1))
+	c.mu.Unlock()
+
+	// forming a contract should fail
+	_, err = c.managedNewContract(hostEntry, types.SiacoinPrecision.Mul64(50), c.blockHeight+100)
+	if !errors.Contains(err, modules.ErrHostExceedsAllowanceLimits) {
+		t.Fatalf("expected %v, got %v", modules.ErrHostExceedsAllowanceLimits, err)
+	}
+
+	// raising the limit should allow the contract to be formed
+	c.mu.Lock()
+	c.allowance.MaxStoragePrice = hostEntry.StoragePrice
+	c.mu.Unlock()
+	_, err = c.managedNewContract(hostEntry, types.SiacoinPrecision.Mul64(50), c.blockHeight+100)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 // TestIntegrationReviseContract tests that the contractor can revise a
 // contract previously formed with a host.
 func TestIntegrationReviseContract(t *testing.T) {
@@ -242,7 +285,7 @@ func TestIntegrationReviseContract(t *testing.T) {
 		t.Fatal(err)
 	}
 	data := fastrand.Bytes(int(modules.SectorSize))
-	_, err = editor.Upload(data)
+	_, _, err = editor.Upload(data)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -285,7 +328,7 @@ func TestIntegrationUploadDownload(t *testing.T) {
 		t.Fatal(err)
 	}
 	data := fastrand.Bytes(int(modules.SectorSize))
-	root, err := editor.Upload(data)
+	root, uploadCost, err := editor.Upload(data)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -294,24 +337,68 @@ func TestIntegrationUploadDownload(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// the cost of the upload should be the amount paid in the revision
+	revised, _ := c.staticContracts.View(contract.ID)
+	if uploadCost.IsZero() || !uploadCost.Equals(revised.StorageSpending.Add(revised.UploadSpending)) {
+		t.Fatal("wrong upload cost:", uploadCost, revised.StorageSpending, revised.UploadSpending)
+	}
+
 	// download the data
 	downloader, err := c.Downloader(contract.HostPublicKey, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	retrieved, err := downloader.Sector(root)
+	retrieved, downloadCost, err := downloader.Sector(root)
 	if err != nil {
 		t.Fatal(err)
 	}
 	if !bytes.Equal(data, retrieved) {
 		t.Fatal("downloaded data does not match original")
 	}
+	revised, _ = c.staticContracts.View(contract.ID)
+	if downloadCost.IsZero() || !downloadCost.Equals(revised.DownloadSpending) {
+		t.Fatal("wrong download cost:", downloadCost, revised.DownloadSpending)
+	}
 	err = downloader.Close()
 	if err != nil {
 		t.Fatal(err)
 	}
 }
 
+// TestIntegrationDownloadMissingSector tests that a host's rejection of a
+// download of a sector that it doesn't store is reported as
+// proto.ErrSectorNotFound.
+func TestIntegrationDownloadMissingSector(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	h, c, _, err := newTestingTrio(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	defer c.Close()
+
+	hostEntry, ok := c.hdb.Host(h.PublicKey())
+	if !ok {
+		t.Fatal("no entry for host in db")
+	}
+	contract, err := c.managedNewContract(hostEntry, types.SiacoinPrecision.Mul64(50), c.blockHeight+100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	downloader, err := c.Downloader(contract.HostPublicKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer downloader.Close()
+	_, _, err = downloader.Sector(crypto.Hash{1})
+	if !errors.Contains(err, proto.ErrSectorNotFound) {
+		t.Fatal("expected the missing sector to be reported, got", err)
+	}
+}
+
 // TestIntegrationRenew tests that the contractor can renew a previously-
 // formed file contract.
 func TestIntegrationRenew(t *testing.T) {
@@ -346,7 +433,7 @@ func TestIntegrationRenew(t *testing.T) {
 	}
 	data := fastrand.Bytes(int(modules.SectorSize))
 	// insert the sector
-	root, err := editor.Upload(data)
+	root, _, err := editor.Upload(data)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -380,7 +467,7 @@ func TestIntegrationRenew(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	retrieved, err := downloader.Sector(root)
+	retrieved, _, err := downloader.Sector(root)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -414,7 +501,7 @@ func TestIntegrationRenew(t *testing.T) {
 	}
 	data = fastrand.Bytes(int(modules.SectorSize))
 	// insert the sector
-	_, err = editor.Upload(data)
+	_, _, err = editor.Upload(data)
 	if err != nil {
 		t.Fatal(err)
 	}
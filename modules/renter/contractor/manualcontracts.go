@@ -0,0 +1,293 @@
+package contractor
+
+// manualcontracts.go contains the methods that allow the user to manage
+// contracts by hand, next to the automatic contract maintenance. All manual
+// actions hold the maintenance lock, so they never run concurrently with
+// contract maintenance, and all funding is taken from the allowance.
+
+import (
+	"reflect"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/errors"
+)
+
+var (
+	// errContractExists is returned when the user tries to form a contract
+	// with a host that the renter already has a contract with that was not
+	// canceled.
+	errContractExists = errors.New("a contract with this host already exists")
+
+	// errInsufficientAllowance is returned when the funding of a manual
+	// action exceeds the funds that remain in the allowance.
+	errInsufficientAllowance = errors.New("contract funding exceeds the funds remaining in the allowance")
+
+	// errInvalidEndHeight is returned when the user tries to form a contract
+	// that ends before the current block height.
+	errInvalidEndHeight = errors.New("contract end height must be greater than the current block height")
+
+	// errNoAllowance is returned when the user tries to manage contracts
+	// without an allowance.
+	errNoAllowance = errors.New("an allowance needs to be set to manage contracts")
+
+	// errNoSuchContract is returned when the user references a contract that
+	// is not part of the current contract set.
+	errNoSuchContract = errors.New("no contract with the provided id exists")
+
+	// errUnknownHost is returned when the user tries to form a contract with
+	// a host that is not in the hostdb.
+	errUnknownHost = errors.New("host is not in the hostdb")
+)
+
+type (
+	// contractOverride records a decision that the user made about a
+	// contract. Contract maintenance never marks a contract as good against
+	// the decision of the user.
+	contractOverride struct {
+		// Canceled contracts are neither renewed nor used for uploads. Their
+		// data can still be downloaded until the contract expires.
+		Canceled bool `json:"canceled"`
+
+		// FailedAudit is set when the host failed a storage audit. The host
+		// lost data, so the contract is neither renewed nor used for uploads,
+		// and the data it held is repaired onto other hosts.
+		FailedAudit bool `json:"failedaudit"`
+
+		// StopRenewing prevents the contract from being renewed, but it can
+		// still be used for uploads until the renew window is reached.
+		StopRenewing bool `json:"stoprenewing"`
+	}
+
+	// contractOverridePersist is the persisted form of a contractOverride.
+	contractOverridePersist struct {
+		ID types.FileContractID `json:"id"`
+		contractOverride
+	}
+)
+
+// apply returns the utility with the override applied to it.
+func (o contractOverride) apply(u modules.ContractUtility) modules.ContractUtility {
+	if o.Canceled || o.FailedAudit || o.StopRenewing {
+		u.GoodForRenew = false
+	}
+	if o.Canceled || o.FailedAudit {
+		u.GoodForUpload = false
+	}
+	return u
+}
+
+// managedFundsRemaining returns the funds of the allowance that have not been
+// allocated to contracts yet.
+func (c *Contractor) managedFundsRemaining(allowance modules.Allowance) types.Currency {
+	spending := c.PeriodSpending()
+	if spending.TotalAllocated.Cmp(allowance.Funds) >= 0 {
+		return types.ZeroCurrency
+	}
+	return allowance.Funds.Sub(spending.TotalAllocated)
+}
+
+// managedLockMaintenance interrupts any running contract maintenance and then
+// grabs the maintenance lock. The caller is responsible for unlocking the
+// maintenance lock.
+func (c *Contractor) managedLockMaintenance() {
+	c.managedInterruptContractMaintenance()
+	c.maintenanceLock.Lock()
+}
+
+// managedSetContractOverride applies the override to the contract with the
+// given id, and stores it so that contract maintenance respects it.
+func (c *Contractor) managedSetContractOverride(id types.FileContractID, override contractOverride) error {
+	if _, ok := c.staticContracts.View(id); !ok {
+		return errNoSuchContract
+	}
+	c.mu.Lock()
+	existing := c.contractOverrides[id]
+	override.Canceled = override.Canceled || existing.Canceled
+	override.FailedAudit = override.FailedAudit || existing.FailedAudit
+	override.StopRenewing = override.StopRenewing || existing.StopRenewing
+	c.contractOverrides[id] = override
+	err := c.saveSync()
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	utility, ok := c.managedContractUtility(id)
+	if !ok {
+		return errNoSuchContract
+	}
+	return c.managedUpdateContractUtility(id, override.apply(utility))
+}
+
+// CancelContract stops the contract with the given id from being renewed or
+// used for uploads. The data stored in the contract can still be downloaded
+// until the contract expires. Contract maintenance will form a new contract to
+// replace the canceled one.
+func (c *Contractor) CancelContract(id types.FileContractID) error {
+	if err := c.tg.Add(); err != nil {
+		return err
+	}
+	defer c.tg.Done()
+	c.managedLockMaintenance()
+	defer c.maintenanceLock.Unlock()
+
+	if err := c.managedSetContractOverride(id, contractOverride{Canceled: true}); err != nil {
+		return err
+	}
+	c.log.Println("INFO: contract canceled by the user:", id)
+	return nil
+}
+
+// FormContract forms a contract with the host with the given public key. The
+// contract is funded from the allowance with the given amount and ends at the
+// given height. If endHeight is zero, the contract ends with the current
+// period. A contract with the host that was canceled is replaced by the new
+// contract.
+func (c *Contractor) FormContract(hostKey types.SiaPublicKey, funding types.Currency, endHeight types.BlockHeight) (modules.RenterContract, error) {
+	if err := c.tg.Add(); err != nil {
+		return modules.RenterContract{}, err
+	}
+	defer c.tg.Done()
+	c.managedLockMaintenance()
+	defer c.maintenanceLock.Unlock()
+
+	// Check the inputs against the state of the contractor.
+	c.mu.RLock()
+	allowance := c.allowance
+	blockHeight := c.blockHeight
+	currentPeriod := c.currentPeriod
+	id, exists := c.pubKeysToContractID[string(hostKey.Key)]
+	exists = exists && !c.contractOverrides[id].Canceled
+	c.mu.RUnlock()
+	if reflect.DeepEqual(allowance, modules.Allowance{}) {
+		return modules.RenterContract{}, errNoAllowance
+	}
+	if exists {
+		return modules.RenterContract{}, errContractExists
+	}
+	if endHeight == 0 {
+		endHeight = currentPeriod + allowance.Period
+	}
+	if endHeight <= blockHeight {
+		return modules.RenterContract{}, errInvalidEndHeight
+	}
+	if funding.Cmp(c.managedFundsRemaining(allowance)) > 0 {
+		return modules.RenterContract{}, errInsufficientAllowance
+	}
+	host, ok := c.hdb.Host(hostKey)
+	if !ok {
+		return modules.RenterContract{}, errUnknownHost
+	}
+
+	// Form the contract and mark it as good.
+	contract, err := c.managedNewContract(host, funding, endHeight)
+	if err != nil {
+		return modules.RenterContract{}, errors.AddContext(err, "unable to form contract")
+	}
+	err = c.managedUpdateContractUtility(contract.ID, modules.ContractUtility{
+		GoodForUpload: true,
+		GoodForRenew:  true,
+	})
+	if err != nil {
+		return modules.RenterContract{}, err
+	}
+	c.mu.Lock()
+	err = c.saveSync()
+	c.mu.Unlock()
+	if err != nil {
+		return modules.RenterContract{}, err
+	}
+	c.log.Println("INFO: contract formed by the user:", contract.ID)
+	return contract, nil
+}
+
+// RenewContract renews the contract with the given id right away instead of
+// waiting for the renew window. The renewed contract ends when the contract
+// would have ended after a regular renewal. If funding is zero, the funding
+// is estimated the same way as for regular renewals.
+func (c *Contractor) RenewContract(id types.FileContractID, funding types.Currency) error {
+	if err := c.tg.Add(); err != nil {
+		return err
+	}
+	defer c.tg.Done()
+	c.managedLockMaintenance()
+	defer c.maintenanceLock.Unlock()
+
+	c.mu.RLock()
+	allowance := c.allowance
+	blockHeight := c.blockHeight
+	currentPeriod := c.currentPeriod
+	c.mu.RUnlock()
+	if reflect.DeepEqual(allowance, modules.Allowance{}) {
+		return errNoAllowance
+	}
+	contract, ok := c.staticContracts.View(id)
+	if !ok {
+		return errNoSuchContract
+	}
+	if funding.IsZero() {
+		var err error
+		funding, err = c.managedEstimateRenewFundingRequirements(contract, blockHeight, allowance)
+		if err != nil {
+			return errors.AddContext(err, "unable to estimate renew funding")
+		}
+	}
+	if funding.Cmp(c.managedFundsRemaining(allowance)) > 0 {
+		return errInsufficientAllowance
+	}
+
+	// A regular renewal happens at the start of the next cycle, and extends
+	// the contract by a full period from there.
+	cycleLen := allowance.Period - allowance.RenewWindow
+	endHeight := currentPeriod + cycleLen + allowance.Period
+	if contract.EndHeight > endHeight {
+		endHeight = contract.EndHeight
+	}
+	_, err := c.managedRenewContract(fileContractRenewal{
+		id:        id,
+		amount:    funding,
+		endHeight: endHeight,
+	}, currentPeriod, allowance, blockHeight)
+	if err != nil {
+		return err
+	}
+	c.log.Println("INFO: contract renewed by the user:", id)
+	return nil
+}
+
+// ReportFailedAudit marks the contract with the given id as no longer good for
+// renewing or uploading, because its host failed a storage audit. Unlike the
+// manual actions, it does not interrupt contract maintenance, which respects
+// the override anyway.
+func (c *Contractor) ReportFailedAudit(id types.FileContractID) error {
+	if err := c.tg.Add(); err != nil {
+		return err
+	}
+	defer c.tg.Done()
+
+	if err := c.managedSetContractOverride(id, contractOverride{FailedAudit: true}); err != nil {
+		return err
+	}
+	c.log.Println("WARN: host of contract failed a storage audit:", id)
+	return nil
+}
+
+// StopRenewingContract prevents the contract with the given id from being
+// renewed. The contract can still be used for uploads until it reaches the
+// renew window, and its data can be downloaded until it expires.
+func (c *Contractor) StopRenewingContract(id types.FileContractID) error {
+	if err := c.tg.Add(); err != nil {
+		return err
+	}
+	defer c.tg.Done()
+	c.managedLockMaintenance()
+	defer c.maintenanceLock.Unlock()
+
+	if err := c.managedSetContractOverride(id, contractOverride{StopRenewing: true}); err != nil {
+		return err
+	}
+	c.log.Println("INFO: contract renewal stopped by the user:", id)
+	return nil
+}
@@ -0,0 +1,134 @@
+package contractor
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestContractOverrideApply checks that contract overrides only ever take
+// utility away from a contract.
+func TestContractOverrideApply(t *testing.T) {
+	good := modules.ContractUtility{GoodForUpload: true, GoodForRenew: true}
+	tests := []struct {
+		override contractOverride
+		upload   bool
+		renew    bool
+	}{
+		{contractOverride{}, true, true},
+		{contractOverride{StopRenewing: true}, true, false},
+		{contractOverride{Canceled: true}, false, false},
+		{contractOverride{Canceled: true, StopRenewing: true}, false, false},
+		{contractOverride{FailedAudit: true}, false, false},
+	}
+	for _, test := range tests {
+		u := test.override.apply(good)
+		if u.GoodForUpload != test.upload || u.GoodForRenew != test.renew {
+			t.Errorf("override %+v: expected upload %v renew %v, got %+v", test.override, test.upload, test.renew, u)
+		}
+		// An override must never mark a bad contract as good.
+		if u := test.override.apply(modules.ContractUtility{}); u.GoodForUpload || u.GoodForRenew {
+			t.Errorf("override %+v marked a bad contract as good", test.override)
+		}
+	}
+}
+
+// TestManualContractErrors checks that the manual contract actions and audit
+// reports reject invalid requests.
+func TestManualContractErrors(t *testing.T) {
+	var stub newStub
+	c, err := New(stub, stub, stub, stub, build.TempDir("contractor", t.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// Without an allowance, no contracts can be formed or renewed.
+	_, err = c.FormContract(types.SiaPublicKey{}, types.SiacoinPrecision, 0)
+	if err != errNoAllowance {
+		t.Fatalf("expected %v, got %v", errNoAllowance, err)
+	}
+	err = c.RenewContract(types.FileContractID{}, types.ZeroCurrency)
+	if err != errNoAllowance {
+		t.Fatalf("expected %v, got %v", errNoAllowance, err)
+	}
+
+	// Contracts that don't exist can't be canceled or stopped from renewing.
+	err = c.CancelContract(types.FileContractID{1})
+	if err != errNoSuchContract {
+		t.Fatalf("expected %v, got %v", errNoSuchContract, err)
+	}
+	err = c.StopRenewingContract(types.FileContractID{1})
+	if err != errNoSuchContract {
+		t.Fatalf("expected %v, got %v", errNoSuchContract, err)
+	}
+	err = c.ReportFailedAudit(types.FileContractID{1})
+	if err != errNoSuchContract {
+		t.Fatalf("expected %v, got %v", errNoSuchContract, err)
+	}
+
+	// With an allowance, the end height and funding are validated.
+	c.mu.Lock()
+	c.allowance = modules.Allowance{
+		Funds:       types.SiacoinPrecision.Mul64(100),
+		Hosts:       1,
+		Period:      100,
+		RenewWindow: 10,
+	}
+	c.blockHeight = 10
+	c.mu.Unlock()
+	_, err = c.FormContract(types.SiaPublicKey{}, types.SiacoinPrecision, 5)
+	if err != errInvalidEndHeight {
+		t.Fatalf("expected %v, got %v", errInvalidEndHeight, err)
+	}
+	_, err = c.FormContract(types.SiaPublicKey{}, c.allowance.Funds.Add(types.SiacoinPrecision), 0)
+	if err != errInsufficientAllowance {
+		t.Fatalf("expected %v, got %v", errInsufficientAllowance, err)
+	}
+	_, err = c.FormContract(types.SiaPublicKey{}, types.SiacoinPrecision, 0)
+	if err != errUnknownHost {
+		t.Fatalf("expected %v, got %v", errUnknownHost, err)
+	}
+}
+
+// TestFormContractAfterCancel checks that a contract can be formed manually
+// with a host whose only contract was canceled.
+func TestFormContractAfterCancel(t *testing.T) {
+	var stub newStub
+	c, err := New(stub, stub, stub, stub, build.TempDir("contractor", t.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	hostKey := types.SiaPublicKey{Key: []byte("host")}
+	id := types.FileContractID{1}
+	c.mu.Lock()
+	c.allowance = modules.Allowance{
+		Funds:       types.SiacoinPrecision.Mul64(100),
+		Hosts:       1,
+		Period:      100,
+		RenewWindow: 10,
+	}
+	c.pubKeysToContractID[string(hostKey.Key)] = id
+	c.mu.Unlock()
+
+	// While the contract is active, no other contract can be formed with the
+	// host.
+	_, err = c.FormContract(hostKey, types.SiacoinPrecision, 0)
+	if err != errContractExists {
+		t.Fatalf("expected %v, got %v", errContractExists, err)
+	}
+
+	// Once the contract is canceled, the check passes and forming fails only
+	// because the stub hostdb doesn't know the host.
+	c.mu.Lock()
+	c.contractOverrides[id] = contractOverride{Canceled: true}
+	c.mu.Unlock()
+	_, err = c.FormContract(hostKey, types.SiacoinPrecision, 0)
+	if err != errUnknownHost {
+		t.Fatalf("expected %v, got %v", errUnknownHost, err)
+	}
+}
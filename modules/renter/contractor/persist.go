@@ -1,6 +1,7 @@
 package contractor
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
 
@@ -14,16 +15,20 @@ import (
 
 // contractorPersist defines what Contractor data persists across sessions.
 type contractorPersist struct {
+	Alerts        []modules.Alert           `json:"alerts"`
 	Allowance     modules.Allowance         `json:"allowance"`
 	BlockHeight   types.BlockHeight         `json:"blockheight"`
 	CurrentPeriod types.BlockHeight         `json:"currentperiod"`
 	LastChange    modules.ConsensusChangeID `json:"lastchange"`
 	OldContracts  []modules.RenterContract  `json:"oldcontracts"`
+
+	ContractOverrides []contractOverridePersist `json:"contractoverrides"`
 }
 
 // persistData returns the data in the Contractor that will be saved to disk.
 func (c *Contractor) persistData() contractorPersist {
 	data := contractorPersist{
+		Alerts:        c.staticAlerter.Alerts(),
 		Allowance:     c.allowance,
 		BlockHeight:   c.blockHeight,
 		CurrentPeriod: c.currentPeriod,
@@ -32,6 +37,12 @@ func (c *Contractor) persistData() contractorPersist {
 	for _, contract := range c.oldContracts {
 		data.OldContracts = append(data.OldContracts, contract)
 	}
+	for id, override := range c.contractOverrides {
+		data.ContractOverrides = append(data.ContractOverrides, contractOverridePersist{
+			ID:               id,
+			contractOverride: override,
+		})
+	}
 	return data
 }
 
@@ -42,6 +53,7 @@ func (c *Contractor) load() error {
 	if err != nil {
 		return err
 	}
+	c.staticAlerter.Load(data.Alerts)
 	c.allowance = data.Allowance
 	c.blockHeight = data.BlockHeight
 	c.currentPeriod = data.CurrentPeriod
@@ -49,6 +61,9 @@ func (c *Contractor) load() error {
 	for _, contract := range data.OldContracts {
 		c.oldContracts[contract.ID] = contract
 	}
+	for _, override := range data.ContractOverrides {
+		c.contractOverrides[override.ID] = override.contractOverride
+	}
 
 	return nil
 }
@@ -114,8 +129,9 @@ func convertPersist(dir string) error {
 		return err
 	}
 
-	// create the contracts directory if it does not yet exist
-	cs, err := proto.NewContractSet(filepath.Join(dir, "contracts"), modules.ProdDependencies)
+	// create the contracts directory if it does not yet exist. Converting the
+	// contracts doesn't record any spending, so nothing is logged.
+	cs, err := proto.NewContractSet(filepath.Join(dir, "contracts"), persist.NewLogger(ioutil.Discard), modules.ProdDependencies)
 	if err != nil {
 		return err
 	}
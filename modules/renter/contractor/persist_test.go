@@ -9,6 +9,7 @@ import (
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/modules/renter/proto"
+	"github.com/NebulousLabs/Sia/persist"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -22,7 +23,8 @@ func (m memPersist) load(data *contractorPersist) error { *data = contractorPers
 func TestSaveLoad(t *testing.T) {
 	// create contractor with mocked persist dependency
 	c := &Contractor{
-		persist: new(memPersist),
+		persist:       new(memPersist),
+		staticAlerter: modules.NewAlerter("contractor"),
 	}
 
 	c.oldContracts = map[types.FileContractID]modules.RenterContract{
@@ -30,6 +32,11 @@ func TestSaveLoad(t *testing.T) {
 		{1}: {ID: types.FileContractID{1}, HostPublicKey: types.SiaPublicKey{Key: []byte("bar")}},
 		{2}: {ID: types.FileContractID{2}, HostPublicKey: types.SiaPublicKey{Key: []byte("baz")}},
 	}
+	c.contractOverrides = map[types.FileContractID]contractOverride{
+		{0}: {Canceled: true},
+		{1}: {StopRenewing: true},
+	}
+	c.staticAlerter.RegisterAlert(modules.AlertIDRenterAllowanceLow, modules.AlertSeverityWarning, "msg", "cause")
 
 	// save, clear, and reload
 	err := c.save()
@@ -38,6 +45,8 @@ func TestSaveLoad(t *testing.T) {
 	}
 	c.hdb = stubHostDB{}
 	c.oldContracts = make(map[types.FileContractID]modules.RenterContract)
+	c.contractOverrides = make(map[types.FileContractID]contractOverride)
+	c.staticAlerter = modules.NewAlerter("contractor")
 	err = c.load()
 	if err != nil {
 		t.Fatal(err)
@@ -49,6 +58,12 @@ func TestSaveLoad(t *testing.T) {
 	if !ok0 || !ok1 || !ok2 {
 		t.Fatal("oldContracts were not restored properly:", c.oldContracts)
 	}
+	if !c.contractOverrides[types.FileContractID{0}].Canceled || !c.contractOverrides[types.FileContractID{1}].StopRenewing {
+		t.Fatal("contractOverrides were not restored properly:", c.contractOverrides)
+	}
+	if alerts := c.Alerts(); len(alerts) != 1 || alerts[0].ID != modules.AlertIDRenterAllowanceLow {
+		t.Fatal("alerts were not restored properly:", alerts)
+	}
 	// use stdPersist instead of mock
 	c.persist = NewPersist(build.TempDir("contractor", t.Name()))
 	os.MkdirAll(build.TempDir("contractor", t.Name()), 0700)
@@ -59,6 +74,7 @@ func TestSaveLoad(t *testing.T) {
 		t.Fatal(err)
 	}
 	c.oldContracts = make(map[types.FileContractID]modules.RenterContract)
+	c.contractOverrides = make(map[types.FileContractID]contractOverride)
 	err = c.load()
 	if err != nil {
 		t.Fatal(err)
@@ -70,6 +86,9 @@ func TestSaveLoad(t *testing.T) {
 	if !ok0 || !ok1 || !ok2 {
 		t.Fatal("oldContracts were not restored properly:", c.oldContracts)
 	}
+	if !c.contractOverrides[types.FileContractID{0}].Canceled || !c.contractOverrides[types.FileContractID{1}].StopRenewing {
+		t.Fatal("contractOverrides were not restored properly:", c.contractOverrides)
+	}
 }
 
 // TestConvertPersist tests that contracts previously stored in the
@@ -104,7 +123,7 @@ func TestConvertPersist(t *testing.T) {
 	}
 
 	// load the contracts
-	cs, err := proto.NewContractSet(filepath.Join(dir, "contracts"), modules.ProdDependencies)
+	cs, err := proto.NewContractSet(filepath.Join(dir, "contracts"), persist.NewLogger(ioutil.Discard), modules.ProdDependencies)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -21,6 +21,7 @@ func (c *Contractor) managedArchiveContracts() {
 			id := contract.ID
 			c.mu.Lock()
 			c.oldContracts[id] = contract
+			delete(c.contractOverrides, id)
 			c.mu.Unlock()
 			expired = append(expired, id)
 			c.log.Println("INFO: archived expired contract", id)
@@ -162,6 +162,10 @@ type (
 		staticOffset          uint64 // Offset within the file to start the download.
 		staticSiaPath         string // The path of the siafile at the time the download started.
 
+		// Transfer statistics for the transfer history.
+		transferCost  types.Currency
+		transferHosts map[string]types.SiaPublicKey
+
 		// Retrieval settings for the file.
 		staticLatencyTarget time.Duration // In milliseconds. Lower latency results in lower total system throughput.
 		staticOverdrive     int           // How many extra pieces to download to prevent slow hosts from being a bottleneck.
@@ -178,7 +182,9 @@ type (
 		destination       downloadDestination // The place to write the downloaded data.
 		destinationType   string              // "file", "buffer", "http stream", etc.
 		destinationString string              // The string to report to the user for the destination.
+		excludeHosts      map[string]struct{} // Hosts that are never used for the download, keyed by public key.
 		file              *file               // The file to download.
+		preferHosts       map[string]struct{} // Hosts that are used before any other hosts, keyed by public key.
 
 		latencyTarget time.Duration // Workers above this latency will be automatically put on standby initially.
 		length        uint64        // Length of download. Cannot be 0.
@@ -186,6 +192,8 @@ type (
 		offset        uint64        // Offset within the file to start the download. Must be less than the total filesize.
 		overdrive     int           // How many extra pieces to download to prevent slow hosts from being a bottleneck.
 		priority      uint64        // Files with a higher priority will be downloaded first.
+		tenantCharge  uint64        // Bytes charged to the tenant of the file, which are refunded if the download fails.
+		transferType  string        // The type of the download in the transfer history. Empty if it is not recorded.
 	}
 )
 
@@ -259,10 +267,25 @@ func (r *Renter) DownloadAsync(p modules.RenterDownloadParameters) error {
 	return err
 }
 
+// downloadOverdrive returns the overdrive of a download with the given
+// MaxOverdrive. Zero selects the default overdrive, RenterNoOverdrive
+// disables overdrive.
+func downloadOverdrive(maxOverdrive int) (int, error) {
+	switch {
+	case maxOverdrive == modules.RenterNoOverdrive:
+		return 0, nil
+	case maxOverdrive < 0:
+		return 0, fmt.Errorf("invalid MaxOverdrive %v", maxOverdrive)
+	case maxOverdrive > 0 && maxOverdrive < defaultDownloadOverdrive:
+		return maxOverdrive, nil
+	}
+	return defaultDownloadOverdrive, nil
+}
+
 // managedDownload performs a file download using the passed parameters and
 // returns the download object and an error that indicates if the download
 // setup was successful.
-func (r *Renter) managedDownload(p modules.RenterDownloadParameters) (*download, error) {
+func (r *Renter) managedDownload(p modules.RenterDownloadParameters) (_ *download, err error) {
 	// Lookup the file associated with the nickname.
 	lockID := r.mu.RLock()
 	file, exists := r.files[p.SiaPath]
@@ -296,6 +319,32 @@ func (r *Renter) managedDownload(p modules.RenterDownloadParameters) (*download,
 	if p.Offset < 0 || p.Offset+p.Length > file.size {
 		return nil, fmt.Errorf("offset and length combination invalid, max byte is at index %d", file.size-1)
 	}
+	// Count the download against the budget of the file's tenant. The
+	// download is refunded if it fails.
+	if err := r.managedRecordTenantDownload(p.SiaPath, p.Length); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			r.managedRefundTenantDownload(p.SiaPath, p.Length)
+		}
+	}()
+	// Build the sets of excluded and preferred hosts.
+	excludeHosts := make(map[string]struct{})
+	for _, pk := range p.ExcludeHosts {
+		excludeHosts[string(pk.Key)] = struct{}{}
+	}
+	preferHosts := make(map[string]struct{})
+	for _, pk := range p.PreferHosts {
+		if _, exists := excludeHosts[string(pk.Key)]; exists {
+			return nil, fmt.Errorf("host %v is both excluded and preferred", pk)
+		}
+		preferHosts[string(pk.Key)] = struct{}{}
+	}
+	overdrive, err := downloadOverdrive(p.MaxOverdrive)
+	if err != nil {
+		return nil, err
+	}
 
 	// Instantiate the correct downloadWriter implementation.
 	var dw downloadDestination
@@ -317,14 +366,18 @@ func (r *Renter) managedDownload(p modules.RenterDownloadParameters) (*download,
 		destination:       dw,
 		destinationType:   destinationType,
 		destinationString: p.Destination,
+		excludeHosts:      excludeHosts,
 		file:              file,
+		preferHosts:       preferHosts,
 
 		latencyTarget: 25e3 * time.Millisecond, // TODO: high default until full latency support is added.
 		length:        p.Length,
 		needsMemory:   true,
 		offset:        p.Offset,
-		overdrive:     3, // TODO: moderate default until full overdrive support is added.
+		overdrive:     overdrive,
 		priority:      5, // TODO: moderate default until full priority support is added.
+		tenantCharge:  p.Length,
+		transferType:  modules.RenterTransferDownload,
 	})
 	if err != nil {
 		return nil, err
@@ -389,6 +442,9 @@ func (r *Renter) managedNewDownload(params downloadParams) (*download, error) {
 	params.file.mu.Lock()
 	for id, contract := range params.file.contracts {
 		resolvedKey := r.hostContractor.ResolveIDToPubKey(id)
+		if _, excluded := params.excludeHosts[string(resolvedKey.Key)]; excluded {
+			continue
+		}
 		for _, piece := range contract.Pieces {
 			if piece.Chunk >= minChunk && piece.Chunk <= maxChunk {
 				// Sanity check - the same worker should not have two pieces for
@@ -416,11 +472,13 @@ func (r *Renter) managedNewDownload(params downloadParams) (*download, error) {
 			masterKey:   params.file.masterKey,
 
 			staticChunkIndex: i,
-			staticCacheID:    fmt.Sprintf("%v:%v", d.staticSiaPath, i),
+			staticCacheID:    fmt.Sprintf("%v:%v", params.file.staticUID, i),
 			staticChunkMap:   chunkMaps[i-minChunk],
 			staticChunkSize:  params.file.staticChunkSize(),
 			staticPieceSize:  params.file.pieceSize,
 
+			staticPreferredHosts: params.preferHosts,
+
 			// TODO: 25ms is just a guess for a good default. Really, we want to
 			// set the latency target such that slower workers will pick up the
 			// later chunks, but only if there's a very strong chance that
@@ -473,6 +531,14 @@ func (r *Renter) managedNewDownload(params downloadParams) (*download, error) {
 		default:
 		}
 	}
+
+	// Add the download to the transfer history once it has finished.
+	if params.transferType != "" {
+		go r.threadedRecordDownload(d, params.transferType)
+	}
+	if params.tenantCharge > 0 {
+		go r.threadedRefundFailedTenantDownload(d, params.tenantCharge)
+	}
 	return d, nil
 }
 
@@ -523,7 +589,8 @@ func (r *Renter) DownloadHistory() []modules.DownloadInfo {
 }
 
 // ClearDownloadHistory clears the renter's download history inclusive of the
-// provided before and after timestamps
+// provided before and after timestamps. The downloads are also removed from the
+// transfer history.
 //
 // TODO: This function can be improved by implementing a binary search, the
 // trick will be making the binary search be just as readable while handling
@@ -536,16 +603,21 @@ func (r *Renter) ClearDownloadHistory(after, before time.Time) error {
 	r.downloadHistoryMu.Lock()
 	defer r.downloadHistoryMu.Unlock()
 
-	// Check to confirm there are downloads to clear
-	if len(r.downloadHistory) == 0 {
-		return nil
-	}
-
 	// Timestamp validation
 	if before.Before(after) {
 		return errors.New("before timestamp can not be newer then after timestamp")
 	}
 
+	// Apply the retention to the transfer history.
+	if err := r.transfers.clear(modules.RenterTransferDownload, after, before); err != nil {
+		return errors.AddContext(err, "unable to clear transfer history")
+	}
+
+	// Check to confirm there are downloads to clear
+	if len(r.downloadHistory) == 0 {
+		return nil
+	}
+
 	// Clear download history if both before and after timestamps are zero values
 	if before.Equal(types.EndOfTime) && after.IsZero() {
 		r.downloadHistory = r.downloadHistory[:0]
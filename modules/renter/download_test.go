@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -300,3 +301,72 @@ func checkDownloadHistory(downloads []*download, check []int64) bool {
 	}
 	return true
 }
+
+// TestDownloadPreferredHosts checks that workers of hosts that are not
+// preferred are put on standby until the preferred workers can't provide
+// enough pieces.
+func TestDownloadPreferredHosts(t *testing.T) {
+	rsc, err := NewRSCode(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newWorker := func(key string) *worker {
+		return &worker{contract: modules.RenterContract{HostPublicKey: types.SiaPublicKey{Key: []byte(key)}}}
+	}
+	preferred, other := newWorker("preferred"), newWorker("other")
+	udc := &unfinishedDownloadChunk{
+		erasureCode: rsc,
+		staticChunkMap: map[string]downloadPieceInfo{
+			"preferred": {index: 0},
+			"other":     {index: 1},
+		},
+		staticPreferredHosts:    map[string]struct{}{"preferred": {}},
+		preferredWorkersPending: map[string]struct{}{"preferred": {}},
+		pieceUsage:              make([]bool, rsc.NumPieces()),
+		workersRemaining:        2,
+	}
+
+	// The other worker is put on standby while the preferred worker has not
+	// processed the chunk yet.
+	if other.ownedProcessDownloadChunk(udc) != nil {
+		t.Fatal("worker of a host that is not preferred was registered")
+	}
+	if len(udc.workersStandby) != 1 {
+		t.Fatal("worker of a host that is not preferred was not put on standby")
+	}
+	if preferred.ownedProcessDownloadChunk(udc) == nil {
+		t.Fatal("worker of a preferred host was not registered")
+	}
+
+	// After the preferred worker fails, the other worker is used.
+	udc.managedUnregisterWorker(preferred)
+	if other.ownedProcessDownloadChunk(udc) == nil {
+		t.Fatal("worker of a host that is not preferred was not used after the preferred worker failed")
+	}
+}
+
+// TestDownloadOverdrive checks that a zero MaxOverdrive selects the default
+// overdrive and that RenterNoOverdrive disables overdrive.
+func TestDownloadOverdrive(t *testing.T) {
+	tests := []struct {
+		maxOverdrive int
+		overdrive    int
+	}{
+		{0, defaultDownloadOverdrive},
+		{modules.RenterNoOverdrive, 0},
+		{1, 1},
+		{defaultDownloadOverdrive + 1, defaultDownloadOverdrive},
+	}
+	for _, test := range tests {
+		overdrive, err := downloadOverdrive(test.maxOverdrive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if overdrive != test.overdrive {
+			t.Errorf("MaxOverdrive %v: expected overdrive %v, got %v", test.maxOverdrive, test.overdrive, overdrive)
+		}
+	}
+	if _, err := downloadOverdrive(-2); err == nil {
+		t.Fatal("expected an error for a negative MaxOverdrive")
+	}
+}
@@ -50,6 +50,10 @@ type unfinishedDownloadChunk struct {
 	staticPieceSize   uint64
 	staticWriteOffset int64 // Offset within the writer to write the completed data.
 
+	// Hosts that are asked for pieces before any other hosts, keyed by public
+	// key. If the set is empty, all hosts are treated equally.
+	staticPreferredHosts map[string]struct{}
+
 	// Fetch + Write instructions - read only or otherwise thread safe.
 	staticLatencyTarget time.Duration
 	staticNeedsMemory   bool // Set to true if memory was not pre-allocated for this chunk.
@@ -66,6 +70,11 @@ type unfinishedDownloadChunk struct {
 	workersRemaining  int       // Number of workers still able to fetch the chunk.
 	workersStandby    []*worker // Set of workers that are able to work on this download, but are not needed unless other workers fail.
 
+	// Preferred workers that hold a piece of the chunk, but have not
+	// processed the chunk yet. Other workers are put on standby until they
+	// are needed even if all of these workers fetch a piece.
+	preferredWorkersPending map[string]struct{}
+
 	// Memory management variables.
 	memoryAllocated uint64
 
@@ -133,6 +142,15 @@ func (udc *unfinishedDownloadChunk) managedCleanUp() {
 	}
 }
 
+// managedRemovePendingWorker removes a worker from the set of preferred
+// workers that have not processed the chunk yet. It needs to be called if the
+// worker is removed from the chunk without processing it.
+func (udc *unfinishedDownloadChunk) managedRemovePendingWorker(w *worker) {
+	udc.mu.Lock()
+	delete(udc.preferredWorkersPending, string(w.contract.HostPublicKey.Key))
+	udc.mu.Unlock()
+}
+
 // managedRemoveWorker will decrement a worker from the set of remaining workers
 // in the udc. After a worker has been removed, the udc needs to be cleaned up.
 func (udc *unfinishedDownloadChunk) managedRemoveWorker() {
@@ -124,6 +124,15 @@ func (r *Renter) managedDistributeDownloadChunkToWorkers(udc *unfinishedDownload
 	id := r.mu.Lock()
 	udc.mu.Lock()
 	udc.workersRemaining = len(r.workerPool)
+	udc.preferredWorkersPending = make(map[string]struct{})
+	for _, worker := range r.workerPool {
+		key := string(worker.contract.HostPublicKey.Key)
+		_, preferred := udc.staticPreferredHosts[key]
+		_, hasPiece := udc.staticChunkMap[key]
+		if preferred && hasPiece {
+			udc.preferredWorkersPending[key] = struct{}{}
+		}
+	}
 	udc.mu.Unlock()
 	for _, worker := range r.workerPool {
 		worker.managedQueueDownloadChunk(udc)
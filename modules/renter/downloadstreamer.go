@@ -5,18 +5,27 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"sync/atomic"
 	"time"
 
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+
 	"github.com/NebulousLabs/errors"
 )
 
 type (
-	// streamer is a io.ReadSeeker that can be used to stream downloads from
-	// the sia network.
+	// streamer is a modules.Streamer that can be used to stream downloads
+	// from the sia network.
 	streamer struct {
 		file   *file
 		offset int64
 		r      *Renter
+
+		// The downloads of the stream are added to the transfer history as a
+		// single transfer when the stream is closed.
+		transfer      modules.RenterTransfer
+		transferHosts map[string]types.SiaPublicKey
 	}
 )
 
@@ -31,9 +40,9 @@ func min(values ...uint64) uint64 {
 	return min
 }
 
-// Streamer creates an io.ReadSeeker that can be used to stream downloads from
-// the sia network.
-func (r *Renter) Streamer(siaPath string) (string, io.ReadSeeker, error) {
+// Streamer creates a modules.Streamer that can be used to stream downloads
+// from the sia network.
+func (r *Renter) Streamer(siaPath string) (string, modules.Streamer, error) {
 	// Lookup the file associated with the nickname.
 	lockID := r.mu.RLock()
 	file, exists := r.files[siaPath]
@@ -43,8 +52,9 @@ func (r *Renter) Streamer(siaPath string) (string, io.ReadSeeker, error) {
 	}
 	// Create the streamer
 	s := &streamer{
-		file: file,
-		r:    r,
+		file:          file,
+		r:             r,
+		transferHosts: make(map[string]types.SiaPublicKey),
 	}
 	return file.name, s, nil
 }
@@ -71,6 +81,15 @@ func (s *streamer) Read(p []byte) (n int, err error) {
 	remainingChunk := chunkSize - uint64(s.offset)%chunkSize
 	length := min(remainingData, requestedData, remainingChunk)
 
+	// Count the download against the budget of the file's tenant. The
+	// download is refunded if it fails.
+	s.file.mu.RLock()
+	siaPath := s.file.name
+	s.file.mu.RUnlock()
+	if err := s.r.managedRecordTenantDownload(siaPath, length); err != nil {
+		return 0, err
+	}
+
 	// Download data
 	buffer := bytes.NewBuffer([]byte{})
 	d, err := s.r.managedNewDownload(downloadParams{
@@ -87,6 +106,7 @@ func (s *streamer) Read(p []byte) (n int, err error) {
 		priority:      1000, // TODO: high default until full priority support is added.
 	})
 	if err != nil {
+		s.r.managedRefundTenantDownload(siaPath, length)
 		return 0, errors.AddContext(err, "failed to create new download")
 	}
 
@@ -99,10 +119,14 @@ func (s *streamer) Read(p []byte) (n int, err error) {
 	// Block until the download has completed.
 	select {
 	case <-d.completeChan:
+		s.recordDownload(d)
 		if d.Err() != nil {
+			s.r.managedRefundTenantDownload(siaPath, length)
 			return 0, errors.AddContext(d.Err(), "download failed")
 		}
 	case <-s.r.tg.StopChan():
+		s.recordDownload(d)
+		s.r.managedRefundTenantDownload(siaPath, length)
 		return 0, errors.New("download interrupted by shutdown")
 	}
 
@@ -139,3 +163,39 @@ func (s *streamer) Seek(offset int64, whence int) (int64, error) {
 	s.offset = newOffset
 	return s.offset, nil
 }
+
+// recordDownload adds a download of the stream to the stream's transfer.
+func (s *streamer) recordDownload(d *download) {
+	if s.transfer.StartTime.IsZero() {
+		s.transfer.StartTime = d.staticStartTime
+	}
+	hosts, cost := d.managedTransferStats()
+	for _, host := range hosts {
+		s.transferHosts[host.String()] = host
+	}
+	s.transfer.Bytes += atomic.LoadUint64(&d.atomicTotalDataTransferred)
+	s.transfer.Cost = s.transfer.Cost.Add(cost)
+	if err := d.Err(); err != nil {
+		s.transfer.Error = err.Error()
+	}
+}
+
+// Close adds the stream to the transfer history, unless nothing was
+// downloaded.
+func (s *streamer) Close() error {
+	if s.transfer.StartTime.IsZero() {
+		return nil
+	}
+	s.file.mu.RLock()
+	s.transfer.SiaPath = s.file.name
+	s.file.mu.RUnlock()
+	s.transfer.Type = modules.RenterTransferDownload
+	s.transfer.EndTime = time.Now()
+	for _, host := range s.transferHosts {
+		s.transfer.Hosts = append(s.transfer.Hosts, host)
+	}
+	transfer := s.transfer
+	s.transfer = modules.RenterTransfer{}
+	s.transferHosts = make(map[string]types.SiaPublicKey)
+	return errors.AddContext(s.r.transfers.record(transfer), "unable to record stream in transfer history")
+}
@@ -39,6 +39,13 @@ type file struct {
 	mode        uint32               // actually an os.FileMode
 	deleted     bool                 // indicates if the file has been deleted.
 
+	// reencode is the new layout of the file while the file is being
+	// re-encoded with different erasure code settings. isReencode indicates
+	// that the file is such a new layout, which is saved separately from the
+	// file until it is fully uploaded.
+	reencode   *file
+	isReencode bool
+
 	staticUID string // A UID assigned to the file when it gets created.
 
 	mu sync.RWMutex
@@ -89,6 +96,19 @@ func (f *file) numChunks() uint64 {
 	return n
 }
 
+// chunkDataSize returns the number of bytes of file data in a chunk. Only the
+// last chunk of a file can be smaller than the chunk size.
+func (f *file) chunkDataSize(chunkIndex uint64) uint64 {
+	start := chunkIndex * f.staticChunkSize()
+	if start >= f.size {
+		return 0
+	}
+	if f.size-start < f.staticChunkSize() {
+		return f.size - start
+	}
+	return f.staticChunkSize()
+}
+
 // available indicates whether the file is ready to be downloaded.
 func (f *file) available(offline map[types.FileContractID]bool) bool {
 	chunkPieces := make([]int, f.numChunks())
@@ -262,11 +282,29 @@ func (r *Renter) DeleteFile(nickname string) error {
 	delete(r.files, nickname)
 	delete(r.persist.Tracking, nickname)
 
+	// mark the pieces of the file for garbage collection.
+	f.mu.RLock()
+	r.addGCCandidates(f)
+	reencode := f.reencode
+	f.mu.RUnlock()
+
 	err := persist.RemoveFile(filepath.Join(r.persistDir, f.name+ShareExtension))
 	if err != nil {
 		r.log.Println("WARN: couldn't remove file :", err)
 	}
 
+	// abandon the new layout of the file if it is being re-encoded.
+	if reencode != nil {
+		reencode.mu.Lock()
+		r.addGCCandidates(reencode)
+		reencode.deleted = true
+		reencode.mu.Unlock()
+		err := persist.RemoveFile(filepath.Join(r.persistDir, f.name+reencodeExtension))
+		if err != nil {
+			r.log.Println("WARN: couldn't remove re-encoded file :", err)
+		}
+	}
+
 	r.saveSync()
 	r.mu.Unlock(lockID)
 
@@ -276,9 +314,6 @@ func (r *Renter) DeleteFile(nickname string) error {
 
 	// mark the file as deleted
 	f.deleted = true
-
-	// TODO: delete the sectors of the file as well.
-
 	return nil
 }
 
@@ -324,15 +359,17 @@ func (r *Renter) FileList() []modules.FileInfo {
 			localPath = tf.RepairPath
 		}
 		fileList = append(fileList, modules.FileInfo{
-			SiaPath:        f.name,
-			LocalPath:      localPath,
-			Filesize:       f.size,
-			Renewing:       renewing,
-			Available:      f.available(offline),
-			Redundancy:     f.redundancy(offline, goodForRenew),
-			UploadedBytes:  f.uploadedBytes(),
-			UploadProgress: f.uploadProgress(),
-			Expiration:     f.expiration(),
+			SiaPath:          f.name,
+			LocalPath:        localPath,
+			Filesize:         f.size,
+			Renewing:         renewing,
+			Available:        f.available(offline),
+			Redundancy:       f.redundancy(offline, goodForRenew),
+			UploadedBytes:    f.uploadedBytes(),
+			UploadProgress:   f.uploadProgress(),
+			Expiration:       f.expiration(),
+			Reencoding:       f.reencode != nil,
+			ReencodeProgress: f.reencodeProgress(),
 		})
 		f.mu.RUnlock()
 		r.mu.RUnlock(lockID)
@@ -381,15 +418,17 @@ func (r *Renter) File(siaPath string) (modules.FileInfo, error) {
 		localPath = tf.RepairPath
 	}
 	fileInfo = modules.FileInfo{
-		SiaPath:        file.name,
-		LocalPath:      localPath,
-		Filesize:       file.size,
-		Renewing:       renewing,
-		Available:      file.available(offline),
-		Redundancy:     file.redundancy(offline, goodForRenew),
-		UploadedBytes:  file.uploadedBytes(),
-		UploadProgress: file.uploadProgress(),
-		Expiration:     file.expiration(),
+		SiaPath:          file.name,
+		LocalPath:        localPath,
+		Filesize:         file.size,
+		Renewing:         renewing,
+		Available:        file.available(offline),
+		Redundancy:       file.redundancy(offline, goodForRenew),
+		UploadedBytes:    file.uploadedBytes(),
+		UploadProgress:   file.uploadProgress(),
+		Expiration:       file.expiration(),
+		Reencoding:       file.reencode != nil,
+		ReencodeProgress: file.reencodeProgress(),
 	}
 
 	return fileInfo, nil
@@ -421,6 +460,12 @@ func (r *Renter) RenameFile(currentName, newName string) error {
 	file.mu.Lock()
 	file.name = newName
 	err = r.saveFile(file)
+	if err == nil && file.reencode != nil {
+		file.reencode.mu.Lock()
+		file.reencode.name = newName
+		err = r.saveFile(file.reencode)
+		file.reencode.mu.Unlock()
+	}
 	file.mu.Unlock()
 	if err != nil {
 		return err
@@ -438,7 +483,10 @@ func (r *Renter) RenameFile(currentName, newName string) error {
 		return err
 	}
 
-	// Delete the old .sia file.
+	// Delete the files that were saved under the old name.
 	oldPath := filepath.Join(r.persistDir, currentName+ShareExtension)
-	return os.RemoveAll(oldPath)
+	if err := os.RemoveAll(oldPath); err != nil {
+		return err
+	}
+	return os.RemoveAll(filepath.Join(r.persistDir, currentName+reencodeExtension))
 }
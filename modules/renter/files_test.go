@@ -36,6 +36,18 @@ func TestFileNumChunks(t *testing.T) {
 	}
 }
 
+// TestFileChunkDataSize checks that only the last chunk of a file can hold
+// less file data than the chunk size.
+func TestFileChunkDataSize(t *testing.T) {
+	rsc, _ := NewRSCode(2, 1)
+	f := &file{size: 45, erasureCode: rsc, pieceSize: 10}
+	for i, exp := range []uint64{20, 20, 5, 0} {
+		if size := f.chunkDataSize(uint64(i)); size != exp {
+			t.Errorf("chunk %v: expected %v, got %v", i, exp, size)
+		}
+	}
+}
+
 // TestFileAvailable probes the available method of the file type.
 func TestFileAvailable(t *testing.T) {
 	rsc, _ := NewRSCode(1, 10)
@@ -0,0 +1,236 @@
+package renter
+
+// gc.go deletes the sectors of deleted files from the renter's contracts, so
+// that the renter no longer pays for storing them when the contracts are
+// renewed. The hosts do not refund storage that was already paid for.
+//
+// When a file is deleted, the roots of its pieces are persisted as candidates
+// for garbage collection. The candidates are only deleted from a host if no
+// other file references the same root on that host, which keeps sectors that
+// are shared between files alive.
+
+import (
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// addGCCandidates marks the pieces of a deleted file as candidates for garbage
+// collection. The renter and the file need to be locked.
+func (r *Renter) addGCCandidates(f *file) {
+	if r.persist.GCCandidates == nil {
+		r.persist.GCCandidates = make(map[string][]crypto.Hash)
+	}
+	for fcid, fc := range f.contracts {
+		hpk := r.hostContractor.ResolveIDToPubKey(fcid)
+		for _, piece := range fc.Pieces {
+			r.persist.GCCandidates[hpk.String()] = append(r.persist.GCCandidates[hpk.String()], piece.MerkleRoot)
+		}
+	}
+}
+
+// managedAddGCCandidate marks a sector that is not part of any file as a
+// candidate for garbage collection.
+func (r *Renter) managedAddGCCandidate(hpk types.SiaPublicKey, root crypto.Hash) {
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+	if r.persist.GCCandidates == nil {
+		r.persist.GCCandidates = make(map[string][]crypto.Hash)
+	}
+	r.persist.GCCandidates[hpk.String()] = append(r.persist.GCCandidates[hpk.String()], root)
+	if err := r.saveSync(); err != nil {
+		r.log.Println("WARN: unable to save garbage collection candidates:", err)
+	}
+}
+
+// managedGCTargets returns the candidates for garbage collection that are not
+// referenced by any file, indexed by the hosts' public keys. Candidates that
+// are referenced again are no longer considered for garbage collection.
+func (r *Renter) managedGCTargets() map[string][]crypto.Hash {
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+	if len(r.persist.GCCandidates) == 0 {
+		return nil
+	}
+
+	// Find the candidates that are referenced by the remaining files.
+	referenced := make(map[string]map[crypto.Hash]struct{})
+	resolved := make(map[types.FileContractID]string)
+	for _, f := range r.files {
+		f.mu.RLock()
+		for fcid, fc := range f.contracts {
+			hpk, ok := resolved[fcid]
+			if !ok {
+				pk := r.hostContractor.ResolveIDToPubKey(fcid)
+				hpk = pk.String()
+				resolved[fcid] = hpk
+			}
+			if _, ok := r.persist.GCCandidates[hpk]; !ok {
+				continue
+			}
+			if referenced[hpk] == nil {
+				referenced[hpk] = make(map[crypto.Hash]struct{})
+			}
+			for _, piece := range fc.Pieces {
+				referenced[hpk][piece.MerkleRoot] = struct{}{}
+			}
+		}
+		f.mu.RUnlock()
+	}
+
+	// Drop the referenced and duplicate candidates.
+	targets := make(map[string][]crypto.Hash)
+	for hpk, roots := range r.persist.GCCandidates {
+		seen := make(map[crypto.Hash]struct{})
+		var unreferenced []crypto.Hash
+		for _, root := range roots {
+			_, isReferenced := referenced[hpk][root]
+			_, isSeen := seen[root]
+			if isReferenced || isSeen {
+				continue
+			}
+			seen[root] = struct{}{}
+			unreferenced = append(unreferenced, root)
+		}
+		if len(unreferenced) == 0 {
+			delete(r.persist.GCCandidates, hpk)
+			continue
+		}
+		r.persist.GCCandidates[hpk] = unreferenced
+		targets[hpk] = append([]crypto.Hash(nil), unreferenced...)
+	}
+	if err := r.saveSync(); err != nil {
+		r.log.Println("WARN: unable to save garbage collection candidates:", err)
+	}
+	return targets
+}
+
+// managedRemoveGCCandidates removes roots from the candidates for garbage
+// collection of a host and adds the reclaimed storage to the total.
+func (r *Renter) managedRemoveGCCandidates(hostKey string, roots []crypto.Hash, reclaimed uint64) {
+	remove := make(map[crypto.Hash]struct{}, len(roots))
+	for _, root := range roots {
+		remove[root] = struct{}{}
+	}
+
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+	var remaining []crypto.Hash
+	for _, root := range r.persist.GCCandidates[hostKey] {
+		if _, ok := remove[root]; !ok {
+			remaining = append(remaining, root)
+		}
+	}
+	if len(remaining) == 0 {
+		delete(r.persist.GCCandidates, hostKey)
+	} else {
+		r.persist.GCCandidates[hostKey] = remaining
+	}
+	r.persist.ReclaimedBytes += reclaimed
+	if err := r.saveSync(); err != nil {
+		r.log.Println("WARN: unable to save garbage collection candidates:", err)
+	}
+}
+
+// managedCollectGarbage deletes sectors from the contract with a host in
+// batches. Sectors that could not be deleted, e.g. because the host is
+// offline, remain candidates and are retried later.
+func (r *Renter) managedCollectGarbage(hostKey string, roots []crypto.Hash) {
+	var hpk types.SiaPublicKey
+	hpk.LoadString(hostKey)
+	if len(hpk.Key) == 0 {
+		r.log.Println("WARN: invalid host key in garbage collection candidates:", hostKey)
+		r.managedRemoveGCCandidates(hostKey, roots, 0)
+		return
+	}
+	// If the renter no longer has a contract with the host, the host is no
+	// longer storing the sectors for the renter.
+	if _, ok := r.hostContractor.ContractByPublicKey(hpk); !ok {
+		r.managedRemoveGCCandidates(hostKey, roots, 0)
+		return
+	}
+	if r.hostContractor.IsOffline(hpk) {
+		return
+	}
+
+	// The editor is shared with the upload workers and the contract set only
+	// allows a single revision of a contract at a time, so deletions never
+	// interfere with uploads.
+	editor, err := r.hostContractor.Editor(hpk, r.tg.StopChan())
+	if err != nil {
+		r.log.Debugf("Unable to delete sectors from host %v: %v", hpk, err)
+		return
+	}
+	defer editor.Close()
+
+	for len(roots) > 0 {
+		batch := roots
+		if len(batch) > gcBatchSize {
+			batch = batch[:gcBatchSize]
+		}
+		deleted, err := editor.Delete(batch)
+		if err != nil {
+			r.log.Debugf("Unable to delete sectors from host %v: %v", hpk, err)
+			return
+		}
+		reclaimed := uint64(deleted) * modules.SectorSize
+		r.managedRemoveGCCandidates(hostKey, batch, reclaimed)
+		r.log.Debugf("Deleted %v sectors from host %v, reclaiming %v bytes", deleted, hpk, reclaimed)
+		roots = roots[len(batch):]
+
+		// Return if the renter has shut down.
+		select {
+		case <-r.tg.StopChan():
+			return
+		default:
+		}
+	}
+}
+
+// threadedCollectGarbage is a background thread that periodically deletes the
+// sectors of deleted files from the hosts.
+func (r *Renter) threadedCollectGarbage() {
+	err := r.tg.Add()
+	if err != nil {
+		return
+	}
+	defer r.tg.Done()
+
+	for {
+		select {
+		case <-r.tg.StopChan():
+			return
+		case <-time.After(gcInterval):
+		}
+		if !r.g.Online() {
+			continue
+		}
+
+		for hostKey, roots := range r.managedGCTargets() {
+			r.managedCollectGarbage(hostKey, roots)
+
+			// Return if the renter has shut down.
+			select {
+			case <-r.tg.StopChan():
+				return
+			default:
+			}
+		}
+	}
+}
+
+// GarbageCollection returns the progress of deleting the sectors of deleted
+// files from the hosts.
+func (r *Renter) GarbageCollection() modules.RenterGarbageCollection {
+	id := r.mu.RLock()
+	defer r.mu.RUnlock(id)
+	gc := modules.RenterGarbageCollection{
+		ReclaimedBytes: r.persist.ReclaimedBytes,
+	}
+	for _, roots := range r.persist.GCCandidates {
+		gc.PendingSectors += uint64(len(roots))
+	}
+	return gc
+}
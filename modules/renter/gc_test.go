@@ -0,0 +1,83 @@
+package renter
+
+import (
+	"os"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	siasync "github.com/NebulousLabs/Sia/sync"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestGCTargets checks that the pieces of deleted files are only collected if
+// they are not referenced by other files.
+func TestGCTargets(t *testing.T) {
+	dir := build.TempDir("renter", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	hostA := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte("hostA")}
+	hostB := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte("hostB")}
+	r := &Renter{
+		files: make(map[string]*file),
+		hostContractor: mapContractor{hosts: map[types.FileContractID]types.SiaPublicKey{
+			{1}: hostA,
+			{2}: hostB,
+		}},
+		mu:         siasync.New(modules.SafeMutexDelay, 1),
+		persistDir: dir,
+	}
+
+	// Create a file on both hosts and a file that shares a piece with it on
+	// the first host.
+	newFile := func(name string, contracts map[types.FileContractID][]crypto.Hash) *file {
+		f := &file{name: name, contracts: make(map[types.FileContractID]fileContract)}
+		for id, roots := range contracts {
+			fc := fileContract{ID: id}
+			for _, root := range roots {
+				fc.Pieces = append(fc.Pieces, pieceData{MerkleRoot: root})
+			}
+			f.contracts[id] = fc
+		}
+		r.files[name] = f
+		return f
+	}
+	a, b, c := crypto.Hash{1}, crypto.Hash{2}, crypto.Hash{3}
+	deleted := newFile("deleted", map[types.FileContractID][]crypto.Hash{
+		{1}: {a, b},
+		{2}: {c},
+	})
+	newFile("shared", map[types.FileContractID][]crypto.Hash{
+		{1}: {b},
+	})
+
+	// Delete the first file.
+	delete(r.files, deleted.name)
+	r.addGCCandidates(deleted)
+	if gc := r.GarbageCollection(); gc.PendingSectors != 3 {
+		t.Fatal("expected 3 pending sectors, got", gc.PendingSectors)
+	}
+
+	// Only the pieces that are not shared should be collected.
+	targets := r.managedGCTargets()
+	if len(targets) != 2 {
+		t.Fatal("expected 2 hosts, got", len(targets))
+	}
+	if roots := targets[hostA.String()]; len(roots) != 1 || roots[0] != a {
+		t.Fatal("wrong targets for first host:", roots)
+	}
+	if roots := targets[hostB.String()]; len(roots) != 1 || roots[0] != c {
+		t.Fatal("wrong targets for second host:", roots)
+	}
+	if gc := r.GarbageCollection(); gc.PendingSectors != 2 {
+		t.Fatal("expected 2 pending sectors, got", gc.PendingSectors)
+	}
+
+	// Collect the pieces of the first host.
+	r.managedRemoveGCCandidates(hostA.String(), targets[hostA.String()], modules.SectorSize)
+	if gc := r.GarbageCollection(); gc.PendingSectors != 1 || gc.ReclaimedBytes != modules.SectorSize {
+		t.Fatal("wrong garbage collection status:", gc)
+	}
+}
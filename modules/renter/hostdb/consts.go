@@ -7,6 +7,20 @@ import (
 )
 
 const (
+	// auditFailureWeight is the number of failed interactions that a single
+	// failed storage audit counts as. A failed audit means that the host lost
+	// data, which is much worse than a failed interaction.
+	auditFailureWeight = 10
+
+	// benchmarkLatencyTarget is the round trip latency that a host needs to
+	// stay below to avoid being penalized by the performance adjustment.
+	benchmarkLatencyTarget = 250 * time.Millisecond
+
+	// benchmarkThroughputTarget is the upload and download throughput in bytes
+	// per second that a host needs to reach to avoid being penalized by the
+	// performance adjustment.
+	benchmarkThroughputTarget = 1e6
+
 	// historicInteractionDecay defines the decay of the HistoricSuccessfulInteractions
 	// and HistoricFailedInteractions after every block for a host entry.
 	historicInteractionDecay = 0.9995
@@ -30,6 +44,10 @@ const (
 	// allowed to be before being ignored as a DoS attempt.
 	maxSettingsLen = 10e3
 
+	// maxBenchmarkHistory is the number of benchmarks that are kept for each
+	// host. Older benchmarks are dropped.
+	maxBenchmarkHistory = 10
+
 	// minScans specifies the number of scans that a host should have before the
 	// scans start getting compressed.
 	minScans = 12
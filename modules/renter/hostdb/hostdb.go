@@ -25,6 +25,7 @@ var (
 	ErrInitialScanIncomplete = errors.New("initial hostdb scan is not yet completed")
 	errNilCS                 = errors.New("cannot create hostdb with nil consensus set")
 	errNilGateway            = errors.New("cannot create hostdb with nil gateway")
+	errNoSuchHost            = errors.New("no host with the provided public key exists in the hostdb")
 )
 
 // The HostDB is a database of potential hosts. It assigns a weight to each
@@ -56,6 +57,10 @@ type HostDB struct {
 	scanWait             bool
 	scanningThreads      int
 
+	// The allowance is set by the contractor and is used to filter out hosts
+	// whose prices exceed the limits set by the user.
+	allowance modules.Allowance
+
 	blockHeight types.BlockHeight
 	lastChange  modules.ConsensusChangeID
 }
@@ -259,13 +264,62 @@ func (hdb *HostDB) InitialScanComplete() (complete bool, err error) {
 
 // RandomHosts implements the HostDB interface's RandomHosts() method. It takes
 // a number of hosts to return, and a slice of netaddresses to ignore, and
-// returns a slice of entries.
+// returns a slice of entries. Hosts that violate the price limits of the
+// allowance are never returned.
 func (hdb *HostDB) RandomHosts(n int, excludeKeys []types.SiaPublicKey) ([]modules.HostDBEntry, error) {
 	hdb.mu.RLock()
 	initialScanComplete := hdb.initialScanComplete
+	allowance := hdb.allowance
 	hdb.mu.RUnlock()
 	if !initialScanComplete {
 		return []modules.HostDBEntry{}, ErrInitialScanIncomplete
 	}
-	return hdb.hostTree.SelectRandom(n, excludeKeys), nil
+
+	// Keep selecting hosts until enough hosts within the price limits of the
+	// allowance have been found, or until the tree runs out of hosts. Every
+	// host that has been looked at is added to the exclude list so that it is
+	// not selected twice.
+	hosts := []modules.HostDBEntry{}
+	exclude := append([]types.SiaPublicKey(nil), excludeKeys...)
+	for len(hosts) < n {
+		needed := n - len(hosts)
+		selected := hdb.hostTree.SelectRandom(needed, exclude)
+		for _, host := range selected {
+			exclude = append(exclude, host.PublicKey)
+			if allowance.CheckHostPrices(host.HostExternalSettings) != nil {
+				continue
+			}
+			hosts = append(hosts, host)
+		}
+		if len(selected) < needed {
+			break
+		}
+	}
+	return hosts, nil
+}
+
+// SetAllowance updates the allowance used by the hostdb to filter out hosts
+// that are too expensive and to weigh the prices of hosts against the expected
+// usage of the renter.
+func (hdb *HostDB) SetAllowance(allowance modules.Allowance) error {
+	if err := hdb.tg.Add(); err != nil {
+		return err
+	}
+	defer hdb.tg.Done()
+	hdb.mu.Lock()
+	defer hdb.mu.Unlock()
+
+	// The price adjustments depend on the expected usage of the allowance.
+	// If the expectations changed, the weights of all hosts in the tree need
+	// to be recomputed.
+	oldAllowance := hdb.allowance
+	hdb.allowance = allowance
+	if oldAllowance.Hosts == allowance.Hosts && oldAllowance.Period == allowance.Period &&
+		oldAllowance.ExpectedStorage == allowance.ExpectedStorage &&
+		oldAllowance.ExpectedUpload == allowance.ExpectedUpload &&
+		oldAllowance.ExpectedDownload == allowance.ExpectedDownload &&
+		oldAllowance.ExpectedRedundancy == allowance.ExpectedRedundancy {
+		return nil
+	}
+	return hdb.hostTree.SetWeightFunction(hdb.calculateHostWeight)
 }
@@ -583,3 +583,112 @@ func TestUpdateHistoricInteractions(t *testing.T) {
 			host.HistoricFailedInteractions, host.HistoricSuccessfulInteractions)
 	}
 }
+
+// TestRandomHostsAllowanceLimits checks that RandomHosts does not return hosts
+// that violate the price limits of the allowance.
+func TestRandomHostsAllowanceLimits(t *testing.T) {
+	hdb := bareHostDB()
+	hdb.initialScanComplete = true
+
+	// Insert a mix of cheap and expensive hosts.
+	cheapPrice := types.NewCurrency64(10)
+	expensivePrice := types.NewCurrency64(1000)
+	for i := 0; i < 20; i++ {
+		entry := makeHostDBEntry()
+		entry.StoragePrice = cheapPrice
+		if i%2 == 0 {
+			entry.StoragePrice = expensivePrice
+		}
+		if err := hdb.hostTree.Insert(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Without limits, all hosts should be returned.
+	hosts, err := hdb.RandomHosts(20, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 20 {
+		t.Fatalf("expected %v hosts, got %v", 20, len(hosts))
+	}
+
+	// With a storage price limit, only the cheap hosts should be returned.
+	err = hdb.SetAllowance(modules.Allowance{MaxStoragePrice: cheapPrice})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts, err = hdb.RandomHosts(20, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 10 {
+		t.Fatalf("expected %v hosts, got %v", 10, len(hosts))
+	}
+	for _, host := range hosts {
+		if host.StoragePrice.Cmp(cheapPrice) > 0 {
+			t.Fatal("RandomHosts returned a host that exceeds the allowance limits")
+		}
+	}
+}
+
+// TestRecordBenchmark checks that benchmarks are added to the host entry and
+// that the benchmark history is capped.
+func TestRecordBenchmark(t *testing.T) {
+	hdb := bareHostDB()
+	entry := makeHostDBEntry()
+	if err := hdb.hostTree.Insert(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	// Recording a benchmark for an unknown host should fail.
+	if err := hdb.RecordBenchmark(makeHostDBEntry().PublicKey, modules.HostDBBenchmark{}); err != errNoSuchHost {
+		t.Fatal("expected errNoSuchHost, got", err)
+	}
+
+	for i := 0; i < maxBenchmarkHistory+5; i++ {
+		err := hdb.RecordBenchmark(entry.PublicKey, modules.HostDBBenchmark{
+			UploadThroughput: uint64(i),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	host, ok := hdb.hostTree.Select(entry.PublicKey)
+	if !ok {
+		t.Fatal("host not found")
+	}
+	if len(host.BenchmarkHistory) != maxBenchmarkHistory {
+		t.Fatal("wrong benchmark history length:", len(host.BenchmarkHistory))
+	}
+	if host.BenchmarkHistory[0].UploadThroughput != 5 || host.BenchmarkHistory[maxBenchmarkHistory-1].UploadThroughput != maxBenchmarkHistory+4 {
+		t.Fatal("wrong benchmarks were kept:", host.BenchmarkHistory)
+	}
+}
+
+// TestRecordAudit checks that audit results are counted in the host entry.
+func TestRecordAudit(t *testing.T) {
+	hdb := bareHostDB()
+	entry := makeHostDBEntry()
+	if err := hdb.hostTree.Insert(entry); err != nil {
+		t.Fatal(err)
+	}
+
+	// Recording an audit for an unknown host should fail.
+	if err := hdb.RecordAudit(makeHostDBEntry().PublicKey, true); err != errNoSuchHost {
+		t.Fatal("expected errNoSuchHost, got", err)
+	}
+
+	for _, passed := range []bool{true, true, false} {
+		if err := hdb.RecordAudit(entry.PublicKey, passed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	host, ok := hdb.hostTree.Select(entry.PublicKey)
+	if !ok {
+		t.Fatal("host not found")
+	}
+	if host.SuccessfulAudits != 2 || host.FailedAudits != 1 {
+		t.Fatalf("wrong audit counts: %v successful, %v failed", host.SuccessfulAudits, host.FailedAudits)
+	}
+}
@@ -112,3 +112,51 @@ func (hdb *HostDB) IncrementFailedInteractions(key types.SiaPublicKey) {
 	host.RecentFailedInteractions++
 	hdb.hostTree.Modify(host)
 }
+
+// RecordBenchmark adds a benchmark to the benchmark history of the host with
+// the given key. Only the most recent maxBenchmarkHistory benchmarks are kept.
+func (hdb *HostDB) RecordBenchmark(key types.SiaPublicKey, benchmark modules.HostDBBenchmark) error {
+	if err := hdb.tg.Add(); err != nil {
+		return err
+	}
+	defer hdb.tg.Done()
+	hdb.mu.Lock()
+	defer hdb.mu.Unlock()
+
+	// Fetch the host.
+	host, haveHost := hdb.hostTree.Select(key)
+	if !haveHost {
+		return errNoSuchHost
+	}
+
+	// Append the benchmark and drop the oldest ones if necessary.
+	host.BenchmarkHistory = append(host.BenchmarkHistory, benchmark)
+	if len(host.BenchmarkHistory) > maxBenchmarkHistory {
+		host.BenchmarkHistory = host.BenchmarkHistory[len(host.BenchmarkHistory)-maxBenchmarkHistory:]
+	}
+	return hdb.hostTree.Modify(host)
+}
+
+// RecordAudit records the result of a storage audit of the host with the
+// given key.
+func (hdb *HostDB) RecordAudit(key types.SiaPublicKey, passed bool) error {
+	if err := hdb.tg.Add(); err != nil {
+		return err
+	}
+	defer hdb.tg.Done()
+	hdb.mu.Lock()
+	defer hdb.mu.Unlock()
+
+	// Fetch the host.
+	host, haveHost := hdb.hostTree.Select(key)
+	if !haveHost {
+		return errNoSuchHost
+	}
+
+	if passed {
+		host.SuccessfulAudits++
+	} else {
+		host.FailedAudits++
+	}
+	return hdb.hostTree.Modify(host)
+}
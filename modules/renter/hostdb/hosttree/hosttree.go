@@ -208,7 +208,12 @@ func (ht *HostTree) All() []modules.HostDBEntry {
 func (ht *HostTree) Insert(hdbe modules.HostDBEntry) error {
 	ht.mu.Lock()
 	defer ht.mu.Unlock()
+	return ht.insert(hdbe)
+}
 
+// insert inserts the entry provided to `entry` into the host tree. The caller
+// must hold the lock of the tree.
+func (ht *HostTree) insert(hdbe modules.HostDBEntry) error {
 	entry := &hostEntry{
 		HostDBEntry: hdbe,
 		weight:      ht.weightFn(hdbe),
@@ -263,6 +268,31 @@ func (ht *HostTree) Modify(hdbe modules.HostDBEntry) error {
 	return nil
 }
 
+// SetWeightFunction replaces the weight function of the tree and recomputes
+// the weights of all the hosts in the tree.
+func (ht *HostTree) SetWeightFunction(wf WeightFunc) error {
+	ht.mu.Lock()
+	defer ht.mu.Unlock()
+
+	// Grab all of the entries, then rebuild the tree from scratch using the
+	// new weight function.
+	var entries []modules.HostDBEntry
+	for _, node := range ht.hosts {
+		entries = append(entries, node.entry.HostDBEntry)
+	}
+	ht.root = &node{
+		count: 1,
+	}
+	ht.hosts = make(map[string]*node)
+	ht.weightFn = wf
+	for _, entry := range entries {
+		if err := ht.insert(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Select returns the host with the provided public key, should the host exist.
 func (ht *HostTree) Select(spk types.SiaPublicKey) (modules.HostDBEntry, bool) {
 	ht.mu.Lock()
@@ -281,6 +281,42 @@ func TestHostTreeModify(t *testing.T) {
 	}
 }
 
+// TestHostTreeSetWeightFunction checks that replacing the weight function
+// recomputes the weights of all hosts in the tree.
+func TestHostTreeSetWeightFunction(t *testing.T) {
+	tree := New(func(dbe modules.HostDBEntry) types.Currency {
+		return types.NewCurrency64(10)
+	})
+
+	treeSize := 50
+	for i := 0; i < treeSize; i++ {
+		if err := tree.Insert(makeHostDBEntry()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := verifyTree(tree, treeSize); err != nil {
+		t.Fatal(err)
+	}
+
+	err := tree.SetWeightFunction(func(dbe modules.HostDBEntry) types.Currency {
+		return types.NewCurrency64(20)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyTree(tree, treeSize); err != nil {
+		t.Fatal(err)
+	}
+	if tree.root.weight.Cmp(types.NewCurrency64(uint64(20*treeSize))) != 0 {
+		t.Fatal("tree weight was not recomputed:", tree.root.weight)
+	}
+	for _, node := range tree.hosts {
+		if node.entry.weight.Cmp(types.NewCurrency64(20)) != 0 {
+			t.Fatal("host weight was not recomputed:", node.entry.weight)
+		}
+	}
+}
+
 // TestVariedWeights runs broad statistical tests on selecting hosts with
 // multiple different weights.
 func TestVariedWeights(t *testing.T) {
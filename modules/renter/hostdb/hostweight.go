@@ -3,6 +3,7 @@ package hostdb
 import (
 	"math"
 	"math/big"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
@@ -107,7 +108,8 @@ func (hdb *HostDB) collateralAdjustments(entry modules.HostDBEntry) float64 {
 
 // interactionAdjustments determine the penalty to be applied to a host for the
 // historic and currnet interactions with that host. This function focuses on
-// historic interactions and ignores recent interactions.
+// historic interactions and ignores recent interactions. Storage audits are
+// counted as interactions, with failed audits weighing much more heavily.
 func (hdb *HostDB) interactionAdjustments(entry modules.HostDBEntry) float64 {
 	// Give the host a baseline of 30 successful interactions and 1 failed
 	// interaction. This gives the host a baseline if we've had few
@@ -116,6 +118,10 @@ func (hdb *HostDB) interactionAdjustments(entry modules.HostDBEntry) float64 {
 	hsi := entry.HistoricSuccessfulInteractions + 30
 	hfi := entry.HistoricFailedInteractions + 1
 
+	// Add the storage audits.
+	hsi += float64(entry.SuccessfulAudits)
+	hfi += float64(entry.FailedAudits * auditFailureWeight)
+
 	// Determine the intraction ratio based off of the historic interactions.
 	ratio := float64(hsi) / float64(hsi+hfi)
 
@@ -127,6 +133,43 @@ func (hdb *HostDB) interactionAdjustments(entry modules.HostDBEntry) float64 {
 	return math.Pow(ratio, 15)
 }
 
+// performanceAdjustments will adjust the weight of the entry according to the
+// benchmarks that have been taken of the host. Hosts that have not been
+// benchmarked are not penalized. Hosts that have a higher average latency
+// or a lower average throughput than the targets are penalized.
+func (hdb *HostDB) performanceAdjustments(entry modules.HostDBEntry) float64 {
+	if len(entry.BenchmarkHistory) == 0 {
+		return 1
+	}
+
+	// Average the benchmarks.
+	var latency time.Duration
+	var uploadThroughput, downloadThroughput float64
+	for _, benchmark := range entry.BenchmarkHistory {
+		latency += benchmark.Latency
+		uploadThroughput += float64(benchmark.UploadThroughput)
+		downloadThroughput += float64(benchmark.DownloadThroughput)
+	}
+	n := float64(len(entry.BenchmarkHistory))
+	latency /= time.Duration(len(entry.BenchmarkHistory))
+	uploadThroughput /= n
+	downloadThroughput /= n
+
+	// A slow connection hurts more than a high latency, so the throughput
+	// penalties are squared.
+	weight := float64(1)
+	if latency > benchmarkLatencyTarget {
+		weight *= float64(benchmarkLatencyTarget) / float64(latency)
+	}
+	if uploadThroughput < benchmarkThroughputTarget {
+		weight *= math.Pow(uploadThroughput/benchmarkThroughputTarget, 2)
+	}
+	if downloadThroughput < benchmarkThroughputTarget {
+		weight *= math.Pow(downloadThroughput/benchmarkThroughputTarget, 2)
+	}
+	return weight
+}
+
 // priceAdjustments will adjust the weight of the entry according to the prices
 // that it has set.
 func (hdb *HostDB) priceAdjustments(entry modules.HostDBEntry) float64 {
@@ -147,17 +190,32 @@ func (hdb *HostDB) priceAdjustments(entry modules.HostDBEntry) float64 {
 	//    - the upload bandwidth price is per byte
 	//    - the download bandwidth price is per byte
 	//
-	// The hostdb will naively assume the following for now:
+	// If the allowance contains the expected usage of the renter, the contract
+	// price and the bandwidth prices are spread out over the data that the
+	// host is expected to store for the duration of the period.
+	//
+	// Otherwise, the hostdb will naively assume the following:
 	//    - each contract covers 6 weeks of storage (default is 12 weeks, but
 	//      renewals occur at midpoint) - 6048 blocks - and 25GB of storage.
 	//    - uploads happen once per 12 weeks (average lifetime of a file is 12 weeks)
 	//    - downloads happen once per 12 weeks (files are on average downloaded once throughout lifetime)
-	//
-	// In the future, the renter should be able to track average user behavior
-	// and adjust accordingly. This flexibility will be added later.
-	adjustedContractPrice := entry.ContractPrice.Div64(6048).Div64(25e9)        // Adjust contract price to match 25GB for 6 weeks.
-	adjustedUploadPrice := entry.UploadBandwidthPrice.Div64(24192)              // Adjust upload price to match a single upload over 24 weeks.
-	adjustedDownloadPrice := entry.DownloadBandwidthPrice.Div64(12096).Div64(3) // Adjust download price to match one download over 12 weeks, 1 redundancy.
+	var adjustedContractPrice, adjustedUploadPrice, adjustedDownloadPrice types.Currency
+	if hdb.allowance.HasExpectedUsage() && hdb.allowance.Period != 0 {
+		storage, upload, download := hdb.allowance.ExpectedHostUsage()
+		if storage == 0 {
+			// Without any expected storage there is nothing to spread the
+			// costs over, fall back to the default contract size.
+			storage = 25e9
+		}
+		byteBlocks := types.NewCurrency64(storage).Mul64(uint64(hdb.allowance.Period))
+		adjustedContractPrice = entry.ContractPrice.Div(byteBlocks)
+		adjustedUploadPrice = entry.UploadBandwidthPrice.Mul64(upload).Div(byteBlocks)
+		adjustedDownloadPrice = entry.DownloadBandwidthPrice.Mul64(download).Div(byteBlocks)
+	} else {
+		adjustedContractPrice = entry.ContractPrice.Div64(6048).Div64(25e9)        // Adjust contract price to match 25GB for 6 weeks.
+		adjustedUploadPrice = entry.UploadBandwidthPrice.Div64(24192)              // Adjust upload price to match a single upload over 24 weeks.
+		adjustedDownloadPrice = entry.DownloadBandwidthPrice.Div64(12096).Div64(3) // Adjust download price to match one download over 12 weeks, 1 redundancy.
+	}
 	siafundFee := adjustedContractPrice.Add(adjustedUploadPrice).Add(adjustedDownloadPrice).Add(entry.Collateral).MulTax()
 	totalPrice := entry.StoragePrice.Add(adjustedContractPrice).Add(adjustedUploadPrice).Add(adjustedDownloadPrice).Add(siafundFee)
 
@@ -369,6 +427,7 @@ func (hdb *HostDB) calculateHostWeight(entry modules.HostDBEntry) types.Currency
 	collateralReward := hdb.collateralAdjustments(entry)
 	interactionPenalty := hdb.interactionAdjustments(entry)
 	lifetimePenalty := hdb.lifetimeAdjustments(entry)
+	performancePenalty := hdb.performanceAdjustments(entry)
 	pricePenalty := hdb.priceAdjustments(entry)
 	storageRemainingPenalty := storageRemainingAdjustments(entry)
 	uptimePenalty := hdb.uptimeAdjustments(entry)
@@ -376,7 +435,7 @@ func (hdb *HostDB) calculateHostWeight(entry modules.HostDBEntry) types.Currency
 
 	// Combine the adjustments.
 	fullPenalty := collateralReward * interactionPenalty * lifetimePenalty *
-		pricePenalty * storageRemainingPenalty * uptimePenalty * versionPenalty
+		performancePenalty * pricePenalty * storageRemainingPenalty * uptimePenalty * versionPenalty
 
 	// Return a types.Currency.
 	weight := baseWeight.MulFloat(fullPenalty)
@@ -408,8 +467,13 @@ func (hdb *HostDB) calculateConversionRate(score types.Currency) float64 {
 // EstimateHostScore takes a HostExternalSettings and returns the estimated
 // score of that host in the hostdb, assuming no penalties for age or uptime.
 func (hdb *HostDB) EstimateHostScore(entry modules.HostDBEntry) modules.HostScoreBreakdown {
-	// Grab the adjustments. Age, and uptime penalties are set to '1', to
-	// assume best behavior from the host.
+	// The price adjustments depend on the allowance, which is guarded by the
+	// hostdb lock.
+	hdb.mu.RLock()
+	defer hdb.mu.RUnlock()
+
+	// Grab the adjustments. Age, performance, and uptime penalties are set to
+	// '1', to assume best behavior from the host.
 	collateralReward := hdb.collateralAdjustments(entry)
 	pricePenalty := hdb.priceAdjustments(entry)
 	storageRemainingPenalty := storageRemainingAdjustments(entry)
@@ -431,6 +495,7 @@ func (hdb *HostDB) EstimateHostScore(entry modules.HostDBEntry) modules.HostScor
 		AgeAdjustment:              1,
 		BurnAdjustment:             1,
 		CollateralAdjustment:       collateralReward,
+		PerformanceAdjustment:      1,
 		PriceAdjustment:            pricePenalty,
 		StorageRemainingAdjustment: storageRemainingPenalty,
 		UptimeAdjustment:           1,
@@ -453,6 +518,7 @@ func (hdb *HostDB) ScoreBreakdown(entry modules.HostDBEntry) modules.HostScoreBr
 		BurnAdjustment:             1,
 		CollateralAdjustment:       hdb.collateralAdjustments(entry),
 		InteractionAdjustment:      hdb.interactionAdjustments(entry),
+		PerformanceAdjustment:      hdb.performanceAdjustments(entry),
 		PriceAdjustment:            hdb.priceAdjustments(entry),
 		StorageRemainingAdjustment: storageRemainingAdjustments(entry),
 		UptimeAdjustment:           hdb.uptimeAdjustments(entry),
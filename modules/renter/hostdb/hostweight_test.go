@@ -147,6 +147,79 @@ func TestHostWeightLifetimeDifferences(t *testing.T) {
 	}
 }
 
+// TestHostWeightPerformanceDifferences checks that slow hosts are weighted
+// lower than fast hosts, and that hosts without benchmarks are not penalized.
+func TestHostWeightPerformanceDifferences(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	hdb := bareHostDB()
+	var entry modules.HostDBEntry
+	entry.RemainingStorage = 250e3
+	entry.StoragePrice = types.NewCurrency64(1000).Mul(types.SiacoinPrecision)
+	entry.Collateral = types.NewCurrency64(1000).Mul(types.SiacoinPrecision)
+	entry.Version = build.Version
+
+	fast := entry
+	fast.BenchmarkHistory = []modules.HostDBBenchmark{{
+		Latency:            50 * time.Millisecond,
+		UploadThroughput:   10e6,
+		DownloadThroughput: 10e6,
+	}}
+	slow := entry
+	slow.BenchmarkHistory = []modules.HostDBBenchmark{{
+		Latency:            50 * time.Millisecond,
+		UploadThroughput:   50e3,
+		DownloadThroughput: 50e3,
+	}}
+	laggy := entry
+	laggy.BenchmarkHistory = []modules.HostDBBenchmark{{
+		Latency:            time.Second,
+		UploadThroughput:   10e6,
+		DownloadThroughput: 10e6,
+	}}
+
+	if hdb.performanceAdjustments(entry) != 1 || hdb.performanceAdjustments(fast) != 1 {
+		t.Error("hosts without benchmarks or with fast benchmarks should not be penalized")
+	}
+	w := hdb.calculateHostWeight(fast)
+	if w.Cmp(hdb.calculateHostWeight(slow)) <= 0 {
+		t.Error("slow host should have less weight than fast host")
+	}
+	if w.Cmp(hdb.calculateHostWeight(laggy)) <= 0 {
+		t.Error("host with high latency should have less weight than fast host")
+	}
+	if hdb.calculateHostWeight(laggy).Cmp(hdb.calculateHostWeight(slow)) <= 0 {
+		t.Error("low throughput should be penalized more than high latency")
+	}
+}
+
+func TestHostWeightAuditDifferences(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	hdb := bareHostDB()
+	var entry modules.HostDBEntry
+	entry.RemainingStorage = 250e3
+	entry.StoragePrice = types.NewCurrency64(1000).Mul(types.SiacoinPrecision)
+	entry.Collateral = types.NewCurrency64(1000).Mul(types.SiacoinPrecision)
+	entry.Version = build.Version
+
+	passed := entry
+	passed.SuccessfulAudits = 10
+	failed := entry
+	failed.SuccessfulAudits = 10
+	failed.FailedAudits = 1
+
+	w := hdb.calculateHostWeight(entry)
+	if hdb.calculateHostWeight(passed).Cmp(w) <= 0 {
+		t.Error("host that passed audits should have more weight than an unaudited host")
+	}
+	if hdb.calculateHostWeight(failed).Cmp(w) >= 0 {
+		t.Error("host that failed an audit should have less weight than an unaudited host")
+	}
+}
+
 func TestHostWeightUptimeDifferences(t *testing.T) {
 	if testing.Short() {
 		t.SkipNow()
@@ -286,3 +359,84 @@ func TestHostWeightUptimeDifferences4(t *testing.T) {
 		t.Error("Been around longer should have more weight")
 	}
 }
+
+// TestHostWeightExpectedUsage checks that the expected usage in the allowance
+// changes how the bandwidth prices of hosts are weighed.
+func TestHostWeightExpectedUsage(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	hdb := bareHostDB()
+
+	// Create two hosts with the same storage price, one with cheap uploads
+	// and expensive downloads, and one with expensive uploads and cheap
+	// downloads.
+	var cheapUpload, cheapDownload modules.HostDBEntry
+	for _, entry := range []*modules.HostDBEntry{&cheapUpload, &cheapDownload} {
+		entry.Version = build.Version
+		entry.RemainingStorage = 250e3
+		entry.StoragePrice = types.SiacoinPrecision.Mul64(100).Div64(4032).Div64(1e9)
+	}
+	cheap := types.SiacoinPrecision.Mul64(10).Div64(1e12)
+	expensive := types.SiacoinPrecision.Mul64(1000).Div64(1e12)
+	cheapUpload.UploadBandwidthPrice = cheap
+	cheapUpload.DownloadBandwidthPrice = expensive
+	cheapDownload.UploadBandwidthPrice = expensive
+	cheapDownload.DownloadBandwidthPrice = cheap
+
+	// A renter that uploads a lot and never downloads should prefer the host
+	// with cheap uploads.
+	hdb.allowance = modules.Allowance{
+		Hosts:           10,
+		Period:          4032,
+		ExpectedStorage: 1e12,
+		ExpectedUpload:  1e9,
+	}
+	if hdb.calculateHostWeight(cheapUpload).Cmp(hdb.calculateHostWeight(cheapDownload)) <= 0 {
+		t.Error("host with cheap uploads should be preferred by an upload heavy renter")
+	}
+
+	// A renter that downloads a lot and never uploads should prefer the host
+	// with cheap downloads.
+	hdb.allowance = modules.Allowance{
+		Hosts:            10,
+		Period:           4032,
+		ExpectedStorage:  1e12,
+		ExpectedDownload: 1e9,
+	}
+	if hdb.calculateHostWeight(cheapDownload).Cmp(hdb.calculateHostWeight(cheapUpload)) <= 0 {
+		t.Error("host with cheap downloads should be preferred by a download heavy renter")
+	}
+}
+
+// TestEstimateHostScoreSetAllowance estimates the score of a host while the
+// allowance is changed, which the race detector checks for unsafe access to
+// the allowance.
+func TestEstimateHostScoreSetAllowance(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	hdb := bareHostDB()
+	entry := makeHostDBEntry()
+	entry.Version = build.Version
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := uint64(0); i < 100; i++ {
+			err := hdb.SetAllowance(modules.Allowance{
+				Hosts:           10,
+				Period:          4032,
+				ExpectedStorage: 1e12 + i,
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		hdb.EstimateHostScore(entry)
+	}
+	<-done
+}
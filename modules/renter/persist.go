@@ -11,6 +11,7 @@ import (
 	"strconv"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/persist"
@@ -23,6 +24,9 @@ const (
 	PersistFilename = "renter.json"
 	// ShareExtension is the extension to be used
 	ShareExtension = ".sia"
+	// reencodeExtension is the extension of the files that contain the new
+	// layout of files that are being re-encoded.
+	reencodeExtension = ".reencode"
 )
 
 var (
@@ -55,6 +59,19 @@ type (
 		MaxUploadSpeed   int64
 		StreamCacheSize  uint64
 		Tracking         map[string]trackedFile
+		BenchmarkHosts   bool
+		TenantUploadDir  string
+
+		// GCCandidates are the sector roots of deleted files that still have
+		// to be deleted from the hosts, indexed by the hosts' public keys.
+		GCCandidates   map[string][]crypto.Hash
+		ReclaimedBytes uint64
+
+		// Tenants are the tenants of the renter, indexed by name.
+		Tenants map[string]tenant
+
+		// Alerts are the alerts of the renter.
+		Alerts []modules.Alert
 	}
 )
 
@@ -180,13 +197,16 @@ func (r *Renter) saveFile(f *file) error {
 	}
 	// Create directory structure specified in nickname.
 	fullPath := filepath.Join(r.persistDir, f.name+ShareExtension)
+	if f.isReencode {
+		fullPath = filepath.Join(r.persistDir, f.name+reencodeExtension)
+	}
 	err := os.MkdirAll(filepath.Dir(fullPath), 0700)
 	if err != nil {
 		return err
 	}
 
 	// Open SafeFile handle.
-	handle, err := persist.NewSafeFile(filepath.Join(r.persistDir, f.name+ShareExtension))
+	handle, err := persist.NewSafeFile(fullPath)
 	if err != nil {
 		return err
 	}
@@ -270,6 +290,7 @@ func (r *Renter) loadSettings() error {
 	} else if err != nil {
 		return err
 	}
+	r.staticAlerter.Load(r.persist.Alerts)
 
 	// Set the bandwidth limits on the contractor, which was already initialized
 	// without bandwidth limits.
@@ -363,9 +384,8 @@ func (r *Renter) ShareFilesASCII(nicknames []string) (string, error) {
 	return buf.String(), nil
 }
 
-// loadSharedFiles reads .sia data from reader and registers the contained
-// files in the renter. It returns the nicknames of the loaded files.
-func (r *Renter) loadSharedFiles(reader io.Reader) ([]string, error) {
+// readSharedFiles reads the files contained in .sia data from reader.
+func readSharedFiles(reader io.Reader) ([]*file, error) {
 	// read header
 	var header [15]byte
 	var version string
@@ -398,7 +418,18 @@ func (r *Renter) loadSharedFiles(reader io.Reader) ([]string, error) {
 		if err != nil {
 			return nil, err
 		}
+	}
+	return files, nil
+}
 
+// loadSharedFiles reads .sia data from reader and registers the contained
+// files in the renter. It returns the nicknames of the loaded files.
+func (r *Renter) loadSharedFiles(reader io.Reader) ([]string, error) {
+	files, err := readSharedFiles(reader)
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
 		// Make sure the file's name does not conflict with existing files.
 		dupCount := 0
 		origName := files[i].name
@@ -413,7 +444,7 @@ func (r *Renter) loadSharedFiles(reader io.Reader) ([]string, error) {
 	}
 
 	// Add files to renter.
-	names := make([]string, numFiles)
+	names := make([]string, len(files))
 	for i, f := range files {
 		r.files[f.name] = f
 		names[i] = f.name
@@ -441,6 +472,16 @@ func (r *Renter) initPersist() error {
 		return err
 	}
 
+	// Open the transfer history.
+	r.transfers, err = newTransferLog(filepath.Join(r.persistDir, transferHistoryFile))
+	if err != nil {
+		return err
+	}
+	err = r.tg.AfterStop(r.transfers.close)
+	if err != nil {
+		return err
+	}
+
 	// Load the prior persistence structures.
 	err = r.loadSettings()
 	if err != nil {
@@ -448,7 +489,13 @@ func (r *Renter) initPersist() error {
 	}
 
 	// Load the siafiles into memory.
-	return r.loadSiaFiles()
+	err = r.loadSiaFiles()
+	if err != nil {
+		return err
+	}
+
+	// Resume re-encoding the files that were being re-encoded.
+	return r.loadReencodeFiles()
 }
 
 // LoadSharedFiles loads a .sia file into the renter. It returns the nicknames
@@ -12,6 +12,10 @@ const (
 	// contractExtension is the extension given to contract files.
 	contractExtension = ".contract"
 
+	// spendingLedgerFilename is the name of the file that holds the spending
+	// ledger of the contract set.
+	spendingLedgerFilename = "spending.json"
+
 	// rootsDiskLoadBulkSize is the max number of roots we read from disk at
 	// once to avoid using up all the ram.
 	rootsDiskLoadBulkSize = 1024 * crypto.HashSize // 32 kib
@@ -55,4 +59,21 @@ var (
 		}
 		return height
 	}()
+
+	// spendingLedgerLimit is the number of records that are kept in the
+	// spending ledger. The ledger is compacted to this size once it holds
+	// twice as many records.
+	spendingLedgerLimit = build.Select(build.Var{
+		Dev:      10000,
+		Standard: 1000000,
+		Testing:  20,
+	}).(int)
+
+	// spendingLedgerRetention is how long records are kept in the spending
+	// ledger.
+	spendingLedgerRetention = build.Select(build.Var{
+		Dev:      30 * 24 * time.Hour,
+		Standard: 2 * 365 * 24 * time.Hour,
+		Testing:  time.Hour,
+	}).(time.Duration)
 )
@@ -9,6 +9,7 @@ import (
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
 	"github.com/NebulousLabs/Sia/types"
 	"github.com/NebulousLabs/errors"
 	"github.com/NebulousLabs/writeaheadlog"
@@ -19,8 +20,9 @@ const (
 	// portion of a contract can consume.
 	contractHeaderSize = writeaheadlog.MaxPayloadSize // TODO: test this
 
-	updateNameSetHeader = "setHeader"
-	updateNameSetRoot   = "setRoot"
+	updateNameSetHeader    = "setHeader"
+	updateNameSetRoot      = "setRoot"
+	updateNameRewriteRoots = "rewriteRoots"
 )
 
 type updateSetHeader struct {
@@ -42,6 +44,12 @@ type updateSetRoot struct {
 	Index int
 }
 
+type updateRewriteRoots struct {
+	ID     types.FileContractID
+	Offset int
+	Roots  []crypto.Hash
+}
+
 type contractHeader struct {
 	// transaction is the signed transaction containing the most recent
 	// revision of the file contract.
@@ -134,6 +142,8 @@ type SafeContract struct {
 	unappliedTxns []*writeaheadlog.Transaction
 
 	headerFile *fileSection
+	ledger     *spendingLedger
+	log        *persist.Logger
 	wal        *writeaheadlog.WAL
 	mu         sync.Mutex
 }
@@ -231,6 +241,20 @@ func (c *SafeContract) makeUpdateSetRoot(root crypto.Hash, index int) writeahead
 	}
 }
 
+func (c *SafeContract) makeUpdateRewriteRoots(offset int, roots []crypto.Hash) writeaheadlog.Update {
+	c.headerMu.Lock()
+	id := c.header.ID()
+	c.headerMu.Unlock()
+	return writeaheadlog.Update{
+		Name: updateNameRewriteRoots,
+		Instructions: encoding.Marshal(updateRewriteRoots{
+			ID:     id,
+			Offset: offset,
+			Roots:  roots,
+		}),
+	}
+}
+
 func (c *SafeContract) applySetHeader(h contractHeader) error {
 	headerBytes := make([]byte, contractHeaderSize)
 	copy(headerBytes, encoding.Marshal(h))
@@ -247,6 +271,10 @@ func (c *SafeContract) applySetRoot(root crypto.Hash, index int) error {
 	return c.merkleRoots.insert(index, root)
 }
 
+func (c *SafeContract) applyRewriteRoots(offset int, roots []crypto.Hash) error {
+	return c.merkleRoots.rewrite(offset, roots)
+}
+
 func (c *SafeContract) recordUploadIntent(rev types.FileContractRevision, root crypto.Hash, storageCost, bandwidthCost types.Currency) (*writeaheadlog.Transaction, error) {
 	// construct new header
 	// NOTE: this header will not include the host signature
@@ -293,6 +321,13 @@ func (c *SafeContract) commitUpload(t *writeaheadlog.Transaction, signedTxn type
 		return err
 	}
 	c.unappliedTxns = nil
+	err := c.recordSpending(modules.RenterSpendingRecord{
+		StorageSpending: storageCost,
+		UploadSpending:  bandwidthCost,
+	})
+	if err != nil {
+		c.log.Println("Unable to record spending of upload:", err)
+	}
 	return nil
 }
 
@@ -336,9 +371,76 @@ func (c *SafeContract) commitDownload(t *writeaheadlog.Transaction, signedTxn ty
 		return err
 	}
 	c.unappliedTxns = nil
+	err := c.recordSpending(modules.RenterSpendingRecord{
+		DownloadSpending: bandwidthCost,
+	})
+	if err != nil {
+		c.log.Println("Unable to record spending of download:", err)
+	}
+	return nil
+}
+
+// recordDeleteIntent records the intent to delete sectors from the contract.
+// Deleting sectors shifts the roots after them, so the roots starting at
+// offset are replaced with roots as a whole.
+func (c *SafeContract) recordDeleteIntent(rev types.FileContractRevision, offset int, roots []crypto.Hash) (*writeaheadlog.Transaction, error) {
+	// construct new header
+	// NOTE: this header will not include the host signature
+	c.headerMu.Lock()
+	newHeader := c.header
+	c.headerMu.Unlock()
+	newHeader.Transaction.FileContractRevisions = []types.FileContractRevision{rev}
+
+	t, err := c.wal.NewTransaction([]writeaheadlog.Update{
+		c.makeUpdateSetHeader(newHeader),
+		c.makeUpdateRewriteRoots(offset, roots),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := <-t.SignalSetupComplete(); err != nil {
+		return nil, err
+	}
+	c.unappliedTxns = append(c.unappliedTxns, t)
+	return t, nil
+}
+
+func (c *SafeContract) commitDelete(t *writeaheadlog.Transaction, signedTxn types.Transaction, offset int, roots []crypto.Hash) error {
+	// construct new header
+	c.headerMu.Lock()
+	newHeader := c.header
+	c.headerMu.Unlock()
+	newHeader.Transaction = signedTxn
+
+	if err := c.applySetHeader(newHeader); err != nil {
+		return err
+	}
+	if err := c.applyRewriteRoots(offset, roots); err != nil {
+		return err
+	}
+	if err := c.headerFile.Sync(); err != nil {
+		return err
+	}
+	if err := t.SignalUpdatesApplied(); err != nil {
+		return err
+	}
+	c.unappliedTxns = nil
 	return nil
 }
 
+// recordSpending adds a record of money that was paid to the host of the
+// contract to the spending ledger. The ledger is purely informational, so
+// callers should log the error rather than fail the revision, which has
+// already been committed.
+func (c *SafeContract) recordSpending(r modules.RenterSpendingRecord) error {
+	c.headerMu.Lock()
+	r.ContractID = c.header.ID()
+	r.EndHeight = c.header.EndHeight()
+	r.HostPublicKey = c.header.HostPublicKey()
+	c.headerMu.Unlock()
+	return c.ledger.record(r)
+}
+
 // commitTxns commits the unapplied transactions to the contract file and marks
 // the transactions as applied.
 func (c *SafeContract) commitTxns() error {
@@ -361,6 +463,14 @@ func (c *SafeContract) commitTxns() error {
 				if err := c.applySetRoot(u.Root, u.Index); err != nil {
 					return err
 				}
+			case updateNameRewriteRoots:
+				var u updateRewriteRoots
+				if err := encoding.Unmarshal(update.Instructions, &u); err != nil {
+					return err
+				}
+				if err := c.applyRewriteRoots(u.Offset, u.Roots); err != nil {
+					return err
+				}
 			}
 		}
 		if err := c.headerFile.Sync(); err != nil {
@@ -420,6 +530,8 @@ func (cs *ContractSet) managedInsertContract(h contractHeader, roots []crypto.Ha
 		header:      h,
 		merkleRoots: merkleRoots,
 		headerFile:  headerSection,
+		ledger:      cs.ledger,
+		log:         cs.log,
 		wal:         cs.wal,
 	}
 	cs.mu.Lock()
@@ -474,6 +586,12 @@ func (cs *ContractSet) loadSafeContract(filename string, walTxns []*writeaheadlo
 				return err
 			}
 			id = u.ID
+		case updateNameRewriteRoots:
+			var u updateRewriteRoots
+			if err := encoding.Unmarshal(update.Instructions, &u); err != nil {
+				return err
+			}
+			id = u.ID
 		}
 		if id == header.ID() {
 			unappliedTxns = append(unappliedTxns, t)
@@ -485,6 +603,8 @@ func (cs *ContractSet) loadSafeContract(filename string, walTxns []*writeaheadlo
 		merkleRoots:   merkleRoots,
 		unappliedTxns: unappliedTxns,
 		headerFile:    headerSection,
+		ledger:        cs.ledger,
+		log:           cs.log,
 		wal:           cs.wal,
 	}
 	cs.contracts[sc.header.ID()] = sc
@@ -2,6 +2,7 @@ package proto
 
 import (
 	"bytes"
+	"io/ioutil"
 	"path/filepath"
 	"reflect"
 	"testing"
@@ -10,6 +11,7 @@ import (
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/encoding"
 	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
 	"github.com/NebulousLabs/Sia/types"
 )
 
@@ -21,7 +23,7 @@ func TestContractUncommittedTxn(t *testing.T) {
 	}
 	// create contract set with one contract
 	dir := build.TempDir(filepath.Join("proto", t.Name()))
-	cs, err := NewContractSet(dir, modules.ProdDependencies)
+	cs, err := NewContractSet(dir, persist.NewLogger(ioutil.Discard), modules.ProdDependencies)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -82,7 +84,7 @@ func TestContractUncommittedTxn(t *testing.T) {
 
 	// close and reopen the contract set
 	cs.Close()
-	cs, err = NewContractSet(dir, modules.ProdDependencies)
+	cs, err = NewContractSet(dir, persist.NewLogger(ioutil.Discard), modules.ProdDependencies)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -4,9 +4,11 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
 	"github.com/NebulousLabs/Sia/types"
 	"github.com/NebulousLabs/ratelimit"
 
@@ -22,6 +24,8 @@ type ContractSet struct {
 	pubKeys   map[string]types.FileContractID
 	deps      modules.Dependencies
 	dir       string
+	ledger    *spendingLedger
+	log       *persist.Logger
 	mu        sync.Mutex
 	rl        *ratelimit.RateLimit
 	wal       *writeaheadlog.WAL
@@ -118,6 +122,13 @@ func (cs *ContractSet) SetRateLimits(readBPS int64, writeBPS int64, packetSize u
 	cs.rl.SetLimits(readBPS, writeBPS, packetSize)
 }
 
+// SpendingHistory returns the spending records of the given host that were
+// created between start and end. If the host key is empty, the records of all
+// hosts are returned.
+func (cs *ContractSet) SpendingHistory(host types.SiaPublicKey, start, end time.Time) ([]modules.RenterSpendingRecord, error) {
+	return cs.ledger.records(host, start, end)
+}
+
 // View returns a copy of the contract with the specified host key. The
 // contracts is not locked. Certain fields, including the MerkleRoots, are set
 // to nil for safety reasons. If the contract is not present in the set, View
@@ -150,12 +161,13 @@ func (cs *ContractSet) Close() error {
 		c.headerFile.Close()
 	}
 	_, err := cs.wal.CloseIncomplete()
-	return err
+	return errors.Compose(err, cs.ledger.close())
 }
 
 // NewContractSet returns a ContractSet storing its contracts in the specified
-// dir.
-func NewContractSet(dir string, deps modules.Dependencies) (*ContractSet, error) {
+// dir. Errors that don't affect the contracts, such as failures to record
+// spending, are written to log.
+func NewContractSet(dir string, log *persist.Logger, deps modules.Dependencies) (*ContractSet, error) {
 	if err := os.MkdirAll(dir, 0700); err != nil {
 		return nil, err
 	}
@@ -181,13 +193,21 @@ func NewContractSet(dir string, deps modules.Dependencies) (*ContractSet, error)
 		return nil, err
 	}
 
+	// Open the spending ledger.
+	ledger, err := newSpendingLedger(filepath.Join(dir, spendingLedgerFilename))
+	if err != nil {
+		return nil, err
+	}
+
 	cs := &ContractSet{
 		contracts: make(map[types.FileContractID]*SafeContract),
 		pubKeys:   make(map[string]types.FileContractID),
 
-		deps: deps,
-		dir:  dir,
-		wal:  wal,
+		deps:   deps,
+		dir:    dir,
+		ledger: ledger,
+		log:    log,
+		wal:    wal,
 	}
 	// Set the initial rate limit to 'unlimited' bandwidth with 4kib packets.
 	cs.rl = ratelimit.NewRateLimit(0, 0, 0)
@@ -1,6 +1,7 @@
 package proto
 
 import (
+	"io/ioutil"
 	"sync"
 	"testing"
 	"time"
@@ -8,6 +9,7 @@ import (
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
 	"github.com/NebulousLabs/Sia/types"
 
 	"github.com/NebulousLabs/fastrand"
@@ -31,7 +33,7 @@ func TestContractSet(t *testing.T) {
 	}
 	// create contract set
 	testDir := build.TempDir(t.Name())
-	cs, err := NewContractSet(testDir, modules.ProdDependencies)
+	cs, err := NewContractSet(testDir, persist.NewLogger(ioutil.Discard), modules.ProdDependencies)
 	if err != nil {
 		t.Fatal(err)
 	}
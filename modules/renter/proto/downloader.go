@@ -2,6 +2,7 @@ package proto
 
 import (
 	"net"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,6 +14,16 @@ import (
 	"github.com/NebulousLabs/errors"
 )
 
+var (
+	// ErrBadSectorData is returned when a host sends a sector that is too
+	// short or that does not match the requested Merkle root.
+	ErrBadSectorData = errors.New("host sent bad sector data")
+
+	// ErrSectorNotFound is returned when a host reports that it does not
+	// store the requested sector.
+	ErrSectorNotFound = errors.New("host does not store the sector")
+)
+
 // A Downloader retrieves sectors by calling the download RPC on a host.
 // Downloaders are NOT thread- safe; calls to Sector must be serialized.
 type Downloader struct {
@@ -104,6 +115,11 @@ func (hd *Downloader) Sector(root crypto.Hash) (_ modules.RenterContract, _ []by
 		// until we've finished downloading the sector.
 		defer hd.conn.Close()
 	} else if err != nil {
+		// Hosts include modules.ErrSectorNotFound in the rejection when they
+		// don't store the sector.
+		if strings.Contains(err.Error(), modules.ErrSectorNotFound.Error()) {
+			err = errors.Extend(err, ErrSectorNotFound)
+		}
 		return modules.RenterContract{}, nil, err
 	}
 
@@ -119,13 +135,13 @@ func (hd *Downloader) Sector(root crypto.Hash) (_ modules.RenterContract, _ []by
 	if err := encoding.ReadObject(hd.conn, &sectors, modules.SectorSize+16); err != nil {
 		return modules.RenterContract{}, nil, err
 	} else if len(sectors) != 1 {
-		return modules.RenterContract{}, nil, errors.New("host did not send enough sectors")
+		return modules.RenterContract{}, nil, errors.AddContext(ErrBadSectorData, "host did not send enough sectors")
 	}
 	sector := sectors[0]
 	if uint64(len(sector)) != modules.SectorSize {
-		return modules.RenterContract{}, nil, errors.New("host did not send enough sector data")
+		return modules.RenterContract{}, nil, errors.AddContext(ErrBadSectorData, "host did not send enough sector data")
 	} else if crypto.MerkleRoot(sector) != root {
-		return modules.RenterContract{}, nil, errors.New("host sent bad sector data")
+		return modules.RenterContract{}, nil, ErrBadSectorData
 	}
 
 	// update contract and metrics
@@ -0,0 +1,102 @@
+package proto
+
+import (
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/encoding"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/errors"
+)
+
+// stubHostDB is a hostDB that ignores interactions.
+type stubHostDB struct{}
+
+func (stubHostDB) IncrementSuccessfulInteractions(types.SiaPublicKey) {}
+func (stubHostDB) IncrementFailedInteractions(types.SiaPublicKey)     {}
+
+// TestDownloaderErrors checks that the downloader only reports a missing
+// sector if the host says so, and not if the connection to the host drops.
+func TestDownloaderErrors(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	cs, err := NewContractSet(build.TempDir("proto", t.Name()), persist.NewLogger(ioutil.Discard), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	header := contractHeader{Transaction: types.Transaction{
+		FileContractRevisions: []types.FileContractRevision{{
+			ParentID:              types.FileContractID{1},
+			NewValidProofOutputs:  []types.SiacoinOutput{{}, {}},
+			NewMissedProofOutputs: []types.SiacoinOutput{{}, {}, {}},
+			UnlockConditions: types.UnlockConditions{
+				PublicKeys: []types.SiaPublicKey{{}, {}},
+			},
+		}},
+	}}
+	if _, err := cs.managedInsertContract(header, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// download runs a download against a host that sends its settings, reads
+	// the download request and the revision, and then responds with respond.
+	sk, pk := crypto.GenerateKeyPair()
+	host := modules.HostDBEntry{
+		PublicKey: types.SiaPublicKey{
+			Algorithm: types.SignatureEd25519,
+			Key:       pk[:],
+		},
+	}
+	download := func(respond func(net.Conn)) error {
+		renterConn, hostConn := net.Pipe()
+		defer renterConn.Close()
+		go func() {
+			defer hostConn.Close()
+			if crypto.WriteSignedObject(hostConn, modules.HostExternalSettings{}, sk) != nil || modules.ReadNegotiationAcceptance(hostConn) != nil {
+				return
+			}
+			var actions []modules.DownloadAction
+			var rev types.FileContractRevision
+			if encoding.ReadObject(hostConn, &actions, 1e3) != nil || encoding.ReadObject(hostConn, &rev, 1e4) != nil {
+				return
+			}
+			respond(hostConn)
+		}()
+		hd := &Downloader{
+			conn:        renterConn,
+			contractID:  header.ID(),
+			contractSet: cs,
+			deps:        modules.ProdDependencies,
+			hdb:         stubHostDB{},
+			host:        host,
+		}
+		_, _, err := hd.Sector(crypto.Hash{})
+		return err
+	}
+
+	// A dropped connection is the host's fault, but it doesn't show that the
+	// host lost the sector.
+	err = download(func(net.Conn) {})
+	if !modules.IsHostsFault(err) {
+		t.Fatal("dropped connection was not tagged as the host's fault:", err)
+	}
+	if errors.Contains(err, ErrSectorNotFound) || errors.Contains(err, ErrBadSectorData) {
+		t.Fatal("dropped connection was reported as a lost sector:", err)
+	}
+
+	// A host that reports that it doesn't store the sector.
+	err = download(func(conn net.Conn) {
+		modules.WriteNegotiationRejection(conn, errors.New("failed to load sector: "+modules.ErrSectorNotFound.Error()))
+	})
+	if !errors.Contains(err, ErrSectorNotFound) {
+		t.Fatal("missing sector was not reported:", err)
+	}
+}
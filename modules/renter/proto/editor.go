@@ -169,6 +169,118 @@ func (he *Editor) Upload(data []byte) (_ modules.RenterContract, _ crypto.Hash,
 	return sc.Metadata(), sectorRoot, nil
 }
 
+// deleteActions returns the actions that delete the sectors with the given
+// roots from a contract with the given roots. The host shifts the roots after
+// a deleted sector, so the actions delete the sectors from back to front to
+// keep the indices valid. Since the roots before the first deleted sector are
+// unaffected, only the roots starting at offset are returned in tail.
+func deleteActions(contractRoots, roots []crypto.Hash) (actions []modules.RevisionAction, offset int, tail []crypto.Hash) {
+	remove := make(map[crypto.Hash]struct{}, len(roots))
+	for _, root := range roots {
+		remove[root] = struct{}{}
+	}
+	offset = len(contractRoots)
+	for i, root := range contractRoots {
+		if _, ok := remove[root]; !ok {
+			if i > offset {
+				tail = append(tail, root)
+			}
+			continue
+		}
+		if len(actions) == 0 {
+			offset = i
+		}
+		actions = append(actions, modules.RevisionAction{
+			Type:        modules.ActionDelete,
+			SectorIndex: uint64(i),
+		})
+	}
+	for i, j := 0, len(actions)-1; i < j; i, j = i+1, j-1 {
+		actions[i], actions[j] = actions[j], actions[i]
+	}
+	return actions, offset, tail
+}
+
+// Delete negotiates a revision that removes the sectors with the given roots
+// from a file contract. Roots that are not covered by the contract are
+// ignored. The number of deleted sectors is returned.
+//
+// NOTE: the host does not refund the storage that was already paid for.
+// Deleting sectors only reduces the size of the contract, which lowers the
+// cost of renewing it.
+func (he *Editor) Delete(roots []crypto.Hash) (_ modules.RenterContract, _ int, err error) {
+	// Acquire the contract.
+	sc, haveContract := he.contractSet.Acquire(he.contractID)
+	if !haveContract {
+		return modules.RenterContract{}, 0, errors.New("contract not present in contract set")
+	}
+	defer he.contractSet.Return(sc)
+	contract := sc.header // for convenience
+
+	// determine which sectors to delete
+	contractRoots, err := sc.merkleRoots.merkleRoots()
+	if err != nil {
+		return modules.RenterContract{}, 0, errors.AddContext(err, "unable to read roots of contract")
+	}
+	actions, offset, tail := deleteActions(contractRoots, roots)
+	if len(actions) == 0 {
+		return sc.Metadata(), 0, nil
+	}
+
+	// calculate the new Merkle root and create the revision
+	merkleRoot := cachedMerkleRoot(append(contractRoots[:offset:offset], tail...))
+	rev := newDeleteRevision(contract.LastRevision(), uint64(len(actions)), merkleRoot)
+
+	// run the revision iteration
+	defer func() {
+		// Increase Successful/Failed interactions accordingly
+		if err != nil {
+			he.hdb.IncrementFailedInteractions(he.host.PublicKey)
+			err = errors.Extend(err, modules.ErrHostFault)
+		} else {
+			he.hdb.IncrementSuccessfulInteractions(he.host.PublicKey)
+		}
+
+		// reset deadline
+		extendDeadline(he.conn, time.Hour)
+	}()
+
+	// initiate revision
+	extendDeadline(he.conn, modules.NegotiateSettingsTime)
+	if err := startRevision(he.conn, he.host); err != nil {
+		return modules.RenterContract{}, 0, err
+	}
+
+	// record the change we are about to make to the contract.
+	walTxn, err := sc.recordDeleteIntent(rev, offset, tail)
+	if err != nil {
+		return modules.RenterContract{}, 0, err
+	}
+
+	// send actions
+	extendDeadline(he.conn, modules.NegotiateFileContractRevisionTime)
+	if err := encoding.WriteObject(he.conn, actions); err != nil {
+		return modules.RenterContract{}, 0, err
+	}
+
+	// send revision to host and exchange signatures
+	extendDeadline(he.conn, connTimeout)
+	signedTxn, err := negotiateRevision(he.conn, rev, contract.SecretKey)
+	if err == modules.ErrStopResponse {
+		// if host gracefully closed, close our connection as well; this will
+		// cause the next operation to fail
+		he.conn.Close()
+	} else if err != nil {
+		return modules.RenterContract{}, 0, err
+	}
+
+	// update contract
+	if err := sc.commitDelete(walTxn, signedTxn, offset, tail); err != nil {
+		return modules.RenterContract{}, 0, err
+	}
+	return sc.Metadata(), len(actions), nil
+}
+
 // NewEditor initiates the contract revision process with a host, and returns
 // an Editor.
 func (cs *ContractSet) NewEditor(host modules.HostDBEntry, id types.FileContractID, currentHeight types.BlockHeight, hdb hostDB, cancel <-chan struct{}) (_ *Editor, err error) {
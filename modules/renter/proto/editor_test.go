@@ -0,0 +1,58 @@
+package proto
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/fastrand"
+)
+
+// TestDeleteActions checks that the actions returned by deleteActions leave
+// the host with the same roots that the renter expects.
+func TestDeleteActions(t *testing.T) {
+	contractRoots := make([]crypto.Hash, 300)
+	for i := range contractRoots {
+		fastrand.Read(contractRoots[i][:])
+	}
+
+	// Delete a random selection of roots and a root that is not part of the
+	// contract.
+	var roots, expected []crypto.Hash
+	for _, root := range contractRoots {
+		if fastrand.Intn(3) == 0 {
+			roots = append(roots, root)
+		} else {
+			expected = append(expected, root)
+		}
+	}
+	roots = append(roots, crypto.Hash{1})
+
+	actions, offset, tail := deleteActions(contractRoots, roots)
+	if len(actions) != len(contractRoots)-len(expected) {
+		t.Fatalf("expected %v actions, got %v", len(contractRoots)-len(expected), len(actions))
+	}
+	if got := append(contractRoots[:offset:offset], tail...); !reflect.DeepEqual(got, expected) {
+		t.Fatal("offset and tail don't match the expected roots")
+	}
+
+	// Apply the actions the way the host does.
+	hostRoots := append([]crypto.Hash(nil), contractRoots...)
+	for _, action := range actions {
+		if action.Type != modules.ActionDelete {
+			t.Fatal("unexpected action type", action.Type)
+		}
+		i := action.SectorIndex
+		hostRoots = append(hostRoots[:i], hostRoots[i+1:]...)
+	}
+	if !reflect.DeepEqual(hostRoots, expected) {
+		t.Fatal("host roots don't match the expected roots")
+	}
+
+	// Deleting roots that are not part of the contract is a no-op.
+	actions, offset, tail = deleteActions(contractRoots, []crypto.Hash{{1}})
+	if len(actions) != 0 || offset != len(contractRoots) || len(tail) != 0 {
+		t.Fatal("expected no actions", len(actions), offset, len(tail))
+	}
+}
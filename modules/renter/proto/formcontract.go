@@ -281,5 +281,14 @@ func (cs *ContractSet) FormContract(params ContractParams, txnBuilder transactio
 	if err != nil {
 		return modules.RenterContract{}, err
 	}
+	err = cs.ledger.record(modules.RenterSpendingRecord{
+		ContractID:    meta.ID,
+		EndHeight:     meta.EndHeight,
+		HostPublicKey: meta.HostPublicKey,
+		Fees:          header.ContractFee.Add(header.TxnFee).Add(header.SiafundFee),
+	})
+	if err != nil {
+		cs.log.Println("Unable to record spending of contract formation:", err)
+	}
 	return meta, nil
 }
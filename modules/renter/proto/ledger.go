@@ -0,0 +1,88 @@
+package proto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// A spendingLedger is a log of the money that was paid to hosts. Every
+// contract formation, renewal and revision adds a record containing the cost
+// deltas of that action. The ledger keeps the most recent
+// spendingLedgerLimit records that are younger than spendingLedgerRetention.
+type spendingLedger struct {
+	log *persist.JSONLog
+}
+
+// spendingRecordTime returns the time of an encoded spending record.
+func spendingRecordTime(record []byte) time.Time {
+	var r struct {
+		Timestamp time.Time `json:"timestamp"`
+	}
+	json.Unmarshal(record, &r)
+	return r.Timestamp
+}
+
+// newSpendingLedger opens the spending ledger at the given path, creating it
+// if it doesn't exist yet.
+func newSpendingLedger(path string) (*spendingLedger, error) {
+	log, err := persist.OpenJSONLog(path, persist.JSONLogOptions{
+		Limit:     spendingLedgerLimit,
+		Retention: spendingLedgerRetention,
+		Timestamp: spendingRecordTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &spendingLedger{log: log}, nil
+}
+
+// close closes the ledger file.
+func (l *spendingLedger) close() error {
+	if l == nil {
+		return nil
+	}
+	return l.log.Close()
+}
+
+// record appends a record to the ledger. Records without any spending are
+// ignored. A nil ledger ignores all records.
+func (l *spendingLedger) record(r modules.RenterSpendingRecord) error {
+	if l == nil {
+		return nil
+	}
+	if r.DownloadSpending.IsZero() && r.Fees.IsZero() && r.StorageSpending.IsZero() && r.UploadSpending.IsZero() {
+		return nil
+	}
+	if r.Timestamp.IsZero() {
+		r.Timestamp = time.Now()
+	}
+	return l.log.Append(r)
+}
+
+// records returns the records of the ledger that belong to the given host and
+// were created within [start, end]. If the host key is empty, the records of
+// all hosts are returned.
+func (l *spendingLedger) records(host types.SiaPublicKey, start, end time.Time) ([]modules.RenterSpendingRecord, error) {
+	if l == nil {
+		return nil, nil
+	}
+	var records []modules.RenterSpendingRecord
+	err := l.log.ForEach(func(record []byte) {
+		var r modules.RenterSpendingRecord
+		if json.Unmarshal(record, &r) != nil {
+			return
+		}
+		if len(host.Key) != 0 && r.HostPublicKey.String() != host.String() {
+			return
+		}
+		if r.Timestamp.Before(start) || r.Timestamp.After(end) {
+			return
+		}
+		records = append(records, r)
+	})
+	return records, err
+}
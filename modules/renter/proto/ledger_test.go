@@ -0,0 +1,199 @@
+package proto
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// newLedgerTestContract creates a contract set with a single contract with
+// the given host.
+func newLedgerTestContract(t *testing.T, hostKey types.SiaPublicKey) (*ContractSet, contractHeader) {
+	cs, err := NewContractSet(build.TempDir(t.Name()), persist.NewLogger(ioutil.Discard), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := contractHeader{Transaction: types.Transaction{
+		FileContractRevisions: []types.FileContractRevision{{
+			ParentID:             types.FileContractID{1},
+			NewValidProofOutputs: []types.SiacoinOutput{{}, {}},
+			NewWindowStart:       50,
+			UnlockConditions: types.UnlockConditions{
+				PublicKeys: []types.SiaPublicKey{{}, hostKey},
+			},
+		}},
+	}}
+	if _, err := cs.managedInsertContract(header, nil); err != nil {
+		t.Fatal(err)
+	}
+	return cs, header
+}
+
+// TestSpendingLedger tests that spending records are filtered correctly and
+// that the ledger survives an unclean shutdown.
+func TestSpendingLedger(t *testing.T) {
+	hostKey := types.SiaPublicKey{Key: []byte("host")}
+	cs, header := newLedgerTestContract(t, hostKey)
+
+	sc := cs.mustAcquire(t, header.ID())
+	sc.recordSpending(modules.RenterSpendingRecord{
+		StorageSpending: types.NewCurrency64(3),
+		UploadSpending:  types.NewCurrency64(4),
+	})
+	sc.recordSpending(modules.RenterSpendingRecord{})
+	sc.recordSpending(modules.RenterSpendingRecord{
+		DownloadSpending: types.NewCurrency64(5),
+	})
+	cs.Return(sc)
+
+	// Empty records are not stored.
+	records, err := cs.SpendingHistory(hostKey, time.Unix(0, 0), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatal("expected 2 records, got", len(records))
+	}
+	if records[0].ContractID != header.ID() || records[0].EndHeight != 50 || records[0].HostPublicKey.String() != hostKey.String() {
+		t.Fatal("record has the wrong contract:", records[0])
+	}
+	if !records[0].StorageSpending.Equals64(3) || !records[0].UploadSpending.Equals64(4) || !records[1].DownloadSpending.Equals64(5) {
+		t.Fatal("records have the wrong spending:", records)
+	}
+
+	// Records of other hosts and outside of the time range are filtered.
+	records, err = cs.SpendingHistory(types.SiaPublicKey{Key: []byte("other")}, time.Unix(0, 0), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	} else if len(records) != 0 {
+		t.Fatal("expected no records for other host, got", len(records))
+	}
+	records, err = cs.SpendingHistory(types.SiaPublicKey{}, time.Unix(0, 0), time.Unix(1, 0))
+	if err != nil {
+		t.Fatal(err)
+	} else if len(records) != 0 {
+		t.Fatal("expected no records in the past, got", len(records))
+	}
+
+	// Simulate an unclean shutdown that left a partial record behind.
+	if err := cs.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(filepath.Join(cs.dir, spendingLedgerFilename), os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte(`{"timestamp":`)); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	// Reload the contract set and add another record. The partial record
+	// should be skipped.
+	cs, err = NewContractSet(cs.dir, persist.NewLogger(ioutil.Discard), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+	sc = cs.mustAcquire(t, header.ID())
+	sc.recordSpending(modules.RenterSpendingRecord{Fees: types.NewCurrency64(6)})
+	cs.Return(sc)
+	records, err = cs.SpendingHistory(types.SiaPublicKey{}, time.Unix(0, 0), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || !records[2].Fees.Equals64(6) {
+		t.Fatal("expected 3 records after reload, got", records)
+	}
+}
+
+// TestSpendingLedgerRevisions tests that committed revisions are recorded in
+// the spending ledger.
+func TestSpendingLedgerRevisions(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	hostKey := types.SiaPublicKey{Key: []byte("host")}
+	cs, header := newLedgerTestContract(t, hostKey)
+	defer cs.Close()
+
+	// Upload and download a sector.
+	sc := cs.mustAcquire(t, header.ID())
+	rev := header.LastRevision()
+	root := crypto.Hash{1}
+	walTxn, err := sc.recordUploadIntent(rev, root, types.NewCurrency64(3), types.NewCurrency64(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sc.commitUpload(walTxn, header.Transaction, root, types.NewCurrency64(3), types.NewCurrency64(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	walTxn, err = sc.recordDownloadIntent(rev, types.NewCurrency64(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.commitDownload(walTxn, header.Transaction, types.NewCurrency64(5)); err != nil {
+		t.Fatal(err)
+	}
+	cs.Return(sc)
+
+	records, err := cs.SpendingHistory(hostKey, time.Unix(0, 0), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatal("expected 2 records, got", len(records))
+	}
+	if !records[0].StorageSpending.Equals64(3) || !records[0].UploadSpending.Equals64(4) {
+		t.Fatal("upload record is wrong:", records[0])
+	}
+	if !records[1].DownloadSpending.Equals64(5) {
+		t.Fatal("download record is wrong:", records[1])
+	}
+}
+
+// TestSpendingLedgerFailure tests that a failure to record spending is
+// returned and logged, without failing the revision.
+func TestSpendingLedgerFailure(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	hostKey := types.SiaPublicKey{Key: []byte("host")}
+	cs, header := newLedgerTestContract(t, hostKey)
+	defer cs.Close()
+
+	// Close the ledger so that no more records can be written.
+	if err := cs.ledger.close(); err != nil {
+		t.Fatal(err)
+	}
+	sc := cs.mustAcquire(t, header.ID())
+	defer cs.Return(sc)
+	if err := sc.recordSpending(modules.RenterSpendingRecord{Fees: types.NewCurrency64(1)}); err == nil {
+		t.Fatal("expected recording to fail")
+	}
+
+	// Committing a download succeeds, but the lost record is logged.
+	var buf bytes.Buffer
+	sc.log = persist.NewLogger(&buf)
+	walTxn, err := sc.recordDownloadIntent(header.LastRevision(), types.NewCurrency64(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.commitDownload(walTxn, header.Transaction, types.NewCurrency64(5)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Unable to record spending of download") {
+		t.Fatal("lost spending record was not logged:", buf.String())
+	}
+}
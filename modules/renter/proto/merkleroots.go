@@ -187,6 +187,42 @@ func (mr *merkleRoots) delete(i int, lastRoot crypto.Hash, truncateSize int64) e
 	return nil
 }
 
+// rewrite replaces all the roots starting at index offset with roots and
+// truncates the file after the last of them. Since the full tail of the roots
+// is overwritten, the operation is indempotent.
+func (mr *merkleRoots) rewrite(offset int, roots []crypto.Hash) error {
+	if offset > mr.numMerkleRoots {
+		return fmt.Errorf("offset %v is beyond the last root %v", offset, mr.numMerkleRoots)
+	}
+	// Write the new roots to disk and truncate the file after them.
+	data := make([]byte, 0, len(roots)*crypto.HashSize)
+	for _, root := range roots {
+		data = append(data, root[:]...)
+	}
+	if _, err := mr.rootsFile.WriteAt(data, fileOffsetFromRootIndex(offset)); err != nil {
+		return errors.AddContext(err, "failed to write roots to disk")
+	}
+	if err := mr.rootsFile.Truncate(fileOffsetFromRootIndex(offset + len(roots))); err != nil {
+		return errors.AddContext(err, "failed to truncate file")
+	}
+	mr.numMerkleRoots = offset + len(roots)
+
+	// Drop the cached subTrees that contain rewritten roots and rebuild the
+	// in-memory structure from the roots on disk.
+	firstTree := offset / merkleRootsPerCache
+	if firstTree > len(mr.cachedSubTrees) {
+		firstTree = len(mr.cachedSubTrees)
+	}
+	mr.cachedSubTrees = mr.cachedSubTrees[:firstTree]
+	mr.uncachedRoots = mr.uncachedRoots[:0]
+	remaining, err := mr.merkleRootsFromIndexFromDisk(firstTree*merkleRootsPerCache, mr.numMerkleRoots)
+	if err != nil {
+		return errors.AddContext(err, "failed to read rewritten roots")
+	}
+	mr.appendRootMemory(remaining...)
+	return nil
+}
+
 // insert inserts a root by replacing a root at an existing index.
 func (mr *merkleRoots) insert(index int, root crypto.Hash) error {
 	// If the index does point to an offset beyond the end of the file we fill
@@ -381,6 +381,72 @@ func TestDelete(t *testing.T) {
 	}
 }
 
+// TestRewrite tests that rewriting the tail of the merkle roots works and is
+// idempotent.
+func TestRewrite(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	dir := build.TempDir(t.Name())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	filePath := path.Join(dir, "file.dat")
+	file, err := os.Create(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create many sector roots.
+	numMerkleRoots := 1000
+	rootSection := newFileSection(file, 0, -1)
+	merkleRoots := newMerkleRoots(rootSection)
+	for i := 0; i < numMerkleRoots; i++ {
+		hash := crypto.Hash{}
+		copy(hash[:], fastrand.Bytes(crypto.HashSize)[:])
+		merkleRoots.push(hash)
+	}
+
+	for merkleRoots.numMerkleRoots > 0 {
+		// Randomly drop some of the roots after a random offset.
+		roots, err := merkleRoots.merkleRoots()
+		if err != nil {
+			t.Fatal(err)
+		}
+		offset := fastrand.Intn(len(roots))
+		var tail []crypto.Hash
+		for _, root := range roots[offset+1:] {
+			if fastrand.Intn(4) != 0 {
+				tail = append(tail, root)
+			}
+		}
+		expected := append(roots[:offset:offset], tail...)
+
+		// Call rewrite twice to make sure it's idempotent.
+		if err := merkleRoots.rewrite(offset, tail); err != nil {
+			t.Fatal(err)
+		}
+		if err := merkleRoots.rewrite(offset, tail); err != nil {
+			t.Fatal(err)
+		}
+
+		// The roots on disk should match the expected roots and the cache
+		// should match the cache of freshly loaded roots.
+		if roots, err := merkleRoots.merkleRoots(); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(roots, expected) {
+			t.Fatal("roots on disk don't match the expected roots")
+		}
+		loadedRoots, err := loadExistingMerkleRoots(merkleRoots.rootsFile)
+		if err != nil {
+			t.Fatal("failed to load existing roots", err)
+		}
+		if err := cmpRoots(loadedRoots, merkleRoots); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
 // TestMerkleRootsRandom creates a large number of merkle roots and runs random
 // valid operations on them that shouldn't result in any errors.
 func TestMerkleRootsRandom(t *testing.T) {
@@ -215,10 +215,10 @@ func newUploadRevision(current types.FileContractRevision, merkleRoot crypto.Has
 }
 
 // newDeleteRevision revises the current revision to cover the cost of
-// deleting a sector.
-func newDeleteRevision(current types.FileContractRevision, merkleRoot crypto.Hash) types.FileContractRevision {
+// deleting numSectors sectors.
+func newDeleteRevision(current types.FileContractRevision, numSectors uint64, merkleRoot crypto.Hash) types.FileContractRevision {
 	rev := newRevision(current, types.ZeroCurrency)
-	rev.NewFileSize -= modules.SectorSize
+	rev.NewFileSize -= modules.SectorSize * numSectors
 	rev.NewFileMerkleRoot = merkleRoot
 	return rev
 }
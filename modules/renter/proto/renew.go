@@ -301,5 +301,15 @@ func (cs *ContractSet) Renew(oldContract *SafeContract, params ContractParams, t
 	if err != nil {
 		return modules.RenterContract{}, err
 	}
+	err = cs.ledger.record(modules.RenterSpendingRecord{
+		ContractID:      meta.ID,
+		EndHeight:       meta.EndHeight,
+		HostPublicKey:   meta.HostPublicKey,
+		Fees:            header.ContractFee.Add(header.TxnFee).Add(header.SiafundFee),
+		StorageSpending: header.StorageSpending,
+	})
+	if err != nil {
+		cs.log.Println("Unable to record spending of contract renewal:", err)
+	}
 	return meta, nil
 }
@@ -0,0 +1,170 @@
+package renter
+
+// quote.go estimates the cost of a specific upload or download. The pieces of
+// the job are placed on the cheapest hosts that the renter has contracts with
+// that are good for uploading, and on random hosts from the hostdb once those
+// run out, the same way that the renter would place them. The estimate does
+// not include the siafund fee and the collateral of new contracts.
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errQuoteNoDuration is returned when a quote is requested for a job
+	// without a duration.
+	errQuoteNoDuration = errors.New("duration of the job must be at least one block")
+
+	// errQuoteNoSize is returned when a quote is requested for a job without
+	// any data.
+	errQuoteNoSize = errors.New("size of the job must be at least one byte")
+)
+
+// A quoteContract is a contract that can be used for a quoted job together
+// with the current prices of its host.
+type quoteContract struct {
+	contract modules.RenterContract
+	host     modules.HostDBEntry
+}
+
+// quoteHostCost returns the cost of storing and transferring the pieces of a
+// job on a single host.
+func quoteHostCost(host modules.HostDBEntry, bytesPerHost, downloadPerHost uint64, duration types.BlockHeight) (storage, upload, download types.Currency) {
+	storage = host.StoragePrice.Mul64(bytesPerHost).Mul64(uint64(duration))
+	upload = host.UploadBandwidthPrice.Mul64(bytesPerHost)
+	download = host.DownloadBandwidthPrice.Mul64(downloadPerHost)
+	return
+}
+
+// buildQuote estimates the cost of a job using the given contracts and new
+// hosts. contractFee is the transaction fee of forming or renewing a contract
+// and unallocated are the funds of the allowance that are not yet allocated
+// to contracts.
+func buildQuote(params modules.RenterQuoteParams, contracts []quoteContract, newHosts []modules.HostDBEntry, height types.BlockHeight, contractFee, unallocated types.Currency) (modules.RenterQuote, error) {
+	ec := params.ErasureCode
+	numChunks := params.Size / (modules.SectorSize * uint64(ec.MinPieces()))
+	if params.Size%(modules.SectorSize*uint64(ec.MinPieces())) != 0 {
+		numChunks++
+	}
+	bytesPerHost := numChunks * modules.SectorSize
+	downloadPerHost := params.ExpectedDownload / uint64(ec.NumPieces())
+	cost := func(host modules.HostDBEntry) types.Currency {
+		storage, upload, download := quoteHostCost(host, bytesPerHost, downloadPerHost, params.Duration)
+		return storage.Add(upload).Add(download)
+	}
+
+	// Use the cheapest contracts first and form new contracts for the
+	// remaining pieces.
+	sort.Slice(contracts, func(i, j int) bool {
+		return cost(contracts[i].host).Cmp(cost(contracts[j].host)) < 0
+	})
+	if len(contracts) > ec.NumPieces() {
+		contracts = contracts[:ec.NumPieces()]
+	}
+	needed := ec.NumPieces() - len(contracts)
+	if len(newHosts) < needed {
+		return modules.RenterQuote{}, fmt.Errorf("not enough hosts to quote job: got %v, needed %v", len(contracts)+len(newHosts), ec.NumPieces())
+	}
+	newHosts = newHosts[:needed]
+
+	quote := modules.RenterQuote{
+		UploadBytes:      bytesPerHost * uint64(ec.NumPieces()),
+		NewHosts:         needed,
+		UnallocatedFunds: unallocated,
+	}
+	addHost := func(host modules.HostDBEntry) types.Currency {
+		storage, upload, download := quoteHostCost(host, bytesPerHost, downloadPerHost, params.Duration)
+		quote.StorageCost = quote.StorageCost.Add(storage)
+		quote.UploadCost = quote.UploadCost.Add(upload)
+		quote.DownloadCost = quote.DownloadCost.Add(download)
+		return storage.Add(upload).Add(download)
+	}
+	for _, c := range contracts {
+		hostCost := addHost(c.host)
+		var reason string
+		if c.contract.EndHeight < height+params.Duration {
+			reason = "contract ends before the job does"
+		} else if c.contract.RenterFunds.Cmp(hostCost) < 0 {
+			reason = "contract has insufficient funds"
+		} else {
+			continue
+		}
+		renewCost := c.host.ContractPrice.Add(contractFee)
+		quote.ContractCost = quote.ContractCost.Add(renewCost)
+		quote.RequiredFunds = quote.RequiredFunds.Add(hostCost).Add(renewCost)
+		quote.RefreshContracts = append(quote.RefreshContracts, modules.RenterQuoteContract{
+			ID:            c.contract.ID,
+			HostPublicKey: c.contract.HostPublicKey,
+			Reason:        reason,
+			RequiredFunds: hostCost,
+		})
+	}
+	for _, host := range newHosts {
+		hostCost := addHost(host)
+		formCost := host.ContractPrice.Add(contractFee)
+		quote.ContractCost = quote.ContractCost.Add(formCost)
+		quote.RequiredFunds = quote.RequiredFunds.Add(hostCost).Add(formCost)
+	}
+
+	quote.TotalCost = quote.StorageCost.Add(quote.UploadCost).Add(quote.DownloadCost).Add(quote.ContractCost)
+	quote.AllowanceSufficient = quote.RequiredFunds.Cmp(unallocated) <= 0
+	return quote, nil
+}
+
+// Quote estimates the cost of uploading, storing and downloading data using
+// the prices of the hosts that the renter has contracts with and of new hosts
+// from the hostdb.
+func (r *Renter) Quote(params modules.RenterQuoteParams) (modules.RenterQuote, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.RenterQuote{}, err
+	}
+	defer r.tg.Done()
+	if params.Size == 0 {
+		return modules.RenterQuote{}, errQuoteNoSize
+	}
+	if params.Duration == 0 {
+		return modules.RenterQuote{}, errQuoteNoDuration
+	}
+	if params.ErasureCode == nil {
+		params.ErasureCode, _ = NewRSCode(defaultDataPieces, defaultParityPieces)
+	}
+
+	// Collect the contracts that can be uploaded to, priced by the current
+	// settings of their hosts.
+	var contracts []quoteContract
+	var exclude []types.SiaPublicKey
+	for _, c := range r.hostContractor.Contracts() {
+		exclude = append(exclude, c.HostPublicKey)
+		if !c.Utility.GoodForUpload {
+			continue
+		}
+		host, ok := r.hostDB.Host(c.HostPublicKey)
+		if !ok {
+			continue
+		}
+		contracts = append(contracts, quoteContract{contract: c, host: host})
+	}
+	var newHosts []modules.HostDBEntry
+	if needed := params.ErasureCode.NumPieces() - len(contracts); needed > 0 {
+		var err error
+		newHosts, err = r.hostDB.RandomHosts(needed, exclude)
+		if err != nil {
+			return modules.RenterQuote{}, err
+		}
+	}
+
+	_, feePerByte := r.tpool.FeeEstimation()
+	contractFee := feePerByte.Mul64(modules.EstimatedFileContractTransactionSetSize)
+	var unallocated types.Currency
+	allowance := r.hostContractor.Allowance()
+	allocated := r.hostContractor.PeriodSpending().TotalAllocated
+	if allowance.Funds.Cmp(allocated) > 0 {
+		unallocated = allowance.Funds.Sub(allocated)
+	}
+	return buildQuote(params, contracts, newHosts, r.cs.Height(), contractFee, unallocated)
+}
@@ -0,0 +1,89 @@
+package renter
+
+import (
+	"testing"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestBuildQuote checks that quotes use the cheapest contracts, form new
+// contracts for the remaining pieces and report which contracts need
+// refreshing.
+func TestBuildQuote(t *testing.T) {
+	ec, _ := NewRSCode(1, 2)
+	params := modules.RenterQuoteParams{
+		Size:             modules.SectorSize + 1,
+		ErasureCode:      ec,
+		Duration:         10,
+		ExpectedDownload: 3,
+	}
+	host := func(price uint64) modules.HostDBEntry {
+		var h modules.HostDBEntry
+		h.ContractPrice = types.NewCurrency64(1000)
+		h.StoragePrice = types.NewCurrency64(price)
+		h.UploadBandwidthPrice = types.NewCurrency64(price)
+		h.DownloadBandwidthPrice = types.NewCurrency64(price)
+		return h
+	}
+	contract := func(id byte, endHeight types.BlockHeight, funds uint64) modules.RenterContract {
+		return modules.RenterContract{
+			ID:            types.FileContractID{id},
+			HostPublicKey: types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{id}},
+			EndHeight:     endHeight,
+			RenterFunds:   types.NewCurrency64(funds),
+		}
+	}
+
+	// Each host stores 2 sectors for 10 blocks, uploads 2 sectors and
+	// serves 1 byte of the downloads.
+	bytesPerHost := 2 * modules.SectorSize
+	hostCost := func(price uint64) types.Currency {
+		return types.NewCurrency64(price * (bytesPerHost*10 + bytesPerHost + 1))
+	}
+
+	// The most expensive contract should not be used, the cheapest contract
+	// ends too early and the second cheapest one lacks funds.
+	contracts := []quoteContract{
+		{contract(1, 200, 1e18), host(5)},
+		{contract(2, 105, 1e18), host(1)},
+		{contract(3, 200, 1), host(2)},
+		{contract(4, 200, 1e18), host(3)},
+	}
+	fee := types.NewCurrency64(10)
+	quote, err := buildQuote(params, contracts, nil, 100, fee, types.NewCurrency64(1e18))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.UploadBytes != 3*bytesPerHost || quote.NewHosts != 0 {
+		t.Fatal("wrong quote:", quote)
+	}
+	if !quote.TotalCost.Equals(hostCost(1).Add(hostCost(2)).Add(hostCost(3)).Add(types.NewCurrency64(2 * 1010))) {
+		t.Fatal("wrong total cost:", quote.TotalCost)
+	}
+	if len(quote.RefreshContracts) != 2 || quote.RefreshContracts[0].ID != (types.FileContractID{2}) || quote.RefreshContracts[1].ID != (types.FileContractID{3}) {
+		t.Fatal("wrong contracts to refresh:", quote.RefreshContracts)
+	}
+	if !quote.RequiredFunds.Equals(hostCost(1).Add(hostCost(2)).Add(types.NewCurrency64(2*1010))) || !quote.AllowanceSufficient {
+		t.Fatal("wrong required funds:", quote.RequiredFunds)
+	}
+
+	// New contracts should be formed for the pieces that don't fit on the
+	// existing contracts.
+	contracts = []quoteContract{{contract(4, 200, 1e18), host(3)}}
+	quote, err = buildQuote(params, contracts, []modules.HostDBEntry{host(1), host(1)}, 100, fee, types.NewCurrency64(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.NewHosts != 2 || len(quote.RefreshContracts) != 0 || quote.AllowanceSufficient {
+		t.Fatal("wrong quote:", quote)
+	}
+	if !quote.RequiredFunds.Equals(hostCost(1).Mul64(2).Add(types.NewCurrency64(2 * 1010))) {
+		t.Fatal("wrong required funds:", quote.RequiredFunds)
+	}
+
+	// Quotes should fail if there are not enough hosts.
+	if _, err := buildQuote(params, contracts, []modules.HostDBEntry{host(1)}, 100, fee, types.ZeroCurrency); err == nil {
+		t.Fatal("expected an error for a missing host")
+	}
+}
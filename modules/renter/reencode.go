@@ -0,0 +1,248 @@
+package renter
+
+// reencode.go migrates files to new erasure code settings. The new layout of a
+// file is uploaded by the upload loop like any other file, using the old
+// layout to download the data if the file is not available locally. The old
+// layout keeps being repaired and used for downloads until every piece of the
+// new layout is stored on a contract that is good for renewing, after which
+// the file is replaced by its new layout and the pieces of the old layout are
+// garbage collected. This way the redundancy of the file never drops during
+// the migration.
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+)
+
+var (
+	// errAlreadyReencoding is returned when a file is re-encoded while it is
+	// still being re-encoded.
+	errAlreadyReencoding = errors.New("file is already being re-encoded")
+
+	// errSameErasureCode is returned when a file is re-encoded with the
+	// erasure code settings that it already uses.
+	errSameErasureCode = errors.New("file already uses these erasure code settings")
+
+	// errUntrackedFile is returned when a file is re-encoded that is not
+	// repaired by the renter.
+	errUntrackedFile = errors.New("file is not tracked by the renter")
+)
+
+// reencodeProgress indicates what percentage of the new layout of a file that
+// is being re-encoded has been uploaded. The file needs to be locked.
+func (f *file) reencodeProgress() float64 {
+	if f.reencode == nil {
+		return 0
+	}
+	f.reencode.mu.RLock()
+	defer f.reencode.mu.RUnlock()
+	return f.reencode.uploadProgress()
+}
+
+// reencodeRequiredContracts returns the number of contracts that are required
+// to start re-encoding a file, the same as for uploads.
+func reencodeRequiredContracts(ec modules.ErasureCoder) int {
+	return (ec.NumPieces() + ec.MinPieces()) / 2
+}
+
+// reencodeComplete returns whether every piece of the new layout of a file is
+// stored on a contract that is good for renewing. The new layout needs to be
+// locked.
+func (r *Renter) reencodeComplete(f *file) bool {
+	pieces := make([]map[uint64]struct{}, f.numChunks())
+	for fcid, fc := range f.contracts {
+		utility, ok := r.hostContractor.ContractUtility(r.hostContractor.ResolveIDToPubKey(fcid))
+		if !ok || !utility.GoodForRenew {
+			continue
+		}
+		for _, piece := range fc.Pieces {
+			if pieces[piece.Chunk] == nil {
+				pieces[piece.Chunk] = make(map[uint64]struct{})
+			}
+			pieces[piece.Chunk][piece.Piece] = struct{}{}
+		}
+	}
+	for _, chunkPieces := range pieces {
+		if len(chunkPieces) < f.erasureCode.NumPieces() {
+			return false
+		}
+	}
+	return true
+}
+
+// buildUnfinishedReencodeChunks returns the unfinished chunks of the new
+// layout of a file that is being re-encoded.
+func (r *Renter) buildUnfinishedReencodeChunks(f *file, hosts map[string]struct{}) []*unfinishedUploadChunk {
+	f.mu.RLock()
+	reencode := f.reencode
+	f.mu.RUnlock()
+	if reencode == nil {
+		return nil
+	}
+	chunks := r.buildUnfinishedChunks(reencode, hosts)
+	for _, chunk := range chunks {
+		chunk.sourceFile = f
+	}
+	return chunks
+}
+
+// managedFinishReencodes replaces the files whose new layout has been fully
+// uploaded with their new layout. The pieces of the old layout are marked for
+// garbage collection.
+func (r *Renter) managedFinishReencodes() {
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+
+	finished := false
+	for name, f := range r.files {
+		f.mu.Lock()
+		reencode := f.reencode
+		if reencode == nil {
+			f.mu.Unlock()
+			continue
+		}
+		reencode.mu.Lock()
+		if !r.reencodeComplete(reencode) {
+			reencode.mu.Unlock()
+			f.mu.Unlock()
+			continue
+		}
+
+		// Replace the .sia file of the file with the new layout.
+		reencode.isReencode = false
+		if err := r.saveFile(reencode); err != nil {
+			r.log.Println("WARN: unable to save re-encoded file:", err)
+			reencode.isReencode = true
+			reencode.mu.Unlock()
+			f.mu.Unlock()
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(r.persistDir, name+reencodeExtension)); err != nil {
+			r.log.Println("WARN: unable to remove new layout of re-encoded file:", err)
+		}
+		r.files[name] = reencode
+		reencode.mu.Unlock()
+
+		// Retire the old layout. Downloads that are still using it can
+		// finish, since its pieces are only deleted from the hosts later.
+		f.reencode = nil
+		f.deleted = true
+		r.addGCCandidates(f)
+		f.mu.Unlock()
+		finished = true
+		r.log.Printf("Finished re-encoding %v", name)
+	}
+	if finished {
+		if err := r.saveSync(); err != nil {
+			r.log.Println("WARN: unable to save garbage collection candidates:", err)
+		}
+	}
+}
+
+// loadReencodeFiles loads the new layouts of the files that are being
+// re-encoded. New layouts of files that no longer exist are removed.
+func (r *Renter) loadReencodeFiles() error {
+	return filepath.Walk(r.persistDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			r.log.Println("WARN: could not stat file or folder during walk:", err)
+			return nil
+		}
+		if info.IsDir() || filepath.Ext(path) != reencodeExtension {
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			r.log.Println("ERROR: could not open re-encoded file:", err)
+			return nil
+		}
+		defer file.Close()
+		files, err := readSharedFiles(file)
+		if err != nil || len(files) != 1 {
+			r.log.Println("ERROR: could not load re-encoded file:", err)
+			return nil
+		}
+		reencode := files[0]
+		reencode.isReencode = true
+
+		f, exists := r.files[reencode.name]
+		if !exists {
+			r.log.Println("WARN: removing new layout of unknown re-encoded file:", reencode.name)
+			return os.RemoveAll(path)
+		}
+		f.reencode = reencode
+		return nil
+	})
+}
+
+// Reencode starts re-encoding a file with new erasure code settings. The file
+// is re-encoded in the background by the upload loop.
+func (r *Renter) Reencode(siaPath string, ec modules.ErasureCoder) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+
+	// Check that we have enough contracts to upload the new layout, the same
+	// way as for uploads.
+	numContracts := len(r.hostContractor.Contracts())
+	requiredContracts := reencodeRequiredContracts(ec)
+	if numContracts < requiredContracts && build.Release != "testing" {
+		return fmt.Errorf("not enough contracts to re-encode file: got %v, needed %v", numContracts, requiredContracts)
+	}
+
+	id := r.mu.Lock()
+	f, exists := r.files[siaPath]
+	if !exists {
+		r.mu.Unlock(id)
+		return ErrUnknownPath
+	}
+	if _, tracked := r.persist.Tracking[siaPath]; !tracked {
+		r.mu.Unlock(id)
+		return errUntrackedFile
+	}
+	f.mu.Lock()
+	if f.reencode != nil {
+		f.mu.Unlock()
+		r.mu.Unlock(id)
+		return errAlreadyReencoding
+	}
+	if f.erasureCode.NumPieces() == ec.NumPieces() && f.erasureCode.MinPieces() == ec.MinPieces() {
+		f.mu.Unlock()
+		r.mu.Unlock(id)
+		return errSameErasureCode
+	}
+
+	// Create the new layout of the file and save it next to the file.
+	reencode := newFile(f.name, ec, f.pieceSize, f.size)
+	reencode.mode = f.mode
+	reencode.isReencode = true
+	err := r.saveFile(reencode)
+	if err == nil {
+		f.reencode = reencode
+	}
+	f.mu.Unlock()
+	r.mu.Unlock(id)
+	if err != nil {
+		return err
+	}
+
+	// Send the new layout to the upload loop.
+	hosts := r.managedRefreshHostsAndWorkers()
+	id = r.mu.Lock()
+	unfinishedChunks := r.buildUnfinishedReencodeChunks(f, hosts)
+	r.mu.Unlock(id)
+	for i := 0; i < len(unfinishedChunks); i++ {
+		r.uploadHeap.managedPush(unfinishedChunks[i])
+	}
+	select {
+	case r.uploadHeap.newUploads <- struct{}{}:
+	default:
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package renter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
+	siasync "github.com/NebulousLabs/Sia/sync"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestFinishReencode checks that a file is only replaced by its new layout once
+// every piece of the new layout has been uploaded, and that the new layout
+// survives a restart.
+func TestFinishReencode(t *testing.T) {
+	dir := build.TempDir("renter", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	log, err := persist.NewFileLogger(filepath.Join(dir, logFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostA := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte("hostA")}
+	hostB := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte("hostB")}
+	r := &Renter{
+		files: make(map[string]*file),
+		hostContractor: mapContractor{
+			hosts: map[types.FileContractID]types.SiaPublicKey{
+				{1}: hostA,
+				{2}: hostB,
+			},
+			utilities: map[string]modules.ContractUtility{
+				hostA.String(): {GoodForRenew: true},
+				hostB.String(): {GoodForRenew: true},
+			},
+		},
+		log:        log,
+		mu:         siasync.New(modules.SafeMutexDelay, 1),
+		persistDir: dir,
+	}
+
+	// Create a 1-of-2 file that is being re-encoded as a 1-of-3 file. The new
+	// layout only has a single piece, but it needs all three pieces to replace
+	// the old layout.
+	addPieces := func(f *file, id types.FileContractID, pieces ...uint64) {
+		fc := f.contracts[id]
+		fc.ID = id
+		for _, piece := range pieces {
+			fc.Pieces = append(fc.Pieces, pieceData{Piece: piece, MerkleRoot: crypto.Hash{byte(id[0]), byte(piece)}})
+		}
+		f.contracts[id] = fc
+	}
+	oldCode, _ := NewRSCode(1, 1)
+	newCode, _ := NewRSCode(1, 2)
+	f := newFile("foo", oldCode, 64, 64)
+	addPieces(f, types.FileContractID{1}, 0, 1)
+	r.files[f.name] = f
+	reencode := newFile(f.name, newCode, f.pieceSize, f.size)
+	reencode.isReencode = true
+	addPieces(reencode, types.FileContractID{2}, 0)
+	f.reencode = reencode
+	if err := r.saveFile(reencode); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file should not be replaced yet.
+	r.managedFinishReencodes()
+	if r.files[f.name] != f || f.reencode != reencode {
+		t.Fatal("file was replaced before its new layout was uploaded")
+	}
+	if progress := f.reencodeProgress(); progress <= 33 || progress >= 34 {
+		t.Fatal("wrong re-encode progress:", progress)
+	}
+
+	// Reload the new layout from disk.
+	f.reencode = nil
+	if err := r.loadReencodeFiles(); err != nil {
+		t.Fatal(err)
+	}
+	reencode = f.reencode
+	if reencode == nil || !reencode.isReencode || reencode.erasureCode.NumPieces() != 3 || len(reencode.contracts) != 1 {
+		t.Fatal("new layout was not loaded correctly:", reencode)
+	}
+
+	// Upload a second piece. The new layout now has as many pieces as an
+	// upload needs contracts to start, but the old layout has to be kept
+	// until the third piece is uploaded.
+	addPieces(reencode, types.FileContractID{1}, 1)
+	r.managedFinishReencodes()
+	if r.files[f.name] != f || f.reencode != reencode || f.deleted {
+		t.Fatal("file was replaced before all pieces of its new layout were uploaded")
+	}
+	if gc := r.GarbageCollection(); gc.PendingSectors != 0 {
+		t.Fatal("old pieces were garbage collected before the new layout was uploaded:", gc.PendingSectors)
+	}
+
+	// Upload the third piece. The file should be replaced by its new layout,
+	// and the old pieces should be garbage collected.
+	addPieces(reencode, types.FileContractID{2}, 2)
+	r.managedFinishReencodes()
+	if r.files[f.name] != reencode || reencode.isReencode || f.reencode != nil || !f.deleted {
+		t.Fatal("file was not replaced by its new layout")
+	}
+	if _, err := os.Stat(filepath.Join(dir, f.name+reencodeExtension)); !os.IsNotExist(err) {
+		t.Fatal("new layout was not removed from disk:", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, f.name+ShareExtension)); err != nil {
+		t.Fatal("re-encoded file was not saved:", err)
+	}
+	if gc := r.GarbageCollection(); gc.PendingSectors != 2 {
+		t.Fatal("expected the 2 old pieces to be garbage collected, got", gc.PendingSectors)
+	}
+}
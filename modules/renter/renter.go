@@ -25,8 +25,10 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/modules/renter/contractor"
 	"github.com/NebulousLabs/Sia/modules/renter/hostdb"
@@ -86,6 +88,12 @@ type hostDB interface {
 	// any offline or inactive hosts.
 	RandomHosts(int, []types.SiaPublicKey) ([]modules.HostDBEntry, error)
 
+	// RecordAudit records the result of a storage audit of a host.
+	RecordAudit(types.SiaPublicKey, bool) error
+
+	// RecordBenchmark adds a performance benchmark to the history of a host.
+	RecordBenchmark(types.SiaPublicKey, modules.HostDBBenchmark) error
+
 	// ScoreBreakdown returns a detailed explanation of the various properties
 	// of the host.
 	ScoreBreakdown(modules.HostDBEntry) modules.HostScoreBreakdown
@@ -104,6 +112,9 @@ type hostContractor interface {
 	// soon as SetAllowance is called; that is, it may block.
 	SetAllowance(modules.Allowance) error
 
+	// Alerts returns the alerts of the contractor.
+	Alerts() []modules.Alert
+
 	// Allowance returns the current allowance
 	Allowance() modules.Allowance
 
@@ -123,14 +134,35 @@ type hostContractor interface {
 	// with a bool indicating if it exists.
 	ContractUtility(types.SiaPublicKey) (modules.ContractUtility, bool)
 
+	// CancelContract stops a contract from being renewed or used for
+	// uploads.
+	CancelContract(types.FileContractID) error
+
 	// CurrentPeriod returns the height at which the current allowance period
 	// began.
 	CurrentPeriod() types.BlockHeight
 
+	// FormContract forms a contract with a specific host.
+	FormContract(types.SiaPublicKey, types.Currency, types.BlockHeight) (modules.RenterContract, error)
+
 	// PeriodSpending returns the amount spent on contracts during the current
 	// billing period.
 	PeriodSpending() modules.ContractorSpending
 
+	// RenewContract renews a contract right away.
+	RenewContract(types.FileContractID, types.Currency) error
+
+	// ReportFailedAudit marks a contract as bad because its host failed a
+	// storage audit.
+	ReportFailedAudit(types.FileContractID) error
+
+	// SpendingHistory returns the spending records of a host within a time
+	// range.
+	SpendingHistory(types.SiaPublicKey, time.Time, time.Time) ([]modules.RenterSpendingRecord, error)
+
+	// StopRenewingContract prevents a contract from being renewed.
+	StopRenewingContract(types.FileContractID) error
+
 	// Editor creates an Editor from the specified contract ID, allowing the
 	// insertion, deletion, and modification of sectors.
 	Editor(types.SiaPublicKey, <-chan struct{}) (contractor.Editor, error)
@@ -193,6 +225,10 @@ type Renter struct {
 	downloadHistory   []*download
 	downloadHistoryMu sync.Mutex
 
+	// Transfer history. The persistent record of all finished downloads,
+	// uploads and repairs.
+	transfers *transferLog
+
 	// Upload management.
 	uploadHeap uploadHeap
 
@@ -203,7 +239,17 @@ type Renter struct {
 	// Cache the last price estimation result.
 	lastEstimation modules.RenterPriceEstimation
 
+	// tenantUsageChanged is set when the usage of a tenant has changed and
+	// has not been saved yet.
+	tenantUsageChanged bool
+
+	// tenantAuth holds the recent authentications of the tenants, and
+	// tenantAuthKey is the key of the MACs of their credentials.
+	tenantAuth    map[string]*tenantAuth
+	tenantAuthKey crypto.Hash
+
 	// Utilities.
+	staticAlerter     *modules.Alerter
 	staticStreamCache *streamCache
 	cs                modules.ConsensusSet
 	deps              modules.Dependencies
@@ -238,7 +284,8 @@ func (r *Renter) PriceEstimation() modules.RenterPriceEstimation {
 		return lastEstimation
 	}
 
-	// Grab hosts to perform the estimation.
+	// Grab hosts to perform the estimation. The hostdb will only return hosts
+	// that are within the price limits of the allowance.
 	hosts, err := r.hostDB.RandomHosts(priceEstimationScope, nil)
 	if err != nil {
 		return modules.RenterPriceEstimation{}
@@ -331,12 +378,20 @@ func (r *Renter) SetSettings(s modules.RenterSettings) error {
 	if s.StreamCacheSize <= 0 {
 		return errors.New("stream cache size needs to be 1 or larger")
 	}
+	if err := r.checkTenantUploadDir(s.TenantUploadDir); err != nil {
+		return err
+	}
 
 	// Set allowance.
 	err := r.hostContractor.SetAllowance(s.Allowance)
 	if err != nil {
 		return err
 	}
+	// The price limits of the allowance affect which hosts are used for the
+	// price estimation, so the cached estimation needs to be cleared.
+	id := r.mu.Lock()
+	r.lastEstimation = modules.RenterPriceEstimation{}
+	r.mu.Unlock(id)
 
 	// Set the bandwidth limits.
 	err = r.setBandwidthLimits(s.MaxDownloadSpeed, s.MaxUploadSpeed)
@@ -353,6 +408,12 @@ func (r *Renter) SetSettings(s modules.RenterSettings) error {
 	}
 	r.persist.StreamCacheSize = s.StreamCacheSize
 
+	// Set whether hosts should be benchmarked and where tenants upload from.
+	id = r.mu.Lock()
+	r.persist.BenchmarkHosts = s.BenchmarkHosts
+	r.persist.TenantUploadDir = s.TenantUploadDir
+	r.mu.Unlock(id)
+
 	// Save the changes.
 	err = r.saveSync()
 	if err != nil {
@@ -391,6 +452,27 @@ func (r *Renter) EstimateHostScore(e modules.HostDBEntry) modules.HostScoreBreak
 // Contracts returns an array of host contractor's staticContracts
 func (r *Renter) Contracts() []modules.RenterContract { return r.hostContractor.Contracts() }
 
+// CancelContract stops a contract from being renewed or used for uploads.
+func (r *Renter) CancelContract(id types.FileContractID) error {
+	return r.hostContractor.CancelContract(id)
+}
+
+// FormContract forms a contract with a specific host.
+func (r *Renter) FormContract(hostKey types.SiaPublicKey, funding types.Currency, endHeight types.BlockHeight) (modules.RenterContract, error) {
+	return r.hostContractor.FormContract(hostKey, funding, endHeight)
+}
+
+// RenewContract renews a contract right away instead of waiting for the renew
+// window.
+func (r *Renter) RenewContract(id types.FileContractID, funding types.Currency) error {
+	return r.hostContractor.RenewContract(id, funding)
+}
+
+// StopRenewingContract prevents a contract from being renewed.
+func (r *Renter) StopRenewingContract(id types.FileContractID) error {
+	return r.hostContractor.StopRenewingContract(id)
+}
+
 // OldContracts returns an array of host contractor's oldContracts
 func (r *Renter) OldContracts() []modules.RenterContract {
 	return r.hostContractor.OldContracts()
@@ -408,14 +490,26 @@ func (r *Renter) ContractUtility(pk types.SiaPublicKey) (modules.ContractUtility
 // PeriodSpending returns the host contractor's period spending
 func (r *Renter) PeriodSpending() modules.ContractorSpending { return r.hostContractor.PeriodSpending() }
 
+// SpendingHistory returns the host contractor's spending records of a host
+// within a time range.
+func (r *Renter) SpendingHistory(host types.SiaPublicKey, start, end time.Time) ([]modules.RenterSpendingRecord, error) {
+	return r.hostContractor.SpendingHistory(host, start, end)
+}
+
 // Settings returns the host contractor's allowance
 func (r *Renter) Settings() modules.RenterSettings {
 	download, upload, _ := r.hostContractor.RateLimits()
+	id := r.mu.RLock()
+	benchmarkHosts := r.persist.BenchmarkHosts
+	tenantUploadDir := r.persist.TenantUploadDir
+	r.mu.RUnlock(id)
 	return modules.RenterSettings{
 		Allowance:        r.hostContractor.Allowance(),
 		MaxDownloadSpeed: download,
 		MaxUploadSpeed:   upload,
 		StreamCacheSize:  r.staticStreamCache.cacheSize,
+		BenchmarkHosts:   benchmarkHosts,
+		TenantUploadDir:  tenantUploadDir,
 	}
 }
 
@@ -490,12 +584,14 @@ func NewCustomRenter(g modules.Gateway, cs modules.ConsensusSet, tpool modules.T
 		downloadHeap: new(downloadChunkHeap),
 
 		uploadHeap: uploadHeap{
-			activeChunks: make(map[uploadChunkID]struct{}),
-			newUploads:   make(chan struct{}, 1),
+			activeChunks:  make(map[uploadChunkID]struct{}),
+			fileTransfers: make(map[fileTransferID]*fileTransfer),
+			newUploads:    make(chan struct{}, 1),
 		},
 
 		workerPool: make(map[types.FileContractID]*worker),
 
+		staticAlerter:  modules.NewAlerter("renter"),
 		cs:             cs,
 		deps:           deps,
 		g:              g,
@@ -535,6 +631,10 @@ func NewCustomRenter(g modules.Gateway, cs modules.ConsensusSet, tpool modules.T
 	r.managedUpdateWorkerPool()
 	go r.threadedDownloadLoop()
 	go r.threadedUploadLoop()
+	go r.threadedBenchmarkHosts()
+	go r.threadedAuditHosts()
+	go r.threadedCollectGarbage()
+	go r.threadedSaveTenantUsage()
 
 	// Kill workers on shutdown.
 	r.tg.OnStop(func() error {
@@ -545,6 +645,10 @@ func NewCustomRenter(g modules.Gateway, cs modules.ConsensusSet, tpool modules.T
 		r.mu.RUnlock(id)
 		return nil
 	})
+	// Save the usage of the tenants on shutdown.
+	r.tg.OnStop(func() error {
+		return r.managedSaveTenantUsage()
+	})
 
 	return r, nil
 }
@@ -118,10 +118,36 @@ func (stubHostDB) EstimateHostScore(modules.HostDBEntry) modules.HostScoreBreakd
 func (stubHostDB) Host(types.SiaPublicKey) (modules.HostDBEntry, bool) {
 	return modules.HostDBEntry{}, false
 }
+func (stubHostDB) RecordAudit(types.SiaPublicKey, bool) error {
+	return nil
+}
+func (stubHostDB) RecordBenchmark(types.SiaPublicKey, modules.HostDBBenchmark) error {
+	return nil
+}
 func (stubHostDB) ScoreBreakdown(modules.HostDBEntry) modules.HostScoreBreakdown {
 	return modules.HostScoreBreakdown{}
 }
 
+// mapContractor is a hostContractor that resolves contract ids to host keys
+// and host keys to contract utilities using maps.
+type mapContractor struct {
+	hostContractor
+	hosts     map[types.FileContractID]types.SiaPublicKey
+	utilities map[string]modules.ContractUtility
+	period    types.BlockHeight
+}
+
+func (c mapContractor) CurrentPeriod() types.BlockHeight {
+	return c.period
+}
+func (c mapContractor) ResolveIDToPubKey(id types.FileContractID) types.SiaPublicKey {
+	return c.hosts[id]
+}
+func (c mapContractor) ContractUtility(pk types.SiaPublicKey) (modules.ContractUtility, bool) {
+	utility, ok := c.utilities[pk.String()]
+	return utility, ok
+}
+
 // stubContractor is the minimal implementation of the hostContractor
 // interface.
 type stubContractor struct{}
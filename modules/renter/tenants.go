@@ -0,0 +1,436 @@
+package renter
+
+// tenants.go implements tenant namespaces. Each tenant owns the files below
+// its root directory and is limited by a storage quota and upload and
+// download budgets. The API restricts tenants to their own files; the renter
+// tracks their usage and enforces their quotas when files are uploaded and
+// downloaded.
+//
+// Both budgets are charged in bytes of file data. Uploads are charged for
+// each chunk once enough of its pieces are uploaded to recover it. Repairs
+// are not charged, since they are caused by hosts going offline. Downloads
+// are charged when they start, so that concurrent downloads can't exceed the
+// budget, and are refunded if they fail.
+//
+// The usage of the tenants changes with every transferred piece, so it is
+// saved periodically instead of on every change. Passwords are hashed with
+// scrypt. Since every API request of a tenant is authenticated, successful
+// authentications are remembered for tenantAuthCacheTTL as a MAC of the
+// credentials, and tenants are locked out for tenantAuthLockout after
+// tenantAuthAttempts failed authentications, so that requests can't be used
+// to make the renter hash passwords over and over.
+//
+// Tenants upload files from their own directory below the TenantUploadDir
+// setting. The setting has to be outside of the persist directory, where
+// tenants could otherwise place .sia files that are loaded on startup, or
+// upload the renter's metadata.
+
+import (
+	"crypto/subtle"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+	"github.com/NebulousLabs/fastrand"
+	"golang.org/x/crypto/scrypt"
+)
+
+var (
+	// ErrUnknownTenant is returned when a tenant does not exist.
+	ErrUnknownTenant = errors.New("no tenant with that name")
+
+	// errDownloadBudgetExceeded is returned when a download would exceed the
+	// download budget of a tenant.
+	errDownloadBudgetExceeded = errors.New("download would exceed the tenant's download budget")
+
+	// errInvalidTenantUploadDir is returned when the tenant upload directory
+	// is not an absolute path, or overlaps with the persist directory.
+	errInvalidTenantUploadDir = errors.New("tenant upload directory must be an absolute path outside of the renter directory")
+
+	// errInvalidTenantName is returned when a tenant name contains characters
+	// other than lowercase letters, digits, dashes and underscores.
+	errInvalidTenantName = errors.New("tenant name must be 1-64 lowercase letters, digits, dashes or underscores")
+
+	// errStorageQuotaExceeded is returned when an upload would exceed the
+	// storage quota of a tenant.
+	errStorageQuotaExceeded = errors.New("upload would exceed the tenant's storage quota")
+
+	// errTenantAuth is returned when a tenant supplies the wrong credentials.
+	errTenantAuth = errors.New("wrong tenant name or password")
+
+	// errNoTenantUploadDir is returned when a tenant tries to upload a file
+	// from disk, but no tenant upload directory is set.
+	errNoTenantUploadDir = errors.New("no tenant upload directory is set")
+
+	// ErrTenantAuthLockout is returned when a tenant tries to authenticate
+	// after too many failed authentications.
+	ErrTenantAuthLockout = errors.New("too many failed authentications, try again later")
+
+	// errTenantPassword is returned when a tenant is created without a
+	// password.
+	errTenantPassword = errors.New("a password is required to create a tenant")
+
+	// errUploadBudgetExceeded is returned when an upload would exceed the
+	// upload budget of a tenant.
+	errUploadBudgetExceeded = errors.New("upload would exceed the tenant's upload budget")
+
+	// tenantNameRegexp matches valid tenant names.
+	tenantNameRegexp = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+)
+
+// tenant is the persisted state of a tenant. The bandwidth usage is reset
+// when the allowance period changes.
+type tenant struct {
+	modules.RenterTenantQuota
+
+	PasswordHash crypto.Hash
+	Salt         [32]byte
+
+	Period       types.BlockHeight
+	UploadUsed   uint64
+	DownloadUsed uint64
+}
+
+// tenantAuth tracks the recent authentications of a tenant.
+type tenantAuth struct {
+	mac         crypto.Hash // MAC of the last successfully authenticated credentials
+	expires     time.Time   // when the MAC expires
+	failures    int         // authentications that failed or are in progress
+	lockedUntil time.Time
+}
+
+// currentUsage resets the bandwidth usage of the tenant if a new allowance
+// period has started.
+func (t *tenant) currentUsage(period types.BlockHeight) {
+	if t.Period != period {
+		t.Period = period
+		t.UploadUsed = 0
+		t.DownloadUsed = 0
+	}
+}
+
+// hashTenantPassword hashes the password of a tenant with scrypt.
+func hashTenantPassword(salt [32]byte, password string) (hash crypto.Hash, err error) {
+	key, err := scrypt.Key([]byte(password), salt[:], tenantScryptN, 8, 1, len(hash))
+	if err != nil {
+		return crypto.Hash{}, err
+	}
+	copy(hash[:], key)
+	return hash, nil
+}
+
+// setPassword sets a new random salt and the scrypt hash of the password of
+// the tenant.
+func (t *tenant) setPassword(password string) error {
+	fastrand.Read(t.Salt[:])
+	hash, err := hashTenantPassword(t.Salt, password)
+	if err != nil {
+		return err
+	}
+	t.PasswordHash = hash
+	return nil
+}
+
+// tenantStorageUsed returns the total size of the files of a tenant. The
+// renter needs to be locked.
+func (r *Renter) tenantStorageUsed(name string) (used uint64) {
+	for siaPath, f := range r.files {
+		if modules.RenterTenantOf(siaPath) == name {
+			used += f.size
+		}
+	}
+	return used
+}
+
+// checkTenantUpload checks whether a new file fits into the storage quota
+// and upload budget of the tenant that owns it. The renter needs to be
+// locked.
+func (r *Renter) checkTenantUpload(f *file) error {
+	t, exists := r.persist.Tenants[modules.RenterTenantOf(f.name)]
+	if !exists {
+		return nil
+	}
+	t.currentUsage(r.hostContractor.CurrentPeriod())
+	if t.StorageQuota != 0 && r.tenantStorageUsed(modules.RenterTenantOf(f.name))+f.size > t.StorageQuota {
+		return errStorageQuotaExceeded
+	}
+	if t.UploadBudget != 0 && t.UploadUsed+f.size > t.UploadBudget {
+		return errUploadBudgetExceeded
+	}
+	return nil
+}
+
+// recordTenantUpload adds uploaded file data to the usage of the tenant that
+// owns a file. The renter needs to be locked.
+func (r *Renter) recordTenantUpload(siaPath string, n uint64) {
+	name := modules.RenterTenantOf(siaPath)
+	t, exists := r.persist.Tenants[name]
+	if !exists {
+		return
+	}
+	t.currentUsage(r.hostContractor.CurrentPeriod())
+	t.UploadUsed += n
+	r.persist.Tenants[name] = t
+	r.tenantUsageChanged = true
+}
+
+// managedRecordTenantDownload adds a download of file data to the usage of
+// the tenant that owns a file, unless the download would exceed the tenant's
+// download budget.
+func (r *Renter) managedRecordTenantDownload(siaPath string, n uint64) error {
+	name := modules.RenterTenantOf(siaPath)
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+	t, exists := r.persist.Tenants[name]
+	if !exists {
+		return nil
+	}
+	t.currentUsage(r.hostContractor.CurrentPeriod())
+	if t.DownloadBudget != 0 && t.DownloadUsed+n > t.DownloadBudget {
+		return errDownloadBudgetExceeded
+	}
+	t.DownloadUsed += n
+	r.persist.Tenants[name] = t
+	r.tenantUsageChanged = true
+	return nil
+}
+
+// managedRefundTenantDownload removes a download that failed from the usage
+// of the tenant that owns a file.
+func (r *Renter) managedRefundTenantDownload(siaPath string, n uint64) {
+	name := modules.RenterTenantOf(siaPath)
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+	t, exists := r.persist.Tenants[name]
+	if !exists {
+		return
+	}
+	t.currentUsage(r.hostContractor.CurrentPeriod())
+	if n > t.DownloadUsed {
+		n = t.DownloadUsed
+	}
+	t.DownloadUsed -= n
+	r.persist.Tenants[name] = t
+	r.tenantUsageChanged = true
+}
+
+// threadedRefundFailedTenantDownload refunds the download of a tenant's file
+// if it fails.
+func (r *Renter) threadedRefundFailedTenantDownload(d *download, n uint64) {
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+	select {
+	case <-d.completeChan:
+	case <-r.tg.StopChan():
+		return
+	}
+	if d.Err() != nil {
+		r.managedRefundTenantDownload(d.staticSiaPath, n)
+	}
+}
+
+// managedSaveTenantUsage saves the renter if the usage of a tenant has
+// changed since it was last saved.
+func (r *Renter) managedSaveTenantUsage() error {
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+	if !r.tenantUsageChanged {
+		return nil
+	}
+	if err := r.saveSync(); err != nil {
+		return err
+	}
+	r.tenantUsageChanged = false
+	return nil
+}
+
+// threadedSaveTenantUsage periodically saves the usage of the tenants.
+func (r *Renter) threadedSaveTenantUsage() {
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+	for {
+		select {
+		case <-r.tg.StopChan():
+			return
+		case <-time.After(tenantUsageSaveInterval):
+		}
+		if err := r.managedSaveTenantUsage(); err != nil {
+			r.log.Println("WARN: unable to save tenant usage:", err)
+		}
+	}
+}
+
+// AuthenticateTenant checks the API credentials of a tenant. Credentials that
+// were authenticated recently are checked against their MAC instead of
+// hashing the password again.
+func (r *Renter) AuthenticateTenant(name, password string) error {
+	id := r.mu.Lock()
+	t, exists := r.persist.Tenants[name]
+	if !exists {
+		r.mu.Unlock(id)
+		return ErrUnknownTenant
+	}
+	if r.tenantAuth == nil {
+		r.tenantAuth = make(map[string]*tenantAuth)
+		fastrand.Read(r.tenantAuthKey[:])
+	}
+	auth, exists := r.tenantAuth[name]
+	if !exists {
+		auth = new(tenantAuth)
+		r.tenantAuth[name] = auth
+	}
+	mac := crypto.HashAll(r.tenantAuthKey, name, password)
+	now := time.Now()
+	if now.Before(auth.expires) && subtle.ConstantTimeCompare(mac[:], auth.mac[:]) == 1 {
+		r.mu.Unlock(id)
+		return nil
+	}
+	if now.Before(auth.lockedUntil) {
+		r.mu.Unlock(id)
+		return ErrTenantAuthLockout
+	}
+	// The attempt counts as a failure until it succeeds, so that concurrent
+	// attempts can't exceed the limit.
+	auth.failures++
+	if auth.failures >= tenantAuthAttempts {
+		auth.failures = 0
+		auth.lockedUntil = now.Add(tenantAuthLockout)
+	}
+	r.mu.Unlock(id)
+
+	hash, err := hashTenantPassword(t.Salt, password)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(hash[:], t.PasswordHash[:]) != 1 {
+		return errTenantAuth
+	}
+
+	id = r.mu.Lock()
+	if current, exists := r.tenantAuth[name]; exists && current == auth {
+		auth.mac = mac
+		auth.expires = time.Now().Add(tenantAuthCacheTTL)
+		auth.failures = 0
+		auth.lockedUntil = time.Time{}
+	}
+	r.mu.Unlock(id)
+	return nil
+}
+
+// DeleteTenant deletes a tenant. The files of the tenant are kept and can
+// still be accessed with the API password.
+func (r *Renter) DeleteTenant(name string) error {
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+	if _, exists := r.persist.Tenants[name]; !exists {
+		return ErrUnknownTenant
+	}
+	delete(r.persist.Tenants, name)
+	delete(r.tenantAuth, name)
+	return r.saveSync()
+}
+
+// SetTenant creates a tenant or updates the quota of an existing tenant. The
+// password of an existing tenant is only changed if a password is supplied.
+func (r *Renter) SetTenant(name, password string, quota modules.RenterTenantQuota) error {
+	if !tenantNameRegexp.MatchString(name) {
+		return errInvalidTenantName
+	}
+
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+	t, exists := r.persist.Tenants[name]
+	if !exists && password == "" {
+		return errTenantPassword
+	}
+	t.RenterTenantQuota = quota
+	if password != "" {
+		if err := t.setPassword(password); err != nil {
+			return err
+		}
+		delete(r.tenantAuth, name)
+	}
+	if r.persist.Tenants == nil {
+		r.persist.Tenants = make(map[string]tenant)
+	}
+	r.persist.Tenants[name] = t
+	return r.saveSync()
+}
+
+// checkTenantUploadDir checks that a tenant upload directory is an absolute
+// path that neither is inside of the persist directory nor contains it. An
+// empty directory disables uploads from disk for tenants.
+func (r *Renter) checkTenantUploadDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if !filepath.IsAbs(dir) {
+		return errInvalidTenantUploadDir
+	}
+	persistDir, err := filepath.Abs(r.persistDir)
+	if err != nil {
+		return err
+	}
+	dir = filepath.Clean(dir)
+	within := func(path, parent string) bool {
+		return path == parent || strings.HasPrefix(path, parent+string(filepath.Separator))
+	}
+	if within(dir, persistDir) || within(persistDir, dir) {
+		return errInvalidTenantUploadDir
+	}
+	return nil
+}
+
+// TenantDir returns the directory on disk from which a tenant uploads files,
+// creating it if it doesn't exist yet. The API does not let tenants upload
+// files from other paths, or download files to disk.
+func (r *Renter) TenantDir(name string) (string, error) {
+	id := r.mu.RLock()
+	_, exists := r.persist.Tenants[name]
+	root := r.persist.TenantUploadDir
+	r.mu.RUnlock(id)
+	if !exists {
+		return "", ErrUnknownTenant
+	}
+	if root == "" {
+		return "", errNoTenantUploadDir
+	}
+	dir := filepath.Join(root, name)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Tenants returns the tenants of the renter and their usage.
+func (r *Renter) Tenants() []modules.RenterTenant {
+	id := r.mu.RLock()
+	defer r.mu.RUnlock(id)
+	period := r.hostContractor.CurrentPeriod()
+	tenants := []modules.RenterTenant{}
+	for name, t := range r.persist.Tenants {
+		t.currentUsage(period)
+		tenants = append(tenants, modules.RenterTenant{
+			Name:              name,
+			RenterTenantQuota: t.RenterTenantQuota,
+			StorageUsed:       r.tenantStorageUsed(name),
+			UploadUsed:        t.UploadUsed,
+			DownloadUsed:      t.DownloadUsed,
+		})
+	}
+	sort.Slice(tenants, func(i, j int) bool {
+		return tenants[i].Name < tenants[j].Name
+	})
+	return tenants
+}
@@ -0,0 +1,294 @@
+package renter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
+	siasync "github.com/NebulousLabs/Sia/sync"
+)
+
+// TestTenantQuotas checks that tenants are authenticated and that their
+// quotas are enforced.
+func TestTenantQuotas(t *testing.T) {
+	dir := build.TempDir("renter", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	contractor := &mapContractor{}
+	r := &Renter{
+		files:          make(map[string]*file),
+		hostContractor: contractor,
+		mu:             siasync.New(modules.SafeMutexDelay, 1),
+		persistDir:     dir,
+	}
+
+	// Create a tenant.
+	if err := r.SetTenant("Alice", "foo", modules.RenterTenantQuota{}); err != errInvalidTenantName {
+		t.Fatal("expected errInvalidTenantName, got", err)
+	}
+	if err := r.SetTenant("alice", "", modules.RenterTenantQuota{}); err != errTenantPassword {
+		t.Fatal("expected errTenantPassword, got", err)
+	}
+	quota := modules.RenterTenantQuota{
+		StorageQuota:   100,
+		UploadBudget:   105,
+		DownloadBudget: 50,
+	}
+	if err := r.SetTenant("alice", "foo", quota); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AuthenticateTenant("alice", "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AuthenticateTenant("alice", "bar"); err != errTenantAuth {
+		t.Fatal("expected errTenantAuth, got", err)
+	}
+	if err := r.AuthenticateTenant("bob", "foo"); err != ErrUnknownTenant {
+		t.Fatal("expected ErrUnknownTenant, got", err)
+	}
+
+	// Updating the quota without a password keeps the password.
+	quota.StorageQuota = 150
+	if err := r.SetTenant("alice", "", quota); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AuthenticateTenant("alice", "foo"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the storage quota and upload budget. Files outside the tenant's
+	// root are not limited.
+	rsc, _ := NewRSCode(1, 1)
+	root := modules.RenterTenantRoot("alice")
+	r.files[root+"a"] = newFile(root+"a", rsc, modules.SectorSize, 100)
+	if err := r.checkTenantUpload(newFile(root+"b", rsc, modules.SectorSize, 50)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.checkTenantUpload(newFile(root+"b", rsc, modules.SectorSize, 51)); err != errStorageQuotaExceeded {
+		t.Fatal("expected errStorageQuotaExceeded, got", err)
+	}
+	if err := r.checkTenantUpload(newFile("b", rsc, modules.SectorSize, 1000)); err != nil {
+		t.Fatal(err)
+	}
+	r.recordTenantUpload(root+"a", 100)
+	if err := r.checkTenantUpload(newFile(root+"b", rsc, modules.SectorSize, 10)); err != errUploadBudgetExceeded {
+		t.Fatal("expected errUploadBudgetExceeded, got", err)
+	}
+
+	// Check the download budget.
+	if err := r.managedRecordTenantDownload(root+"a", 40); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.managedRecordTenantDownload(root+"a", 11); err != errDownloadBudgetExceeded {
+		t.Fatal("expected errDownloadBudgetExceeded, got", err)
+	}
+
+	// Failed downloads are refunded, including downloads that fail during
+	// setup.
+	if err := r.managedRecordTenantDownload(root+"a", 10); err != nil {
+		t.Fatal(err)
+	}
+	r.managedRefundTenantDownload(root+"a", 10)
+	_, err := r.managedDownload(modules.RenterDownloadParameters{
+		SiaPath:     root + "a",
+		Length:      10,
+		Destination: filepath.Join(dir, "missing", "a"),
+	})
+	if err == nil {
+		t.Fatal("download to a missing directory succeeded")
+	}
+	tenants := r.Tenants()
+	if len(tenants) != 1 || tenants[0].StorageUsed != 100 || tenants[0].UploadUsed != 100 || tenants[0].DownloadUsed != 40 {
+		t.Fatal("wrong tenant usage:", tenants)
+	}
+
+	// The usage is saved periodically instead of on every change.
+	var saved persistence
+	if err := persist.LoadJSON(settingsMetadata, &saved, filepath.Join(dir, PersistFilename)); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Tenants["alice"].DownloadUsed != 0 || !r.tenantUsageChanged {
+		t.Fatal("usage was saved before the save interval")
+	}
+	if err := r.managedSaveTenantUsage(); err != nil {
+		t.Fatal(err)
+	}
+	if err := persist.LoadJSON(settingsMetadata, &saved, filepath.Join(dir, PersistFilename)); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Tenants["alice"].DownloadUsed != 40 || r.tenantUsageChanged {
+		t.Fatal("usage was not saved:", saved.Tenants["alice"])
+	}
+
+	// The bandwidth usage is reset when a new period starts.
+	contractor.period = 100
+	if tenants := r.Tenants(); tenants[0].UploadUsed != 0 || tenants[0].DownloadUsed != 0 {
+		t.Fatal("usage was not reset:", tenants)
+	}
+	if err := r.managedRecordTenantDownload(root+"a", 50); err != nil {
+		t.Fatal(err)
+	}
+
+	// Delete the tenant.
+	if err := r.DeleteTenant("alice"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AuthenticateTenant("alice", "foo"); err != ErrUnknownTenant {
+		t.Fatal("expected ErrUnknownTenant, got", err)
+	}
+}
+
+// TestTenantUploadDir checks that the upload directories of the tenants are
+// kept outside of the persist directory, so that .sia files that tenants place
+// in them are never loaded.
+func TestTenantUploadDir(t *testing.T) {
+	testDir := build.TempDir("renter", t.Name())
+	dir := filepath.Join(testDir, modules.RenterDir)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	log, err := persist.NewFileLogger(filepath.Join(dir, logFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &Renter{
+		files:          make(map[string]*file),
+		hostContractor: &mapContractor{},
+		log:            log,
+		mu:             siasync.New(modules.SafeMutexDelay, 1),
+		persistDir:     dir,
+	}
+	if err := r.SetTenant("alice", "foo", modules.RenterTenantQuota{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.TenantDir("alice"); err != errNoTenantUploadDir {
+		t.Fatal("expected errNoTenantUploadDir, got", err)
+	}
+
+	// The upload directory must be an absolute path that doesn't overlap
+	// with the persist directory.
+	uploadDir := filepath.Join(testDir, "uploads")
+	for _, invalid := range []string{"uploads", dir, filepath.Join(dir, "uploads"), testDir} {
+		if err := r.checkTenantUploadDir(invalid); err != errInvalidTenantUploadDir {
+			t.Fatalf("expected errInvalidTenantUploadDir for %v, got %v", invalid, err)
+		}
+	}
+	if err := r.checkTenantUploadDir(uploadDir); err != nil {
+		t.Fatal(err)
+	}
+	r.persist.TenantUploadDir = uploadDir
+	aliceDir, err := r.TenantDir("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(aliceDir); err != nil {
+		t.Fatal("upload directory was not created:", err)
+	}
+	if _, err := r.TenantDir("bob"); err != ErrUnknownTenant {
+		t.Fatal("expected ErrUnknownTenant, got", err)
+	}
+
+	// Place a .sia file for another tenant's namespace in alice's upload
+	// directory, next to a .sia file in the persist directory. Only the
+	// latter is loaded.
+	rsc, _ := NewRSCode(1, 1)
+	good := newFile(modules.RenterTenantRoot("bob")+"good", rsc, modules.SectorSize, 100)
+	evil := newFile(modules.RenterTenantRoot("bob")+"evil", rsc, modules.SectorSize, 100)
+	for _, f := range []*file{good, evil} {
+		if err := r.saveFile(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = os.Rename(filepath.Join(dir, evil.name+ShareExtension), filepath.Join(aliceDir, "evil"+ShareExtension))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.loadSiaFiles(); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := r.files[good.name]; !exists {
+		t.Fatal("file in the persist directory was not loaded")
+	}
+	if _, exists := r.files[evil.name]; exists {
+		t.Fatal("file in the tenant's upload directory was loaded")
+	}
+}
+
+// TestTenantAuthCache checks that successful authentications of a tenant are
+// remembered, and that tenants are locked out after too many failed
+// authentications.
+func TestTenantAuthCache(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	dir := build.TempDir("renter", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	r := &Renter{
+		files:          make(map[string]*file),
+		hostContractor: &mapContractor{},
+		mu:             siasync.New(modules.SafeMutexDelay, 1),
+		persistDir:     dir,
+	}
+	if err := r.SetTenant("alice", "foo", modules.RenterTenantQuota{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AuthenticateTenant("alice", "foo"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Change the salt so that hashing the password fails to authenticate
+	// the tenant. The cached credentials are still accepted, other
+	// credentials are not.
+	alice := r.persist.Tenants["alice"]
+	alice.Salt[0]++
+	r.persist.Tenants["alice"] = alice
+	if err := r.AuthenticateTenant("alice", "foo"); err != nil {
+		t.Fatal("cached credentials were not accepted:", err)
+	}
+	if err := r.AuthenticateTenant("alice", "bar"); err != errTenantAuth {
+		t.Fatal("expected errTenantAuth, got", err)
+	}
+
+	// The cached credentials expire.
+	time.Sleep(tenantAuthCacheTTL)
+	if err := r.AuthenticateTenant("alice", "foo"); err != errTenantAuth {
+		t.Fatal("expected errTenantAuth after the cache expired, got", err)
+	}
+
+	// Setting a new password clears the cache.
+	if err := r.SetTenant("alice", "foo", modules.RenterTenantQuota{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AuthenticateTenant("alice", "foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetTenant("alice", "baz", modules.RenterTenantQuota{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AuthenticateTenant("alice", "foo"); err != errTenantAuth {
+		t.Fatal("old password was accepted after changing it:", err)
+	}
+
+	// After too many failed authentications, the tenant is locked out, even
+	// with the right password, until the lockout has passed.
+	for i := 1; i < tenantAuthAttempts; i++ {
+		if err := r.AuthenticateTenant("alice", "wrong"); err != errTenantAuth {
+			t.Fatal("expected errTenantAuth, got", err)
+		}
+	}
+	if err := r.AuthenticateTenant("alice", "baz"); err != ErrTenantAuthLockout {
+		t.Fatal("expected ErrTenantAuthLockout, got", err)
+	}
+	time.Sleep(tenantAuthLockout)
+	if err := r.AuthenticateTenant("alice", "baz"); err != nil {
+		t.Fatal(err)
+	}
+}
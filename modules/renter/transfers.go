@@ -0,0 +1,315 @@
+package renter
+
+// transfers.go keeps a persistent history of the renter's downloads, uploads
+// and repairs. The history keeps the most recent transferHistoryLimit
+// transfers that are younger than transferHistoryRetention.
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+const (
+	// transferHistoryFile is the name of the file that holds the transfer
+	// history.
+	transferHistoryFile = "transfers.json"
+)
+
+// A transferLog is the persistent transfer history of the renter.
+type transferLog struct {
+	log *persist.JSONLog
+}
+
+type (
+	// fileTransferID identifies the transfer of a file in the upload heap.
+	fileTransferID struct {
+		fileUID      string
+		transferType string
+	}
+
+	// A fileTransfer collects the statistics of the chunks of a file that
+	// are uploaded or repaired.
+	fileTransfer struct {
+		transfer        modules.RenterTransfer
+		hosts           map[string]types.SiaPublicKey
+		chunks          int    // chunks that were processed
+		chunksFailed    int    // processed chunks that did not upload all pieces
+		chunkErr        string // error of the most recent failed chunk
+		chunksRemaining int    // chunks that are queued or being processed
+	}
+)
+
+// transferRecordTime returns the time at which an encoded transfer ended.
+func transferRecordTime(record []byte) time.Time {
+	var r struct {
+		EndTime time.Time `json:"endtime"`
+	}
+	json.Unmarshal(record, &r)
+	return r.EndTime
+}
+
+// newTransferLog opens the transfer history at the given path, creating it if
+// it doesn't exist yet.
+func newTransferLog(path string) (*transferLog, error) {
+	log, err := persist.OpenJSONLog(path, persist.JSONLogOptions{
+		Limit:     transferHistoryLimit,
+		Retention: transferHistoryRetention,
+		Timestamp: transferRecordTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &transferLog{log: log}, nil
+}
+
+// close closes the transfer history.
+func (l *transferLog) close() error {
+	if l == nil {
+		return nil
+	}
+	return l.log.Close()
+}
+
+// record appends a transfer to the transfer history. A nil log ignores all
+// records.
+func (l *transferLog) record(r modules.RenterTransfer) error {
+	if l == nil {
+		return nil
+	}
+	if elapsed := r.EndTime.Sub(r.StartTime).Seconds(); elapsed > 0 {
+		r.Throughput = uint64(float64(r.Bytes) / elapsed)
+	}
+	return l.log.Append(r)
+}
+
+// records returns the records of the transfer history that match the filter,
+// most recent first.
+func (l *transferLog) records(filter modules.RenterTransferFilter) ([]modules.RenterTransfer, error) {
+	if l == nil {
+		return nil, nil
+	}
+	var matches []modules.RenterTransfer
+	err := l.log.ForEach(func(record []byte) {
+		var r modules.RenterTransfer
+		if json.Unmarshal(record, &r) != nil {
+			return
+		}
+		if transferMatches(r, filter) {
+			matches = append(matches, r)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(matches)-1; i < j; i, j = i+1, j-1 {
+		matches[i], matches[j] = matches[j], matches[i]
+	}
+	return matches, nil
+}
+
+// clear removes the records of a type that started within [after, before]
+// from the transfer history.
+func (l *transferLog) clear(transferType string, after, before time.Time) error {
+	if l == nil {
+		return nil
+	}
+	return l.log.Compact(func(record []byte) bool {
+		var r modules.RenterTransfer
+		if json.Unmarshal(record, &r) != nil {
+			return false
+		}
+		return r.Type != transferType || r.StartTime.Before(after) || r.StartTime.After(before)
+	})
+}
+
+// transferMatches returns whether a transfer matches a filter.
+func transferMatches(r modules.RenterTransfer, filter modules.RenterTransferFilter) bool {
+	if filter.Type != "" && r.Type != filter.Type {
+		return false
+	}
+	if filter.SiaPath != "" && r.SiaPath != filter.SiaPath {
+		return false
+	}
+	if !filter.Start.IsZero() && r.StartTime.Before(filter.Start) {
+		return false
+	}
+	if !filter.End.IsZero() && r.StartTime.After(filter.End) {
+		return false
+	}
+	if filter.FailedOnly && r.Error == "" {
+		return false
+	}
+	if len(filter.Host.Key) != 0 {
+		for _, host := range r.Hosts {
+			if host.String() == filter.Host.String() {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// managedRecordPiece adds a piece that was downloaded from a host to the
+// transfer statistics of a download.
+func (d *download) managedRecordPiece(host types.SiaPublicKey, cost types.Currency) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.transferHosts == nil {
+		d.transferHosts = make(map[string]types.SiaPublicKey)
+	}
+	d.transferHosts[host.String()] = host
+	d.transferCost = d.transferCost.Add(cost)
+}
+
+// managedTransferStats returns the hosts that a download used and the cost of
+// the download.
+func (d *download) managedTransferStats() ([]types.SiaPublicKey, types.Currency) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var hosts []types.SiaPublicKey
+	for _, host := range d.transferHosts {
+		hosts = append(hosts, host)
+	}
+	return hosts, d.transferCost
+}
+
+// threadedRecordDownload adds a download to the transfer history once it has
+// finished.
+func (r *Renter) threadedRecordDownload(d *download, transferType string) {
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+	select {
+	case <-d.completeChan:
+	case <-r.tg.StopChan():
+		return
+	}
+
+	hosts, cost := d.managedTransferStats()
+	d.mu.Lock()
+	endTime := d.endTime
+	d.mu.Unlock()
+	if endTime.IsZero() {
+		endTime = time.Now()
+	}
+	transfer := modules.RenterTransfer{
+		Type:      transferType,
+		SiaPath:   d.staticSiaPath,
+		StartTime: d.staticStartTime,
+		EndTime:   endTime,
+		Bytes:     atomic.LoadUint64(&d.atomicTotalDataTransferred),
+		Cost:      cost,
+		Hosts:     hosts,
+	}
+	if err := d.Err(); err != nil {
+		transfer.Error = err.Error()
+	}
+	if err := r.transfers.record(transfer); err != nil {
+		r.log.Println("WARN: unable to record download in transfer history:", err)
+	}
+}
+
+// queueChunkTransfer adds a chunk that was pushed onto the upload heap to the
+// transfer of its file. Chunks that already have pieces are repaired. The
+// upload heap must be locked.
+func (uh *uploadHeap) queueChunkTransfer(uc *unfinishedUploadChunk) {
+	uc.mu.Lock()
+	uc.transferType = modules.RenterTransferUpload
+	if uc.piecesCompleted > 0 {
+		uc.transferType = modules.RenterTransferRepair
+	}
+	id := fileTransferID{fileUID: uc.id.fileUID, transferType: uc.transferType}
+	uc.mu.Unlock()
+
+	ft, exists := uh.fileTransfers[id]
+	if !exists {
+		ft = &fileTransfer{hosts: make(map[string]types.SiaPublicKey)}
+		uh.fileTransfers[id] = ft
+	}
+	ft.chunksRemaining++
+}
+
+// managedFinishChunkTransfer adds the statistics of a chunk that has finished
+// uploading, or that was dropped from the upload heap, to the transfer of its
+// file. The transfer is added to the transfer history once all chunks of the
+// file have finished, unless none of them was processed.
+func (r *Renter) managedFinishChunkTransfer(uc *unfinishedUploadChunk) {
+	uc.mu.Lock()
+	id := fileTransferID{fileUID: uc.id.fileUID, transferType: uc.transferType}
+	start := uc.transferStart
+	bytes, cost, hosts := uc.transferBytes, uc.transferCost, uc.transferHosts
+	var chunkErr string
+	if !start.IsZero() && uc.piecesCompleted < uc.piecesNeeded {
+		chunkErr = fmt.Sprintf("only %v of %v pieces of chunk %v were uploaded", uc.piecesCompleted, uc.piecesNeeded, uc.index)
+		if uc.transferErr != nil {
+			chunkErr += ": " + uc.transferErr.Error()
+		}
+	}
+	uc.mu.Unlock()
+
+	r.uploadHeap.mu.Lock()
+	ft, exists := r.uploadHeap.fileTransfers[id]
+	if !exists {
+		r.uploadHeap.mu.Unlock()
+		return
+	}
+	ft.chunksRemaining--
+	if !start.IsZero() {
+		if ft.transfer.StartTime.IsZero() || start.Before(ft.transfer.StartTime) {
+			ft.transfer.StartTime = start
+		}
+		ft.transfer.Bytes += bytes
+		ft.transfer.Cost = ft.transfer.Cost.Add(cost)
+		for _, host := range hosts {
+			ft.hosts[host.String()] = host
+		}
+		ft.chunks++
+		if chunkErr != "" {
+			ft.chunksFailed++
+			ft.chunkErr = chunkErr
+		}
+	}
+	if ft.chunksRemaining > 0 {
+		r.uploadHeap.mu.Unlock()
+		return
+	}
+	delete(r.uploadHeap.fileTransfers, id)
+	r.uploadHeap.mu.Unlock()
+	if ft.chunks == 0 {
+		return
+	}
+
+	transfer := ft.transfer
+	transfer.Type = id.transferType
+	transfer.EndTime = time.Now()
+	for _, host := range ft.hosts {
+		transfer.Hosts = append(transfer.Hosts, host)
+	}
+	if ft.chunksFailed > 0 {
+		transfer.Error = fmt.Sprintf("%v of %v chunks failed, last error: %v", ft.chunksFailed, ft.chunks, ft.chunkErr)
+	}
+	uc.renterFile.mu.RLock()
+	transfer.SiaPath = uc.renterFile.name
+	uc.renterFile.mu.RUnlock()
+	if err := r.transfers.record(transfer); err != nil {
+		r.log.Println("WARN: unable to record upload in transfer history:", err)
+	}
+}
+
+// TransferHistory returns the records of the transfer history that match the
+// filter, most recent first.
+func (r *Renter) TransferHistory(filter modules.RenterTransferFilter) ([]modules.RenterTransfer, error) {
+	if err := r.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer r.tg.Done()
+	return r.transfers.records(filter)
+}
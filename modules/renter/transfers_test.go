@@ -0,0 +1,199 @@
+package renter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/persist"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestTransferLog checks that the transfer history is persisted, filtered,
+// cleared and compacted correctly.
+func TestTransferLog(t *testing.T) {
+	dir := build.TempDir("renter", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, transferHistoryFile)
+	l, err := newTransferLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Record a download every second and a failed upload using a host.
+	host := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte("host")}
+	start := time.Now().Add(-time.Minute).Round(time.Second)
+	for i := 0; i < 5; i++ {
+		err := l.record(modules.RenterTransfer{
+			Type:      modules.RenterTransferDownload,
+			SiaPath:   "foo",
+			StartTime: start.Add(time.Duration(i) * time.Second),
+			EndTime:   start.Add(time.Duration(i+2) * time.Second),
+			Bytes:     100,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = l.record(modules.RenterTransfer{
+		Type:      modules.RenterTransferUpload,
+		SiaPath:   "bar",
+		StartTime: start,
+		EndTime:   start.Add(time.Second),
+		Hosts:     []types.SiaPublicKey{host},
+		Error:     "upload failed",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The history should survive reopening.
+	if err := l.close(); err != nil {
+		t.Fatal(err)
+	}
+	l, err = newTransferLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.close()
+	records, err := l.records(modules.RenterTransferFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 6 || records[0].Type != modules.RenterTransferUpload || records[1].Throughput != 50 {
+		t.Fatal("wrong records:", records)
+	}
+
+	// Filter the history.
+	filters := []struct {
+		filter   modules.RenterTransferFilter
+		expected int
+	}{
+		{modules.RenterTransferFilter{Type: modules.RenterTransferDownload}, 5},
+		{modules.RenterTransferFilter{SiaPath: "bar"}, 1},
+		{modules.RenterTransferFilter{Host: host}, 1},
+		{modules.RenterTransferFilter{FailedOnly: true}, 1},
+		{modules.RenterTransferFilter{Start: start.Add(time.Second), End: start.Add(3 * time.Second)}, 3},
+	}
+	for _, f := range filters {
+		records, err := l.records(f.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != f.expected {
+			t.Errorf("expected %v records for filter %+v, got %v", f.expected, f.filter, len(records))
+		}
+	}
+
+	// Clearing downloads should not affect uploads.
+	if err := l.clear(modules.RenterTransferDownload, start, start.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if records, _ := l.records(modules.RenterTransferFilter{}); len(records) != 4 {
+		t.Fatal("expected 4 records after clearing downloads, got", len(records))
+	}
+
+	// The history should be compacted once it reaches twice the limit.
+	for l.log.Len() < 2*transferHistoryLimit-1 {
+		if err := l.record(modules.RenterTransfer{Type: modules.RenterTransferRepair, EndTime: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.record(modules.RenterTransfer{Type: modules.RenterTransferRepair, SiaPath: "last", EndTime: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	records, err = l.records(modules.RenterTransferFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != transferHistoryLimit || l.log.Len() != transferHistoryLimit || records[0].SiaPath != "last" {
+		t.Fatal("history was not compacted correctly:", len(records), l.log.Len())
+	}
+}
+
+// TestFileTransfer checks that the chunks of a file are added to the transfer
+// history as a single transfer once all of them have finished.
+func TestFileTransfer(t *testing.T) {
+	dir := build.TempDir("renter", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	l, err := newTransferLog(filepath.Join(dir, transferHistoryFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.close()
+	r := &Renter{
+		log:       persist.NewLogger(ioutil.Discard),
+		transfers: l,
+		uploadHeap: uploadHeap{
+			activeChunks:  make(map[uploadChunkID]struct{}),
+			fileTransfers: make(map[fileTransferID]*fileTransfer),
+		},
+	}
+
+	// Queue three chunks of a new file.
+	f := &file{name: "foo", staticUID: "uid"}
+	chunks := make([]*unfinishedUploadChunk, 3)
+	for i := range chunks {
+		chunks[i] = &unfinishedUploadChunk{
+			renterFile:   f,
+			id:           uploadChunkID{fileUID: f.staticUID, index: uint64(i)},
+			index:        uint64(i),
+			piecesNeeded: 2,
+		}
+		r.uploadHeap.managedPush(chunks[i])
+	}
+
+	// Upload the first chunk to two hosts and fail the second chunk after
+	// one piece. The third chunk is dropped from the heap.
+	hosts := []types.SiaPublicKey{
+		{Algorithm: types.SignatureEd25519, Key: []byte("host1")},
+		{Algorithm: types.SignatureEd25519, Key: []byte("host2")},
+	}
+	start := time.Now()
+	chunks[0].transferStart = start
+	chunks[0].transferBytes = 200
+	chunks[0].transferCost = types.NewCurrency64(20)
+	chunks[0].transferHosts = hosts
+	chunks[0].piecesCompleted = 2
+	chunks[1].transferStart = start.Add(time.Second)
+	chunks[1].transferBytes = 100
+	chunks[1].transferCost = types.NewCurrency64(10)
+	chunks[1].transferHosts = hosts[:1]
+	chunks[1].piecesCompleted = 1
+	for _, uc := range chunks[:2] {
+		r.managedFinishChunkTransfer(uc)
+	}
+	if records, _ := l.records(modules.RenterTransferFilter{}); len(records) != 0 {
+		t.Fatal("file was recorded before all of its chunks finished:", records)
+	}
+	r.managedFinishChunkTransfer(chunks[2])
+
+	records, err := l.records(modules.RenterTransferFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatal("expected one transfer for the file, got", len(records))
+	}
+	transfer := records[0]
+	if transfer.Type != modules.RenterTransferUpload || transfer.SiaPath != "foo" || !transfer.StartTime.Equal(start) {
+		t.Fatal("wrong transfer:", transfer)
+	}
+	if transfer.Bytes != 300 || !transfer.Cost.Equals64(30) || len(transfer.Hosts) != 2 {
+		t.Fatal("wrong transfer statistics:", transfer.Bytes, transfer.Cost, transfer.Hosts)
+	}
+	if transfer.Error == "" {
+		t.Fatal("failed chunk was not reported")
+	}
+	if len(r.uploadHeap.fileTransfers) != 0 {
+		t.Fatal("finished file transfer was not removed")
+	}
+}
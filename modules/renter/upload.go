@@ -84,8 +84,12 @@ func (r *Renter) Upload(up modules.FileUploadParams) error {
 	f := newFile(up.SiaPath, up.ErasureCode, pieceSize, uint64(fileInfo.Size()))
 	f.mode = uint32(fileInfo.Mode())
 
-	// Add file to renter.
+	// Add file to renter, unless it exceeds the quota of its tenant.
 	lockID = r.mu.Lock()
+	if err := r.checkTenantUpload(f); err != nil {
+		r.mu.Unlock(lockID)
+		return err
+	}
 	r.files[up.SiaPath] = f
 	r.persist.Tracking[up.SiaPath] = trackedFile{
 		RepairPath: up.Source,
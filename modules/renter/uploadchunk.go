@@ -4,8 +4,11 @@ import (
 	"io"
 	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/NebulousLabs/Sia/crypto"
+	"github.com/NebulousLabs/Sia/types"
 
 	"github.com/NebulousLabs/errors"
 )
@@ -20,10 +23,13 @@ type uploadChunkID struct {
 // finished uploading, including knowledge of the progress.
 type unfinishedUploadChunk struct {
 	// Information about the file. localPath may be the empty string if the file
-	// is known not to exist locally.
+	// is known not to exist locally. sourceFile is the file that the logical
+	// data is downloaded from if it is not available locally, which is not the
+	// renterFile if the file is being re-encoded.
 	id         uploadChunkID
 	localPath  string
 	renterFile *file
+	sourceFile *file
 
 	// Information about the chunk, namely where it exists within the file.
 	//
@@ -80,6 +86,16 @@ type unfinishedUploadChunk struct {
 	unusedHosts      map[string]struct{} // hosts that aren't yet storing any pieces or performing any work.
 	workersRemaining int                 // number of inactive workers still able to upload a piece.
 	workersStandby   []*worker           // workers that can be used if other workers fail.
+
+	// Transfer statistics for the transfer history. The transfer starts when
+	// the logical data of the chunk is fetched, the type is set when the
+	// chunk is added to the upload heap.
+	transferBytes uint64
+	transferCost  types.Currency
+	transferErr   error // most recent error
+	transferHosts []types.SiaPublicKey
+	transferStart time.Time
+	transferType  string
 }
 
 // managedNotifyStandbyWorkers is called when a worker fails to upload a piece, meaning
@@ -138,7 +154,7 @@ func (r *Renter) managedDownloadLogicalChunkData(chunk *unfinishedUploadChunk) e
 	d, err := r.managedNewDownload(downloadParams{
 		destination:     buf,
 		destinationType: "buffer",
-		file:            chunk.renterFile,
+		file:            chunk.sourceFile,
 
 		latencyTarget: 200e3, // No need to rush latency on repair downloads.
 		length:        downloadLength,
@@ -163,6 +179,15 @@ func (r *Renter) managedDownloadLogicalChunkData(chunk *unfinishedUploadChunk) e
 	case <-r.tg.StopChan():
 		return errors.New("repair download interrupted by stop call")
 	}
+
+	// Count the download towards the transfer statistics of the chunk.
+	hosts, cost := d.managedTransferStats()
+	chunk.mu.Lock()
+	chunk.transferBytes += atomic.LoadUint64(&d.atomicTotalDataTransferred)
+	chunk.transferCost = chunk.transferCost.Add(cost)
+	chunk.transferHosts = append(chunk.transferHosts, hosts...)
+	chunk.mu.Unlock()
+
 	if d.Err() != nil {
 		buf = nil
 		return d.Err()
@@ -198,9 +223,17 @@ func (r *Renter) managedFetchAndRepairChunk(chunk *unfinishedUploadChunk) {
 	// fails before the erasure coding occurs.
 	defer r.managedCleanUpUploadChunk(chunk)
 
+	// Start the transfer.
+	chunk.mu.Lock()
+	chunk.transferStart = time.Now()
+	chunk.mu.Unlock()
+
 	// Fetch the logical data for the chunk.
 	err := r.managedFetchLogicalChunkData(chunk)
 	if err != nil {
+		chunk.mu.Lock()
+		chunk.transferErr = err
+		chunk.mu.Unlock()
 		// Logical data is not available, cannot upload. Chunk will not be
 		// distributed to workers, therefore set workersRemaining equal to zero.
 		// The erasure coding memory has not been released yet, be sure to
@@ -369,11 +402,13 @@ func (r *Renter) managedCleanUpUploadChunk(uc *unfinishedUploadChunk) {
 	if memoryReleased > 0 {
 		r.memoryManager.Return(memoryReleased)
 	}
-	// If required, remove the chunk from the set of active chunks.
+	// If required, remove the chunk from the set of active chunks and add it
+	// to the transfer history.
 	if chunkComplete && !released {
 		r.uploadHeap.mu.Lock()
 		delete(r.uploadHeap.activeChunks, uc.id)
 		r.uploadHeap.mu.Unlock()
+		r.managedFinishChunkTransfer(uc)
 	}
 	// Sanity check - all memory should be released if the chunk is complete.
 	if chunkComplete && totalMemoryReleased != uc.memoryNeeded {
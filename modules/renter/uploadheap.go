@@ -38,7 +38,13 @@ type uploadHeap struct {
 	activeChunks map[uploadChunkID]struct{}
 	heap         uploadChunkHeap
 	newUploads   chan struct{}
-	mu           sync.Mutex
+
+	// fileTransfers collects the transfer statistics of the chunks of each
+	// file, so that the upload or repair of a file is added to the transfer
+	// history once all of its chunks have finished.
+	fileTransfers map[fileTransferID]*fileTransfer
+
+	mu sync.Mutex
 }
 
 // uploadChunkHeap is a bunch of priority-sorted chunks that need to be either
@@ -83,6 +89,7 @@ func (uh *uploadHeap) managedPush(uuc *unfinishedUploadChunk) {
 	if !exists {
 		uh.activeChunks[ucid] = struct{}{}
 		uh.heap.Push(uuc)
+		uh.queueChunkTransfer(uuc)
 	}
 	uh.mu.Unlock()
 }
@@ -128,6 +135,7 @@ func (r *Renter) buildUnfinishedChunks(f *file, hosts map[string]struct{}) []*un
 	for i := uint64(0); i < chunkCount; i++ {
 		newUnfinishedChunks[i] = &unfinishedUploadChunk{
 			renterFile: f,
+			sourceFile: f,
 			localPath:  trackedFile.RepairPath,
 
 			id: uploadChunkID{
@@ -251,6 +259,7 @@ func (r *Renter) managedBuildChunkHeap(hosts map[string]struct{}) {
 		file.mu.RUnlock()
 
 		unfinishedUploadChunks := r.buildUnfinishedChunks(file, hosts)
+		unfinishedUploadChunks = append(unfinishedUploadChunks, r.buildUnfinishedReencodeChunks(file, hosts)...)
 		for i := 0; i < len(unfinishedUploadChunks); i++ {
 			r.uploadHeap.managedPush(unfinishedUploadChunks[i])
 		}
@@ -281,6 +290,7 @@ func (r *Renter) managedPrepareNextChunk(uuc *unfinishedUploadChunk, hosts map[s
 	// of memory available, and then spin up a thread to asynchronously handle
 	// the rest of the chunk tasks.
 	if !r.memoryManager.Request(uuc.memoryNeeded, memoryPriorityLow) {
+		r.managedFinishChunkTransfer(uuc)
 		return
 	}
 	// Fetch the chunk in a separate goroutine, as it can take a long time and
@@ -327,6 +337,12 @@ func (r *Renter) threadedUploadLoop() {
 		// useful for uploading.
 		hosts := r.managedRefreshHostsAndWorkers()
 
+		// Replace the files that finished re-encoding with their new layout.
+		r.managedFinishReencodes()
+
+		// Check whether files have dropped below a redundancy of 1.0.
+		r.managedCheckRedundancyAlert()
+
 		// Build a min-heap of chunks organized by upload progress.
 		//
 		// TODO: After replacing the filesystem to resemble a tree, we'll be
@@ -371,6 +387,7 @@ func (r *Renter) threadedUploadLoop() {
 			availableWorkers := len(r.workerPool)
 			r.mu.RUnlock(id)
 			if availableWorkers < nextChunk.minimumPieces {
+				r.managedFinishChunkTransfer(nextChunk)
 				continue
 			}
 
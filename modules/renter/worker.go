@@ -1,6 +1,8 @@
 package renter
 
 import (
+	"bytes"
+	"sort"
 	"sync"
 	"time"
 
@@ -10,8 +12,8 @@ import (
 
 // A worker listens for work on a certain host.
 //
-// The mutex of the worker only protects the 'unprocessedChunks' and the
-// 'standbyChunks' fields of the worker. The rest of the fields are only
+// The mutexes of the worker protect the work queues and the statistics of the
+// worker, which are also read by the API. The rest of the fields are only
 // interacted with exclusively by the primary worker thread, and only one of
 // those ever exists at a time.
 //
@@ -27,35 +29,106 @@ type worker struct {
 	hostPubKey types.SiaPublicKey
 	renter     *Renter
 
-	// Download variables that are not protected by a mutex, but also do not
-	// need to be protected by a mutex, as they are only accessed by the master
-	// thread for the worker.
-	ownedDownloadConsecutiveFailures int       // How many failures in a row?
-	ownedDownloadRecentFailure       time.Time // How recent was the last failure?
-
-	// Download variables related to queuing work. They have a separate mutex to
-	// minimize lock contention.
-	downloadChan       chan struct{}              // Notifications of new work. Takes priority over uploads.
-	downloadChunks     []*unfinishedDownloadChunk // Yet unprocessed work items.
-	downloadMu         sync.Mutex
-	downloadTerminated bool // Has downloading been terminated for this worker?
+	// Download variables. They have a separate mutex to minimize lock
+	// contention.
+	downloadChan                chan struct{}              // Notifications of new work. Takes priority over uploads.
+	downloadChunks              []*unfinishedDownloadChunk // Yet unprocessed work items.
+	downloadConsecutiveFailures int                        // How many failures in a row?
+	downloadRecentFailure       time.Time                  // How recent was the last failure?
+	downloadRecentErr           error                      // What was the reason for the last failed download?
+	downloadRecentErrTime       time.Time                  // When did the last download fail?
+	downloadStats               workerTransferStats        // How fast were the successful downloads?
+	downloadMu                  sync.Mutex
+	downloadTerminated          bool // Has downloading been terminated for this worker?
 
 	// Upload variables.
 	unprocessedChunks         []*unfinishedUploadChunk // Yet unprocessed work items.
 	uploadChan                chan struct{}            // Notifications of new work.
 	uploadConsecutiveFailures int                      // How many times in a row uploading has failed.
 	uploadRecentFailure       time.Time                // How recent was the last failure?
+	uploadRecentFailureErr    error                    // What was the reason for the last failure?
+	uploadStats               workerTransferStats      // How fast were the successful uploads?
 	uploadTerminated          bool                     // Have we stopped uploading?
 
 	// Utilities.
 	//
-	// The mutex is only needed when interacting with 'downloadChunks' and
-	// 'unprocessedChunks', as everything else is only accessed from the single
-	// master thread.
+	// The mutex protects the upload variables. The download variables are
+	// protected by the download mutex.
 	killChan chan struct{} // Worker will shut down if a signal is sent down this channel.
 	mu       sync.Mutex
 }
 
+// workerTransferStats tracks the successful transfers of a worker in one
+// direction.
+type workerTransferStats struct {
+	bytes    uint64
+	duration time.Duration
+}
+
+// add records a successful transfer of n bytes that took the given amount of
+// time.
+func (s *workerTransferStats) add(n uint64, elapsed time.Duration) {
+	s.bytes += n
+	s.duration += elapsed
+}
+
+// throughput returns the average throughput of the transfers in bytes per
+// second, or zero if there were no transfers.
+func (s workerTransferStats) throughput() uint64 {
+	if s.bytes == 0 {
+		return 0
+	}
+	return throughput(s.bytes, s.duration)
+}
+
+// cooldownRemaining returns the time that remains of the cooldown of a worker
+// that failed consecutiveFailures times in a row, most recently at
+// recentFailure. The cooldown doubles with every consecutive failure, up to
+// maxConsecutivePenalty times.
+func cooldownRemaining(cooldown time.Duration, consecutiveFailures int, recentFailure time.Time) time.Duration {
+	for i := 0; i < consecutiveFailures && i < maxConsecutivePenalty; i++ {
+		cooldown *= 2
+	}
+	remaining := time.Until(recentFailure.Add(cooldown))
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// managedStatus returns the status of the worker.
+func (w *worker) managedStatus() modules.RenterWorkerStatus {
+	status := modules.RenterWorkerStatus{
+		ContractID:    w.contract.ID,
+		HostPublicKey: w.hostPubKey,
+	}
+
+	w.downloadMu.Lock()
+	status.DownloadQueueSize = len(w.downloadChunks)
+	status.DownloadCooldownRemaining = cooldownRemaining(downloadFailureCooldown, w.downloadConsecutiveFailures, w.downloadRecentFailure)
+	status.DownloadConsecutiveFailures = w.downloadConsecutiveFailures
+	if w.downloadRecentErr != nil {
+		status.DownloadRecentError = w.downloadRecentErr.Error()
+		status.DownloadRecentErrorTime = w.downloadRecentErrTime
+	}
+	status.DownloadThroughput = w.downloadStats.throughput()
+	w.downloadMu.Unlock()
+	status.DownloadOnCooldown = status.DownloadCooldownRemaining > 0
+
+	w.mu.Lock()
+	status.UploadQueueSize = len(w.unprocessedChunks)
+	status.UploadCooldownRemaining = cooldownRemaining(uploadFailureCooldown, w.uploadConsecutiveFailures, w.uploadRecentFailure)
+	status.UploadConsecutiveFailures = w.uploadConsecutiveFailures
+	if w.uploadRecentFailureErr != nil {
+		status.UploadRecentError = w.uploadRecentFailureErr.Error()
+		status.UploadRecentErrorTime = w.uploadRecentFailure
+	}
+	status.UploadThroughput = w.uploadStats.throughput()
+	w.mu.Unlock()
+	status.UploadOnCooldown = status.UploadCooldownRemaining > 0
+	return status
+}
+
 // updateWorkerPool will grab the set of contracts from the contractor and
 // update the worker pool to match.
 func (r *Renter) managedUpdateWorkerPool() {
@@ -143,3 +216,23 @@ func (w *worker) threadedWorkLoop() {
 		}
 	}
 }
+
+// WorkerPoolStatus returns the status of the workers of the renter, sorted by
+// contract id.
+func (r *Renter) WorkerPoolStatus() []modules.RenterWorkerStatus {
+	id := r.mu.RLock()
+	workers := make([]*worker, 0, len(r.workerPool))
+	for _, w := range r.workerPool {
+		workers = append(workers, w)
+	}
+	r.mu.RUnlock(id)
+
+	statuses := make([]modules.RenterWorkerStatus, 0, len(workers))
+	for _, w := range workers {
+		statuses = append(statuses, w.managedStatus())
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return bytes.Compare(statuses[i].ContractID[:], statuses[j].ContractID[:]) < 0
+	})
+	return statuses
+}
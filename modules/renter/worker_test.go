@@ -0,0 +1,70 @@
+package renter
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/types"
+)
+
+// TestCooldownRemaining checks that the cooldown doubles with every
+// consecutive failure and is capped at maxConsecutivePenalty doublings.
+func TestCooldownRemaining(t *testing.T) {
+	if r := cooldownRemaining(time.Minute, 0, time.Time{}); r != 0 {
+		t.Fatal("worker without failures should not be on cooldown:", r)
+	}
+	now := time.Now()
+	if r := cooldownRemaining(time.Minute, 0, now); r <= 59*time.Second || r > time.Minute {
+		t.Fatal("wrong cooldown after a single failure:", r)
+	}
+	if r := cooldownRemaining(time.Minute, 2, now); r <= 239*time.Second || r > 4*time.Minute {
+		t.Fatal("wrong cooldown after consecutive failures:", r)
+	}
+	max := time.Minute << uint(maxConsecutivePenalty)
+	if r := cooldownRemaining(time.Minute, maxConsecutivePenalty+5, now); r > max || r <= max-time.Second {
+		t.Fatal("cooldown was not capped:", r)
+	}
+}
+
+// TestWorkerStatus checks that the status of a worker reflects its queues,
+// failures and transfers.
+func TestWorkerStatus(t *testing.T) {
+	w := &worker{
+		contract:   modules.RenterContract{ID: types.FileContractID{1}},
+		hostPubKey: types.SiaPublicKey{Key: []byte("host")},
+
+		downloadChunks: make([]*unfinishedDownloadChunk, 2),
+
+		unprocessedChunks:         make([]*unfinishedUploadChunk, 3),
+		uploadConsecutiveFailures: 1,
+		uploadRecentFailure:       time.Now(),
+		uploadRecentFailureErr:    errors.New("upload failed"),
+	}
+	w.downloadStats.add(1e6, time.Second)
+
+	status := w.managedStatus()
+	if status.ContractID != w.contract.ID || status.HostPublicKey.String() != w.hostPubKey.String() {
+		t.Fatal("status has the wrong worker:", status)
+	}
+	if status.DownloadQueueSize != 2 || status.UploadQueueSize != 3 {
+		t.Fatal("status has the wrong queue sizes:", status)
+	}
+	if status.DownloadOnCooldown || status.DownloadRecentError != "" || status.DownloadThroughput != 1e6 {
+		t.Fatal("status has the wrong download state:", status)
+	}
+	if !status.UploadOnCooldown || status.UploadCooldownRemaining <= 0 || status.UploadRecentError != "upload failed" || status.UploadThroughput != 0 {
+		t.Fatal("status has the wrong upload state:", status)
+	}
+
+	// A failed download is reported, but doesn't put the worker on cooldown.
+	w.managedRecordDownloadError(errors.New("download failed"))
+	status = w.managedStatus()
+	if status.DownloadRecentError != "download failed" || status.DownloadRecentErrorTime.IsZero() {
+		t.Fatal("status doesn't report the download error:", status)
+	}
+	if status.DownloadOnCooldown || status.DownloadConsecutiveFailures != 0 || w.managedOnDownloadCooldown() {
+		t.Fatal("failed download put the worker on cooldown:", status)
+	}
+}
@@ -30,22 +30,29 @@ func (w *worker) managedDownload(udc *unfinishedDownloadChunk) {
 	d, err := w.renter.hostContractor.Downloader(w.contract.HostPublicKey, w.renter.tg.StopChan())
 	if err != nil {
 		w.renter.log.Debugln("worker failed to create downloader:", err)
+		w.managedRecordDownloadError(err)
 		udc.managedUnregisterWorker(w)
 		return
 	}
 	defer d.Close()
-	pieceData, err := d.Sector(udc.staticChunkMap[string(w.contract.HostPublicKey.Key)].root)
+	start := time.Now()
+	pieceData, cost, err := d.Sector(udc.staticChunkMap[string(w.contract.HostPublicKey.Key)].root)
 	if err != nil {
 		w.renter.log.Debugln("worker failed to download sector:", err)
+		w.managedRecordDownloadError(err)
 		udc.managedUnregisterWorker(w)
 		return
 	}
+	w.downloadMu.Lock()
+	w.downloadStats.add(uint64(len(pieceData)), time.Since(start))
+	w.downloadMu.Unlock()
 	// TODO: Instead of adding the whole sector after the download completes,
 	// have the 'd.Sector' call add to this value ongoing as the sector comes
 	// in. Perhaps even include the data from creating the downloader and other
 	// data sent to and received from the host (like signatures) that aren't
 	// actually payload data.
 	atomic.AddUint64(&udc.download.atomicTotalDataTransferred, udc.staticPieceSize)
+	udc.download.managedRecordPiece(w.contract.HostPublicKey, cost)
 
 	// Decrypt the piece. This might introduce some overhead for downloads with
 	// a large overdrive. It shouldn't be a bottleneck though since bandwidth
@@ -74,6 +81,16 @@ func (w *worker) managedDownload(udc *unfinishedDownloadChunk) {
 	udc.mu.Unlock()
 }
 
+// managedRecordDownloadError records the error of a failed download so that it
+// can be reported in the worker's status. It does not put the worker on
+// cooldown.
+func (w *worker) managedRecordDownloadError(err error) {
+	w.downloadMu.Lock()
+	w.downloadRecentErr = err
+	w.downloadRecentErrTime = time.Now()
+	w.downloadMu.Unlock()
+}
+
 // managedKillDownloading will drop all of the download work given to the
 // worker, and set a signal to prevent the worker from accepting more download
 // work.
@@ -90,6 +107,7 @@ func (w *worker) managedKillDownloading() {
 	w.downloadTerminated = true
 	w.downloadMu.Unlock()
 	for i := 0; i < len(removedChunks); i++ {
+		removedChunks[i].managedRemovePendingWorker(w)
 		removedChunks[i].managedRemoveWorker()
 	}
 }
@@ -129,6 +147,7 @@ func (w *worker) managedQueueDownloadChunk(udc *unfinishedDownloadChunk) {
 	// If the worker has terminated, remove it from the udc. This call needs to
 	// happen without holding the worker lock.
 	if terminated {
+		udc.managedRemovePendingWorker(w)
 		udc.managedRemoveWorker()
 	}
 }
@@ -143,15 +162,12 @@ func (udc *unfinishedDownloadChunk) managedUnregisterWorker(w *worker) {
 	udc.mu.Unlock()
 }
 
-// ownedOnDownloadCooldown returns true if the worker is on cooldown from failed
-// downloads. This function should only be called by the master worker thread,
-// and does not require any mutexes.
-func (w *worker) ownedOnDownloadCooldown() bool {
-	requiredCooldown := downloadFailureCooldown
-	for i := 0; i < w.ownedDownloadConsecutiveFailures && i < maxConsecutivePenalty; i++ {
-		requiredCooldown *= 2
-	}
-	return time.Now().Before(w.ownedDownloadRecentFailure.Add(requiredCooldown))
+// managedOnDownloadCooldown returns true if the worker is on cooldown from
+// failed downloads.
+func (w *worker) managedOnDownloadCooldown() bool {
+	w.downloadMu.Lock()
+	defer w.downloadMu.Unlock()
+	return cooldownRemaining(downloadFailureCooldown, w.downloadConsecutiveFailures, w.downloadRecentFailure) > 0
 }
 
 // ownedProcessDownloadChunk will take a potential download chunk, figure out if
@@ -162,13 +178,17 @@ func (w *worker) ownedOnDownloadCooldown() bool {
 func (w *worker) ownedProcessDownloadChunk(udc *unfinishedDownloadChunk) *unfinishedDownloadChunk {
 	// Determine whether the worker needs to drop the chunk. If so, remove the
 	// worker and return nil. Worker only needs to be removed if worker is being
-	// dropped.
+	// dropped. The cooldown is checked before grabbing the chunk lock, so
+	// that the worker lock and the chunk lock are never held simultaneously.
+	onCooldown := w.managedOnDownloadCooldown()
 	udc.mu.Lock()
 	chunkComplete := udc.piecesCompleted >= udc.erasureCode.MinPieces()
 	chunkFailed := udc.piecesCompleted+udc.workersRemaining < udc.erasureCode.MinPieces()
 	pieceData, workerHasPiece := udc.staticChunkMap[string(w.contract.HostPublicKey.Key)]
 	pieceTaken := udc.pieceUsage[pieceData.index]
-	if chunkComplete || chunkFailed || w.ownedOnDownloadCooldown() || !workerHasPiece || pieceTaken {
+	_, preferred := udc.staticPreferredHosts[string(w.contract.HostPublicKey.Key)]
+	delete(udc.preferredWorkersPending, string(w.contract.HostPublicKey.Key))
+	if chunkComplete || chunkFailed || onCooldown || !workerHasPiece || pieceTaken {
 		udc.mu.Unlock()
 		udc.managedRemoveWorker()
 		return nil
@@ -200,7 +220,13 @@ func (w *worker) ownedProcessDownloadChunk(udc *unfinishedDownloadChunk) *unfini
 	// metrics, so that we can avoid holding the worker lock and the udc lock
 	// simultaneously (deadlock risk). The 'owned' variables of the worker are
 	// variables that are only accessed by the master worker thread.
-	meetsExtraCriteria := true
+	//
+	// Workers of hosts that are not preferred only meet the extra criteria
+	// if the preferred workers can't provide enough pieces on their own.
+	piecesInProgress := udc.piecesRegistered + udc.piecesCompleted
+	desiredPiecesInProgress := udc.erasureCode.MinPieces() + udc.staticOverdrive
+	meetsExtraCriteria := preferred || len(udc.staticPreferredHosts) == 0 ||
+		piecesInProgress+len(udc.preferredWorkersPending) < desiredPiecesInProgress
 
 	// TODO: There's going to need to be some method for relaxing criteria after
 	// the first wave of workers are sent off. If the first waves of workers
@@ -214,8 +240,6 @@ func (w *worker) ownedProcessDownloadChunk(udc *unfinishedDownloadChunk) *unfini
 	// number of overdrive workers (typically zero). For our purposes, completed
 	// pieces count as active workers, though the workers have actually
 	// finished.
-	piecesInProgress := udc.piecesRegistered + udc.piecesCompleted
-	desiredPiecesInProgress := udc.erasureCode.MinPieces() + udc.staticOverdrive
 	workersDesired := piecesInProgress < desiredPiecesInProgress
 
 	if workersDesired && meetsExtraCriteria {
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules"
 )
 
 // managedDropChunk will remove a worker from the responsibility of tracking a chunk.
@@ -101,21 +102,23 @@ func (w *worker) managedUpload(uc *unfinishedUploadChunk, pieceIndex uint64) {
 	e, err := w.renter.hostContractor.Editor(w.contract.HostPublicKey, w.renter.tg.StopChan())
 	if err != nil {
 		w.renter.log.Debugln("Worker failed to acquire an editor:", err)
-		w.managedUploadFailed(uc, pieceIndex)
+		w.managedUploadFailed(uc, pieceIndex, err)
 		return
 	}
 	defer e.Close()
 
 	// Perform the upload, and update the failure stats based on the success of
 	// the upload attempt.
-	root, err := e.Upload(uc.physicalChunkData[pieceIndex])
+	start := time.Now()
+	root, cost, err := e.Upload(uc.physicalChunkData[pieceIndex])
 	if err != nil {
 		w.renter.log.Debugln("Worker failed to upload via the editor:", err)
-		w.managedUploadFailed(uc, pieceIndex)
+		w.managedUploadFailed(uc, pieceIndex, err)
 		return
 	}
 	w.mu.Lock()
 	w.uploadConsecutiveFailures = 0
+	w.uploadStats.add(uint64(len(uc.physicalChunkData[pieceIndex])), time.Since(start))
 	w.mu.Unlock()
 
 	// Update the renter metadata.
@@ -145,11 +148,24 @@ func (w *worker) managedUpload(uc *unfinishedUploadChunk, pieceIndex uint64) {
 	// available to reflect the completed upload.
 	uc.mu.Lock()
 	releaseSize := len(uc.physicalChunkData[pieceIndex])
+	uc.transferBytes += uint64(releaseSize)
+	uc.transferCost = uc.transferCost.Add(cost)
+	uc.transferHosts = append(uc.transferHosts, w.contract.HostPublicKey)
 	uc.piecesRegistered--
 	uc.piecesCompleted++
 	uc.physicalChunkData[pieceIndex] = nil
 	uc.memoryReleased += uint64(releaseSize)
+	// The tenant that owns the file pays for the data of the chunk once the
+	// chunk can be recovered. Repairs are not charged.
+	chargeTenant := uc.transferType == modules.RenterTransferUpload && uc.piecesCompleted == uc.minimumPieces
 	uc.mu.Unlock()
+	if chargeTenant {
+		id := w.renter.mu.Lock()
+		uc.renterFile.mu.RLock()
+		w.renter.recordTenantUpload(uc.renterFile.name, uc.renterFile.chunkDataSize(uc.index))
+		uc.renterFile.mu.RUnlock()
+		w.renter.mu.Unlock(id)
+	}
 	w.renter.memoryManager.Return(uint64(releaseSize))
 	w.renter.managedCleanUpUploadChunk(uc)
 }
@@ -157,11 +173,7 @@ func (w *worker) managedUpload(uc *unfinishedUploadChunk, pieceIndex uint64) {
 // onUploadCooldown returns true if the worker is on cooldown from failed
 // uploads.
 func (w *worker) onUploadCooldown() bool {
-	requiredCooldown := uploadFailureCooldown
-	for i := 0; i < w.uploadConsecutiveFailures && i < maxConsecutivePenalty; i++ {
-		requiredCooldown *= 2
-	}
-	return time.Now().Before(w.uploadRecentFailure.Add(requiredCooldown))
+	return cooldownRemaining(uploadFailureCooldown, w.uploadConsecutiveFailures, w.uploadRecentFailure) > 0
 }
 
 // managedProcessUploadChunk will process a chunk from the worker chunk queue.
@@ -222,12 +234,13 @@ func (w *worker) managedProcessUploadChunk(uc *unfinishedUploadChunk) (nextChunk
 
 // managedUploadFailed is called if a worker failed to upload part of an unfinished
 // chunk.
-func (w *worker) managedUploadFailed(uc *unfinishedUploadChunk, pieceIndex uint64) {
+func (w *worker) managedUploadFailed(uc *unfinishedUploadChunk, pieceIndex uint64, err error) {
 	// Mark the failure in the worker if the gateway says we are online. It's
 	// not the worker's fault if we are offline.
 	if w.renter.g.Online() {
 		w.mu.Lock()
 		w.uploadRecentFailure = time.Now()
+		w.uploadRecentFailureErr = err
 		w.uploadConsecutiveFailures++
 		w.mu.Unlock()
 	}
@@ -236,6 +249,7 @@ func (w *worker) managedUploadFailed(uc *unfinishedUploadChunk, pieceIndex uint6
 	uc.mu.Lock()
 	uc.piecesRegistered--
 	uc.pieceUsage[pieceIndex] = false
+	uc.transferErr = err
 	uc.mu.Unlock()
 
 	// Notify the standby workers of the chunk
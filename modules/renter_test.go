@@ -9,6 +9,9 @@ import (
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/persist"
+	"github.com/NebulousLabs/Sia/types"
+
+	"github.com/NebulousLabs/errors"
 	"github.com/NebulousLabs/fastrand"
 )
 
@@ -225,3 +228,83 @@ func BenchmarkSliceCryptoHashSave(b *testing.B) {
 		}
 	}
 }
+
+// TestAllowanceCheckHostPrices checks that the optional price limits of the
+// allowance are enforced correctly.
+func TestAllowanceCheckHostPrices(t *testing.T) {
+	var settings HostExternalSettings
+	settings.Collateral = types.NewCurrency64(10)
+	settings.ContractPrice = types.NewCurrency64(10)
+	settings.DownloadBandwidthPrice = types.NewCurrency64(10)
+	settings.StoragePrice = types.NewCurrency64(10)
+	settings.UploadBandwidthPrice = types.NewCurrency64(10)
+
+	// An allowance without limits should accept any host.
+	var a Allowance
+	if err := a.CheckHostPrices(settings); err != nil {
+		t.Fatal("empty allowance rejected host:", err)
+	}
+
+	// Limits that the host stays within should be accepted.
+	a.MaxContractPrice = types.NewCurrency64(10)
+	a.MaxDownloadBandwidthPrice = types.NewCurrency64(10)
+	a.MaxStoragePrice = types.NewCurrency64(10)
+	a.MaxUploadBandwidthPrice = types.NewCurrency64(10)
+	a.MinCollateral = types.NewCurrency64(10)
+	if err := a.CheckHostPrices(settings); err != nil {
+		t.Fatal("host within limits was rejected:", err)
+	}
+
+	// Each violated limit should be reported.
+	tests := []func(*Allowance){
+		func(a *Allowance) { a.MaxContractPrice = types.NewCurrency64(9) },
+		func(a *Allowance) { a.MaxDownloadBandwidthPrice = types.NewCurrency64(9) },
+		func(a *Allowance) { a.MaxStoragePrice = types.NewCurrency64(9) },
+		func(a *Allowance) { a.MaxUploadBandwidthPrice = types.NewCurrency64(9) },
+		func(a *Allowance) { a.MinCollateral = types.NewCurrency64(11) },
+	}
+	for i, modify := range tests {
+		limited := a
+		modify(&limited)
+		if err := limited.CheckHostPrices(settings); !errors.Contains(err, ErrHostExceedsAllowanceLimits) {
+			t.Fatalf("test %v: expected %v, got %v", i, ErrHostExceedsAllowanceLimits, err)
+		}
+	}
+}
+
+// TestAllowanceExpectedHostUsage checks that the expected usage of an
+// allowance is split correctly across the hosts.
+func TestAllowanceExpectedHostUsage(t *testing.T) {
+	a := Allowance{
+		Hosts:            10,
+		Period:           100,
+		ExpectedStorage:  1000,
+		ExpectedUpload:   10,
+		ExpectedDownload: 20,
+	}
+	if !a.HasExpectedUsage() {
+		t.Fatal("allowance should have expected usage")
+	}
+
+	// Without an expected redundancy the default redundancy is used.
+	storage, upload, download := a.ExpectedHostUsage()
+	if storage != 300 || upload != 300 || download != 200 {
+		t.Fatal("wrong expected host usage:", storage, upload, download)
+	}
+
+	a.ExpectedRedundancy = 1.5
+	storage, upload, download = a.ExpectedHostUsage()
+	if storage != 150 || upload != 150 || download != 200 {
+		t.Fatal("wrong expected host usage:", storage, upload, download)
+	}
+
+	// An allowance without hosts has no usage per host.
+	a.Hosts = 0
+	storage, upload, download = a.ExpectedHostUsage()
+	if storage != 0 || upload != 0 || download != 0 {
+		t.Fatal("wrong expected host usage:", storage, upload, download)
+	}
+	if (Allowance{}).HasExpectedUsage() {
+		t.Fatal("empty allowance should not have expected usage")
+	}
+}
@@ -1,6 +1,8 @@
 package modules
 
 import (
+	"time"
+
 	"github.com/NebulousLabs/Sia/crypto"
 )
 
@@ -35,11 +37,44 @@ type (
 		SuccessfulWrites uint64 `json:"successfulwrites"`
 
 		// Certain operations on a storage folder can take a long time (Add,
-		// Remove, and Resize). The fields below indicate the progress of any
+		// Move, Remove, and Resize). The fields below indicate the progress of any
 		// long running operations that might be under way in the storage
 		// folder. Progress is always reported in bytes.
 		ProgressNumerator   uint64
 		ProgressDenominator uint64
+
+		// The fields below report on the integrity scrubber, which
+		// periodically rereads every sector in the storage folder and checks
+		// it against its Merkle root. Corrupt sectors hold data that does not
+		// match their root, missing sectors could not be read at all.
+		// ScrubProgress is the position of the scrub that is under way, and
+		// LastScrubTime is the time that the last complete scrub finished.
+		CorruptSectors uint64    `json:"corruptsectors"`
+		LastScrubTime  time.Time `json:"lastscrubtime"`
+		MissingSectors uint64    `json:"missingsectors"`
+		ScrubProgress  uint64    `json:"scrubprogress"` // bytes
+	}
+
+	// SectorCacheMetrics describes the sector cache of the storage manager,
+	// which keeps the most recently read sectors in memory. HitRate is the
+	// fraction of sector reads that were served from the cache.
+	SectorCacheMetrics struct {
+		Capacity uint64 `json:"capacity"` // bytes
+		Size     uint64 `json:"size"`     // bytes
+		Sectors  uint64 `json:"sectors"`
+
+		Hits    uint64  `json:"hits"`
+		Misses  uint64  `json:"misses"`
+		HitRate float64 `json:"hitrate"`
+	}
+
+	// SectorLocation is the place where a sector is stored by the storage
+	// manager. Found is false if the storage manager does not have the
+	// sector.
+	SectorLocation struct {
+		Found         bool   `json:"found"`
+		Index         uint32 `json:"index"`
+		StorageFolder string `json:"storagefolder"`
 	}
 
 	// A StorageManager is responsible for managing storage folders and
@@ -80,6 +115,18 @@ type (
 		// requests to remove data.
 		DeleteSector(sectorRoot crypto.Hash) error
 
+		// FailedSectors returns the roots out of sectorRoots whose sectors
+		// were found to be corrupt or missing when the storage manager last
+		// checked them.
+		FailedSectors(sectorRoots []crypto.Hash) []crypto.Hash
+
+		// MoveStorageFolder will move a storage folder to a new path. The
+		// sectors of the folder remain readable while they are copied, and the
+		// folder only switches to the new path once all of them have been
+		// copied. If the move is interrupted, the folder stays at its old
+		// path.
+		MoveStorageFolder(index uint16, newPath string) error
+
 		// ReadSector will read a sector from the storage manager, returning the
 		// bytes that match the input sector root.
 		ReadSector(sectorRoot crypto.Hash) ([]byte, error)
@@ -118,6 +165,18 @@ type (
 		// that data will be lost.
 		ResizeStorageFolder(index uint16, newSize uint64, force bool) error
 
+		// SectorCacheMetrics returns the size and the hit rate of the sector
+		// cache.
+		SectorCacheMetrics() SectorCacheMetrics
+
+		// SectorLocations returns the locations of the sectors with the
+		// provided roots, in the same order as the roots.
+		SectorLocations(sectorRoots []crypto.Hash) []SectorLocation
+
+		// SetSectorCacheSize sets the number of bytes of sector data that the
+		// storage manager keeps in memory. A size of zero disables the cache.
+		SetSectorCacheSize(size uint64)
+
 		// StorageFolders will return a list of storage folders tracked by the
 		// manager.
 		StorageFolders() []StorageFolderMetadata
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/NebulousLabs/Sia/crypto"
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/node/api"
+	"github.com/NebulousLabs/Sia/types"
 )
 
 // HostParam is a parameter in the host's settings that can be changed via the
@@ -47,6 +49,51 @@ const (
 	HostParamMaxReviseBatchSize = HostParam("maxrevisebatchsize")
 	// HostParamNetAddress is the announced netaddress of the host.
 	HostParamNetAddress = HostParam("netaddress")
+	// HostParamPricingAutopilot indicates if the host adjusts its prices to
+	// the prices of the other hosts.
+	HostParamPricingAutopilot = HostParam("pricingautopilot")
+	// HostParamPricingTargetPercentile is the percentile of the prices of the
+	// other hosts that the pricing autopilot targets.
+	HostParamPricingTargetPercentile = HostParam("pricingtargetpercentile")
+	// HostParamContractPriceCeiling is the highest contract price that the
+	// pricing autopilot sets in hastings.
+	HostParamContractPriceCeiling = HostParam("contractpriceceiling")
+	// HostParamContractPriceFloor is the lowest contract price that the
+	// pricing autopilot sets in hastings.
+	HostParamContractPriceFloor = HostParam("contractpricefloor")
+	// HostParamDownloadBandwidthPriceCeiling is the highest download
+	// bandwidth price that the pricing autopilot sets in hastings/byte.
+	HostParamDownloadBandwidthPriceCeiling = HostParam("downloadbandwidthpriceceiling")
+	// HostParamDownloadBandwidthPriceFloor is the lowest download bandwidth
+	// price that the pricing autopilot sets in hastings/byte.
+	HostParamDownloadBandwidthPriceFloor = HostParam("downloadbandwidthpricefloor")
+	// HostParamStoragePriceCeiling is the highest storage price that the
+	// pricing autopilot sets in hastings/byte/block.
+	HostParamStoragePriceCeiling = HostParam("storagepriceceiling")
+	// HostParamStoragePriceFloor is the lowest storage price that the pricing
+	// autopilot sets in hastings/byte/block.
+	HostParamStoragePriceFloor = HostParam("storagepricefloor")
+	// HostParamUploadBandwidthPriceCeiling is the highest upload bandwidth
+	// price that the pricing autopilot sets in hastings/byte.
+	HostParamUploadBandwidthPriceCeiling = HostParam("uploadbandwidthpriceceiling")
+	// HostParamUploadBandwidthPriceFloor is the lowest upload bandwidth price
+	// that the pricing autopilot sets in hastings/byte.
+	HostParamUploadBandwidthPriceFloor = HostParam("uploadbandwidthpricefloor")
+	// HostParamMaxDownloadSpeed is the bandwidth limit in bytes per second
+	// for the data that renters download from the host.
+	HostParamMaxDownloadSpeed = HostParam("maxdownloadspeed")
+	// HostParamMaxUploadSpeed is the bandwidth limit in bytes per second for
+	// the data that renters upload to the host.
+	HostParamMaxUploadSpeed = HostParam("maxuploadspeed")
+	// HostParamMaxRenterDownloadSpeed is the download bandwidth limit in
+	// bytes per second of each renter.
+	HostParamMaxRenterDownloadSpeed = HostParam("maxrenterdownloadspeed")
+	// HostParamMaxRenterUploadSpeed is the upload bandwidth limit in bytes
+	// per second of each renter.
+	HostParamMaxRenterUploadSpeed = HostParam("maxrenteruploadspeed")
+	// HostParamSectorCacheSize is the number of bytes of sector data that
+	// the host keeps in memory.
+	HostParamSectorCacheSize = HostParam("sectorcachesize")
 )
 
 // HostAnnouncePost uses the /host/announce endpoint to announce the host to
@@ -63,6 +110,19 @@ func (c *Client) HostAnnounceAddrPost(address modules.NetAddress) (err error) {
 	return
 }
 
+// HostBandwidthGet requests the /host/bandwidth endpoint.
+func (c *Client) HostBandwidthGet() (hbg api.HostBandwidthGET, err error) {
+	err = c.get("/host/bandwidth", &hbg)
+	return
+}
+
+// HostContractGet uses the /host/contracts/:id endpoint to get the details of
+// a contract on the host.
+func (c *Client) HostContractGet(id types.FileContractID) (hcg api.HostContractGET, err error) {
+	err = c.get("/host/contracts/"+id.String(), &hcg)
+	return
+}
+
 // HostContractInfoGet uses the /host/contracts endpoint to get information
 // about contracts on the host.
 func (c *Client) HostContractInfoGet() (cg api.ContractInfoGET, err error) {
@@ -89,6 +149,63 @@ func (c *Client) HostModifySettingPost(param HostParam, value interface{}) (err
 	return
 }
 
+// HostMaintenanceGet requests the /host/maintenance endpoint.
+func (c *Client) HostMaintenanceGet() (hmg api.HostMaintenanceGET, err error) {
+	err = c.get("/host/maintenance", &hmg)
+	return
+}
+
+// HostMaintenancePost uses the /host/maintenance endpoint to put the host in
+// maintenance between start and end. A zero start starts the maintenance
+// right away, a zero end keeps the host in maintenance until it is cancelled.
+func (c *Client) HostMaintenancePost(start, end time.Time) (err error) {
+	values := url.Values{}
+	if !start.IsZero() {
+		values.Set("start", strconv.FormatInt(start.Unix(), 10))
+	}
+	if !end.IsZero() {
+		values.Set("end", strconv.FormatInt(end.Unix(), 10))
+	}
+	err = c.post("/host/maintenance", values.Encode(), nil)
+	return
+}
+
+// HostMaintenanceCancelPost uses the /host/maintenance/cancel endpoint to end
+// the maintenance of the host.
+func (c *Client) HostMaintenanceCancelPost() (err error) {
+	err = c.post("/host/maintenance/cancel", "", nil)
+	return
+}
+
+// HostPolicyGet requests the /host/policy endpoint.
+func (c *Client) HostPolicyGet() (hpg api.HostPolicyGET, err error) {
+	err = c.get("/host/policy", &hpg)
+	return
+}
+
+// HostPolicyPost uses the /host/policy endpoint to change the host's policy
+// toward renters. Unset values are left unchanged.
+func (c *Client) HostPolicyPost(values url.Values) (err error) {
+	err = c.post("/host/policy", values.Encode(), nil)
+	return
+}
+
+// HostProofsGet requests the /host/proofs endpoint.
+func (c *Client) HostProofsGet() (hpg api.HostProofsGET, err error) {
+	err = c.get("/host/proofs", &hpg)
+	return
+}
+
+// HostLedgerGet requests the /host/ledger endpoint, returning the financial
+// events of the host between start and end.
+func (c *Client) HostLedgerGet(start, end time.Time) (hlg api.HostLedgerGET, err error) {
+	values := url.Values{}
+	values.Set("start", strconv.FormatInt(start.Unix(), 10))
+	values.Set("end", strconv.FormatInt(end.Unix(), 10))
+	err = c.get("/host/ledger?"+values.Encode(), &hlg)
+	return
+}
+
 // HostStorageFoldersAddPost uses the /host/storage/folders/add api endpoint to
 // add a storage folder to a host
 func (c *Client) HostStorageFoldersAddPost(path string, size uint64) (err error) {
@@ -99,6 +216,16 @@ func (c *Client) HostStorageFoldersAddPost(path string, size uint64) (err error)
 	return
 }
 
+// HostStorageFoldersMovePost uses the /host/storage/folders/move api endpoint
+// to move a storage folder of a host to a new path.
+func (c *Client) HostStorageFoldersMovePost(path, newPath string) (err error) {
+	values := url.Values{}
+	values.Set("path", path)
+	values.Set("newpath", newPath)
+	err = c.post("/host/storage/folders/move", values.Encode(), nil)
+	return
+}
+
 // HostStorageFoldersRemovePost uses the /host/storage/folders/remove api
 // endpoint to remove a storage folder from a host.
 func (c *Client) HostStorageFoldersRemovePost(path string) (err error) {
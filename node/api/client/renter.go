@@ -9,8 +9,60 @@ import (
 
 	"github.com/NebulousLabs/Sia/modules"
 	"github.com/NebulousLabs/Sia/node/api"
+	"github.com/NebulousLabs/Sia/types"
 )
 
+// RenterAlertsGet requests the /renter/alerts resource.
+func (c *Client) RenterAlertsGet() (rag api.RenterAlertsGET, err error) {
+	err = c.get("/renter/alerts", &rag)
+	return
+}
+
+// RenterContractCancelPost uses the /renter/contracts/cancel endpoint to
+// cancel the contract with the given id.
+func (c *Client) RenterContractCancelPost(id types.FileContractID) (err error) {
+	values := url.Values{}
+	values.Set("id", id.String())
+	err = c.post("/renter/contracts/cancel", values.Encode(), nil)
+	return
+}
+
+// RenterContractFormPost uses the /renter/contracts/form endpoint to form a
+// contract with the given host. An endHeight of zero forms a contract that
+// ends with the current period.
+func (c *Client) RenterContractFormPost(hostKey types.SiaPublicKey, funds types.Currency, endHeight types.BlockHeight) (rcf api.RenterContractFormPOST, err error) {
+	values := url.Values{}
+	values.Set("host", hostKey.String())
+	values.Set("funds", funds.String())
+	if endHeight != 0 {
+		values.Set("endheight", strconv.FormatUint(uint64(endHeight), 10))
+	}
+	err = c.post("/renter/contracts/form", values.Encode(), &rcf)
+	return
+}
+
+// RenterContractRenewPost uses the /renter/contracts/renew endpoint to renew
+// the contract with the given id. If funds is zero, the renter estimates the
+// funding.
+func (c *Client) RenterContractRenewPost(id types.FileContractID, funds types.Currency) (err error) {
+	values := url.Values{}
+	values.Set("id", id.String())
+	if !funds.IsZero() {
+		values.Set("funds", funds.String())
+	}
+	err = c.post("/renter/contracts/renew", values.Encode(), nil)
+	return
+}
+
+// RenterContractStopRenewingPost uses the /renter/contracts/stoprenewing
+// endpoint to stop the renewal of the contract with the given id.
+func (c *Client) RenterContractStopRenewingPost(id types.FileContractID) (err error) {
+	values := url.Values{}
+	values.Set("id", id.String())
+	err = c.post("/renter/contracts/stoprenewing", values.Encode(), nil)
+	return
+}
+
 // RenterContractsGet requests the /renter/contracts resource and returns
 // Contracts and ActiveContracts
 func (c *Client) RenterContractsGet() (rc api.RenterContracts, err error) {
@@ -61,6 +113,37 @@ func (c *Client) RenterDownloadFullGet(siaPath, destination string, async bool)
 	return
 }
 
+// RenterDownloadHostsGet uses the /renter/download endpoint to download a
+// full file while excluding or preferring certain hosts. If maxOverdrive is
+// negative, the default overdrive is used.
+func (c *Client) RenterDownloadHostsGet(siaPath, destination string, exclude, prefer []types.SiaPublicKey, maxOverdrive int, async bool) (err error) {
+	siaPath = strings.TrimPrefix(siaPath, "/")
+	values := url.Values{}
+	values.Set("destination", destination)
+	values.Set("httpresp", "false")
+	values.Set("async", strconv.FormatBool(async))
+	if len(exclude) > 0 {
+		values.Set("excludehosts", joinHostKeys(exclude))
+	}
+	if len(prefer) > 0 {
+		values.Set("preferhosts", joinHostKeys(prefer))
+	}
+	if maxOverdrive >= 0 {
+		values.Set("maxoverdrive", strconv.Itoa(maxOverdrive))
+	}
+	err = c.get("/renter/download/"+siaPath+"?"+values.Encode(), nil)
+	return
+}
+
+// joinHostKeys joins host public keys into a comma separated list.
+func joinHostKeys(keys []types.SiaPublicKey) string {
+	strs := make([]string, len(keys))
+	for i, pk := range keys {
+		strs[i] = pk.String()
+	}
+	return strings.Join(strs, ",")
+}
+
 // RenterClearAllDownloadsPost requests the /renter/downloads/clear resource
 // with no parameters
 func (c *Client) RenterClearAllDownloadsPost() (err error) {
@@ -137,6 +220,15 @@ func (c *Client) RenterPostAllowance(allowance modules.Allowance) (err error) {
 	values.Set("hosts", strconv.FormatUint(allowance.Hosts, 10))
 	values.Set("period", strconv.FormatUint(uint64(allowance.Period), 10))
 	values.Set("renewwindow", strconv.FormatUint(uint64(allowance.RenewWindow), 10))
+	values.Set("maxcontractprice", allowance.MaxContractPrice.String())
+	values.Set("maxdownloadbandwidthprice", allowance.MaxDownloadBandwidthPrice.String())
+	values.Set("maxstorageprice", allowance.MaxStoragePrice.String())
+	values.Set("maxuploadbandwidthprice", allowance.MaxUploadBandwidthPrice.String())
+	values.Set("mincollateral", allowance.MinCollateral.String())
+	values.Set("expectedstorage", strconv.FormatUint(allowance.ExpectedStorage, 10))
+	values.Set("expectedupload", strconv.FormatUint(allowance.ExpectedUpload, 10))
+	values.Set("expecteddownload", strconv.FormatUint(allowance.ExpectedDownload, 10))
+	values.Set("expectedredundancy", strconv.FormatFloat(allowance.ExpectedRedundancy, 'f', -1, 64))
 	err = c.post("/renter", values.Encode(), nil)
 	return
 }
@@ -153,6 +245,105 @@ func (c *Client) RenterPricesGet() (rpg api.RenterPricesGET, err error) {
 	return
 }
 
+// RenterQuotePost uses the /renter/quote endpoint to estimate the cost of
+// uploading size bytes with the given erasure coding parameters, storing them
+// for duration blocks and downloading expectedDownload bytes of them.
+func (c *Client) RenterQuotePost(size, dataPieces, parityPieces uint64, duration types.BlockHeight, expectedDownload uint64) (rqp api.RenterQuotePOST, err error) {
+	values := url.Values{}
+	values.Set("size", strconv.FormatUint(size, 10))
+	values.Set("datapieces", strconv.FormatUint(dataPieces, 10))
+	values.Set("paritypieces", strconv.FormatUint(parityPieces, 10))
+	values.Set("duration", strconv.FormatUint(uint64(duration), 10))
+	values.Set("expecteddownload", strconv.FormatUint(expectedDownload, 10))
+	err = c.post("/renter/quote", values.Encode(), &rqp)
+	return
+}
+
+// RenterSpendingGet requests the /renter/spending endpoint's resources. If
+// the host key is empty, the spending of all hosts is returned.
+func (c *Client) RenterSpendingGet(host types.SiaPublicKey, start, end time.Time) (rsg api.RenterSpendingGET, err error) {
+	values := url.Values{}
+	if len(host.Key) != 0 {
+		values.Set("host", host.String())
+	}
+	values.Set("start", strconv.FormatInt(start.Unix(), 10))
+	values.Set("end", strconv.FormatInt(end.Unix(), 10))
+	err = c.get("/renter/spending?"+values.Encode(), &rsg)
+	return
+}
+
+// RenterTenantsGet requests the /renter/tenants resource.
+func (c *Client) RenterTenantsGet() (rtg api.RenterTenantsGET, err error) {
+	err = c.get("/renter/tenants", &rtg)
+	return
+}
+
+// RenterTenantsPost uses the /renter/tenants endpoint to create a tenant or
+// update the quota of a tenant. An empty password keeps the password of an
+// existing tenant.
+func (c *Client) RenterTenantsPost(name, password string, quota modules.RenterTenantQuota) (err error) {
+	values := url.Values{}
+	values.Set("name", name)
+	if password != "" {
+		values.Set("password", password)
+	}
+	values.Set("storagequota", strconv.FormatUint(quota.StorageQuota, 10))
+	values.Set("uploadbudget", strconv.FormatUint(quota.UploadBudget, 10))
+	values.Set("downloadbudget", strconv.FormatUint(quota.DownloadBudget, 10))
+	err = c.post("/renter/tenants", values.Encode(), nil)
+	return
+}
+
+// RenterTenantsDeletePost uses the /renter/tenants/delete endpoint to delete
+// a tenant.
+func (c *Client) RenterTenantsDeletePost(name string) (err error) {
+	values := url.Values{}
+	values.Set("name", name)
+	err = c.post("/renter/tenants/delete", values.Encode(), nil)
+	return
+}
+
+// RenterTransfersGet requests the /renter/transfers resource, filtering the
+// transfer history with the given filter.
+func (c *Client) RenterTransfersGet(filter modules.RenterTransferFilter) (rtg api.RenterTransfersGET, err error) {
+	values := url.Values{}
+	if filter.Type != "" {
+		values.Set("type", filter.Type)
+	}
+	if filter.SiaPath != "" {
+		values.Set("siapath", filter.SiaPath)
+	}
+	if len(filter.Host.Key) != 0 {
+		values.Set("host", filter.Host.String())
+	}
+	if !filter.Start.IsZero() {
+		values.Set("start", strconv.FormatInt(filter.Start.Unix(), 10))
+	}
+	if !filter.End.IsZero() {
+		values.Set("end", strconv.FormatInt(filter.End.Unix(), 10))
+	}
+	if filter.FailedOnly {
+		values.Set("failed", "true")
+	}
+	err = c.get("/renter/transfers?"+values.Encode(), &rtg)
+	return
+}
+
+// RenterWorkersGet requests the /renter/workers endpoint's resources.
+func (c *Client) RenterWorkersGet() (rwg api.RenterWorkersGET, err error) {
+	err = c.get("/renter/workers", &rwg)
+	return
+}
+
+// RenterPostBenchmarkHosts uses the /renter endpoint to enable or disable the
+// benchmarking of the renter's hosts.
+func (c *Client) RenterPostBenchmarkHosts(enabled bool) (err error) {
+	values := url.Values{}
+	values.Set("benchmarkhosts", strconv.FormatBool(enabled))
+	err = c.post("/renter", values.Encode(), nil)
+	return
+}
+
 // RenterPostRateLimit uses the /renter endpoint to change the renter's bandwidth rate
 // limit.
 func (c *Client) RenterPostRateLimit(readBPS, writeBPS int64) (err error) {
@@ -163,6 +354,17 @@ func (c *Client) RenterPostRateLimit(readBPS, writeBPS int64) (err error) {
 	return
 }
 
+// RenterReencodePost uses the /renter/reencode endpoint to re-encode a file
+// with new erasure coding parameters.
+func (c *Client) RenterReencodePost(siaPath string, dataPieces, parityPieces uint64) (err error) {
+	siaPath = strings.TrimPrefix(siaPath, "/")
+	values := url.Values{}
+	values.Set("datapieces", strconv.FormatUint(dataPieces, 10))
+	values.Set("paritypieces", strconv.FormatUint(parityPieces, 10))
+	err = c.post(fmt.Sprintf("/renter/reencode/%v", siaPath), values.Encode(), nil)
+	return
+}
+
 // RenterRenamePost uses the /renter/rename/:siapath endpoint to rename a file.
 func (c *Client) RenterRenamePost(siaPathOld, siaPathNew string) (err error) {
 	siaPathOld = strings.TrimPrefix(siaPathOld, "/")
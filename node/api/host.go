@@ -4,9 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/NebulousLabs/Sia/build"
 	"github.com/NebulousLabs/Sia/modules"
+	"github.com/NebulousLabs/Sia/modules/host"
 	"github.com/NebulousLabs/Sia/types"
 
 	"github.com/julienschmidt/httprouter"
@@ -39,6 +42,14 @@ type (
 		NetworkMetrics       modules.HostNetworkMetrics       `json:"networkmetrics"`
 		ConnectabilityStatus modules.HostConnectabilityStatus `json:"connectabilitystatus"`
 		WorkingStatus        modules.HostWorkingStatus        `json:"workingstatus"`
+
+		PriceAdjustments []modules.HostPriceAdjustment `json:"priceadjustments"`
+	}
+
+	// HostContractGET contains the details of a storage obligation that are
+	// returned after a GET request to /host/contracts/:id.
+	HostContractGET struct {
+		Contract modules.StorageObligationDetails `json:"contract"`
 	}
 
 	// HostEstimateScoreGET contains the information that is returned from a
@@ -48,11 +59,42 @@ type (
 		ConversionRate float64        `json:"conversionrate"`
 	}
 
+	// HostBandwidthGET contains the RPC traffic of the host that is returned
+	// after a GET request to /host/bandwidth.
+	HostBandwidthGET struct {
+		modules.HostBandwidthMetrics
+	}
+
+	// HostLedgerGET contains the financial events of the host that are
+	// returned after a GET request to /host/ledger.
+	HostLedgerGET struct {
+		Entries []modules.HostLedgerEntry `json:"entries"`
+	}
+
+	// HostMaintenanceGET contains the maintenance window of the host that is
+	// returned after a GET request to /host/maintenance.
+	HostMaintenanceGET struct {
+		modules.HostMaintenance
+	}
+
+	// HostPolicyGET contains the host's policy toward renters that is
+	// returned after a GET request to /host/policy.
+	HostPolicyGET struct {
+		modules.HostPolicy
+	}
+
+	// HostProofsGET contains the storage proofs that the host has yet to get
+	// confirmed, returned after a GET request to /host/proofs.
+	HostProofsGET struct {
+		Proofs []modules.HostStorageProof `json:"proofs"`
+	}
+
 	// StorageGET contains the information that is returned after a GET request
 	// to /host/storage - a bunch of information about the status of storage
 	// management on the host.
 	StorageGET struct {
-		Folders []modules.StorageFolderMetadata `json:"folders"`
+		Folders     []modules.StorageFolderMetadata `json:"folders"`
+		SectorCache modules.SectorCacheMetrics      `json:"sectorcache"`
 	}
 )
 
@@ -76,6 +118,25 @@ func (api *API) hostContractInfoHandler(w http.ResponseWriter, req *http.Request
 	WriteJSON(w, cg)
 }
 
+// hostContractHandlerGET handles GET requests to /host/contracts/:id,
+// returning the details of a storage obligation.
+func (api *API) hostContractHandlerGET(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	h, err := scanHash(ps.ByName("id"))
+	if err != nil {
+		WriteError(w, Error{"unable to parse contract id: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	details, err := api.host.StorageObligationDetails(types.FileContractID(h))
+	if err == host.ErrNoStorageObligation {
+		WriteError(w, Error{"unable to get contract: " + err.Error()}, http.StatusNotFound)
+		return
+	} else if err != nil {
+		WriteError(w, Error{"unable to get contract: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostContractGET{Contract: details})
+}
+
 // hostHandlerGET handles GET requests to the /host API endpoint, returning key
 // information about the host.
 func (api *API) hostHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -85,6 +146,7 @@ func (api *API) hostHandlerGET(w http.ResponseWriter, req *http.Request, _ httpr
 	nm := api.host.NetworkMetrics()
 	cs := api.host.ConnectabilityStatus()
 	ws := api.host.WorkingStatus()
+	pa := api.host.PriceAdjustments()
 	hg := HostGET{
 		ExternalSettings:     es,
 		FinancialMetrics:     fm,
@@ -92,6 +154,8 @@ func (api *API) hostHandlerGET(w http.ResponseWriter, req *http.Request, _ httpr
 		NetworkMetrics:       nm,
 		ConnectabilityStatus: cs,
 		WorkingStatus:        ws,
+
+		PriceAdjustments: pa,
 	}
 	WriteJSON(w, hg)
 }
@@ -209,9 +273,296 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		settings.MinUploadBandwidthPrice = x
 	}
 
+	if req.FormValue("pricingautopilot") != "" {
+		var x bool
+		_, err := fmt.Sscan(req.FormValue("pricingautopilot"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.PricingAutopilot = x
+	}
+	if req.FormValue("pricingtargetpercentile") != "" {
+		var x float64
+		_, err := fmt.Sscan(req.FormValue("pricingtargetpercentile"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.PricingTargetPercentile = x
+	}
+	if req.FormValue("contractpriceceiling") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("contractpriceceiling"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.ContractPriceCeiling = x
+	}
+	if req.FormValue("contractpricefloor") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("contractpricefloor"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.ContractPriceFloor = x
+	}
+	if req.FormValue("downloadbandwidthpriceceiling") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("downloadbandwidthpriceceiling"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.DownloadBandwidthPriceCeiling = x
+	}
+	if req.FormValue("downloadbandwidthpricefloor") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("downloadbandwidthpricefloor"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.DownloadBandwidthPriceFloor = x
+	}
+	if req.FormValue("storagepriceceiling") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("storagepriceceiling"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.StoragePriceCeiling = x
+	}
+	if req.FormValue("storagepricefloor") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("storagepricefloor"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.StoragePriceFloor = x
+	}
+	if req.FormValue("uploadbandwidthpriceceiling") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("uploadbandwidthpriceceiling"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.UploadBandwidthPriceCeiling = x
+	}
+	if req.FormValue("uploadbandwidthpricefloor") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("uploadbandwidthpricefloor"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.UploadBandwidthPriceFloor = x
+	}
+
+	if req.FormValue("maxdownloadspeed") != "" {
+		var x int64
+		_, err := fmt.Sscan(req.FormValue("maxdownloadspeed"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxDownloadSpeed = x
+	}
+	if req.FormValue("maxuploadspeed") != "" {
+		var x int64
+		_, err := fmt.Sscan(req.FormValue("maxuploadspeed"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxUploadSpeed = x
+	}
+	if req.FormValue("maxrenterdownloadspeed") != "" {
+		var x int64
+		_, err := fmt.Sscan(req.FormValue("maxrenterdownloadspeed"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxRenterDownloadSpeed = x
+	}
+	if req.FormValue("maxrenteruploadspeed") != "" {
+		var x int64
+		_, err := fmt.Sscan(req.FormValue("maxrenteruploadspeed"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxRenterUploadSpeed = x
+	}
+	if req.FormValue("sectorcachesize") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("sectorcachesize"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.SectorCacheSize = x
+	}
+
 	return settings, nil
 }
 
+// hostBandwidthHandlerGET handles GET requests to /host/bandwidth, returning
+// the RPC traffic of the host.
+func (api *API) hostBandwidthHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, HostBandwidthGET{api.host.BandwidthMetrics()})
+}
+
+// hostLedgerHandlerGET handles GET requests to /host/ledger, returning the
+// financial events of the host within a time range.
+func (api *API) hostLedgerHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	start := time.Unix(0, 0)
+	end := time.Now()
+	for param, t := range map[string]*time.Time{"start": &start, "end": &end} {
+		if str := req.FormValue(param); str != "" {
+			unix, err := strconv.ParseInt(str, 10, 64)
+			if err != nil {
+				WriteError(w, Error{"parsing integer value for parameter `" + param + "` failed: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+			*t = time.Unix(unix, 0)
+		}
+	}
+	entries, err := api.host.Ledger(start, end)
+	if err != nil {
+		WriteError(w, Error{"unable to get ledger: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []modules.HostLedgerEntry{}
+	}
+	WriteJSON(w, HostLedgerGET{Entries: entries})
+}
+
+// hostMaintenanceHandlerGET handles GET requests to /host/maintenance,
+// returning the maintenance window of the host.
+func (api *API) hostMaintenanceHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, HostMaintenanceGET{api.host.Maintenance()})
+}
+
+// hostMaintenanceHandlerPOST handles POST requests to /host/maintenance,
+// scheduling the maintenance of the host.
+func (api *API) hostMaintenanceHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var start, end time.Time
+	for param, t := range map[string]*time.Time{"start": &start, "end": &end} {
+		if str := req.FormValue(param); str != "" {
+			unix, err := strconv.ParseInt(str, 10, 64)
+			if err != nil {
+				WriteError(w, Error{"parsing integer value for parameter `" + param + "` failed: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+			*t = time.Unix(unix, 0)
+		}
+	}
+	err := api.host.ScheduleMaintenance(start, end)
+	if err != nil {
+		WriteError(w, Error{"unable to schedule maintenance: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostMaintenanceCancelHandler handles POST requests to
+// /host/maintenance/cancel, ending the maintenance of the host.
+func (api *API) hostMaintenanceCancelHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	err := api.host.CancelMaintenance()
+	if err != nil {
+		WriteError(w, Error{"unable to cancel maintenance: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostPolicyHandlerGET handles GET requests to /host/policy, returning the
+// host's policy toward renters.
+func (api *API) hostPolicyHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, HostPolicyGET{api.host.Policy()})
+}
+
+// hostPolicyHandlerPOST handles POST requests to /host/policy, changing the
+// host's policy toward renters. Unspecified parameters are left unchanged.
+func (api *API) hostPolicyHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	policy := api.host.Policy()
+
+	if req.FormValue("maxcontractcollateral") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("maxcontractcollateral"), &x)
+		if err != nil {
+			WriteError(w, Error{"unable to parse maxcontractcollateral: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		policy.MaxContractCollateral = x
+	}
+	if req.FormValue("maxcontractsize") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxcontractsize"), &x)
+		if err != nil {
+			WriteError(w, Error{"unable to parse maxcontractsize: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		policy.MaxContractSize = x
+	}
+	if req.FormValue("maxrentercontracts") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxrentercontracts"), &x)
+		if err != nil {
+			WriteError(w, Error{"unable to parse maxrentercontracts: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		policy.MaxRenterContracts = x
+	}
+	if req.FormValue("maxrenterdata") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxrenterdata"), &x)
+		if err != nil {
+			WriteError(w, Error{"unable to parse maxrenterdata: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		policy.MaxRenterData = x
+	}
+
+	// Renters are added to and removed from the blocklist one at a time.
+	if req.FormValue("blockrenter") != "" {
+		var renter types.SiaPublicKey
+		renter.LoadString(req.FormValue("blockrenter"))
+		if len(renter.Key) == 0 {
+			WriteError(w, Error{"unable to parse blockrenter, expected a public key like ed25519:<hex>"}, http.StatusBadRequest)
+			return
+		}
+		blocked := false
+		for _, pk := range policy.BlockedRenters {
+			blocked = blocked || pk.String() == renter.String()
+		}
+		if !blocked {
+			policy.BlockedRenters = append(policy.BlockedRenters, renter)
+		}
+	}
+	if req.FormValue("unblockrenter") != "" {
+		var renter types.SiaPublicKey
+		renter.LoadString(req.FormValue("unblockrenter"))
+		var blockedRenters []types.SiaPublicKey
+		for _, pk := range policy.BlockedRenters {
+			if pk.String() != renter.String() {
+				blockedRenters = append(blockedRenters, pk)
+			}
+		}
+		if len(blockedRenters) == len(policy.BlockedRenters) {
+			WriteError(w, Error{"renter is not blocked"}, http.StatusBadRequest)
+			return
+		}
+		policy.BlockedRenters = blockedRenters
+	}
+
+	err := api.host.SetPolicy(policy)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostProofsHandlerGET handles GET requests to /host/proofs, returning the
+// storage proofs that the host has yet to get confirmed.
+func (api *API) hostProofsHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, HostProofsGET{Proofs: api.host.StorageProofs()})
+}
+
 // hostEstimateScoreGET handles the POST request to /host/estimatescore and
 // computes an estimated HostDB score for the provided settings.
 func (api *API) hostEstimateScoreGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -299,7 +650,8 @@ func (api *API) hostAnnounceHandler(w http.ResponseWriter, req *http.Request, _
 // the host.
 func (api *API) storageHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	WriteJSON(w, StorageGET{
-		Folders: api.host.StorageFolders(),
+		Folders:     api.host.StorageFolders(),
+		SectorCache: api.host.SectorCacheMetrics(),
 	})
 }
 
@@ -349,6 +701,35 @@ func (api *API) storageFoldersResizeHandler(w http.ResponseWriter, req *http.Req
 	WriteSuccess(w)
 }
 
+// storageFoldersMoveHandler moves a storage folder in the storage manager to a
+// new path.
+func (api *API) storageFoldersMoveHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	folderPath := req.FormValue("path")
+	if folderPath == "" {
+		WriteError(w, Error{"path parameter is required"}, http.StatusBadRequest)
+		return
+	}
+	newPath := req.FormValue("newpath")
+	if newPath == "" {
+		WriteError(w, Error{"newpath parameter is required"}, http.StatusBadRequest)
+		return
+	}
+
+	storageFolders := api.host.StorageFolders()
+	folderIndex, err := folderIndex(folderPath, storageFolders)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	err = api.host.MoveStorageFolder(uint16(folderIndex), newPath)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // storageFoldersRemoveHandler removes a storage folder from the storage
 // manager.
 func (api *API) storageFoldersRemoveHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
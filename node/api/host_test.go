@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -1119,3 +1120,26 @@ func TestDeleteNonexistentSector(t *testing.T) {
 		t.Fatalf("expected error to be %v; got %v", crypto.ErrHashWrongLen, err)
 	}
 }
+
+// TestHostContractNotFound checks that /host/contracts/:id returns a 404 for
+// storage obligations that the host doesn't have.
+func TestHostContractNotFound(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+
+	resp, err := HttpGET("http://" + st.server.listener.Addr().String() + "/host/contracts/" + types.FileContractID{}.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatal("expected 404 for an unknown storage obligation, got", resp.StatusCode, decodeError(resp))
+	}
+}
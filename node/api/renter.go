@@ -1,9 +1,11 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -17,6 +19,14 @@ import (
 )
 
 var (
+	// errTenantDestination is returned when a tenant tries to download a
+	// file to a path on the renter's disk.
+	errTenantDestination = errors.New("tenants can only download files with httpresp")
+
+	// errTenantSource is returned when a tenant tries to upload a file from
+	// outside the tenant's upload directory.
+	errTenantSource = errors.New("source must be a relative path within the tenant's upload directory")
+
 	// recommendedHosts is the number of hosts that the renter will form
 	// contracts with if the value is not specified explicitly in the call to
 	// SetSettings.
@@ -66,9 +76,15 @@ var (
 type (
 	// RenterGET contains various renter metrics.
 	RenterGET struct {
-		Settings         modules.RenterSettings     `json:"settings"`
-		FinancialMetrics modules.ContractorSpending `json:"financialmetrics"`
-		CurrentPeriod    types.BlockHeight          `json:"currentperiod"`
+		Settings          modules.RenterSettings          `json:"settings"`
+		FinancialMetrics  modules.ContractorSpending      `json:"financialmetrics"`
+		CurrentPeriod     types.BlockHeight               `json:"currentperiod"`
+		GarbageCollection modules.RenterGarbageCollection `json:"garbagecollection"`
+	}
+
+	// RenterAlertsGET lists the alerts of the renter and its contractor.
+	RenterAlertsGET struct {
+		Alerts []modules.Alert `json:"alerts"`
 	}
 
 	// RenterContract represents a contract formed by the renter.
@@ -113,6 +129,12 @@ type (
 		GoodForRenew bool `json:"goodforrenew"`
 	}
 
+	// RenterContractFormPOST contains the id of a contract that was formed
+	// by a call to /renter/contracts/form.
+	RenterContractFormPOST struct {
+		ID types.FileContractID `json:"id"`
+	}
+
 	// RenterContracts contains the renter's contracts.
 	RenterContracts struct {
 		Contracts         []RenterContract `json:"contracts"`
@@ -147,6 +169,35 @@ type (
 		modules.RenterPriceEstimation
 	}
 
+	// RenterQuotePOST is the estimated cost of a job that is returned when a
+	// POST call is made to /renter/quote.
+	RenterQuotePOST struct {
+		modules.RenterQuote
+	}
+
+	// RenterSpendingGET lists the money that was paid to each host, through
+	// each contract and in each allowance period within a time range.
+	RenterSpendingGET struct {
+		Contracts []modules.RenterContractSpending `json:"contracts"`
+		Hosts     []modules.RenterHostSpending     `json:"hosts"`
+		Periods   []modules.RenterPeriodSpending   `json:"periods"`
+	}
+
+	// RenterTenantsGET lists the tenants of the renter.
+	RenterTenantsGET struct {
+		Tenants []modules.RenterTenant `json:"tenants"`
+	}
+
+	// RenterTransfersGET lists the records of the renter's transfer history.
+	RenterTransfersGET struct {
+		Transfers []modules.RenterTransfer `json:"transfers"`
+	}
+
+	// RenterWorkersGET lists the status of the renter's workers.
+	RenterWorkersGET struct {
+		Workers []modules.RenterWorkerStatus `json:"workers"`
+	}
+
 	// RenterShareASCII contains an ASCII-encoded .sia file.
 	RenterShareASCII struct {
 		ASCIIsia string `json:"asciisia"`
@@ -169,16 +220,76 @@ type (
 		StartTimeUnix        int64     `json:"starttimeunix"`        // The time when the download was started in unix format.
 		TotalDataTransferred uint64    `json:"totaldatatransferred"` // The total amount of data transferred, including negotiation, overdrive etc.
 	}
+
+	// tenantContextKey is the context key of the name of the renter tenant
+	// that made a request.
+	tenantContextKey struct{}
 )
 
+// requestTenant returns the name of the renter tenant that made a request, or
+// an empty string if the request was not made by a tenant.
+func requestTenant(req *http.Request) string {
+	name, _ := req.Context().Value(tenantContextKey{}).(string)
+	return name
+}
+
+// renterSiaPath maps a siapath supplied in a request to the siapath used by
+// the renter. The siapaths of tenants are relative to their root.
+func renterSiaPath(req *http.Request, siaPath string) (string, error) {
+	name := requestTenant(req)
+	if name == "" {
+		return siaPath, nil
+	}
+	if siaPath == "" {
+		return "", renter.ErrEmptyFilename
+	}
+	return modules.RenterTenantRoot(name) + siaPath, nil
+}
+
+// requestSiaPath maps a siapath used by the renter to the siapath seen by the
+// maker of a request. It returns false if the request was made by a tenant
+// that does not own the file.
+func requestSiaPath(req *http.Request, siaPath string) (string, bool) {
+	name := requestTenant(req)
+	if name == "" {
+		return siaPath, true
+	}
+	if !strings.HasPrefix(siaPath, modules.RenterTenantRoot(name)) {
+		return "", false
+	}
+	return strings.TrimPrefix(siaPath, modules.RenterTenantRoot(name)), true
+}
+
+// tenantSourcePath maps the source of an upload by a tenant to a path within
+// the tenant's upload directory. Absolute paths, and paths that leave the
+// directory either directly or through a symlink, are rejected.
+func tenantSourcePath(dir, source string) (string, error) {
+	if source == "" || filepath.IsAbs(source) {
+		return "", errTenantSource
+	}
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", build.ExtendErr("unable to open the tenant's upload directory", err)
+	}
+	path, err := filepath.EvalSymlinks(filepath.Join(dir, source))
+	if err != nil {
+		return "", errTenantSource
+	}
+	if !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+		return "", errTenantSource
+	}
+	return path, nil
+}
+
 // renterHandlerGET handles the API call to /renter.
 func (api *API) renterHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	settings := api.renter.Settings()
 	periodStart := api.renter.CurrentPeriod()
 	WriteJSON(w, RenterGET{
-		Settings:         settings,
-		FinancialMetrics: api.renter.PeriodSpending(),
-		CurrentPeriod:    periodStart,
+		Settings:          settings,
+		FinancialMetrics:  api.renter.PeriodSpending(),
+		CurrentPeriod:     periodStart,
+		GarbageCollection: api.renter.GarbageCollection(),
 	})
 }
 
@@ -239,6 +350,80 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 		// Sane defaults if renew window hasn't been set before.
 		settings.Allowance.RenewWindow = settings.Allowance.Period / 2
 	}
+	// Scan the price limits. (optional parameters)
+	if mcp := req.FormValue("maxcontractprice"); mcp != "" {
+		maxContractPrice, ok := scanAmount(mcp)
+		if !ok {
+			WriteError(w, Error{"unable to parse maxcontractprice"}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.MaxContractPrice = maxContractPrice
+	}
+	if mdbp := req.FormValue("maxdownloadbandwidthprice"); mdbp != "" {
+		maxDownloadBandwidthPrice, ok := scanAmount(mdbp)
+		if !ok {
+			WriteError(w, Error{"unable to parse maxdownloadbandwidthprice"}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.MaxDownloadBandwidthPrice = maxDownloadBandwidthPrice
+	}
+	if msp := req.FormValue("maxstorageprice"); msp != "" {
+		maxStoragePrice, ok := scanAmount(msp)
+		if !ok {
+			WriteError(w, Error{"unable to parse maxstorageprice"}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.MaxStoragePrice = maxStoragePrice
+	}
+	if mubp := req.FormValue("maxuploadbandwidthprice"); mubp != "" {
+		maxUploadBandwidthPrice, ok := scanAmount(mubp)
+		if !ok {
+			WriteError(w, Error{"unable to parse maxuploadbandwidthprice"}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.MaxUploadBandwidthPrice = maxUploadBandwidthPrice
+	}
+	if mc := req.FormValue("mincollateral"); mc != "" {
+		minCollateral, ok := scanAmount(mc)
+		if !ok {
+			WriteError(w, Error{"unable to parse mincollateral"}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.MinCollateral = minCollateral
+	}
+	// Scan the expected usage. (optional parameters)
+	if es := req.FormValue("expectedstorage"); es != "" {
+		var expectedStorage uint64
+		if _, err := fmt.Sscan(es, &expectedStorage); err != nil {
+			WriteError(w, Error{"unable to parse expectedstorage: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.ExpectedStorage = expectedStorage
+	}
+	if eu := req.FormValue("expectedupload"); eu != "" {
+		var expectedUpload uint64
+		if _, err := fmt.Sscan(eu, &expectedUpload); err != nil {
+			WriteError(w, Error{"unable to parse expectedupload: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.ExpectedUpload = expectedUpload
+	}
+	if ed := req.FormValue("expecteddownload"); ed != "" {
+		var expectedDownload uint64
+		if _, err := fmt.Sscan(ed, &expectedDownload); err != nil {
+			WriteError(w, Error{"unable to parse expecteddownload: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.ExpectedDownload = expectedDownload
+	}
+	if er := req.FormValue("expectedredundancy"); er != "" {
+		var expectedRedundancy float64
+		if _, err := fmt.Sscan(er, &expectedRedundancy); err != nil {
+			WriteError(w, Error{"unable to parse expectedredundancy: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.ExpectedRedundancy = expectedRedundancy
+	}
 	// Scan the download speed limit. (optional parameter)
 	if d := req.FormValue("maxdownloadspeed"); d != "" {
 		var downloadSpeed int64
@@ -266,6 +451,19 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 		}
 		settings.StreamCacheSize = streamCacheSize
 	}
+	// Scan whether hosts should be benchmarked. (optional parameter)
+	if bh := req.FormValue("benchmarkhosts"); bh != "" {
+		benchmarkHosts, err := strconv.ParseBool(bh)
+		if err != nil {
+			WriteError(w, Error{"unable to parse benchmarkhosts: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		settings.BenchmarkHosts = benchmarkHosts
+	}
+	// Scan the tenant upload directory. (optional parameter)
+	if _, ok := req.Form["tenantuploaddir"]; ok {
+		settings.TenantUploadDir = req.FormValue("tenantuploaddir")
+	}
 	// Set the settings in the renter.
 	err := api.renter.SetSettings(settings)
 	if err != nil {
@@ -275,6 +473,14 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 	WriteSuccess(w)
 }
 
+// renterAlertsHandler handles the API call to list the alerts of the renter
+// and its contractor.
+func (api *API) renterAlertsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, RenterAlertsGET{
+		Alerts: api.renter.Alerts(),
+	})
+}
+
 // renterContractsHandler handles the API call to request the Renter's
 // contracts.
 //
@@ -408,6 +614,99 @@ func (api *API) renterContractsHandler(w http.ResponseWriter, req *http.Request,
 	})
 }
 
+// scanContractID parses the id of a contract from the `id` parameter of a
+// request.
+func scanContractID(req *http.Request) (types.FileContractID, error) {
+	h, err := scanHash(req.FormValue("id"))
+	if err != nil {
+		return types.FileContractID{}, errors.New("unable to parse contract id: " + err.Error())
+	}
+	return types.FileContractID(h), nil
+}
+
+// renterContractCancelHandler handles the API call to cancel a contract.
+func (api *API) renterContractCancelHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	id, err := scanContractID(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.renter.CancelContract(id); err != nil {
+		WriteError(w, Error{"unable to cancel contract: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterContractFormHandler handles the API call to form a contract with a
+// specific host.
+func (api *API) renterContractFormHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var hostKey types.SiaPublicKey
+	hostKey.LoadString(req.FormValue("host"))
+	if len(hostKey.Key) == 0 {
+		WriteError(w, Error{"unable to parse host public key"}, http.StatusBadRequest)
+		return
+	}
+	funds, ok := scanAmount(req.FormValue("funds"))
+	if !ok || funds.IsZero() {
+		WriteError(w, Error{"unable to parse funds"}, http.StatusBadRequest)
+		return
+	}
+	var endHeight types.BlockHeight
+	if e := req.FormValue("endheight"); e != "" {
+		_, err := fmt.Sscan(e, &endHeight)
+		if err != nil {
+			WriteError(w, Error{"unable to parse endheight: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	contract, err := api.renter.FormContract(hostKey, funds, endHeight)
+	if err != nil {
+		WriteError(w, Error{"unable to form contract: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, RenterContractFormPOST{ID: contract.ID})
+}
+
+// renterContractRenewHandler handles the API call to renew a contract right
+// away.
+func (api *API) renterContractRenewHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	id, err := scanContractID(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var funds types.Currency
+	if f := req.FormValue("funds"); f != "" {
+		var ok bool
+		funds, ok = scanAmount(f)
+		if !ok {
+			WriteError(w, Error{"unable to parse funds"}, http.StatusBadRequest)
+			return
+		}
+	}
+	if err := api.renter.RenewContract(id, funds); err != nil {
+		WriteError(w, Error{"unable to renew contract: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterContractStopRenewingHandler handles the API call to stop renewing a
+// contract.
+func (api *API) renterContractStopRenewingHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	id, err := scanContractID(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.renter.StopRenewingContract(id); err != nil {
+		WriteError(w, Error{"unable to stop renewing contract: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // renterClearDownloadsHandler handles the API call to request to clear the download queue.
 func (api *API) renterClearDownloadsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	var afterTime time.Time
@@ -439,16 +738,20 @@ func (api *API) renterClearDownloadsHandler(w http.ResponseWriter, req *http.Req
 }
 
 // renterDownloadsHandler handles the API call to request the download queue.
-func (api *API) renterDownloadsHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+func (api *API) renterDownloadsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	var downloads []DownloadInfo
 	for _, di := range api.renter.DownloadHistory() {
+		siaPath, ok := requestSiaPath(req, di.SiaPath)
+		if !ok {
+			continue
+		}
 		downloads = append(downloads, DownloadInfo{
 			Destination:     di.Destination,
 			DestinationType: di.DestinationType,
 			Filesize:        di.Length,
 			Length:          di.Length,
 			Offset:          di.Offset,
-			SiaPath:         di.SiaPath,
+			SiaPath:         siaPath,
 
 			Completed:            di.Completed,
 			EndTime:              di.EndTime,
@@ -496,7 +799,17 @@ func (api *API) renterLoadASCIIHandler(w http.ResponseWriter, req *http.Request,
 // renterRenameHandler handles the API call to rename a file entry in the
 // renter.
 func (api *API) renterRenameHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
-	err := api.renter.RenameFile(strings.TrimPrefix(ps.ByName("siapath"), "/"), req.FormValue("newsiapath"))
+	siaPath, err := renterSiaPath(req, strings.TrimPrefix(ps.ByName("siapath"), "/"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	newSiaPath, err := renterSiaPath(req, req.FormValue("newsiapath"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	err = api.renter.RenameFile(siaPath, newSiaPath)
 	if err != nil {
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
@@ -507,11 +820,17 @@ func (api *API) renterRenameHandler(w http.ResponseWriter, req *http.Request, ps
 
 // renterFileHandler handles the API call to return specific file.
 func (api *API) renterFileHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
-	file, err := api.renter.File(strings.TrimPrefix(ps.ByName("siapath"), "/"))
+	siaPath, err := renterSiaPath(req, strings.TrimPrefix(ps.ByName("siapath"), "/"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	file, err := api.renter.File(siaPath)
 	if err != nil {
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
 	}
+	file.SiaPath, _ = requestSiaPath(req, file.SiaPath)
 	WriteJSON(w, RenterFile{
 		File: file,
 	})
@@ -519,8 +838,21 @@ func (api *API) renterFileHandler(w http.ResponseWriter, req *http.Request, ps h
 
 // renterFilesHandler handles the API call to list all of the files.
 func (api *API) renterFilesHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	files := api.renter.FileList()
+	if requestTenant(req) != "" {
+		var tenantFiles []modules.FileInfo
+		for _, file := range files {
+			siaPath, ok := requestSiaPath(req, file.SiaPath)
+			if !ok {
+				continue
+			}
+			file.SiaPath = siaPath
+			tenantFiles = append(tenantFiles, file)
+		}
+		files = tenantFiles
+	}
 	WriteJSON(w, RenterFiles{
-		Files: api.renter.FileList(),
+		Files: files,
 	})
 }
 
@@ -532,10 +864,250 @@ func (api *API) renterPricesHandler(w http.ResponseWriter, req *http.Request, _
 	})
 }
 
+// renterQuoteHandler estimates the cost of uploading, storing and downloading
+// data using the prices of the renter's contracts and the hostdb.
+func (api *API) renterQuoteHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params modules.RenterQuoteParams
+	_, err := fmt.Sscan(req.FormValue("size"), &params.Size)
+	if err != nil {
+		WriteError(w, Error{"unable to parse size: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	_, err = fmt.Sscan(req.FormValue("duration"), &params.Duration)
+	if err != nil {
+		WriteError(w, Error{"unable to parse duration: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if d := req.FormValue("expecteddownload"); d != "" {
+		_, err = fmt.Sscan(d, &params.ExpectedDownload)
+		if err != nil {
+			WriteError(w, Error{"unable to parse expecteddownload: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	params.ErasureCode, err = parseErasureCodingParameters(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	quote, err := api.renter.Quote(params)
+	if err != nil {
+		WriteError(w, Error{"unable to quote job: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, RenterQuotePOST{quote})
+}
+
+// renterSpendingHandler reports the money that was paid to each host, through
+// each contract and in each allowance period within a time range, broken down
+// into storage, upload, download and fees.
+func (api *API) renterSpendingHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var host types.SiaPublicKey
+	if h := req.FormValue("host"); h != "" {
+		host.LoadString(h)
+		if len(host.Key) == 0 {
+			WriteError(w, Error{"unable to parse host public key"}, http.StatusBadRequest)
+			return
+		}
+	}
+	start := time.Unix(0, 0)
+	end := time.Now()
+	if startStr := req.FormValue("start"); startStr != "" {
+		startInt, err := strconv.ParseInt(startStr, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"parsing integer value for parameter `start` failed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		start = time.Unix(startInt, 0)
+	}
+	if endStr := req.FormValue("end"); endStr != "" {
+		endInt, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"parsing integer value for parameter `end` failed: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		end = time.Unix(endInt, 0)
+	}
+
+	records, err := api.renter.SpendingHistory(host, start, end)
+	if err != nil {
+		WriteError(w, Error{"unable to get spending history: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+
+	// Sum up the records of each host, contract and period.
+	contracts := []modules.RenterContractSpending{}
+	hosts := []modules.RenterHostSpending{}
+	periods := []modules.RenterPeriodSpending{}
+	contractIndices := make(map[types.FileContractID]int)
+	hostIndices := make(map[string]int)
+	periodIndices := make(map[types.BlockHeight]int)
+	for _, r := range records {
+		i, exists := contractIndices[r.ContractID]
+		if !exists {
+			i = len(contracts)
+			contractIndices[r.ContractID] = i
+			contracts = append(contracts, modules.RenterContractSpending{
+				ContractID:    r.ContractID,
+				EndHeight:     r.EndHeight,
+				HostPublicKey: r.HostPublicKey,
+			})
+		}
+		contracts[i].Add(r)
+
+		i, exists = hostIndices[r.HostPublicKey.String()]
+		if !exists {
+			i = len(hosts)
+			hostIndices[r.HostPublicKey.String()] = i
+			hosts = append(hosts, modules.RenterHostSpending{HostPublicKey: r.HostPublicKey})
+		}
+		hosts[i].Add(r)
+
+		i, exists = periodIndices[r.EndHeight]
+		if !exists {
+			i = len(periods)
+			periodIndices[r.EndHeight] = i
+			periods = append(periods, modules.RenterPeriodSpending{EndHeight: r.EndHeight})
+		}
+		periods[i].Add(r)
+	}
+	sort.Slice(contracts, func(i, j int) bool {
+		return contracts[i].Total().Cmp(contracts[j].Total()) > 0
+	})
+	sort.Slice(hosts, func(i, j int) bool {
+		return hosts[i].Total().Cmp(hosts[j].Total()) > 0
+	})
+	sort.Slice(periods, func(i, j int) bool {
+		return periods[i].EndHeight < periods[j].EndHeight
+	})
+	WriteJSON(w, RenterSpendingGET{
+		Contracts: contracts,
+		Hosts:     hosts,
+		Periods:   periods,
+	})
+}
+
+// renterTenantsHandlerGET handles the API call to list the renter's tenants.
+func (api *API) renterTenantsHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, RenterTenantsGET{Tenants: api.renter.Tenants()})
+}
+
+// renterTenantsHandlerPOST handles the API call to create a tenant or update
+// the quota of a tenant. Quota parameters that are not supplied keep their
+// current value.
+func (api *API) renterTenantsHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	name := req.FormValue("name")
+	var quota modules.RenterTenantQuota
+	for _, t := range api.renter.Tenants() {
+		if t.Name == name {
+			quota = t.RenterTenantQuota
+		}
+	}
+	for param, value := range map[string]*uint64{
+		"storagequota":   &quota.StorageQuota,
+		"uploadbudget":   &quota.UploadBudget,
+		"downloadbudget": &quota.DownloadBudget,
+	} {
+		if req.FormValue(param) == "" {
+			continue
+		}
+		if _, err := fmt.Sscan(req.FormValue(param), value); err != nil {
+			WriteError(w, Error{"unable to parse " + param + ": " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if err := api.renter.SetTenant(name, req.FormValue("password"), quota); err != nil {
+		WriteError(w, Error{"unable to set tenant: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterTenantsDeleteHandler handles the API call to delete a tenant.
+func (api *API) renterTenantsDeleteHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if err := api.renter.DeleteTenant(req.FormValue("name")); err != nil {
+		WriteError(w, Error{"unable to delete tenant: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterTransfersHandler handles the API call to query the renter's transfer
+// history.
+func (api *API) renterTransfersHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var filter modules.RenterTransferFilter
+	filter.Type = req.FormValue("type")
+	switch filter.Type {
+	case "", modules.RenterTransferDownload, modules.RenterTransferRepair, modules.RenterTransferUpload:
+	default:
+		WriteError(w, Error{"unknown transfer type: " + filter.Type}, http.StatusBadRequest)
+		return
+	}
+	if siaPath := req.FormValue("siapath"); siaPath != "" {
+		var err error
+		filter.SiaPath, err = renterSiaPath(req, siaPath)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if h := req.FormValue("host"); h != "" {
+		filter.Host.LoadString(h)
+		if len(filter.Host.Key) == 0 {
+			WriteError(w, Error{"unable to parse host public key"}, http.StatusBadRequest)
+			return
+		}
+	}
+	for param, t := range map[string]*time.Time{"start": &filter.Start, "end": &filter.End} {
+		if str := req.FormValue(param); str != "" {
+			unix, err := strconv.ParseInt(str, 10, 64)
+			if err != nil {
+				WriteError(w, Error{"parsing integer value for parameter `" + param + "` failed: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+			*t = time.Unix(unix, 0)
+		}
+	}
+	var err error
+	filter.FailedOnly, err = scanBool(req.FormValue("failed"))
+	if err != nil {
+		WriteError(w, Error{"failed parameter could not be parsed: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	records, err := api.renter.TransferHistory(filter)
+	if err != nil {
+		WriteError(w, Error{"unable to get transfer history: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	transfers := []modules.RenterTransfer{}
+	for _, t := range records {
+		siaPath, ok := requestSiaPath(req, t.SiaPath)
+		if !ok {
+			continue
+		}
+		t.SiaPath = siaPath
+		transfers = append(transfers, t)
+	}
+	WriteJSON(w, RenterTransfersGET{Transfers: transfers})
+}
+
+// renterWorkersHandler handles the API call to request the status of the
+// renter's workers.
+func (api *API) renterWorkersHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, RenterWorkersGET{Workers: api.renter.WorkerPoolStatus()})
+}
+
 // renterDeleteHandler handles the API call to delete a file entry from the
 // renter.
 func (api *API) renterDeleteHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
-	err := api.renter.DeleteFile(strings.TrimPrefix(ps.ByName("siapath"), "/"))
+	siaPath, err := renterSiaPath(req, strings.TrimPrefix(ps.ByName("siapath"), "/"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	err = api.renter.DeleteFile(siaPath)
 	if err != nil {
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
@@ -620,14 +1192,45 @@ func parseDownloadParameters(w http.ResponseWriter, req *http.Request, ps httpro
 		return modules.RenterDownloadParameters{}, build.ExtendErr("async parameter could not be parsed", err)
 	}
 
-	siapath := strings.TrimPrefix(ps.ByName("siapath"), "/") // Sia file name.
+	// Parse the host selection parameters.
+	excludeHosts, err := scanHostKeys(req.FormValue("excludehosts"))
+	if err != nil {
+		return modules.RenterDownloadParameters{}, build.ExtendErr("excludehosts parameter could not be parsed", err)
+	}
+	preferHosts, err := scanHostKeys(req.FormValue("preferhosts"))
+	if err != nil {
+		return modules.RenterDownloadParameters{}, build.ExtendErr("preferhosts parameter could not be parsed", err)
+	}
+	var maxOverdrive int
+	if mo := req.FormValue("maxoverdrive"); mo != "" {
+		_, err := fmt.Sscan(mo, &maxOverdrive)
+		if err != nil || maxOverdrive < 0 {
+			return modules.RenterDownloadParameters{}, errors.New("maxoverdrive must be a non-negative integer")
+		}
+		if maxOverdrive == 0 {
+			maxOverdrive = modules.RenterNoOverdrive
+		}
+	}
+
+	// Tenants can't write to the renter's disk.
+	if requestTenant(req) != "" && (!httpresp || destination != "") {
+		return modules.RenterDownloadParameters{}, errTenantDestination
+	}
+
+	siapath, err := renterSiaPath(req, strings.TrimPrefix(ps.ByName("siapath"), "/")) // Sia file name.
+	if err != nil {
+		return modules.RenterDownloadParameters{}, err
+	}
 
 	dp := modules.RenterDownloadParameters{
-		Destination: destination,
-		Async:       async,
-		Length:      length,
-		Offset:      offset,
-		SiaPath:     siapath,
+		Destination:  destination,
+		Async:        async,
+		Length:       length,
+		Offset:       offset,
+		SiaPath:      siapath,
+		ExcludeHosts: excludeHosts,
+		PreferHosts:  preferHosts,
+		MaxOverdrive: maxOverdrive,
 	}
 	if httpresp {
 		dp.Httpwriter = w
@@ -636,6 +1239,23 @@ func parseDownloadParameters(w http.ResponseWriter, req *http.Request, ps httpro
 	return dp, nil
 }
 
+// scanHostKeys parses a comma separated list of host public keys.
+func scanHostKeys(s string) ([]types.SiaPublicKey, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var keys []types.SiaPublicKey
+	for _, str := range strings.Split(s, ",") {
+		var pk types.SiaPublicKey
+		pk.LoadString(strings.TrimSpace(str))
+		if len(pk.Key) == 0 {
+			return nil, fmt.Errorf("could not parse host public key %q", str)
+		}
+		keys = append(keys, pk)
+	}
+	return keys, nil
+}
+
 // renterShareHandler handles the API call to create a '.sia' file that
 // shares a set of file.
 func (api *API) renterShareHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
@@ -670,70 +1290,123 @@ func (api *API) renterShareASCIIHandler(w http.ResponseWriter, req *http.Request
 
 // renterStreamHandler handles downloads from the /renter/stream endpoint
 func (api *API) renterStreamHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
-	siaPath := strings.TrimPrefix(ps.ByName("siapath"), "/")
+	siaPath, err := renterSiaPath(req, strings.TrimPrefix(ps.ByName("siapath"), "/"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
 	fileName, streamer, err := api.renter.Streamer(siaPath)
 	if err != nil {
 		WriteError(w, Error{fmt.Sprintf("failed to create download streamer: %v", err)},
 			http.StatusInternalServerError)
 		return
 	}
+	defer streamer.Close()
 	http.ServeContent(w, req, fileName, time.Time{}, streamer)
 }
 
-// renterUploadHandler handles the API call to upload a file.
-func (api *API) renterUploadHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
-	source := req.FormValue("source")
-	if !filepath.IsAbs(source) {
-		WriteError(w, Error{"source must be an absolute path"}, http.StatusBadRequest)
-		return
+// parseErasureCodingParameters parses the datapieces and paritypieces
+// parameters of a request into an erasure coder. It returns nil if neither
+// parameter was supplied.
+func parseErasureCodingParameters(req *http.Request) (modules.ErasureCoder, error) {
+	if req.FormValue("datapieces") == "" && req.FormValue("paritypieces") == "" {
+		return nil, nil
+	}
+	// Check that both values have been supplied.
+	if req.FormValue("datapieces") == "" || req.FormValue("paritypieces") == "" {
+		return nil, errors.New("must provide both the datapieces parameter and the paritypieces parameter if specifying erasure coding parameters")
 	}
 
-	// Check whether the erasure coding parameters have been supplied.
-	var ec modules.ErasureCoder
-	if req.FormValue("datapieces") != "" || req.FormValue("paritypieces") != "" {
-		// Check that both values have been supplied.
-		if req.FormValue("datapieces") == "" || req.FormValue("paritypieces") == "" {
-			WriteError(w, Error{"must provide both the datapieces parameter and the paritypieces parameter if specifying erasure coding parameters"}, http.StatusBadRequest)
-			return
-		}
+	// Parse the erasure coding parameters.
+	var dataPieces, parityPieces int
+	_, err := fmt.Sscan(req.FormValue("datapieces"), &dataPieces)
+	if err != nil {
+		return nil, errors.New("unable to read parameter 'datapieces': " + err.Error())
+	}
+	_, err = fmt.Sscan(req.FormValue("paritypieces"), &parityPieces)
+	if err != nil {
+		return nil, errors.New("unable to read parameter 'paritypieces': " + err.Error())
+	}
 
-		// Parse the erasure coding parameters.
-		var dataPieces, parityPieces int
-		_, err := fmt.Sscan(req.FormValue("datapieces"), &dataPieces)
-		if err != nil {
-			WriteError(w, Error{"unable to read parameter 'datapieces': " + err.Error()}, http.StatusBadRequest)
-			return
+	// Verify that sane values for parityPieces and redundancy are being
+	// supplied.
+	if parityPieces < requiredParityPieces {
+		return nil, fmt.Errorf("a minimum of %v parity pieces is required, but %v parity pieces requested", parityPieces, requiredParityPieces)
+	}
+	redundancy := float64(dataPieces+parityPieces) / float64(dataPieces)
+	if float64(dataPieces+parityPieces)/float64(dataPieces) < requiredRedundancy {
+		return nil, fmt.Errorf("a redundancy of %.2f is required, but redundancy of %.2f supplied", redundancy, requiredRedundancy)
+	}
+
+	// Create the erasure coder.
+	ec, err := renter.NewRSCode(dataPieces, parityPieces)
+	if err != nil {
+		return nil, errors.New("unable to encode file using the provided parameters: " + err.Error())
+	}
+	return ec, nil
+}
+
+// renterReencodeHandler handles the API call to re-encode a file with new
+// erasure coding parameters.
+func (api *API) renterReencodeHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	ec, err := parseErasureCodingParameters(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	} else if ec == nil {
+		WriteError(w, Error{"must provide the datapieces and paritypieces parameters"}, http.StatusBadRequest)
+		return
+	}
+	siaPath, err := renterSiaPath(req, strings.TrimPrefix(ps.ByName("siapath"), "/"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	err = api.renter.Reencode(siaPath, ec)
+	if err != nil {
+		WriteError(w, Error{"re-encode failed: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterUploadHandler handles the API call to upload a file.
+func (api *API) renterUploadHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	// The sources of tenants are relative to their upload directory, so that
+	// tenants can't upload other files that the renter can read.
+	source := req.FormValue("source")
+	if name := requestTenant(req); name != "" {
+		var err error
+		dir, err := api.renter.TenantDir(name)
+		if err == nil {
+			source, err = tenantSourcePath(dir, source)
 		}
-		_, err = fmt.Sscan(req.FormValue("paritypieces"), &parityPieces)
 		if err != nil {
-			WriteError(w, Error{"unable to read parameter 'paritypieces': " + err.Error()}, http.StatusBadRequest)
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
 		}
+	} else if !filepath.IsAbs(source) {
+		WriteError(w, Error{"source must be an absolute path"}, http.StatusBadRequest)
+		return
+	}
 
-		// Verify that sane values for parityPieces and redundancy are being
-		// supplied.
-		if parityPieces < requiredParityPieces {
-			WriteError(w, Error{fmt.Sprintf("a minimum of %v parity pieces is required, but %v parity pieces requested", parityPieces, requiredParityPieces)}, http.StatusBadRequest)
-			return
-		}
-		redundancy := float64(dataPieces+parityPieces) / float64(dataPieces)
-		if float64(dataPieces+parityPieces)/float64(dataPieces) < requiredRedundancy {
-			WriteError(w, Error{fmt.Sprintf("a redundancy of %.2f is required, but redundancy of %.2f supplied", redundancy, requiredRedundancy)}, http.StatusBadRequest)
-			return
-		}
+	// Check whether the erasure coding parameters have been supplied.
+	ec, err := parseErasureCodingParameters(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
 
-		// Create the erasure coder.
-		ec, err = renter.NewRSCode(dataPieces, parityPieces)
-		if err != nil {
-			WriteError(w, Error{"unable to encode file using the provided parameters: " + err.Error()}, http.StatusBadRequest)
-			return
-		}
+	siaPath, err := renterSiaPath(req, strings.TrimPrefix(ps.ByName("siapath"), "/"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
 	}
 
 	// Call the renter to upload the file.
-	err := api.renter.Upload(modules.FileUploadParams{
+	err = api.renter.Upload(modules.FileUploadParams{
 		Source:      source,
-		SiaPath:     strings.TrimPrefix(ps.ByName("siapath"), "/"),
+		SiaPath:     siaPath,
 		ErasureCode: ec,
 	})
 	if err != nil {
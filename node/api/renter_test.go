@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -2112,3 +2113,146 @@ func TestAdversarialPriceRenewal(t *testing.T) {
 		time.Sleep(time.Millisecond * 100)
 	}
 }
+
+// TestRenterTenantPaths checks that tenants can't upload files from outside
+// their upload directory, and can't download files to the renter's disk.
+func TestRenterTenantPaths(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createAuthenticatedServerTester(t.Name(), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+	if err := st.renter.SetTenant("alice", "foo", modules.RenterTenantQuota{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The upload directories of the tenants can't be in the renter
+	// directory.
+	settings := st.renter.Settings()
+	settings.TenantUploadDir = filepath.Join(st.dir, modules.RenterDir, "tenants")
+	if err := st.renter.SetSettings(settings); err == nil {
+		t.Fatal("tenant upload directory was set within the renter directory")
+	}
+	settings.TenantUploadDir = filepath.Join(st.dir, "tenants")
+	if err := st.renter.SetSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	tenantDir, err := st.renter.TenantDir("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// tenantCall makes a request on behalf of the tenant and returns the
+	// error of the API.
+	tenantCall := func(method, call string, values url.Values) error {
+		req, err := http.NewRequest(method, "http://"+st.server.listener.Addr().String()+call+"?"+values.Encode(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("User-Agent", "Sia-Agent")
+		req.SetBasicAuth("alice", "foo")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if non2xx(resp.StatusCode) {
+			return decodeError(resp)
+		}
+		return nil
+	}
+
+	// A file outside of the tenant's upload directory, like the API password
+	// of the renter, can't be uploaded by the tenant.
+	secret := filepath.Join(st.dir, "apipassword")
+	if err := ioutil.WriteFile(secret, []byte("password"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(secret, filepath.Join(tenantDir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	rel, err := filepath.Rel(tenantDir, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, source := range []string{secret, rel, "link", ""} {
+		err := tenantCall("POST", "/renter/upload/secret", url.Values{"source": {source}})
+		if err == nil || err.Error() != errTenantSource.Error() {
+			t.Fatalf("tenant could upload %q: %v", source, err)
+		}
+	}
+
+	// Files within the upload directory pass the check.
+	if err := ioutil.WriteFile(filepath.Join(tenantDir, "file"), []byte("data"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	err = tenantCall("POST", "/renter/upload/file", url.Values{"source": {"file"}})
+	if err != nil && err.Error() == errTenantSource.Error() {
+		t.Fatal("tenant can't upload from its upload directory")
+	}
+
+	// Tenants can only download files over http.
+	for _, call := range []string{"/renter/download/file", "/renter/downloadasync/file"} {
+		err := tenantCall("GET", call, url.Values{"destination": {filepath.Join(st.dir, "overwritten")}})
+		if err == nil || err.Error() != errTenantDestination.Error() {
+			t.Fatalf("tenant could download to disk with %v: %v", call, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(st.dir, "overwritten")); !os.IsNotExist(err) {
+		t.Fatal("tenant wrote to the renter's disk:", err)
+	}
+}
+
+// TestRenterTenantAuthLockout checks that a tenant is locked out after too
+// many failed authentications.
+func TestRenterTenantAuthLockout(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createAuthenticatedServerTester(t.Name(), "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.server.panicClose()
+	if err := st.renter.SetTenant("alice", "foo", modules.RenterTenantQuota{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// tenantStatus makes a request with the given tenant password and
+	// returns the status code.
+	tenantStatus := func(password string) int {
+		req, err := http.NewRequest("GET", "http://"+st.server.listener.Addr().String()+"/renter/files", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("User-Agent", "Sia-Agent")
+		req.SetBasicAuth("alice", password)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if status := tenantStatus("foo"); status != http.StatusOK {
+		t.Fatal("tenant could not authenticate:", status)
+	}
+	for i := 0; ; i++ {
+		status := tenantStatus("wrong")
+		if status == http.StatusTooManyRequests {
+			break
+		} else if status != http.StatusUnauthorized || i > 10 {
+			t.Fatal("expected the wrong password to be rejected until the tenant is locked out, got", status)
+		}
+	}
+
+	// The recently authenticated credentials are still accepted.
+	if status := tenantStatus("foo"); status != http.StatusOK {
+		t.Fatal("locked out tenant could not use its cached credentials:", status)
+	}
+}
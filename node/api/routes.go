@@ -1,11 +1,13 @@
 package api
 
 import (
+	"context"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/NebulousLabs/Sia/build"
+	"github.com/NebulousLabs/Sia/modules/renter"
 	"github.com/julienschmidt/httprouter"
 )
 
@@ -45,13 +47,23 @@ func (api *API) buildHTTPRoutes(requiredUserAgent string, requiredPassword strin
 		router.GET("/host", api.hostHandlerGET)                                                   // Get the host status.
 		router.POST("/host", RequirePassword(api.hostHandlerPOST, requiredPassword))              // Change the settings of the host.
 		router.POST("/host/announce", RequirePassword(api.hostAnnounceHandler, requiredPassword)) // Announce the host to the network.
+		router.GET("/host/bandwidth", api.hostBandwidthHandlerGET)                                // Get the RPC traffic of the host.
 		router.GET("/host/contracts", api.hostContractInfoHandler)                                // Get info about contracts.
+		router.GET("/host/contracts/:id", api.hostContractHandlerGET)                             // Get the details of a contract.
 		router.GET("/host/estimatescore", api.hostEstimateScoreGET)
+		router.GET("/host/ledger", api.hostLedgerHandlerGET)
+		router.GET("/host/maintenance", api.hostMaintenanceHandlerGET)
+		router.POST("/host/maintenance", RequirePassword(api.hostMaintenanceHandlerPOST, requiredPassword))
+		router.POST("/host/maintenance/cancel", RequirePassword(api.hostMaintenanceCancelHandler, requiredPassword))
+		router.GET("/host/policy", api.hostPolicyHandlerGET)
+		router.POST("/host/policy", RequirePassword(api.hostPolicyHandlerPOST, requiredPassword))
+		router.GET("/host/proofs", api.hostProofsHandlerGET)
 
 		// Calls pertaining to the storage manager that the host uses.
 		router.GET("/host/storage", api.storageHandler)
 		router.POST("/host/storage/folders/add", RequirePassword(api.storageFoldersAddHandler, requiredPassword))
 		router.POST("/host/storage/folders/remove", RequirePassword(api.storageFoldersRemoveHandler, requiredPassword))
+		router.POST("/host/storage/folders/move", RequirePassword(api.storageFoldersMoveHandler, requiredPassword))
 		router.POST("/host/storage/folders/resize", RequirePassword(api.storageFoldersResizeHandler, requiredPassword))
 		router.POST("/host/storage/sectors/delete/:merkleroot", RequirePassword(api.storageSectorsDeleteHandler, requiredPassword))
 	}
@@ -69,12 +81,24 @@ func (api *API) buildHTTPRoutes(requiredUserAgent string, requiredPassword strin
 	if api.renter != nil {
 		router.GET("/renter", api.renterHandlerGET)
 		router.POST("/renter", RequirePassword(api.renterHandlerPOST, requiredPassword))
+		router.GET("/renter/alerts", api.renterAlertsHandler)
 		router.GET("/renter/contracts", api.renterContractsHandler)
-		router.GET("/renter/downloads", api.renterDownloadsHandler)
+		router.POST("/renter/contracts/cancel", RequirePassword(api.renterContractCancelHandler, requiredPassword))
+		router.POST("/renter/contracts/form", RequirePassword(api.renterContractFormHandler, requiredPassword))
+		router.POST("/renter/contracts/renew", RequirePassword(api.renterContractRenewHandler, requiredPassword))
+		router.POST("/renter/contracts/stoprenewing", RequirePassword(api.renterContractStopRenewingHandler, requiredPassword))
+		router.GET("/renter/downloads", api.requireTenantOrPassword(api.renterDownloadsHandler, requiredPassword, true))
 		router.POST("/renter/downloads/clear", RequirePassword(api.renterClearDownloadsHandler, requiredPassword))
-		router.GET("/renter/files", api.renterFilesHandler)
-		router.GET("/renter/file/*siapath", api.renterFileHandler)
+		router.GET("/renter/files", api.requireTenantOrPassword(api.renterFilesHandler, requiredPassword, true))
+		router.GET("/renter/file/*siapath", api.requireTenantOrPassword(api.renterFileHandler, requiredPassword, true))
 		router.GET("/renter/prices", api.renterPricesHandler)
+		router.POST("/renter/quote", RequirePassword(api.renterQuoteHandler, requiredPassword))
+		router.GET("/renter/spending", api.renterSpendingHandler)
+		router.GET("/renter/tenants", RequirePassword(api.renterTenantsHandlerGET, requiredPassword))
+		router.POST("/renter/tenants", RequirePassword(api.renterTenantsHandlerPOST, requiredPassword))
+		router.POST("/renter/tenants/delete", RequirePassword(api.renterTenantsDeleteHandler, requiredPassword))
+		router.GET("/renter/transfers", api.requireTenantOrPassword(api.renterTransfersHandler, requiredPassword, true))
+		router.GET("/renter/workers", api.renterWorkersHandler)
 
 		// TODO: re-enable these routes once the new .sia format has been
 		// standardized and implemented.
@@ -83,12 +107,13 @@ func (api *API) buildHTTPRoutes(requiredUserAgent string, requiredPassword strin
 		// router.GET("/renter/share", RequirePassword(api.renterShareHandler, requiredPassword))
 		// router.GET("/renter/shareascii", RequirePassword(api.renterShareAsciiHandler, requiredPassword))
 
-		router.POST("/renter/delete/*siapath", RequirePassword(api.renterDeleteHandler, requiredPassword))
-		router.GET("/renter/download/*siapath", RequirePassword(api.renterDownloadHandler, requiredPassword))
-		router.GET("/renter/downloadasync/*siapath", RequirePassword(api.renterDownloadAsyncHandler, requiredPassword))
-		router.POST("/renter/rename/*siapath", RequirePassword(api.renterRenameHandler, requiredPassword))
-		router.GET("/renter/stream/*siapath", api.renterStreamHandler)
-		router.POST("/renter/upload/*siapath", RequirePassword(api.renterUploadHandler, requiredPassword))
+		router.POST("/renter/delete/*siapath", api.requireTenantOrPassword(api.renterDeleteHandler, requiredPassword, false))
+		router.GET("/renter/download/*siapath", api.requireTenantOrPassword(api.renterDownloadHandler, requiredPassword, false))
+		router.GET("/renter/downloadasync/*siapath", api.requireTenantOrPassword(api.renterDownloadAsyncHandler, requiredPassword, false))
+		router.POST("/renter/reencode/*siapath", api.requireTenantOrPassword(api.renterReencodeHandler, requiredPassword, false))
+		router.POST("/renter/rename/*siapath", api.requireTenantOrPassword(api.renterRenameHandler, requiredPassword, false))
+		router.GET("/renter/stream/*siapath", api.requireTenantOrPassword(api.renterStreamHandler, requiredPassword, true))
+		router.POST("/renter/upload/*siapath", api.requireTenantOrPassword(api.renterUploadHandler, requiredPassword, false))
 
 		// HostDB endpoints.
 		router.GET("/hostdb", api.hostdbHandler)
@@ -199,6 +224,38 @@ func RequirePassword(h httprouter.Handle, password string) httprouter.Handle {
 	}
 }
 
+// requireTenantOrPassword is middleware for the renter's file endpoints.
+// Requests that authenticate with the name and password of a renter tenant
+// are handled on behalf of the tenant, which restricts them to the tenant's
+// files. Other requests are handled like RequirePassword, except that public
+// endpoints don't require authentication as long as the renter has no
+// tenants.
+func (api *API) requireTenantOrPassword(h httprouter.Handle, password string, public bool) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		user, pass, ok := req.BasicAuth()
+		if ok && user != "" {
+			err := api.renter.AuthenticateTenant(user, pass)
+			if err == nil {
+				ctx := context.WithValue(req.Context(), tenantContextKey{}, user)
+				h(w, req.WithContext(ctx), ps)
+				return
+			} else if err == renter.ErrTenantAuthLockout {
+				WriteError(w, Error{"API authentication failed: " + err.Error()}, http.StatusTooManyRequests)
+				return
+			} else if err != renter.ErrUnknownTenant {
+				w.Header().Set("WWW-Authenticate", "Basic realm=\"SiaAPI\"")
+				WriteError(w, Error{"API authentication failed."}, http.StatusUnauthorized)
+				return
+			}
+		}
+		if public && len(api.renter.Tenants()) == 0 {
+			h(w, req, ps)
+			return
+		}
+		RequirePassword(h, password)(w, req, ps)
+	}
+}
+
 // isUnrestricted checks if a request may bypass the useragent check.
 func isUnrestricted(req *http.Request) bool {
 	return strings.HasPrefix(req.URL.Path, "/renter/stream/")
@@ -285,15 +285,15 @@ func New(params NodeParams) (*Node, error) {
 			return nil, err
 		}
 		// ContractSet
-		contractSet, err := proto.NewContractSet(filepath.Join(persistDir, "contracts"), contractSetDeps)
+		logger, err := persist.NewFileLogger(filepath.Join(persistDir, "contractor.log"))
 		if err != nil {
 			return nil, err
 		}
-		// Contractor
-		logger, err := persist.NewFileLogger(filepath.Join(persistDir, "contractor.log"))
+		contractSet, err := proto.NewContractSet(filepath.Join(persistDir, "contracts"), logger, contractSetDeps)
 		if err != nil {
 			return nil, err
 		}
+		// Contractor
 		hc, err := contractor.NewCustomContractor(cs, &contractor.WalletBridge{W: w}, tp, hdb, contractSet, contractor.NewPersist(persistDir), logger, contractorDeps)
 		if err != nil {
 			return nil, err
@@ -304,6 +304,12 @@ func New(params NodeParams) (*Node, error) {
 		return nil, errors.Extend(err, errors.New("unable to create renter"))
 	}
 
+	// The host's pricing autopilot follows the prices in the renter's hostdb.
+	// Without a renter, it asks the hosts announced in the blockchain.
+	if hostImpl, ok := h.(*host.Host); ok && r != nil {
+		hostImpl.SetPriceSource(r)
+	}
+
 	// Miner.
 	m, err := func() (modules.TestMiner, error) {
 		if params.CreateMiner && params.Miner != nil {
@@ -0,0 +1,244 @@
+package persist
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+)
+
+type (
+	// A JSONLog is an append-only log of JSON objects, one per line. Records
+	// are appended and read without holding the log in memory. The log is
+	// compacted to its limit and retention once it holds twice as many
+	// records as its limit, or once its oldest record is twice as old as its
+	// retention, so that it never grows without bound.
+	JSONLog struct {
+		count  int       // number of records in the file
+		oldest time.Time // time of the oldest record in the file
+		opts   JSONLogOptions
+		file   *os.File
+		path   string
+		mu     sync.Mutex
+	}
+
+	// JSONLogOptions set the bounds of a JSONLog.
+	JSONLogOptions struct {
+		// Limit is the number of most recent records that are kept when the
+		// log is compacted. Zero keeps all records.
+		Limit int
+
+		// Retention is how long records are kept when the log is compacted.
+		// Zero keeps records forever.
+		Retention time.Duration
+
+		// Timestamp returns the time of a record. It must be set if Retention
+		// is set.
+		Timestamp func(record []byte) time.Time
+	}
+)
+
+// OpenJSONLog opens the log at the given path, creating it if it doesn't
+// exist yet.
+func OpenJSONLog(path string, opts JSONLogOptions) (*JSONLog, error) {
+	if opts.Retention != 0 && opts.Timestamp == nil {
+		build.Critical("JSONLog with a retention needs a Timestamp function")
+	}
+	l := &JSONLog{
+		opts: opts,
+		path: path,
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	l.file = f
+
+	// If the last record was only partially written, terminate it so that
+	// the next record starts on a new line.
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, build.ComposeErrors(err, f.Close())
+	}
+	if stat.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, stat.Size()-1); err != nil {
+			return nil, build.ComposeErrors(err, f.Close())
+		}
+		if last[0] != '\n' {
+			if _, err := f.Write([]byte{'\n'}); err != nil {
+				return nil, build.ComposeErrors(err, f.Close())
+			}
+		}
+	}
+
+	err = l.forEach(func(record []byte) {
+		l.count++
+		l.updateOldest(record)
+	})
+	if err == nil && l.needsCompaction() {
+		err = l.rewrite(nil)
+	}
+	if err != nil {
+		return nil, build.ComposeErrors(err, f.Close())
+	}
+	return l, nil
+}
+
+// updateOldest updates the time of the oldest record with the time of a
+// record. The log needs to be locked.
+func (l *JSONLog) updateOldest(record []byte) {
+	if l.opts.Retention == 0 {
+		return
+	}
+	if t := l.opts.Timestamp(record); l.oldest.IsZero() || t.Before(l.oldest) {
+		l.oldest = t
+	}
+}
+
+// needsCompaction returns whether the log has outgrown its limit or its
+// retention. The log needs to be locked.
+func (l *JSONLog) needsCompaction() bool {
+	if l.opts.Limit > 0 && l.count >= 2*l.opts.Limit {
+		return true
+	}
+	return l.opts.Retention > 0 && !l.oldest.IsZero() && time.Since(l.oldest) > 2*l.opts.Retention
+}
+
+// forEach calls fn for every record of the log, oldest first. Lines that are
+// not valid JSON were only partially written before an unclean shutdown, and
+// are skipped. The log needs to be locked once it has been opened.
+func (l *JSONLog) forEach(fn func(record []byte)) error {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rd := bufio.NewReader(f)
+	for {
+		line, err := rd.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return build.ExtendErr("unable to read log "+l.path, err)
+		}
+		line = bytes.TrimSpace(line)
+		if !json.Valid(line) {
+			continue
+		}
+		fn(line)
+	}
+	return nil
+}
+
+// rewrite replaces the log with the most recent records within the limit and
+// retention of the log for which keep returns true. A nil keep keeps every
+// record. The log needs to be locked.
+func (l *JSONLog) rewrite(keep func(record []byte) bool) error {
+	cutoff := time.Now().Add(-l.opts.Retention)
+	var kept [][]byte
+	err := l.forEach(func(record []byte) {
+		if keep != nil && !keep(record) {
+			return
+		}
+		if l.opts.Retention > 0 && l.opts.Timestamp(record).Before(cutoff) {
+			return
+		}
+		kept = append(kept, record)
+	})
+	if err != nil {
+		return err
+	}
+	if l.opts.Limit > 0 && len(kept) > l.opts.Limit {
+		kept = kept[len(kept)-l.opts.Limit:]
+	}
+
+	sf, err := NewSafeFile(l.path)
+	if err != nil {
+		return err
+	}
+	defer sf.Close()
+	w := bufio.NewWriter(sf)
+	for _, record := range kept {
+		w.Write(record)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if err := sf.CommitSync(); err != nil {
+		return err
+	}
+
+	// Reopen the new file for appending.
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	err = l.file.Close()
+	l.file = f
+	l.count = len(kept)
+	l.oldest = time.Time{}
+	for _, record := range kept {
+		l.updateOldest(record)
+	}
+	return err
+}
+
+// Append adds a record to the log, compacting the log if it has outgrown its
+// limit or its retention.
+func (l *JSONLog) Append(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.file.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	l.count++
+	if l.oldest.IsZero() {
+		l.updateOldest(b)
+	}
+	if l.needsCompaction() {
+		return l.rewrite(nil)
+	}
+	return nil
+}
+
+// Close closes the log.
+func (l *JSONLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
+
+// Compact rewrites the log with the records for which keep returns true,
+// applying the limit and retention of the log.
+func (l *JSONLog) Compact(keep func(record []byte) bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rewrite(keep)
+}
+
+// ForEach calls fn for every record of the log, oldest first. Records that
+// were only partially written before an unclean shutdown are skipped.
+func (l *JSONLog) ForEach(fn func(record []byte)) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.forEach(fn)
+}
+
+// Len returns the number of records in the log.
+func (l *JSONLog) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.count
+}
@@ -0,0 +1,144 @@
+package persist
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/NebulousLabs/Sia/build"
+)
+
+// testLogRecord is the record type of the JSONLog tests.
+type testLogRecord struct {
+	N    int       `json:"n"`
+	Time time.Time `json:"time"`
+}
+
+// testLogRecordTime returns the time of an encoded testLogRecord.
+func testLogRecordTime(record []byte) time.Time {
+	var r testLogRecord
+	json.Unmarshal(record, &r)
+	return r.Time
+}
+
+// readTestLog returns the records of a JSONLog, oldest first.
+func readTestLog(t *testing.T, l *JSONLog) []testLogRecord {
+	var records []testLogRecord
+	err := l.ForEach(func(record []byte) {
+		var r testLogRecord
+		if err := json.Unmarshal(record, &r); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, r)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+// TestJSONLog checks that a JSONLog survives an unclean shutdown and is
+// compacted to its limit and retention.
+func TestJSONLog(t *testing.T) {
+	dir := build.TempDir(persistDir, t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "log.json")
+	opts := JSONLogOptions{
+		Limit:     10,
+		Retention: time.Hour,
+		Timestamp: testLogRecordTime,
+	}
+	l, err := OpenJSONLog(path, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Append(testLogRecord{N: i, Time: now}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate an unclean shutdown that left a partial record behind. The
+	// partial record should be skipped after reopening the log.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte(`{"n":`)); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	l, err = OpenJSONLog(path, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Append(testLogRecord{N: 3, Time: now}); err != nil {
+		t.Fatal(err)
+	}
+	if records := readTestLog(t, l); len(records) != 4 || l.Len() != 4 || records[3].N != 3 {
+		t.Fatal("wrong records after reopening:", records, l.Len())
+	}
+
+	// Compact removes the records that aren't kept.
+	err = l.Compact(func(record []byte) bool {
+		var r testLogRecord
+		return json.Unmarshal(record, &r) == nil && r.N != 0
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records := readTestLog(t, l); len(records) != 3 || records[0].N != 1 {
+		t.Fatal("wrong records after compaction:", records)
+	}
+
+	// The log is compacted to the most recent records once it holds twice
+	// its limit.
+	for i := 4; l.Len() < 2*opts.Limit-1; i++ {
+		if err := l.Append(testLogRecord{N: i, Time: now}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Append(testLogRecord{N: 100, Time: now}); err != nil {
+		t.Fatal(err)
+	}
+	records := readTestLog(t, l)
+	if len(records) != opts.Limit || l.Len() != opts.Limit || records[opts.Limit-1].N != 100 {
+		t.Fatal("log was not compacted to its limit:", records, l.Len())
+	}
+
+	// A record that is twice as old as the retention triggers a compaction
+	// that removes every record older than the retention.
+	if err := l.Compact(func([]byte) bool { return false }); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Append(testLogRecord{N: 0, Time: now.Add(-3 * opts.Retention)}); err != nil {
+		t.Fatal(err)
+	}
+	if l.Len() != 0 {
+		t.Fatal("expired record was not removed:", readTestLog(t, l))
+	}
+	l.Append(testLogRecord{N: 1, Time: now.Add(-opts.Retention / 2)})
+	l.Append(testLogRecord{N: 2, Time: now})
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopening the log with a shorter retention compacts it right away.
+	opts.Retention = opts.Retention / 8
+	l, err = OpenJSONLog(path, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	if records := readTestLog(t, l); len(records) != 1 || records[0].N != 2 {
+		t.Fatal("log was not compacted when opened:", records)
+	}
+}